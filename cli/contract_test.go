@@ -672,6 +672,27 @@ func TestComlileAndInvokeFunction(t *testing.T) {
 	require.Len(t, res.Stack, 1)
 	require.Equal(t, []byte("on create|sub create"), res.Stack[0].Value())
 
+	t.Run("pretty", func(t *testing.T) {
+		cmd := []string{"neo-go", "contract", "testinvokefunction",
+			"--rpc-endpoint", "http://" + e.RPC.Addr, "--pretty"}
+		e.Run(t, append(cmd, h.StringLE(), "getValue")...)
+		e.checkNextLine(t, "^State: HALT")
+		e.checkNextLine(t, "^GAS consumed: ")
+		e.checkNextLine(t, "^Stack:")
+		e.checkNextLine(t, `^0: "on create\|sub create"`)
+		e.checkEOF(t)
+
+		e.Run(t, append(cmd, "--type", "bytes", h.StringLE(), "getValue")...)
+		e.checkNextLine(t, "^State: HALT")
+		e.checkNextLine(t, "^GAS consumed: ")
+		e.checkNextLine(t, "^Stack:")
+		e.checkNextLine(t, "^0: "+hex.EncodeToString([]byte("on create|sub create")))
+		e.checkEOF(t)
+
+		e.RunWithError(t, append(cmd, "--type", "array<", h.StringLE(), "getValue")...)
+		e.RunWithError(t, append(cmd, "--type", "publickey", h.StringLE(), "getValue")...)
+	})
+
 	// deploy verification contract
 	hVerify := deployVerifyContract(t, e)
 
@@ -911,6 +932,185 @@ func TestContractInspect(t *testing.T) {
 		e.Run(t, append(cmd, "--in", nefName)...)
 		require.True(t, strings.Contains(e.Out.String(), "SYSCALL"))
 	})
+	t.Run("with manifest", func(t *testing.T) {
+		e.RunWithError(t, append(cmd, "--in", nefName, "--manifest", filepath.Join(tmpDir, "not.exists"))...)
+		e.Run(t, append(cmd, "--in", nefName, "--manifest", manifestName)...)
+		require.True(t, strings.Contains(e.Out.String(), "SYSCALL"))
+		require.False(t, strings.Contains(e.Out.String(), "error:"))
+	})
+}
+
+func TestContractCustomStandards(t *testing.T) {
+	e := newExecutor(t, false)
+
+	// For proper nef generation.
+	config.Version = "0.90.0-test"
+	const (
+		srcPath = "testdata/verify.go"
+		cfgPath = "testdata/standards/neo-go.yml"
+		stdPath = "testdata/standards/verifiable.yml"
+		badPath = "testdata/standards/invalid.json"
+	)
+	tmpDir := t.TempDir()
+	nefName := filepath.Join(tmpDir, "verify.nef")
+	manifestName := filepath.Join(tmpDir, "verify.manifest.json")
+
+	cmd := []string{"neo-go", "contract", "compile",
+		"--in", srcPath, "--config", cfgPath,
+		"--out", nefName, "--manifest", manifestName}
+	t.Run("missing definition file", func(t *testing.T) {
+		e.RunWithError(t, append(cmd, "--standard", filepath.Join(tmpDir, "not.exists"))...)
+	})
+	t.Run("not compliant", func(t *testing.T) {
+		e.RunWithError(t, append(cmd, "--standard", badPath)...)
+	})
+	e.Run(t, append(cmd, "--standard", stdPath)...)
+
+	cmd = []string{"neo-go", "contract", "inspect", "--in", nefName, "--manifest", manifestName}
+	t.Run("inspect, unknown standard", func(t *testing.T) {
+		e.Run(t, cmd...)
+		out := e.Out.String()
+		require.True(t, strings.Contains(out, "standard 'NEP-X': unknown"))
+		require.True(t, strings.Contains(out, "standard 'NEP-27': OK"))
+	})
+	t.Run("inspect, bad definition", func(t *testing.T) {
+		e.Run(t, append(cmd, "--standard", badPath)...)
+		require.True(t, strings.Contains(e.Out.String(), "error: manifest is not compliant with 'NEP-X'"))
+	})
+	t.Run("inspect", func(t *testing.T) {
+		e.Run(t, append(cmd, "--standard", stdPath)...)
+		require.True(t, strings.Contains(e.Out.String(), "standard 'NEP-X': OK"))
+	})
+}
+
+func TestContractDisasm(t *testing.T) {
+	e := newExecutor(t, false)
+
+	// For proper nef generation.
+	config.Version = "0.90.0-test"
+	const srcPath = "testdata/deploy/main.go"
+	tmpDir := t.TempDir()
+
+	nefName := filepath.Join(tmpDir, "deploy.nef")
+	manifestName := filepath.Join(tmpDir, "deploy.manifest.json")
+	e.Run(t, "neo-go", "contract", "compile",
+		"--in", srcPath,
+		"--config", "testdata/deploy/neo-go.yml",
+		"--out", nefName, "--manifest", manifestName)
+
+	cmd := []string{"neo-go", "contract", "disasm"}
+	t.Run("missing input", func(t *testing.T) {
+		e.RunWithError(t, cmd...)
+	})
+	t.Run("invalid input", func(t *testing.T) {
+		e.RunWithError(t, append(cmd, "--in", srcPath)...)
+		e.RunWithError(t, append(cmd, "--in", filepath.Join(tmpDir, "not.exists"))...)
+		e.RunWithError(t, append(cmd, "--in", nefName, "--manifest", filepath.Join(tmpDir, "not.exists"))...)
+		e.RunWithError(t, append(cmd, "--in", nefName, "--format", "xml")...)
+	})
+	t.Run("compile", func(t *testing.T) {
+		e.Run(t, append(cmd, "--in", srcPath, "--compile")...)
+		require.True(t, strings.Contains(e.Out.String(), "; method sub_0"))
+	})
+	t.Run("text", func(t *testing.T) {
+		e.Run(t, append(cmd, "--in", nefName, "--manifest", manifestName)...)
+		out := e.Out.String()
+		require.True(t, strings.Contains(out, "; method getValue"))
+		require.True(t, strings.Contains(out, "System.Storage.Get"))
+	})
+	t.Run("json", func(t *testing.T) {
+		e.Run(t, append(cmd, "--in", nefName, "--manifest", manifestName, "--format", "json")...)
+		var d vm.Disassembly
+		require.NoError(t, json.Unmarshal(e.Out.Bytes(), &d))
+		require.NotEqual(t, 0, len(d.Methods))
+	})
+	t.Run("dot to file", func(t *testing.T) {
+		out := filepath.Join(tmpDir, "deploy.dot")
+		e.Run(t, append(cmd, "--in", nefName, "--manifest", manifestName, "--format", "dot", "--out", out)...)
+		data, err := os.ReadFile(out)
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(string(data), "digraph script {"))
+	})
+}
+
+func TestContractVerify(t *testing.T) {
+	e := newExecutor(t, true)
+
+	// For proper nef generation.
+	config.Version = "0.90.0-test"
+	const (
+		srcPath = "testdata/verify.go"
+		cfgPath = "testdata/verify.yml"
+	)
+	h := deployVerifyContract(t, e)
+
+	tmpDir := t.TempDir()
+	nefName := filepath.Join(tmpDir, "verify.nef")
+	manifestName := filepath.Join(tmpDir, "verify.manifest.json")
+	e.Run(t, "neo-go", "contract", "compile",
+		"--in", srcPath, "--config", cfgPath,
+		"--out", nefName, "--manifest", manifestName)
+
+	cmd := []string{"neo-go", "contract", "verify"}
+	t.Run("missing input", func(t *testing.T) {
+		e.RunWithError(t, append(cmd, "--config", cfgPath, "--nef", nefName, "--manifest", manifestName)...)
+	})
+	t.Run("missing config", func(t *testing.T) {
+		e.RunWithError(t, append(cmd, "--in", srcPath, "--nef", nefName, "--manifest", manifestName)...)
+	})
+	cmd = append(cmd, "--in", srcPath, "--config", cfgPath)
+	t.Run("invalid optimization level", func(t *testing.T) {
+		e.RunWithError(t, append(cmd, "--optimize", "3", "--nef", nefName, "--manifest", manifestName)...)
+	})
+	t.Run("missing manifest", func(t *testing.T) {
+		e.RunWithError(t, append(cmd, "--nef", nefName)...)
+	})
+	t.Run("both hash and files", func(t *testing.T) {
+		e.RunWithError(t, append(cmd, "--rpc-endpoint", "http://"+e.RPC.Addr,
+			"--nef", nefName, "--manifest", manifestName, h.StringLE())...)
+	})
+	t.Run("invalid hash", func(t *testing.T) {
+		e.RunWithError(t, append(cmd, "--rpc-endpoint", "http://"+e.RPC.Addr, "not-a-hash")...)
+	})
+	t.Run("unknown contract", func(t *testing.T) {
+		e.RunWithError(t, append(cmd, "--rpc-endpoint", "http://"+e.RPC.Addr, util.Uint160{1, 2, 3}.StringLE())...)
+	})
+	t.Run("local files", func(t *testing.T) {
+		e.Run(t, append(cmd, "--nef", nefName, "--manifest", manifestName)...)
+		e.checkNextLine(t, "Contract matches the source code")
+	})
+	t.Run("deployed", func(t *testing.T) {
+		e.Run(t, append(cmd, "--rpc-endpoint", "http://"+e.RPC.Addr, h.StringLE())...)
+		e.checkNextLine(t, "Contract matches the source code")
+	})
+	t.Run("different compiler", func(t *testing.T) {
+		config.Version = "0.91.0-test"
+		defer func() { config.Version = "0.90.0-test" }()
+		e.RunWithError(t, append(cmd, "--nef", nefName, "--manifest", manifestName)...)
+	})
+	t.Run("different source", func(t *testing.T) {
+		e.RunWithError(t, "neo-go", "contract", "verify",
+			"--in", "testdata/deploy/main.go", "--config", cfgPath,
+			"--nef", nefName, "--manifest", manifestName)
+		require.True(t, strings.Contains(e.Out.String(), "--- compiled.nef"))
+	})
+	t.Run("different manifest", func(t *testing.T) {
+		data, err := os.ReadFile(manifestName)
+		require.NoError(t, err)
+		m := new(manifest.Manifest)
+		require.NoError(t, json.Unmarshal(data, m))
+		m.Name = "changed"
+		data, err = json.Marshal(m)
+		require.NoError(t, err)
+		changed := filepath.Join(tmpDir, "changed.manifest.json")
+		require.NoError(t, os.WriteFile(changed, data, os.ModePerm))
+
+		e.RunWithError(t, append(cmd, "--nef", nefName, "--manifest", changed)...)
+		out := e.Out.String()
+		require.False(t, strings.Contains(out, "--- compiled.nef"))
+		require.True(t, strings.Contains(out, "--- compiled.manifest.json"))
+		require.True(t, strings.Contains(out, `+  "name": "changed",`))
+	})
 }
 
 func TestCompileExamples(t *testing.T) {
@@ -981,6 +1181,37 @@ func TestCompileExamples(t *testing.T) {
 	})
 }
 
+func TestContractCompileErrors(t *testing.T) {
+	tmpDir := t.TempDir()
+	e := newExecutor(t, false)
+	cmd := []string{"neo-go", "contract", "compile",
+		"--in", "./testdata/compileerrors",
+		"--out", filepath.Join(tmpDir, "out.nef"),
+	}
+
+	t.Run("text", func(t *testing.T) {
+		e.RunWithError(t, cmd...)
+	})
+	t.Run("JSON", func(t *testing.T) {
+		e.RunWithError(t, append(cmd, "--json")...)
+
+		var diags []struct {
+			File    string `json:"file"`
+			Line    int    `json:"line"`
+			Column  int    `json:"column"`
+			Message string `json:"message"`
+		}
+		require.NoError(t, json.Unmarshal(e.Out.Bytes(), &diags))
+		require.Equal(t, 2, len(diags))
+		for i, line := range []int{9, 13} {
+			require.Equal(t, "main.go", filepath.Base(diags[i].File))
+			require.Equal(t, line, diags[i].Line)
+		}
+		require.Equal(t, "subslices are supported only for []byte", diags[0].Message)
+		require.Equal(t, "taking address of global variable a is not supported", diags[1].Message)
+	})
+}
+
 func filterFilename(infos []os.DirEntry, ext string) string {
 	for _, info := range infos {
 		if !info.IsDir() {
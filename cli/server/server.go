@@ -581,6 +581,7 @@ func initBlockChain(cfg config.Config, log *zap.Logger) (*core.Blockchain, error
 	if err != nil {
 		return nil, cli.NewExitError(fmt.Errorf("could not initialize blockchain: %w", err), 1)
 	}
+	chain.SetMemPoolConfig(cfg.ApplicationConfiguration.MemPool)
 	return chain, nil
 }
 
@@ -0,0 +1,106 @@
+package smartcontract
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/nspcc-dev/neo-go/pkg/compiler"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/manifest"
+	"github.com/nspcc-dev/neo-go/pkg/vm"
+	"github.com/urfave/cli"
+)
+
+var disasmCmd = cli.Command{
+	Name:      "disasm",
+	Usage:     "disassemble the contract into basic blocks and build its control flow graph",
+	UsageText: "neo-go contract disasm -i contract.nef [-m contract.manifest.json] [--format text|json|dot] [-o file]",
+	Description: `Disassembles given NEF file (or Go source with --compile) splitting it into
+   basic blocks connected by jumps, calls and exception handlers. If manifest is
+   provided, its ABI is used to annotate method boundaries, otherwise all methods
+   are named after their offsets. Output format is one of 'text' (default),
+   'json' or 'dot' (Graphviz).
+`,
+	Action: contractDisasm,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "in, i",
+			Usage: "input file of the program (either .go or .nef)",
+		},
+		cli.BoolFlag{
+			Name:  "compile, c",
+			Usage: "compile input file (it should be go code then)",
+		},
+		cli.StringFlag{
+			Name:  "manifest, m",
+			Usage: "manifest file (*.manifest.json) to get method names from",
+		},
+		cli.StringFlag{
+			Name:  "format, f",
+			Value: "text",
+			Usage: "output format: 'text', 'json' or 'dot'",
+		},
+		cli.StringFlag{
+			Name:  "out, o",
+			Usage: "output file (standard output is used if not specified)",
+		},
+	},
+}
+
+func contractDisasm(ctx *cli.Context) error {
+	in := ctx.String("in")
+	if len(in) == 0 {
+		return cli.NewExitError(errNoInput, 1)
+	}
+	var (
+		script []byte
+		m      *manifest.Manifest
+		err    error
+	)
+	if ctx.Bool("compile") {
+		script, err = compiler.Compile(in, nil)
+		if err != nil {
+			return cli.NewExitError(fmt.Errorf("failed to compile: %w", err), 1)
+		}
+	} else {
+		nefFile, _, err := readNEFFile(in)
+		if err != nil {
+			return cli.NewExitError(fmt.Errorf("failed to read .nef file: %w", err), 1)
+		}
+		script = nefFile.Script
+	}
+	if mpath := ctx.String("manifest"); mpath != "" {
+		m, _, err = readManifest(mpath)
+		if err != nil {
+			return cli.NewExitError(fmt.Errorf("failed to read manifest file: %w", err), 1)
+		}
+	}
+
+	d, err := vm.Disassemble(script, m)
+	if err != nil {
+		return cli.NewExitError(fmt.Errorf("failed to disassemble: %w", err), 1)
+	}
+
+	w := ctx.App.Writer
+	if out := ctx.String("out"); out != "" {
+		f, err := os.Create(out)
+		if err != nil {
+			return cli.NewExitError(fmt.Errorf("can't create output file: %w", err), 1)
+		}
+		defer f.Close()
+		w = f
+	}
+	switch format := ctx.String("format"); format {
+	case "text":
+		err = d.WriteText(w)
+	case "json":
+		err = d.WriteJSON(w)
+	case "dot":
+		err = d.WriteDOT(w)
+	default:
+		return cli.NewExitError(fmt.Errorf("unknown output format: %s", format), 1)
+	}
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	return nil
+}
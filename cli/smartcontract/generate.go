@@ -1,8 +1,13 @@
 package smartcontract
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"go/parser"
+	"go/token"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/nspcc-dev/neo-go/pkg/smartcontract/binding"
@@ -17,28 +22,55 @@ var generateWrapperCmd = cli.Command{
 	UsageText:   "neo-go contract generate-wrapper --manifest manifest.json --out file.go",
 	Description: ``,
 	Action:      contractGenerateWrapper,
-	Flags: []cli.Flag{
-		cli.StringFlag{
-			Name:  "config, c",
-			Usage: "Configuration file to use",
-		},
-		cli.StringFlag{
-			Name:  "manifest, m",
-			Usage: "Read contract manifest (*.manifest.json) file",
-		},
-		cli.StringFlag{
-			Name:  "out, o",
-			Usage: "Output of the compiled contract",
-		},
-		cli.StringFlag{
-			Name:  "hash",
-			Usage: "Smart-contract hash",
-		},
+	Flags:       generatorFlags,
+}
+
+var generateRPCWrapperCmd = cli.Command{
+	Name:      "generate-rpcwrapper",
+	Usage:     "generate RPC wrapper to use for data reads and transaction creation",
+	UsageText: "neo-go contract generate-rpcwrapper --manifest manifest.json --out file.go --hash hash",
+	Description: `Generates Go package with RPC client bindings for the contract. Safe
+   methods are invoked in test mode via ContractReader and return Go values,
+   other methods create unsigned transactions via Contract. Every event gets a
+   structure, a parser for the notification and a function extracting events
+   from the application log.
+`,
+	Action: contractGenerateRPCWrapper,
+	Flags:  generatorFlags,
+}
+
+var generatorFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "config, c",
+		Usage: "Configuration file to use",
+	},
+	cli.StringFlag{
+		Name:  "manifest, m",
+		Usage: "Read contract manifest (*.manifest.json) file",
+	},
+	cli.StringFlag{
+		Name:  "out, o",
+		Usage: "Output of the compiled contract",
+	},
+	cli.StringFlag{
+		Name:  "hash",
+		Usage: "Smart-contract hash",
 	},
 }
 
-// contractGenerateWrapper deploys contract.
+// contractGenerateWrapper generates contract wrapper to use in other contracts.
 func contractGenerateWrapper(ctx *cli.Context) error {
+	return contractGenerateSomething(ctx, binding.Generate)
+}
+
+// contractGenerateRPCWrapper generates RPC client wrapper for the contract.
+func contractGenerateRPCWrapper(ctx *cli.Context) error {
+	return contractGenerateSomething(ctx, binding.GenerateRPC)
+}
+
+// contractGenerateSomething reads generator configuration and runs the given
+// generator with it.
+func contractGenerateSomething(ctx *cli.Context, gen func(binding.Config) error) error {
 	m, _, err := readManifest(ctx.String("manifest"))
 	if err != nil {
 		return cli.NewExitError(fmt.Errorf("can't read contract manifest: %w", err), 1)
@@ -72,9 +104,97 @@ func contractGenerateWrapper(ctx *cli.Context) error {
 
 	cfg.Output = f
 
-	err = binding.Generate(cfg)
+	err = gen(cfg)
 	if err != nil {
 		return cli.NewExitError(fmt.Errorf("error during generation: %w", err), 1)
 	}
 	return nil
 }
+
+var generateStorageCmd = cli.Command{
+	Name:      "generate-storage",
+	Usage:     "generate typed storage accessors from the storage schema",
+	UsageText: "neo-go contract generate-storage --config contract.yml --out storage.go [--package name]",
+	Description: `Generates unexported get*, put* and delete* functions for every item of
+   the storage schema specified in the contract configuration file. If the
+   package name is not specified, it's taken from other Go files in the output
+   directory.
+`,
+	Action: contractGenerateStorage,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "config, c",
+			Usage: "Configuration input file (*.yml)",
+		},
+		cli.StringFlag{
+			Name:  "out, o",
+			Usage: "Output Go file",
+		},
+		cli.StringFlag{
+			Name:  "package",
+			Usage: "Package name of the generated file",
+		},
+	},
+}
+
+// contractGenerateStorage generates storage accessors.
+func contractGenerateStorage(ctx *cli.Context) error {
+	confFile := ctx.String("config")
+	if confFile == "" {
+		return cli.NewExitError(errNoConfFile, 1)
+	}
+	out := ctx.String("out")
+	if out == "" {
+		return cli.NewExitError(errors.New("no output file was given"), 1)
+	}
+	conf, err := ParseContractConfig(confFile)
+	if err != nil {
+		return err
+	}
+	if len(conf.Storage) == 0 {
+		return cli.NewExitError(errors.New("no storage schema in the configuration file"), 1)
+	}
+
+	pkg := ctx.String("package")
+	if pkg == "" {
+		pkg, err = packageName(filepath.Dir(out), filepath.Base(out))
+		if err != nil {
+			return cli.NewExitError(err, 1)
+		}
+	}
+
+	buf := bytes.NewBuffer(nil)
+	err = binding.GenerateStorage(binding.StorageConfig{
+		Package: pkg,
+		Items:   conf.Storage,
+		Output:  buf,
+	})
+	if err != nil {
+		return cli.NewExitError(fmt.Errorf("error during generation: %w", err), 1)
+	}
+	if err := os.WriteFile(out, buf.Bytes(), os.ModePerm); err != nil {
+		return cli.NewExitError(fmt.Errorf("can't write output file: %w", err), 1)
+	}
+	return nil
+}
+
+// packageName returns the name of the Go package in dir ignoring the file
+// with the specified name.
+func packageName(dir string, skip string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", fmt.Errorf("can't read output directory: %w", err)
+	}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || name == skip || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(token.NewFileSet(), filepath.Join(dir, name), nil, parser.PackageClauseOnly)
+		if err != nil {
+			return "", fmt.Errorf("can't parse %s: %w", name, err)
+		}
+		return f.Name.Name, nil
+	}
+	return "", errors.New("can't determine package name, please specify it explicitly")
+}
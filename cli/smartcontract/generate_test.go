@@ -327,3 +327,181 @@ callflags:
 			"--config", cfgPath)
 	})
 }
+
+func TestGenerateStorage(t *testing.T) {
+	app := cli.NewApp()
+	app.Commands = []cli.Command{generateStorageCmd}
+	app.ExitErrHandler = func(*cli.Context, error) {}
+
+	const schemaDir = "../../pkg/compiler/testdata/schema"
+	expected, err := os.ReadFile(filepath.Join(schemaDir, "storage.go"))
+	require.NoError(t, err)
+
+	t.Run("explicit package", func(t *testing.T) {
+		outFile := filepath.Join(t.TempDir(), "storage.go")
+		require.NoError(t, app.Run([]string{"", "generate-storage",
+			"--config", filepath.Join(schemaDir, "schema.yml"),
+			"--out", outFile,
+			"--package", "schema",
+		}))
+		data, err := os.ReadFile(outFile)
+		require.NoError(t, err)
+		require.Equal(t, string(expected), string(data))
+	})
+	t.Run("package from directory", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package schema\n"), os.ModePerm))
+		outFile := filepath.Join(dir, "storage.go")
+		require.NoError(t, app.Run([]string{"", "generate-storage",
+			"--config", filepath.Join(schemaDir, "schema.yml"),
+			"--out", outFile,
+		}))
+		data, err := os.ReadFile(outFile)
+		require.NoError(t, err)
+		require.Equal(t, string(expected), string(data))
+	})
+
+	checkError := func(t *testing.T, msg string, args ...string) {
+		err := app.Run(append([]string{"", "generate-storage"}, args...))
+		require.Error(t, err)
+		require.True(t, strings.Contains(err.Error(), msg), "got: %v", err)
+	}
+	t.Run("missing config", func(t *testing.T) {
+		checkError(t, errNoConfFile.Error(), "--out", "storage.go")
+	})
+	t.Run("missing output", func(t *testing.T) {
+		checkError(t, "no output file", "--config", filepath.Join(schemaDir, "schema.yml"))
+	})
+	t.Run("unknown package", func(t *testing.T) {
+		checkError(t, "can't determine package name",
+			"--config", filepath.Join(schemaDir, "schema.yml"),
+			"--out", filepath.Join(t.TempDir(), "storage.go"))
+	})
+	t.Run("no schema", func(t *testing.T) {
+		cfgPath := filepath.Join(t.TempDir(), "contract.yml")
+		require.NoError(t, os.WriteFile(cfgPath, []byte("name: test\n"), os.ModePerm))
+		checkError(t, "no storage schema", "--config", cfgPath, "--out", "storage.go")
+	})
+	t.Run("invalid schema", func(t *testing.T) {
+		cfgPath := filepath.Join(t.TempDir(), "contract.yml")
+		require.NoError(t, os.WriteFile(cfgPath, []byte(`storage:
+  - name: a
+    prefix: 1
+  - name: b
+    prefix: 1
+`), os.ModePerm))
+		checkError(t, "prefix conflicts", "--config", cfgPath, "--out", "storage.go", "--package", "foo")
+	})
+}
+
+func TestGenerateRPCWrapper(t *testing.T) {
+	m := manifest.NewManifest("Types")
+	m.ABI.Methods = []manifest.Method{
+		{
+			Name:       manifest.MethodVerify,
+			ReturnType: smartcontract.BoolType,
+			Safe:       true,
+		},
+		{
+			Name: "sum",
+			Parameters: []manifest.Parameter{
+				manifest.NewParameter("a", smartcontract.IntegerType),
+				manifest.NewParameter("b", smartcontract.IntegerType),
+			},
+			ReturnType: smartcontract.IntegerType,
+			Safe:       true,
+		},
+		{
+			Name: "sum", // overloaded method
+			Parameters: []manifest.Parameter{
+				manifest.NewParameter("a", smartcontract.IntegerType),
+				manifest.NewParameter("b", smartcontract.IntegerType),
+				manifest.NewParameter("c", smartcontract.IntegerType),
+			},
+			ReturnType: smartcontract.IntegerType,
+			Safe:       true,
+		},
+		{
+			Name: "keys",
+			Parameters: []manifest.Parameter{
+				manifest.NewParameter("pub", smartcontract.PublicKeyType),
+				manifest.NewParameter("sig", smartcontract.SignatureType),
+			},
+			ReturnType: smartcontract.PublicKeyType,
+			Safe:       true,
+		},
+		{
+			Name: "hashes",
+			Parameters: []manifest.Parameter{
+				manifest.NewParameter("h160", smartcontract.Hash160Type),
+				manifest.NewParameter("h256", smartcontract.Hash256Type),
+			},
+			ReturnType: smartcontract.Hash256Type,
+			Safe:       true,
+		},
+		{
+			Name: "containers",
+			Parameters: []manifest.Parameter{
+				manifest.NewParameter("arr", smartcontract.ArrayType),
+				manifest.NewParameter("m", smartcontract.MapType),
+			},
+			ReturnType: smartcontract.MapType,
+			Safe:       true,
+		},
+		{
+			Name:       "tokens",
+			ReturnType: smartcontract.InteropInterfaceType,
+			Safe:       true,
+		},
+		{
+			Name: "put",
+			Parameters: []manifest.Parameter{
+				manifest.NewParameter("type", smartcontract.StringType),
+				manifest.NewParameter("", smartcontract.ByteArrayType),
+				manifest.NewParameter("c", smartcontract.AnyType),
+			},
+			ReturnType: smartcontract.VoidType,
+		},
+		{
+			Name:       "clean",
+			ReturnType: smartcontract.VoidType,
+			Safe:       true,
+		},
+	}
+	m.ABI.Events = []manifest.Event{
+		{
+			Name: "Put",
+			Parameters: []manifest.Parameter{
+				manifest.NewParameter("key", smartcontract.StringType),
+				manifest.NewParameter("value", smartcontract.AnyType),
+			},
+		},
+		{
+			Name: "Something happened",
+		},
+	}
+
+	manifestFile := filepath.Join(t.TempDir(), "manifest.json")
+	outFile := filepath.Join(t.TempDir(), "out.go")
+	rawManifest, err := json.Marshal(m)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(manifestFile, rawManifest, os.ModePerm))
+
+	h := util.Uint160{
+		0x04, 0x08, 0x15, 0x16, 0x23, 0x42, 0x43, 0x44, 0x00, 0x01,
+		0xCA, 0xFE, 0xBA, 0xBE, 0xDE, 0xAD, 0xBE, 0xEF, 0x03, 0x04,
+	}
+	app := cli.NewApp()
+	app.Commands = []cli.Command{generateRPCWrapperCmd}
+	require.NoError(t, app.Run([]string{"", "generate-rpcwrapper",
+		"--manifest", manifestFile,
+		"--out", outFile,
+		"--hash", "0x" + h.StringLE(),
+	}))
+
+	expected, err := os.ReadFile(filepath.Join("testdata", "rpcwrapper", "types.go"))
+	require.NoError(t, err)
+	data, err := os.ReadFile(outFile)
+	require.NoError(t, err)
+	require.Equal(t, string(expected), string(data))
+}
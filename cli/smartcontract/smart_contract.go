@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -15,6 +16,7 @@ import (
 	"github.com/nspcc-dev/neo-go/cli/options"
 	"github.com/nspcc-dev/neo-go/cli/paramcontext"
 	"github.com/nspcc-dev/neo-go/pkg/compiler"
+	"github.com/nspcc-dev/neo-go/pkg/core/interop"
 	"github.com/nspcc-dev/neo-go/pkg/core/native/nativenames"
 	"github.com/nspcc-dev/neo-go/pkg/core/state"
 	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
@@ -23,7 +25,9 @@ import (
 	"github.com/nspcc-dev/neo-go/pkg/rpc/client"
 	"github.com/nspcc-dev/neo-go/pkg/rpc/response/result"
 	"github.com/nspcc-dev/neo-go/pkg/smartcontract"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/binding"
 	"github.com/nspcc-dev/neo-go/pkg/smartcontract/manifest"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/manifest/standard"
 	"github.com/nspcc-dev/neo-go/pkg/smartcontract/nef"
 	"github.com/nspcc-dev/neo-go/pkg/util"
 	"github.com/nspcc-dev/neo-go/pkg/vm"
@@ -62,10 +66,22 @@ var (
 		Name:  "out",
 		Usage: "file to put JSON transaction to",
 	}
+	standardFlag = cli.StringSliceFlag{
+		Name:  "standard",
+		Usage: "file with user-defined standard definitions (JSON or YAML), can be repeated",
+	}
 	forceFlag = cli.BoolFlag{
 		Name:  "force",
 		Usage: "force-push the transaction in case of bad VM state after test script invocation",
 	}
+	prettyFlag = cli.BoolFlag{
+		Name:  "pretty",
+		Usage: "print invocation result in human-readable form instead of JSON",
+	}
+	typeFlag = cli.StringFlag{
+		Name:  "type, t",
+		Usage: "type of the returned value for --pretty output (method return type from the manifest is used by default)",
+	}
 )
 
 // ModVersion contains `pkg/interop` module version
@@ -110,6 +126,8 @@ func NewCommands() []cli.Command {
 		forceFlag,
 	}
 	invokeFunctionFlags = append(invokeFunctionFlags, options.RPC...)
+	testInvokeFunctionFlags := []cli.Flag{prettyFlag, typeFlag}
+	testInvokeFunctionFlags = append(testInvokeFunctionFlags, options.RPC...)
 	deployFlags := append(invokeFunctionFlags, []cli.Flag{
 		cli.StringFlag{
 			Name:  "in, i",
@@ -165,10 +183,23 @@ func NewCommands() []cli.Command {
 						Name:  "no-permissions",
 						Usage: "do not check if invoked contracts are allowed in manifest",
 					},
+					cli.BoolFlag{
+						Name:  "no-verify",
+						Usage: "do not perform static verification of the resulting script",
+					},
+					cli.BoolFlag{
+						Name:  "json",
+						Usage: "print compilation errors in JSON format",
+					},
+					cli.UintFlag{
+						Name:  "optimize",
+						Usage: "optimization level: 0 (none, default), 1 (peephole and unreachable code removal) or 2 (also constant folding, dead functions removal and local slots renumbering)",
+					},
 					cli.StringFlag{
 						Name:  "bindings",
 						Usage: "output file for smart-contract bindings configuration",
 					},
+					standardFlag,
 				},
 			},
 			{
@@ -183,6 +214,9 @@ func NewCommands() []cli.Command {
 				Flags:  deployFlags,
 			},
 			generateWrapperCmd,
+			generateRPCWrapperCmd,
+			generateStorageCmd,
+			storageCmd,
 			{
 				Name:      "invokefunction",
 				Usage:     "invoke deployed contract on the blockchain",
@@ -200,7 +234,7 @@ func NewCommands() []cli.Command {
 			{
 				Name:      "testinvokefunction",
 				Usage:     "invoke deployed contract on the blockchain (test mode)",
-				UsageText: "neo-go contract testinvokefunction -r endpoint scripthash [method] [arguments...] [--] [signers...]",
+				UsageText: "neo-go contract testinvokefunction -r endpoint [--pretty [--type type]] scripthash [method] [arguments...] [--] [signers...]",
 				Description: `Executes given (as a script hash) deployed script with the given method,
    arguments and signers (sender is not included by default). If no method is given
    "" is passed to the script, if no arguments are given, an empty array is 
@@ -313,9 +347,16 @@ func NewCommands() []cli.Command {
 					`CustomGroups:0206d7495ceb34c197093b5fc1cccf1996ada05e69ef67e765462a7f5d88ee14d0'
     * '0000000009070e030d0f0e020d0c06050e030c02:CalledByEntry,` +
 					`CustomContracts:1011120009070e030d0f0e020d0c06050e030c02:0x1211100009070e030d0f0e020d0c06050e030c02'
+
+   By default invocation result is printed in JSON form as returned by the RPC
+   node. Use --pretty flag to print VM state, consumed GAS and resulting stack
+   items decoded according to the method return type from the contract
+   manifest. The type can also be specified explicitly via --type flag, it can
+   be any parameter type listed above or a composite type like 'array<hash160>'
+   or 'map<string,int>'.
 `,
 				Action: testInvokeFunction,
-				Flags:  options.RPC,
+				Flags:  testInvokeFunctionFlags,
 			},
 			{
 				Name:      "testinvokescript",
@@ -344,8 +385,14 @@ func NewCommands() []cli.Command {
 				},
 			},
 			{
-				Name:   "inspect",
-				Usage:  "creates a user readable dump of the program instructions",
+				Name:  "inspect",
+				Usage: "creates a user readable dump of the program instructions",
+				Description: `Dumps program instructions and performs static verification of the script
+   reporting found problems after the dump. Manifest is used for verification
+   if provided (or generated from the source code if --compile is used).
+   Standards declared in the manifest are checked too, definitions of
+   non-standard ones can be loaded with --standard flag.
+`,
 				Action: inspect,
 				Flags: []cli.Flag{
 					cli.BoolFlag{
@@ -356,8 +403,15 @@ func NewCommands() []cli.Command {
 						Name:  "in, i",
 						Usage: "input file of the program (either .go or .nef)",
 					},
+					cli.StringFlag{
+						Name:  "manifest, m",
+						Usage: "manifest file (*.manifest.json) to use for verification",
+					},
+					standardFlag,
 				},
 			},
+			disasmCmd,
+			verifyCmd,
 			{
 				Name:   "calc-hash",
 				Usage:  "calculates hash of a contract after deployment",
@@ -493,6 +547,11 @@ func contractCompile(ctx *cli.Context) error {
 		return cli.NewExitError(errNoConfFile, 1)
 	}
 
+	optLevel := ctx.Uint("optimize")
+	if optLevel > uint(compiler.OptimizeFull) {
+		return cli.NewExitError(fmt.Errorf("invalid optimization level: %d", optLevel), 1)
+	}
+
 	o := &compiler.Options{
 		Outfile: ctx.String("out"),
 
@@ -503,6 +562,8 @@ func contractCompile(ctx *cli.Context) error {
 		NoStandardCheck:    ctx.Bool("no-standards"),
 		NoEventsCheck:      ctx.Bool("no-events"),
 		NoPermissionsCheck: ctx.Bool("no-permissions"),
+
+		Optimize: compiler.OptimizationLevel(optLevel),
 	}
 
 	if len(confFile) != 0 {
@@ -510,21 +571,23 @@ func contractCompile(ctx *cli.Context) error {
 		if err != nil {
 			return err
 		}
-		o.Name = conf.Name
-		o.SourceURL = conf.SourceURL
-		o.ContractEvents = conf.Events
-		o.ContractSupportedStandards = conf.SupportedStandards
-		o.Permissions = make([]manifest.Permission, len(conf.Permissions))
-		for i := range conf.Permissions {
-			o.Permissions[i] = manifest.Permission(conf.Permissions[i])
-		}
-		o.SafeMethods = conf.SafeMethods
-		o.Overloads = conf.Overloads
+		applyConfig(o, conf)
+	}
+	defs, err := loadStandards(ctx)
+	if err != nil {
+		return err
 	}
+	o.CustomStandards = defs
 
+	if !ctx.Bool("no-verify") {
+		// Verify before saving, so that no unverified outputs are left.
+		o.Verify = func(f *nef.File, m *manifest.Manifest) error {
+			return verifyScript(ctx.App.ErrWriter, f.Script, f.Tokens, m)
+		}
+	}
 	result, err := compiler.CompileAndSave(src, o)
 	if err != nil {
-		return cli.NewExitError(err, 1)
+		return compileError(ctx, err)
 	}
 	if ctx.Bool("verbose") {
 		fmt.Fprintln(ctx.App.Writer, hex.EncodeToString(result))
@@ -533,6 +596,36 @@ func contractCompile(ctx *cli.Context) error {
 	return nil
 }
 
+// compileError formats compilation error. Diagnostics are printed one per
+// line with file paths relative to the current directory (like `go vet` does)
+// or as a JSON array if `--json` flag is set.
+func compileError(ctx *cli.Context, err error) error {
+	var diags compiler.Diagnostics
+	if !errors.As(err, &diags) {
+		if !ctx.Bool("json") {
+			return cli.NewExitError(err, 1)
+		}
+		diags = compiler.Diagnostics{{Err: err}}
+	}
+	if ctx.Bool("json") {
+		data, err := json.Marshal(diags)
+		if err != nil {
+			return cli.NewExitError(err, 1)
+		}
+		fmt.Fprintln(ctx.App.Writer, string(data))
+		return cli.NewExitError("", 1)
+	}
+	if wd, err := os.Getwd(); err == nil {
+		for i := range diags {
+			rel, err := filepath.Rel(wd, diags[i].Pos.Filename)
+			if err == nil && !strings.HasPrefix(rel, "..") {
+				diags[i].Pos.Filename = rel
+			}
+		}
+	}
+	return cli.NewExitError(diags, 1)
+}
+
 func calcHash(ctx *cli.Context) error {
 	sender := ctx.Generic("sender").(*flags.Address)
 	if !sender.IsSet {
@@ -715,6 +808,11 @@ func invokeWithArgs(ctx *cli.Context, acc *wallet.Account, wall *wallet.Wallet,
 			return sender, cli.NewExitError(fmt.Errorf("failed to push invocation tx: %w", err), 1)
 		}
 		fmt.Fprintf(ctx.App.Writer, "Sent invocation transaction %s\n", txHash.StringLE())
+	} else if ctx.Bool("pretty") {
+		err = printPrettyInvoke(ctx, c, script, operation, len(params), resp)
+		if err != nil {
+			return sender, cli.NewExitError(err, 1)
+		}
 	} else {
 		b, err := json.MarshalIndent(resp, "", "  ")
 		if err != nil {
@@ -727,6 +825,46 @@ func invokeWithArgs(ctx *cli.Context, acc *wallet.Account, wall *wallet.Wallet,
 	return sender, nil
 }
 
+// printPrettyInvoke prints invocation result in human-readable form. The last
+// stack item is decoded according to the type specified via --type flag or
+// to the return type of the invoked method if it's present in the contract
+// manifest.
+func printPrettyInvoke(ctx *cli.Context, c *client.Client, script util.Uint160, operation string, paramCount int, resp *result.Invoke) error {
+	var (
+		hint *smartcontract.TypeHint
+		err  error
+	)
+	if typ := ctx.String("type"); typ != "" {
+		hint, err = smartcontract.ParseTypeHint(typ)
+		if err != nil {
+			return fmt.Errorf("invalid type: %w", err)
+		}
+	} else if cs, err := c.GetContractStateByHash(script); err == nil {
+		if md := cs.Manifest.ABI.GetMethod(operation, paramCount); md != nil {
+			hint = smartcontract.NewTypeHint(md.ReturnType)
+		}
+	}
+
+	fmt.Fprintf(ctx.App.Writer, "State: %s\n", resp.State)
+	fmt.Fprintf(ctx.App.Writer, "GAS consumed: %s\n", fixedn.Fixed8(resp.GasConsumed))
+	if resp.FaultException != "" {
+		fmt.Fprintf(ctx.App.Writer, "Exception: %s\n", resp.FaultException)
+	}
+	fmt.Fprintln(ctx.App.Writer, "Stack:")
+	for i, item := range resp.Stack {
+		var h *smartcontract.TypeHint
+		if i == len(resp.Stack)-1 {
+			h = hint
+		}
+		s, err := smartcontract.FormatStackItem(item, h)
+		if err != nil {
+			return fmt.Errorf("failed to decode stack item %d: %w", i, err)
+		}
+		fmt.Fprintf(ctx.App.Writer, "%d: %s\n", i, s)
+	}
+	return nil
+}
+
 func testInvokeScript(ctx *cli.Context) error {
 	src := ctx.String("in")
 	if len(src) == 0 {
@@ -778,7 +916,8 @@ type ProjectConfig struct {
 	SupportedStandards []string
 	Events             []manifest.Event
 	Permissions        []permission
-	Overloads          map[string]string `yaml:"overloads,omitempty"`
+	Overloads          map[string]string     `yaml:"overloads,omitempty"`
+	Storage            []binding.StorageItem `yaml:"storage,omitempty"`
 }
 
 func inspect(ctx *cli.Context) error {
@@ -788,14 +927,21 @@ func inspect(ctx *cli.Context) error {
 		return cli.NewExitError(errNoInput, 1)
 	}
 	var (
-		b   []byte
-		err error
+		b      []byte
+		tokens []nef.MethodToken
+		m      *manifest.Manifest
+		err    error
 	)
 	if compile {
-		b, err = compiler.Compile(in, nil)
+		nefFile, di, err := compiler.CompileWithOptions(in, nil, nil)
 		if err != nil {
 			return cli.NewExitError(fmt.Errorf("failed to compile: %w", err), 1)
 		}
+		b, tokens = nefFile.Script, nefFile.Tokens
+		m, err = di.ConvertToManifest(&compiler.Options{})
+		if err != nil {
+			return cli.NewExitError(fmt.Errorf("failed to create manifest: %w", err), 1)
+		}
 	} else {
 		f, err := os.ReadFile(in)
 		if err != nil {
@@ -805,12 +951,86 @@ func inspect(ctx *cli.Context) error {
 		if err != nil {
 			return cli.NewExitError(fmt.Errorf("failed to restore .nef file: %w", err), 1)
 		}
-		b = nefFile.Script
+		b, tokens = nefFile.Script, nefFile.Tokens
+	}
+	if mpath := ctx.String("manifest"); mpath != "" {
+		m, _, err = readManifest(mpath)
+		if err != nil {
+			return cli.NewExitError(fmt.Errorf("failed to read manifest file: %w", err), 1)
+		}
+	}
+	defs, err := loadStandards(ctx)
+	if err != nil {
+		return err
+	}
+	sts := standard.Builtin()
+	if err := sts.Add(defs...); err != nil {
+		return cli.NewExitError(fmt.Errorf("invalid standard definitions: %w", err), 1)
 	}
 	v := vm.New()
 	v.LoadScript(b)
 	v.PrintOps(ctx.App.Writer)
+	// Verification errors are not fatal here, the dump is useful anyway.
+	_ = verifyScript(ctx.App.Writer, b, tokens, m)
+	if m != nil {
+		checkStandards(ctx.App.Writer, sts, m)
+	}
+
+	return nil
+}
+
+// loadStandards reads user-defined standard definitions from the files
+// specified with --standard flag.
+func loadStandards(ctx *cli.Context) ([]standard.Definition, error) {
+	var defs []standard.Definition
+	for _, path := range ctx.StringSlice("standard") {
+		ds, err := standard.LoadDefinitions(path)
+		if err != nil {
+			return nil, cli.NewExitError(fmt.Errorf("failed to load standard definitions from %s: %w", path, err), 1)
+		}
+		defs = append(defs, ds...)
+	}
+	return defs, nil
+}
 
+// checkStandards checks manifest compliance with the standards declared in it
+// and prints the result for each standard to w.
+func checkStandards(w io.Writer, sts standard.Standards, m *manifest.Manifest) {
+	for _, name := range m.SupportedStandards {
+		if _, ok := sts[name]; !ok {
+			fmt.Fprintf(w, "standard '%s': unknown\n", name)
+			continue
+		}
+		if err := sts.CheckABI(m, name); err != nil {
+			fmt.Fprintf(w, "error: %s\n", err)
+			continue
+		}
+		fmt.Fprintf(w, "standard '%s': OK\n", name)
+	}
+}
+
+// verifyScript performs static verification of the script, prints all issues
+// found to w and returns an error if there are errors among them.
+func verifyScript(w io.Writer, script []byte, tokens []nef.MethodToken, m *manifest.Manifest) error {
+	issues, err := vm.Verify(script, &vm.VerifyOptions{
+		Manifest: m,
+		Tokens:   tokens,
+		Syscalls: interop.GetSyscallDescs(),
+	})
+	if err != nil {
+		fmt.Fprintf(w, "error: %s\n", err)
+		return fmt.Errorf("script verification failed: %w", err)
+	}
+	var errCount int
+	for _, issue := range issues {
+		fmt.Fprintln(w, issue)
+		if issue.Error {
+			errCount++
+		}
+	}
+	if errCount != 0 {
+		return fmt.Errorf("script verification failed: %d error(s) found", errCount)
+	}
 	return nil
 }
 
@@ -0,0 +1,162 @@
+package smartcontract
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/nspcc-dev/neo-go/cli/options"
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neo-go/pkg/rpc/response/result"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/binding"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/vm/stackitem"
+	"github.com/urfave/cli"
+)
+
+var storageCmd = cli.Command{
+	Name:      "storage",
+	Usage:     "decode contract storage using the storage schema",
+	UsageText: "neo-go contract storage -r endpoint -d contract.debug.json --hash <hash> [--height height]",
+	Description: `Fetches all storage items of the contract with 'findstates' RPC call and
+   decodes them according to the storage schema saved into the debug info file
+   by 'compile' command. The latest local state is used unless the height is
+   specified. Items not matching the schema are printed hex-encoded.
+`,
+	Action: contractStorage,
+	Flags: append([]cli.Flag{
+		cli.StringFlag{
+			Name:  "debug, d",
+			Usage: "debug info file (*.json) with the storage schema",
+		},
+		cli.StringFlag{
+			Name:  "hash",
+			Usage: "contract hash",
+		},
+		cli.UintFlag{
+			Name:  "height",
+			Usage: "state height (latest local state by default)",
+		},
+	}, options.RPC...),
+}
+
+func contractStorage(ctx *cli.Context) error {
+	debugFile := ctx.String("debug")
+	if debugFile == "" {
+		return cli.NewExitError(errors.New("no debug info file was given"), 1)
+	}
+	h, err := util.Uint160DecodeStringLE(strings.TrimPrefix(ctx.String("hash"), "0x"))
+	if err != nil {
+		return cli.NewExitError(fmt.Errorf("invalid contract hash: %w", err), 1)
+	}
+	schema, err := readStorageSchema(debugFile)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	gctx, cancel := options.GetTimeoutContext(ctx)
+	defer cancel()
+	c, exitErr := options.GetRPCClient(gctx, ctx)
+	if exitErr != nil {
+		return exitErr
+	}
+	height := uint32(ctx.Uint("height"))
+	if !ctx.IsSet("height") {
+		sh, err := c.GetStateHeight()
+		if err != nil {
+			return cli.NewExitError(fmt.Errorf("failed to get state height: %w", err), 1)
+		}
+		height = sh.Local
+	}
+	root, err := c.GetStateRootByHeight(height)
+	if err != nil {
+		return cli.NewExitError(fmt.Errorf("failed to get state root: %w", err), 1)
+	}
+	var (
+		kvs   []result.KeyValue
+		start []byte
+	)
+	for {
+		res, err := c.FindStates(root.Root, h, nil, start, nil)
+		if err != nil {
+			return cli.NewExitError(fmt.Errorf("failed to find states: %w", err), 1)
+		}
+		kvs = append(kvs, res.Results...)
+		if !res.Truncated || len(res.Results) == 0 {
+			break
+		}
+		start = res.Results[len(res.Results)-1].Key
+	}
+	writeStorage(ctx.App.Writer, schema, kvs)
+	return nil
+}
+
+// readStorageSchema reads the storage schema from the debug info file.
+func readStorageSchema(filename string) ([]binding.StorageItem, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("can't read debug info file: %w", err)
+	}
+	di := new(struct {
+		Storage []binding.StorageItem `json:"storage"`
+	})
+	if err := json.Unmarshal(data, di); err != nil {
+		return nil, fmt.Errorf("can't parse debug info file: %w", err)
+	}
+	if len(di.Storage) == 0 {
+		return nil, errors.New("no storage schema in the debug info file")
+	}
+	return di.Storage, nil
+}
+
+// writeStorage writes storage items decoded according to the schema to w, one
+// item per line.
+func writeStorage(w io.Writer, schema []binding.StorageItem, kvs []result.KeyValue) {
+	for _, kv := range kvs {
+		d, err := binding.DecodeStorage(schema, kv.Key, kv.Value)
+		if err != nil {
+			fmt.Fprintf(w, "%s: %s (%s)\n", hex.EncodeToString(kv.Key), hex.EncodeToString(kv.Value), err)
+			continue
+		}
+		if d.Key != nil {
+			fmt.Fprintf(w, "%s[%s]: %s\n", d.Name, formatStorageValue(d.Key), formatStorageValue(d.Value))
+		} else {
+			fmt.Fprintf(w, "%s: %s\n", d.Name, formatStorageValue(d.Value))
+		}
+	}
+}
+
+// formatStorageValue returns human-readable representation of the value
+// decoded by binding.DecodeStorage.
+func formatStorageValue(v interface{}) string {
+	switch v := v.(type) {
+	case *big.Int:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	case string:
+		return strconv.Quote(v)
+	case []byte:
+		return hex.EncodeToString(v)
+	case util.Uint160:
+		return "0x" + v.StringLE()
+	case util.Uint256:
+		return "0x" + v.StringLE()
+	case *keys.PublicKey:
+		return hex.EncodeToString(v.Bytes())
+	case stackitem.Item:
+		data, err := stackitem.ToJSONWithTypes(v)
+		if err != nil {
+			return fmt.Sprintf("<%s>", v.Type())
+		}
+		return string(data)
+	default:
+		return fmt.Sprint(v)
+	}
+}
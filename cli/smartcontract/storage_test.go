@@ -0,0 +1,57 @@
+package smartcontract
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/rpc/response/result"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/binding"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/vm/stackitem"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteStorage(t *testing.T) {
+	dir := t.TempDir()
+	debugFile := filepath.Join(dir, "contract.debug.json")
+	require.NoError(t, os.WriteFile(debugFile, []byte(`{"storage":[
+		{"name":"owner","prefix":"01","value":"Hash160"},
+		{"name":"balance","prefix":"62","key":"Hash160","value":"Integer"},
+		{"name":"data","prefix":"6461","value":"Array"}
+	]}`), os.ModePerm))
+	schema, err := readStorageSchema(debugFile)
+	require.NoError(t, err)
+	require.Equal(t, []binding.StorageItem{
+		{Name: "owner", Prefix: binding.StoragePrefix{1}, Value: smartcontract.Hash160Type},
+		{Name: "balance", Prefix: binding.StoragePrefix("b"), Key: smartcontract.Hash160Type, Value: smartcontract.IntegerType},
+		{Name: "data", Prefix: binding.StoragePrefix("da"), Value: smartcontract.ArrayType},
+	}, schema)
+
+	h := util.Uint160{1, 2, 3}
+	arr, err := stackitem.Serialize(stackitem.NewArray([]stackitem.Item{stackitem.Make(1)}))
+	require.NoError(t, err)
+	buf := bytes.NewBuffer(nil)
+	writeStorage(buf, schema, []result.KeyValue{
+		{Key: []byte{1}, Value: h.BytesBE()},
+		{Key: append([]byte("b"), h.BytesBE()...), Value: []byte{100}},
+		{Key: []byte("da"), Value: arr},
+		{Key: []byte{0xff}, Value: []byte{1}},
+	})
+	require.Equal(t, "owner: 0x"+h.StringLE()+"\n"+
+		"balance[0x"+h.StringLE()+"]: 100\n"+
+		`data: {"type":"Array","value":[{"type":"Integer","value":"1"}]}`+"\n"+
+		"ff: 01 (storage item doesn't match any schema prefix)\n", buf.String())
+
+	t.Run("no schema", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(debugFile, []byte(`{}`), os.ModePerm))
+		_, err := readStorageSchema(debugFile)
+		require.Error(t, err)
+	})
+	t.Run("missing file", func(t *testing.T) {
+		_, err := readStorageSchema(filepath.Join(dir, "missing.json"))
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,242 @@
+package smartcontract
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/nspcc-dev/neo-go/cli/flags"
+	"github.com/nspcc-dev/neo-go/cli/options"
+	"github.com/nspcc-dev/neo-go/pkg/compiler"
+	"github.com/nspcc-dev/neo-go/pkg/config"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/manifest"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/nef"
+	"github.com/nspcc-dev/neo-go/pkg/vm"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/urfave/cli"
+)
+
+var verifyCmd = cli.Command{
+	Name:      "verify",
+	Usage:     "check that contract matches its source code",
+	UsageText: "neo-go contract verify -i path -c config.yml [--optimize level] {-r endpoint hash | --nef contract.nef -m contract.manifest.json}",
+	Description: `Compiles contract source code from the given file or directory using
+   the configuration file and compares resulting NEF and manifest with the
+   deployed ones. Deployed contract is either fetched from the RPC node by its
+   hash or read from the local NEF and manifest files. The contract must have
+   been compiled with the same compiler version and optimization level.
+   Manifest groups are not checked since they're added after compilation. If
+   anything differs, unified diffs of the contract code and manifest are
+   printed.
+`,
+	Action: contractVerify,
+	Flags: append([]cli.Flag{
+		cli.StringFlag{
+			Name:  "in, i",
+			Usage: "input file or directory with the contract source code",
+		},
+		cli.StringFlag{
+			Name:  "config, c",
+			Usage: "configuration input file (*.yml)",
+		},
+		cli.UintFlag{
+			Name:  "optimize",
+			Usage: "optimization level the contract was compiled with",
+		},
+		cli.StringFlag{
+			Name:  "nef, n",
+			Usage: "path to the NEF file of the deployed contract",
+		},
+		cli.StringFlag{
+			Name:  "manifest, m",
+			Usage: "path to the manifest of the deployed contract",
+		},
+	}, options.RPC...),
+}
+
+func contractVerify(ctx *cli.Context) error {
+	src := ctx.String("in")
+	if len(src) == 0 {
+		return cli.NewExitError(errNoInput, 1)
+	}
+	confFile := ctx.String("config")
+	if len(confFile) == 0 {
+		return cli.NewExitError(errNoConfFile, 1)
+	}
+	optLevel := ctx.Uint("optimize")
+	if optLevel > uint(compiler.OptimizeFull) {
+		return cli.NewExitError(fmt.Errorf("invalid optimization level: %d", optLevel), 1)
+	}
+
+	var (
+		nefFile *nef.File
+		m       *manifest.Manifest
+		err     error
+	)
+	if ctx.NArg() != 0 {
+		if ctx.IsSet("nef") || ctx.IsSet("manifest") {
+			return cli.NewExitError(errors.New("either contract hash or NEF and manifest files should be given"), 1)
+		}
+		h, err := flags.ParseAddress(ctx.Args().First())
+		if err != nil {
+			return cli.NewExitError(fmt.Errorf("invalid contract hash: %w", err), 1)
+		}
+		gctx, cancel := options.GetTimeoutContext(ctx)
+		defer cancel()
+
+		c, exitErr := options.GetRPCClient(gctx, ctx)
+		if exitErr != nil {
+			return exitErr
+		}
+		cs, err := c.GetContractStateByHash(h)
+		if err != nil {
+			return cli.NewExitError(fmt.Errorf("failed to get contract state: %w", err), 1)
+		}
+		nefFile, m = &cs.NEF, &cs.Manifest
+	} else {
+		nefFile, _, err = readNEFFile(ctx.String("nef"))
+		if err != nil {
+			return cli.NewExitError(fmt.Errorf("failed to read .nef file: %w", err), 1)
+		}
+		m, _, err = readManifest(ctx.String("manifest"))
+		if err != nil {
+			return cli.NewExitError(fmt.Errorf("failed to read manifest file: %w", err), 1)
+		}
+	}
+
+	if current := "neo-go-" + config.Version; nefFile.Compiler != current {
+		return cli.NewExitError(fmt.Errorf("contract was compiled with %q, current compiler is %q", nefFile.Compiler, current), 1)
+	}
+
+	conf, err := ParseContractConfig(confFile)
+	if err != nil {
+		return err
+	}
+	o := &compiler.Options{
+		Optimize: compiler.OptimizationLevel(optLevel),
+
+		NoEventsCheck:      true,
+		NoStandardCheck:    true,
+		NoPermissionsCheck: true,
+	}
+	applyConfig(o, conf)
+	expNEF, di, err := compiler.CompileWithOptions(src, nil, o)
+	if err != nil {
+		return compileError(ctx, err)
+	}
+	expNEF.Source = o.SourceURL
+	expNEF.Checksum = expNEF.CalculateChecksum()
+	expM, err := compiler.CreateManifest(di, o)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	expM.Groups = m.Groups
+
+	nefDiff, err := diffNEF(expNEF, expM, nefFile, m)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	mDiff, err := diffManifest(expM, m)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	if nefDiff == "" && mDiff == "" {
+		fmt.Fprintln(ctx.App.Writer, "Contract matches the source code")
+		return nil
+	}
+	fmt.Fprint(ctx.App.Writer, nefDiff+mDiff)
+	return cli.NewExitError("contract doesn't match the source code", 1)
+}
+
+// applyConfig sets compiler options from the contract configuration.
+func applyConfig(o *compiler.Options, conf ProjectConfig) {
+	o.Name = conf.Name
+	o.SourceURL = conf.SourceURL
+	o.ContractEvents = conf.Events
+	o.ContractSupportedStandards = conf.SupportedStandards
+	o.Permissions = make([]manifest.Permission, len(conf.Permissions))
+	for i := range conf.Permissions {
+		o.Permissions[i] = manifest.Permission(conf.Permissions[i])
+	}
+	o.SafeMethods = conf.SafeMethods
+	o.Overloads = conf.Overloads
+	o.Storage = conf.Storage
+}
+
+// diffNEF returns unified diff between the expected and actual NEF files or
+// an empty string if they're the same.
+func diffNEF(expected *nef.File, expM *manifest.Manifest, actual *nef.File, actM *manifest.Manifest) (string, error) {
+	expBytes, err := expected.Bytes()
+	if err != nil {
+		return "", fmt.Errorf("can't serialize NEF: %w", err)
+	}
+	actBytes, err := actual.Bytes()
+	if err != nil {
+		return "", fmt.Errorf("can't serialize NEF: %w", err)
+	}
+	if bytes.Equal(expBytes, actBytes) {
+		return "", nil
+	}
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(dumpNEF(expected, expM)),
+		B:        difflib.SplitLines(dumpNEF(actual, actM)),
+		FromFile: "compiled.nef",
+		ToFile:   "deployed.nef",
+		Context:  3,
+	})
+}
+
+// dumpNEF returns human-readable representation of the NEF file with
+// disassembled script. Hex script is used if it can't be disassembled.
+func dumpNEF(f *nef.File, m *manifest.Manifest) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "compiler: %s\n", f.Compiler)
+	fmt.Fprintf(&sb, "source: %s\n", f.Source)
+	for i, t := range f.Tokens {
+		fmt.Fprintf(&sb, "token #%d: %s.%s, %d params, return: %t, flags: %s\n",
+			i, t.Hash.StringLE(), t.Method, t.ParamCount, t.HasReturn, t.CallFlag)
+	}
+	fmt.Fprintf(&sb, "checksum: %d\n", f.Checksum)
+	d, err := vm.Disassemble(f.Script, m)
+	if err == nil {
+		err = d.WriteText(&sb)
+	}
+	if err != nil {
+		fmt.Fprintf(&sb, "script: %s\n", hex.EncodeToString(f.Script))
+	}
+	return sb.String()
+}
+
+// diffManifest returns unified diff between the expected and actual manifests
+// or an empty string if they're the same.
+func diffManifest(expected, actual *manifest.Manifest) (string, error) {
+	expBytes, err := json.Marshal(expected)
+	if err != nil {
+		return "", fmt.Errorf("can't marshal manifest: %w", err)
+	}
+	actBytes, err := json.Marshal(actual)
+	if err != nil {
+		return "", fmt.Errorf("can't marshal manifest: %w", err)
+	}
+	if bytes.Equal(expBytes, actBytes) {
+		return "", nil
+	}
+	expIndented, err := json.MarshalIndent(expected, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("can't marshal manifest: %w", err)
+	}
+	actIndented, err := json.MarshalIndent(actual, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("can't marshal manifest: %w", err)
+	}
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(expIndented) + "\n"),
+		B:        difflib.SplitLines(string(actIndented) + "\n"),
+		FromFile: "compiled.manifest.json",
+		ToFile:   "deployed.manifest.json",
+		Context:  3,
+	})
+}
@@ -0,0 +1,17 @@
+package compileerrors
+
+// Main is the contract entry point.
+func Main() int {
+	return len(tail([]int{1, 2, 3})) + ptr()
+}
+
+func tail(xs []int) []int {
+	return xs[1:]
+}
+
+func ptr() int {
+	p := &a
+	return *p
+}
+
+var a = 1
@@ -32,6 +32,15 @@ func NewCommands() []cli.Command {
 					Action:    txDump,
 					Flags:     txDumpFlags,
 				},
+				{
+					Name:      "p2p-decode",
+					Usage:     "Print P2P messages from capture file",
+					UsageText: "p2p-decode <file.cap>",
+					Description: `Prints messages from the file written by the node with P2PCaptureFile
+   setting enabled. Every message is printed with its timestamp, direction,
+   peer address, command and size followed by decoded JSON payload.`,
+					Action: p2pDecode,
+				},
 			},
 		},
 	}
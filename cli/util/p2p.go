@@ -0,0 +1,50 @@
+package util
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/network"
+	"github.com/nspcc-dev/neo-go/pkg/network/payload"
+	"github.com/urfave/cli"
+)
+
+func p2pDecode(ctx *cli.Context) error {
+	if len(ctx.Args()) == 0 {
+		return cli.NewExitError("missing input file", 1)
+	}
+	f, err := os.Open(ctx.Args()[0])
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	defer f.Close()
+
+	r, err := network.NewCaptureReader(f)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	fmt.Fprintf(ctx.App.Writer, "Network: %s, StateRootInHeader: %t\n", r.Magic, r.StateRootInHeader)
+	for {
+		m, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return cli.NewExitError(err, 1)
+		}
+		fmt.Fprintf(ctx.App.Writer, "%s %-3s %s %s (%d bytes)\n", m.Time.UTC().Format(time.RFC3339Nano),
+			m.Direction, m.Peer, m.Message.Command, m.Size)
+		if _, ok := m.Message.Payload.(payload.NullPayload); ok || m.Message.Payload == nil {
+			continue
+		}
+		b, err := json.MarshalIndent(m.Message.Payload, "", "  ")
+		if err != nil {
+			return cli.NewExitError(fmt.Errorf("can't marshal %s payload: %w", m.Message.Command, err), 1)
+		}
+		fmt.Fprintln(ctx.App.Writer, string(b))
+	}
+}
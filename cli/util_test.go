@@ -1,9 +1,14 @@
 package main
 
 import (
+	"path/filepath"
 	"testing"
 
+	"github.com/nspcc-dev/neo-go/pkg/config/netmode"
+	"github.com/nspcc-dev/neo-go/pkg/network"
+	"github.com/nspcc-dev/neo-go/pkg/network/payload"
 	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/stretchr/testify/require"
 )
 
 func TestUtilConvert(t *testing.T) {
@@ -23,3 +28,32 @@ func TestUtilConvert(t *testing.T) {
 	e.checkNextLine(t, "MDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAzMDIwMQ==")                         // string to base64
 	e.checkEOF(t)
 }
+
+func TestUtilP2PDecode(t *testing.T) {
+	e := newExecutor(t, false)
+	path := filepath.Join(t.TempDir(), "p2p.cap")
+
+	e.RunWithError(t, "neo-go", "util", "p2p-decode")
+	e.RunWithError(t, "neo-go", "util", "p2p-decode", path)
+
+	c, err := network.NewCapturer(path, netmode.UnitTestNet, false)
+	require.NoError(t, err)
+	ping, err := network.NewMessage(network.CMDPing, payload.NewPing(42, 7)).Bytes()
+	require.NoError(t, err)
+	getAddr, err := network.NewMessage(network.CMDGetAddr, payload.NewNullPayload()).Bytes()
+	require.NoError(t, err)
+	require.NoError(t, c.Capture(network.CaptureIn, "10.0.0.1:20333", ping))
+	require.NoError(t, c.Capture(network.CaptureOut, "10.0.0.1:20333", getAddr))
+	require.NoError(t, c.Close())
+
+	e.Run(t, "neo-go", "util", "p2p-decode", path)
+	e.checkNextLine(t, "Network: unit_testnet, StateRootInHeader: false")
+	e.checkNextLine(t, "in  10.0.0.1:20333 CMDPing \\(15 bytes\\)")
+	e.checkNextLine(t, "{")
+	e.checkNextLine(t, `"LastBlockIndex": 42,`)
+	e.checkNextLine(t, `"Timestamp": \d+,`)
+	e.checkNextLine(t, `"Nonce": 7`)
+	e.checkNextLine(t, "}")
+	e.checkNextLine(t, "out 10.0.0.1:20333 CMDGetAddr \\(3 bytes\\)")
+	e.checkEOF(t)
+}
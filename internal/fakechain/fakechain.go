@@ -16,6 +16,7 @@ import (
 	"github.com/nspcc-dev/neo-go/pkg/core/mpt"
 	"github.com/nspcc-dev/neo-go/pkg/core/native"
 	"github.com/nspcc-dev/neo-go/pkg/core/state"
+	"github.com/nspcc-dev/neo-go/pkg/core/storage"
 	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
 	"github.com/nspcc-dev/neo-go/pkg/crypto/hash"
 	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
@@ -31,8 +32,9 @@ type FakeChain struct {
 	*mempool.Pool
 	blocksCh                 []chan<- *block.Block
 	Blockheight              uint32
+	Headerheight             uint32
 	PoolTxF                  func(*transaction.Transaction) error
-	poolTxWithData           func(*transaction.Transaction, interface{}, *mempool.Pool) error
+	PoolTxWithDataF          func(*transaction.Transaction, interface{}, *mempool.Pool) error
 	blocks                   map[util.Uint256]*block.Block
 	hdrHashes                map[uint32]util.Uint256
 	txs                      map[util.Uint256]*transaction.Transaction
@@ -42,6 +44,7 @@ type FakeChain struct {
 	NotaryDepositExpiration  uint32
 	PostBlock                []func(func(*transaction.Transaction, *mempool.Pool, bool) bool, *mempool.Pool, *block.Block)
 	UtilityTokenBalance      *big.Int
+	Store                    storage.Store
 }
 
 // FakeStateSync implements StateSync interface.
@@ -68,11 +71,12 @@ func NewFakeChainWithCustomCfg(protocolCfg func(c *config.ProtocolConfiguration)
 	return &FakeChain{
 		Pool:                  mempool.New(10, 0, false),
 		PoolTxF:               func(*transaction.Transaction) error { return nil },
-		poolTxWithData:        func(*transaction.Transaction, interface{}, *mempool.Pool) error { return nil },
+		PoolTxWithDataF:       func(*transaction.Transaction, interface{}, *mempool.Pool) error { return nil },
 		blocks:                make(map[util.Uint256]*block.Block),
 		hdrHashes:             make(map[uint32]util.Uint256),
 		txs:                   make(map[util.Uint256]*transaction.Transaction),
 		ProtocolConfiguration: cfg,
+		Store:                 storage.NewMemoryStore(),
 	}
 }
 
@@ -164,7 +168,7 @@ func (chain *FakeChain) GetMaxVerificationGAS() int64 {
 
 // PoolTxWithData implements Blockchainer interface.
 func (chain *FakeChain) PoolTxWithData(t *transaction.Transaction, data interface{}, mp *mempool.Pool, feer mempool.Feer, verificationFunction func(t *transaction.Transaction, data interface{}) error) error {
-	return chain.poolTxWithData(t, data, mp)
+	return chain.PoolTxWithDataF(t, data, mp)
 }
 
 // RegisterPostBlock implements Blockchainer interface.
@@ -193,8 +197,19 @@ func (chain *FakeChain) P2PSigExtensionsEnabled() bool {
 }
 
 // AddHeaders implements Blockchainer interface.
-func (chain *FakeChain) AddHeaders(...*block.Header) error {
-	panic("TODO")
+func (chain *FakeChain) AddHeaders(hdrs ...*block.Header) error {
+	for _, h := range hdrs {
+		height := chain.HeaderHeight()
+		if h.Index <= height {
+			continue
+		}
+		if h.Index != height+1 {
+			return errors.New("previous header was not found")
+		}
+		chain.hdrHashes[h.Index] = h.Hash()
+		atomic.StoreUint32(&chain.Headerheight, h.Index)
+	}
+	return nil
 }
 
 // AddBlock implements Blockchainer interface.
@@ -217,7 +232,10 @@ func (chain *FakeChain) Close() {
 
 // HeaderHeight implements Blockchainer interface.
 func (chain *FakeChain) HeaderHeight() uint32 {
-	return atomic.LoadUint32(&chain.Blockheight)
+	if h := atomic.LoadUint32(&chain.Headerheight); h > chain.BlockHeight() {
+		return h
+	}
+	return chain.BlockHeight()
 }
 
 // GetAppExecResults implements Blockchainer interface.
@@ -315,6 +333,11 @@ func (chain *FakeChain) GetStateModule() blockchainer.StateRoot {
 	return nil
 }
 
+// GetStore implements Blockchainer interface.
+func (chain *FakeChain) GetStore() storage.Store {
+	return chain.Store
+}
+
 // GetStorageItem implements Blockchainer interface.
 func (chain *FakeChain) GetStorageItem(id int32, key []byte) state.StorageItem {
 	panic("TODO")
@@ -1,7 +1,6 @@
 package compiler
 
 import (
-	"errors"
 	"go/ast"
 	"go/token"
 	"go/types"
@@ -15,7 +14,7 @@ import (
 
 var (
 	// Go language builtin functions.
-	goBuiltins = []string{"len", "append", "panic", "make", "copy", "recover", "delete"}
+	goBuiltins = []string{"len", "append", "panic", "make", "copy", "recover", "delete", "new"}
 	// Custom builtin utility functions.
 	customBuiltins = []string{
 		"FromAddress",
@@ -64,7 +63,7 @@ func (c *codegen) traverseGlobals() bool {
 	}
 
 	if n > 255 {
-		c.prog.BinWriter.Err = errors.New("too many global variables")
+		c.errorf(nil, "too many global variables")
 		return hasDeploy
 	}
 
@@ -274,7 +273,7 @@ func (c *codegen) analyzeFuncUsage() funcUsage {
 				// functions invoked in variable declarations in imported packages
 				// are marked as used.
 				var name string
-				switch t := n.Fun.(type) {
+				switch t := c.unwrapInstance(n.Fun).(type) {
 				case *ast.Ident:
 					name = c.getIdentName(pkgPath, t.Name)
 				case *ast.SelectorExpr:
@@ -286,8 +285,9 @@ func (c *codegen) analyzeFuncUsage() funcUsage {
 			case *ast.FuncDecl:
 				name := c.getFuncNameFromDecl(pkgPath, n)
 
-				// exported functions are always assumed to be used
-				if isMain && n.Name.IsExported() || isInitFunc(n) || isDeployFunc(n) {
+				// exported functions are always assumed to be used, generic
+				// ones are too because instances are created during conversion
+				if isMain && n.Name.IsExported() || isInitFunc(n) || isDeployFunc(n) || isGenericDecl(n) {
 					diff[name] = true
 				}
 				nodeCache[name] = declPair{n, c.importMap, pkgPath}
@@ -317,10 +317,16 @@ func (c *codegen) analyzeFuncUsage() funcUsage {
 			ast.Inspect(fd.decl, func(node ast.Node) bool {
 				switch n := node.(type) {
 				case *ast.CallExpr:
-					switch t := n.Fun.(type) {
+					switch t := c.unwrapInstance(n.Fun).(type) {
 					case *ast.Ident:
 						nextDiff[c.getIdentName(fd.path, t.Name)] = true
 					case *ast.SelectorExpr:
+						if names := c.interfaceMethodUsages(t); names != nil {
+							for _, name := range names {
+								nextDiff[name] = true
+							}
+							return true
+						}
 						name, _ := c.getFuncNameFromSelector(t)
 						nextDiff[name] = true
 					}
@@ -333,6 +339,40 @@ func (c *codegen) analyzeFuncUsage() funcUsage {
 	return usage
 }
 
+// analyzeClosures finds variables captured by function literals. Such
+// variables are stored in boxes (single-element arrays) so that both
+// the closure and the enclosing function see all modifications.
+func (c *codegen) analyzeClosures() {
+	c.ForEachFile(func(f *ast.File, _ *types.Package) {
+		ast.Inspect(f, func(node ast.Node) bool {
+			lit, ok := node.(*ast.FuncLit)
+			if !ok {
+				return true
+			}
+			var names []string
+			seen := make(map[types.Object]bool)
+			ast.Inspect(lit.Body, func(node ast.Node) bool {
+				id, ok := node.(*ast.Ident)
+				if !ok {
+					return true
+				}
+				v, ok := c.typeInfo.Uses[id].(*types.Var)
+				if !ok || v.IsField() || seen[v] || v.Parent() == nil ||
+					v.Pkg() == nil || v.Parent() == v.Pkg().Scope() ||
+					lit.Pos() <= v.Pos() && v.Pos() < lit.End() {
+					return true
+				}
+				seen[v] = true
+				c.captured[v] = true
+				names = append(names, v.Name())
+				return true
+			})
+			c.closureVars[lit] = names
+			return true
+		})
+	})
+}
+
 func isGoBuiltin(name string) bool {
 	for i := range goBuiltins {
 		if name == goBuiltins[i] {
@@ -33,6 +33,15 @@ type codegen struct {
 	// prog holds the output buffer.
 	prog *io.BufBinWriter
 
+	// diagnostics contains all errors found in the program.
+	diagnostics Diagnostics
+	// currNode is the node being converted, it's used as an error position
+	// when no other node is available.
+	currNode ast.Node
+	// failed is set when an error is found in the function being converted,
+	// the rest of it is skipped then.
+	failed bool
+
 	// Type information.
 	typeInfo *types.Info
 	// pkgInfoInline is stack of type information for packages containing inline functions.
@@ -44,6 +53,21 @@ type codegen struct {
 	// A mapping of lambda functions into their scope.
 	lambda map[string]*funcScope
 
+	// captured contains local variables captured by closures or having
+	// their address taken, such variables are stored in boxes.
+	captured map[types.Object]bool
+	// closureVars maps function literals to the names of variables they capture.
+	closureVars map[*ast.FuncLit][]string
+
+	// typeTags contains types which can be stored in interface values,
+	// type tag is an index in this slice plus one.
+	typeTags []types.Type
+	// dispatchers contains interface method dispatchers to be emitted.
+	dispatchers []dispatcher
+
+	// instances contains instances of generic functions and methods.
+	instances []*funcScope
+
 	// reverseOffsetMap maps function offsets to a local variable count.
 	reverseOffsetMap map[int]nameWithLocals
 
@@ -54,9 +78,9 @@ type codegen struct {
 	// staticVariables contains global (static in NDX-DN11) variable names and types.
 	staticVariables []string
 	// initVariables contains variables local to `_initialize` method.
-	initVariables []string
+	initVariables []debugVariable
 	// deployVariables contains variables local to `_initialize` method.
-	deployVariables []string
+	deployVariables []debugVariable
 
 	// A mapping from label's names to their ids.
 	labels map[labelWithType]uint16
@@ -158,7 +182,7 @@ const (
 func (c *codegen) newLabel() (l uint16) {
 	li := len(c.l)
 	if li > math.MaxUint16 {
-		c.prog.Err = errors.New("label number is too big")
+		c.errorf(nil, "label number is too big")
 		return
 	}
 	l = uint16(li)
@@ -190,7 +214,7 @@ func (c *codegen) emitLoadConst(t types.TypeAndValue) {
 
 	typ, ok := t.Type.Underlying().(*types.Basic)
 	if !ok {
-		c.prog.Err = fmt.Errorf("compiler doesn't know how to convert this constant: %v", t)
+		c.errorf(nil, "compiler doesn't know how to convert this constant: %v", t)
 		return
 	}
 
@@ -208,7 +232,7 @@ func (c *codegen) emitLoadConst(t types.TypeAndValue) {
 		val := constant.BoolVal(t.Value)
 		emit.Bool(c.prog.BinWriter, val)
 	default:
-		c.prog.Err = fmt.Errorf("compiler doesn't know how to convert this basic type: %v", t)
+		c.errorf(nil, "compiler doesn't know how to convert this basic type: %v", t)
 		return
 	}
 }
@@ -282,6 +306,9 @@ func (c *codegen) emitLoadVar(pkg string, name string) {
 		return
 	}
 	c.emitLoadByIndex(vi.refType, vi.index)
+	if vi.boxed {
+		c.emitLoadField(0)
+	}
 }
 
 // emitLoadByIndex loads specified variable type with index i.
@@ -301,9 +328,45 @@ func (c *codegen) emitStoreVar(pkg string, name string) {
 		return
 	}
 	vi := c.getVarIndex(pkg, name)
+	if vi.boxed {
+		c.emitLoadByIndex(vi.refType, vi.index)
+		c.emitStoreStructField(0)
+		return
+	}
 	c.emitStoreByIndex(vi.refType, vi.index)
 }
 
+// emitInitVar stores top value from the evaluation stack in the newly
+// declared variable. Boxed variables get a new box on every declaration.
+func (c *codegen) emitInitVar(name string) {
+	if name == "_" {
+		emit.Opcodes(c.prog.BinWriter, opcode.DROP)
+		return
+	}
+	vi := c.getVarIndex("", name)
+	if vi.boxed {
+		emit.Opcodes(c.prog.BinWriter, opcode.PUSH1, opcode.PACK)
+	}
+	c.emitStoreByIndex(vi.refType, vi.index)
+}
+
+// declareLocal creates a new local variable for the identifier, variables
+// captured by closures are marked as boxed.
+func (c *codegen) declareLocal(id *ast.Ident) int {
+	i := c.scope.newLocal(id.Name)
+	if c.isCaptured(id) {
+		c.scope.vars.setBoxed(id.Name)
+	}
+	return i
+}
+
+// isCaptured returns true if the variable denoted by the identifier is
+// captured by some closure.
+func (c *codegen) isCaptured(id *ast.Ident) bool {
+	obj := c.typeInfo.ObjectOf(id)
+	return obj != nil && c.captured[obj]
+}
+
 // emitLoadByIndex stores top value in the specified variable type with index i.
 func (c *codegen) emitStoreByIndex(t varType, i int) {
 	_, base := getBaseOpcode(t)
@@ -445,6 +508,8 @@ func (c *codegen) convertFuncDecl(file ast.Node, decl *ast.FuncDecl, pkg *types.
 	isDeploy := isDeployFunc(decl)
 	if isInit || isDeploy {
 		f = c.newFuncScope(decl, c.newLabel())
+	} else if f, ok = c.getInstanceByDecl(decl); ok {
+		c.setLabel(f.label)
 	} else {
 		f, ok = c.funcs[c.getFuncNameFromDecl("", decl)]
 		if ok {
@@ -463,6 +528,7 @@ func (c *codegen) convertFuncDecl(file ast.Node, decl *ast.FuncDecl, pkg *types.
 
 	f.rng.Start = uint16(c.prog.Len())
 	c.scope = f
+	c.failed = false
 	ast.Inspect(decl, c.scope.analyzeVoidCalls) // @OPTIMIZE
 
 	// All globals copied into the scope of the function need to be added
@@ -470,7 +536,7 @@ func (c *codegen) convertFuncDecl(file ast.Node, decl *ast.FuncDecl, pkg *types.
 	if !isInit && !isDeploy {
 		sizeArg := f.countArgs()
 		if sizeArg > 255 {
-			c.prog.Err = errors.New("maximum of 255 local variables is allowed")
+			c.errorf(decl, "maximum of 255 local variables is allowed")
 		}
 		emit.Instruction(c.prog.BinWriter, opcode.INITSLOT, []byte{byte(0), byte(sizeArg)})
 	}
@@ -491,6 +557,12 @@ func (c *codegen) convertFuncDecl(file ast.Node, decl *ast.FuncDecl, pkg *types.
 		}
 	}
 
+	// Closures get boxes of captured variables as the first arguments.
+	for _, name := range f.captured {
+		c.scope.newVariable(varArgument, name)
+		c.scope.vars.setBoxed(name)
+	}
+
 	// Load the arguments in scope.
 	for _, arg := range decl.Type.Params.List {
 		for _, id := range arg.Names {
@@ -498,6 +570,7 @@ func (c *codegen) convertFuncDecl(file ast.Node, decl *ast.FuncDecl, pkg *types.
 			c.scope.newVariable(varArgument, id.Name)
 		}
 	}
+	c.boxCapturedParams(decl)
 
 	ast.Walk(c, decl.Body)
 
@@ -518,12 +591,26 @@ func (c *codegen) convertFuncDecl(file ast.Node, decl *ast.FuncDecl, pkg *types.
 
 	f.rng.End = uint16(c.prog.Len() - 1)
 
+	if !isInit && !isDeploy && f.vars.localsCnt > 255 {
+		c.errorf(decl, "func '%s' has %d local variables (maximum is 255)", f.name, f.vars.localsCnt)
+	}
+
 	if !isLambda {
-		for _, f := range c.lambda {
-			if _, ok := c.lambda[c.getIdentName("", f.decl.Name.Name)]; !ok {
-				panic("ICE: lambda name doesn't match map key")
+		// Lambdas can be nested, so new ones can be added while converting,
+		// they're processed in the order of appearance.
+		converted := make(map[*funcScope]bool)
+		for {
+			var next *funcScope
+			for _, l := range c.lambda {
+				if !converted[l] && (next == nil || l.label < next.label) {
+					next = l
+				}
 			}
-			c.convertFuncDecl(file, f.decl, pkg)
+			if next == nil {
+				break
+			}
+			converted[next] = true
+			c.convertFuncDecl(file, next.decl, pkg)
 		}
 		c.lambda = make(map[string]*funcScope)
 	}
@@ -537,10 +624,49 @@ func (c *codegen) convertFuncDecl(file ast.Node, decl *ast.FuncDecl, pkg *types.
 	return f
 }
 
+// boxCapturedParams moves captured receivers and parameters into boxes and
+// initializes boxes for captured named results.
+func (c *codegen) boxCapturedParams(decl *ast.FuncDecl) {
+	var args []*ast.Ident
+	if decl.Recv != nil {
+		for _, arg := range decl.Recv.List {
+			args = append(args, arg.Names...)
+		}
+	}
+	for _, arg := range decl.Type.Params.List {
+		args = append(args, arg.Names...)
+	}
+	for _, id := range args {
+		if !c.isCaptured(id) {
+			continue
+		}
+		c.scope.vars.setBoxed(id.Name)
+		vi := c.scope.vars.getVarInfo(id.Name)
+		c.emitLoadByIndex(vi.refType, vi.index)
+		emit.Opcodes(c.prog.BinWriter, opcode.PUSH1, opcode.PACK)
+		c.emitStoreByIndex(vi.refType, vi.index)
+	}
+	if decl.Type.Results == nil {
+		return
+	}
+	for _, res := range decl.Type.Results.List {
+		for _, id := range res.Names {
+			if c.isCaptured(id) {
+				c.emitDefault(c.typeOf(res.Type))
+				c.declareLocal(id)
+				c.emitInitVar(id.Name)
+			}
+		}
+	}
+}
+
 func (c *codegen) Visit(node ast.Node) ast.Visitor {
-	if c.prog.Err != nil {
+	if c.prog.Err != nil || c.failed {
 		return nil
 	}
+	if node != nil {
+		c.currNode = node
+	}
 	switch n := node.(type) {
 	// General declarations.
 	// var (
@@ -574,7 +700,7 @@ func (c *codegen) Visit(node ast.Node) ast.Visitor {
 							// it is a global declaration
 							c.newGlobal("", id.Name)
 						} else {
-							c.scope.newLocal(id.Name)
+							c.declareLocal(id)
 						}
 						c.registerDebugVariable(id.Name, t.Type)
 					}
@@ -582,10 +708,11 @@ func (c *codegen) Visit(node ast.Node) ast.Visitor {
 				for i := range t.Names {
 					if len(t.Values) != 0 {
 						ast.Walk(c, t.Values[i])
+						c.emitConvertToInterface(c.typeOf(t.Values[i]), c.typeOf(t.Names[i]))
 					} else {
 						c.emitDefault(c.typeOf(t.Type))
 					}
-					c.emitStoreVar("", t.Names[i].Name)
+					c.emitInitVar(t.Names[i].Name)
 				}
 			}
 		}
@@ -602,26 +729,52 @@ func (c *codegen) Visit(node ast.Node) ast.Visitor {
 			ast.Walk(c, n.Rhs[0])
 			c.emitToken(n.Tok, c.typeOf(n.Rhs[0]))
 		}
+		commaOk := c.isCommaOkAssert(n.Lhs, n.Rhs, n.Tok)
+		walkRhs := func(i int) {
+			switch {
+			case isAssignOp:
+			case commaOk:
+				if i == 0 {
+					ta := n.Rhs[0].(*ast.TypeAssertExpr)
+					ast.Walk(c, ta.X)
+					c.emitInterfaceAssert(c.typeOf(ta.Type), true)
+				}
+			case multiRet:
+				// All values are pushed at once, i-th one is on top now.
+				if i == 0 {
+					ast.Walk(c, n.Rhs[0])
+				}
+				if tuple, ok := c.typeOf(n.Rhs[0]).(*types.Tuple); ok {
+					c.emitConvertToInterface(tuple.At(i).Type(), c.typeOf(n.Lhs[i]))
+				}
+			default:
+				ast.Walk(c, n.Rhs[i])
+				c.emitConvertToInterface(c.typeOf(n.Rhs[i]), c.typeOf(n.Lhs[i]))
+			}
+		}
 		for i := 0; i < len(n.Lhs); i++ {
-			switch t := n.Lhs[i].(type) {
+			switch t := unparen(n.Lhs[i]).(type) {
 			case *ast.Ident:
+				var isNew bool
 				if n.Tok == token.DEFINE {
+					// Redeclared captured variables must keep their boxes.
+					if t.Name != "_" && (c.typeInfo.Defs[t] != nil || !c.isCaptured(t)) {
+						c.declareLocal(t)
+						isNew = true
+					}
 					if !multiRet {
 						c.registerDebugVariable(t.Name, n.Rhs[i])
 					}
-					if t.Name != "_" {
-						c.scope.newLocal(t.Name)
-					}
 				}
-				if !isAssignOp && (i == 0 || !multiRet) {
-					ast.Walk(c, n.Rhs[i])
+				walkRhs(i)
+				if isNew {
+					c.emitInitVar(t.Name)
+				} else {
+					c.emitStoreVar("", t.Name)
 				}
-				c.emitStoreVar("", t.Name)
 
 			case *ast.SelectorExpr:
-				if !isAssignOp {
-					ast.Walk(c, n.Rhs[i])
-				}
+				walkRhs(i)
 				typ := c.typeOf(t.X)
 				if c.isInvalidType(typ) {
 					// Store to other package global variable.
@@ -630,7 +783,7 @@ func (c *codegen) Visit(node ast.Node) ast.Visitor {
 				}
 				strct, ok := c.getStruct(typ)
 				if !ok {
-					c.prog.Err = fmt.Errorf("nested selector assigns not supported yet")
+					c.errorf(t, "nested selector assigns not supported yet")
 					return nil
 				}
 				ast.Walk(c, t.X)                      // load the struct
@@ -640,19 +793,24 @@ func (c *codegen) Visit(node ast.Node) ast.Visitor {
 			// Assignments to index expressions.
 			// slice[0] = 10
 			case *ast.IndexExpr:
-				if !isAssignOp {
-					ast.Walk(c, n.Rhs[i])
-				}
+				walkRhs(i)
 				ast.Walk(c, t.X)
 				ast.Walk(c, t.Index)
 				emit.Opcodes(c.prog.BinWriter, opcode.ROT, opcode.SETITEM)
+
+			// Assignments through pointers.
+			// *p = 10
+			case *ast.StarExpr:
+				walkRhs(i)
+				ast.Walk(c, t.X)
+				c.emitStoreDeref(c.typeOf(t.X))
 			}
 		}
 		return nil
 
 	case *ast.SliceExpr:
 		if isCompoundSlice(c.typeOf(n.X).Underlying()) {
-			c.prog.Err = errors.New("subslices are supported only for []byte")
+			c.errorf(n, "subslices are supported only for []byte")
 			return nil
 		}
 
@@ -701,8 +859,15 @@ func (c *codegen) Visit(node ast.Node) ast.Visitor {
 			}
 		} else {
 			// first result should be on top of the stack
+			var results []types.Type
+			if len(c.pkgInfoInline) == 0 {
+				results = c.getResultTypes(c.scope.decl.Type)
+			}
 			for i := len(n.Results) - 1; i >= 0; i-- {
 				ast.Walk(c, n.Results[i])
+				if len(results) == len(n.Results) {
+					c.emitConvertToInterface(c.typeOf(n.Results[i]), results[i])
+				}
 			}
 		}
 
@@ -803,23 +968,34 @@ func (c *codegen) Visit(node ast.Node) ast.Visitor {
 		return nil
 
 	case *ast.FuncLit:
-		var found bool
-		var l uint16
+		var f *funcScope
 		for _, fs := range c.lambda {
 			if fs.decl.Body == n.Body {
-				found = true
-				l = fs.label
+				f = fs
 				break
 			}
 		}
-		if !found {
-			l = c.newLabel()
-			c.newLambda(l, n)
+		if f == nil {
+			f = c.newLambda(c.newLabel(), n)
 		}
 
+		// Closures are represented as arrays containing function pointer
+		// followed by boxes of all captured variables.
+		for i := len(f.captured) - 1; i >= 0; i-- {
+			vi := c.scope.vars.getVarInfo(f.captured[i])
+			if vi == nil || !vi.boxed {
+				c.errorf(n, "variable %s can't be captured by closure", f.captured[i])
+				return nil
+			}
+			c.emitLoadByIndex(vi.refType, vi.index)
+		}
 		buf := make([]byte, 4)
-		binary.LittleEndian.PutUint16(buf, l)
+		binary.LittleEndian.PutUint16(buf, f.label)
 		emit.Instruction(c.prog.BinWriter, opcode.PUSHA, buf)
+		if len(f.captured) != 0 {
+			emit.Int(c.prog.BinWriter, int64(len(f.captured)+1))
+			emit.Opcodes(c.prog.BinWriter, opcode.PACK)
+		}
 		return nil
 
 	case *ast.BasicLit:
@@ -827,13 +1003,8 @@ func (c *codegen) Visit(node ast.Node) ast.Visitor {
 		return nil
 
 	case *ast.StarExpr:
-		_, ok := c.getStruct(c.typeOf(n.X))
-		if !ok {
-			c.prog.Err = errors.New("dereferencing is only supported on structs")
-			return nil
-		}
 		ast.Walk(c, n.X)
-		c.emitConvert(stackitem.StructT)
+		c.emitLoadDeref(c.typeOf(n.X))
 		return nil
 
 	case *ast.Ident:
@@ -864,7 +1035,7 @@ func (c *codegen) Visit(node ast.Node) ast.Visitor {
 					expectedLen = 32
 				}
 				if expectedLen != -1 && expectedLen != len(n.Elts) {
-					c.prog.Err = fmt.Errorf("%s type must have size %d", tn.Obj().Name(), expectedLen)
+					c.errorf(n, "%s type must have size %d", tn.Obj().Name(), expectedLen)
 					return nil
 				}
 			}
@@ -874,8 +1045,16 @@ func (c *codegen) Visit(node ast.Node) ast.Visitor {
 				c.convertByteArray(n.Elts)
 				return nil
 			}
+			var elemType types.Type
+			switch typ := typ.(type) {
+			case *types.Slice:
+				elemType = typ.Elem()
+			case *types.Array:
+				elemType = typ.Elem()
+			}
 			for i := ln - 1; i >= 0; i-- {
 				ast.Walk(c, n.Elts[i])
+				c.emitConvertToInterface(c.typeOf(n.Elts[i]), elemType)
 			}
 			emit.Int(c.prog.BinWriter, int64(ln))
 			emit.Opcodes(c.prog.BinWriter, opcode.PACK)
@@ -889,18 +1068,23 @@ func (c *codegen) Visit(node ast.Node) ast.Visitor {
 
 	case *ast.CallExpr:
 		var (
-			f         *funcScope
-			ok        bool
-			name      string
-			numArgs   = len(n.Args)
-			isBuiltin bool
-			isFunc    bool
-			isLiteral bool
+			f          *funcScope
+			ok         bool
+			name       string
+			numArgs    = len(n.Args)
+			isBuiltin  bool
+			isFunc     bool
+			isFuncExpr bool
+			// ifaceMethod is set for interface method calls.
+			ifaceMethod *types.Func
 		)
 
-		switch fun := n.Fun.(type) {
+		switch fun := c.unwrapInstance(n.Fun).(type) {
 		case *ast.Ident:
 			f, ok = c.getFuncFromIdent(fun)
+			if ok && isGenericDecl(f.decl) {
+				f = c.getFuncInstance(f, fun)
+			}
 			isBuiltin = isGoBuiltin(fun.Name)
 			if !ok && !isBuiltin {
 				name = fun.Name
@@ -914,17 +1098,36 @@ func (c *codegen) Visit(node ast.Node) ast.Visitor {
 				return nil
 			}
 		case *ast.SelectorExpr:
+			sel := c.typeInfo.Selections[fun]
+			if sel != nil && isMethodInterface(c.substType(sel.Recv())) {
+				// Interface value is passed as a receiver to the dispatcher.
+				ast.Walk(c, fun.X)
+				numArgs++
+				ifaceMethod = sel.Obj().(*types.Func)
+				break
+			}
+			if sel != nil && sel.Kind() == types.FieldVal {
+				c.errorf(fun, "calling function stored in struct field %s is not supported", fun.Sel.Name)
+				return nil
+			}
 			// If this is a method call we need to walk the AST to load the struct locally.
 			// Otherwise this is a function call from a imported package and we can call it
 			// directly.
 			name, isMethod := c.getFuncNameFromSelector(fun)
 			if isMethod {
-				ast.Walk(c, fun.X)
+				c.emitReceiver(fun)
 				// Dont forget to add 1 extra argument when its a method.
 				numArgs++
 			}
 
 			f, ok = c.funcs[name]
+			if ok && isGenericDecl(f.decl) {
+				if isMethod {
+					f = c.getMethodInstance(f, c.typeOf(fun.X))
+				} else {
+					f = c.getFuncInstance(f, fun.Sel)
+				}
+			}
 			if ok {
 				f.selector = fun.X.(*ast.Ident)
 				isBuiltin = isCustomBuiltin(f)
@@ -944,18 +1147,24 @@ func (c *codegen) Visit(node ast.Node) ast.Visitor {
 			ast.Walk(c, n.Args[0])
 			c.emitConvert(stackitem.BufferT)
 			return nil
-		case *ast.FuncLit:
-			isLiteral = true
+		case *ast.FuncLit, *ast.CallExpr, *ast.IndexExpr:
+			// Function literal or function value returned from
+			// another call or stored in a slice/map.
+			isFuncExpr = true
 		}
 
 		c.saveSequencePoint(n)
 
 		args := transformArgs(f, n.Fun, n.Args)
+		sig, _ := c.typeOf(n.Fun).Underlying().(*types.Signature)
 
 		// Handle the arguments
-		for _, arg := range args {
+		for i, arg := range args {
 			ast.Walk(c, arg)
 			typ := c.typeOf(arg)
+			if sig != nil && len(args) == len(n.Args) {
+				c.emitConvertToInterface(typ, paramType(sig, i, n.Ellipsis.IsValid()))
+			}
 			_, ok := typ.Underlying().(*types.Struct)
 			if ok && !isInteropPath(typ.String()) {
 				// To clone struct fields we create a new array and append struct to it.
@@ -966,7 +1175,7 @@ func (c *codegen) Visit(node ast.Node) ast.Visitor {
 			}
 		}
 		// Do not swap for builtin functions.
-		if !isBuiltin && (f != nil && !isSyscall(f)) {
+		if !isBuiltin && (f == nil || !isSyscall(f)) {
 			typ, ok := c.typeOf(n.Fun).(*types.Signature)
 			if ok && typ.Variadic() && !n.Ellipsis.IsValid() {
 				// pack variadic args into an array only if last argument is not of form `...`
@@ -988,13 +1197,17 @@ func (c *codegen) Visit(node ast.Node) ast.Visitor {
 			// E.g. one cannot write `bool(int(a))`, only `int32(int(a))`.
 			if isString(c.typeOf(n.Fun)) {
 				c.emitConvert(stackitem.ByteArrayT)
+			} else if types.IsInterface(c.typeOf(n.Fun)) && !isFunc {
+				c.emitConvertToInterface(c.typeOf(n.Args[0]), c.typeOf(n.Fun))
 			} else if isFunc {
 				c.emitLoadVar("", name)
-				emit.Opcodes(c.prog.BinWriter, opcode.CALLA)
+				c.emitCallFuncValue()
 			}
-		case isLiteral:
+		case isFuncExpr:
 			ast.Walk(c, n.Fun)
-			emit.Opcodes(c.prog.BinWriter, opcode.CALLA)
+			c.emitCallFuncValue()
+		case ifaceMethod != nil:
+			c.emitInterfaceCall(ifaceMethod)
 		case isSyscall(f):
 			c.convertSyscall(f, n)
 		default:
@@ -1050,7 +1263,7 @@ func (c *codegen) Visit(node ast.Node) ast.Visitor {
 		}
 		strct, ok := c.getStruct(typ)
 		if !ok {
-			c.prog.Err = fmt.Errorf("selectors are supported only on structs")
+			c.errorf(n, "selectors are supported only on structs")
 			return nil
 		}
 		ast.Walk(c, n.X) // load the struct
@@ -1060,15 +1273,7 @@ func (c *codegen) Visit(node ast.Node) ast.Visitor {
 
 	case *ast.UnaryExpr:
 		if n.Op == token.AND {
-			// We support only taking address from struct literals.
-			// For identifiers we can't support "taking address" in a general way
-			// because both struct and array are reference types.
-			lit, ok := n.X.(*ast.CompositeLit)
-			if ok {
-				c.convertStruct(lit, true)
-				return nil
-			}
-			c.prog.Err = fmt.Errorf("'&' can be used only with struct literals")
+			c.emitAddressOf(n.X)
 			return nil
 		}
 
@@ -1087,7 +1292,7 @@ func (c *codegen) Visit(node ast.Node) ast.Visitor {
 		case token.XOR:
 			emit.Opcodes(c.prog.BinWriter, opcode.INVERT)
 		default:
-			c.prog.Err = fmt.Errorf("invalid unary operator: %s", n.Op)
+			c.errorf(n, "invalid unary operator: %s", n.Op)
 			return nil
 		}
 		return nil
@@ -1096,11 +1301,15 @@ func (c *codegen) Visit(node ast.Node) ast.Visitor {
 		ast.Walk(c, n.X)
 		c.emitToken(n.Tok, c.typeOf(n.X))
 
-		// For now only identifiers are supported for (post) for stmts.
+		// For now only identifiers and pointer dereferences are supported.
 		// for i := 0; i < 10; i++ {}
 		// Where the post stmt is ( i++ )
-		if ident, ok := n.X.(*ast.Ident); ok {
-			c.emitStoreVar("", ident.Name)
+		switch t := unparen(n.X).(type) {
+		case *ast.Ident:
+			c.emitStoreVar("", t.Name)
+		case *ast.StarExpr:
+			ast.Walk(c, t.X)
+			c.emitStoreDeref(c.typeOf(t.X))
 		}
 		return nil
 
@@ -1202,6 +1411,10 @@ func (c *codegen) Visit(node ast.Node) ast.Visitor {
 
 		return nil
 
+	case *ast.TypeSwitchStmt:
+		c.convertTypeSwitch(n)
+		return nil
+
 	case *ast.RangeStmt:
 		c.scope.vars.newScope()
 		defer c.scope.vars.dropScope()
@@ -1249,7 +1462,7 @@ func (c *codegen) Visit(node ast.Node) ast.Visitor {
 			} else {
 				emit.Opcodes(c.prog.BinWriter, opcode.DUP)
 			}
-			c.emitStoreVar("", n.Key.(*ast.Ident).Name)
+			c.emitStoreRangeVar(n.Tok, n.Key.(*ast.Ident))
 		}
 		if needValue {
 			if !isMap || !keyLoaded {
@@ -1263,7 +1476,7 @@ func (c *codegen) Visit(node ast.Node) ast.Visitor {
 					opcode.SWAP, // key should be on top
 					opcode.PICKITEM)
 			}
-			c.emitStoreVar("", n.Value.(*ast.Ident).Name)
+			c.emitStoreRangeVar(n.Tok, n.Value.(*ast.Ident))
 		}
 
 		ast.Walk(c, n.Body)
@@ -1290,6 +1503,14 @@ func (c *codegen) Visit(node ast.Node) ast.Visitor {
 		if c.isCallExprSyscall(n.X) {
 			return nil
 		}
+		if isMethodInterface(c.typeOf(n.X)) {
+			c.emitInterfaceAssert(c.typeOf(n.Type), false)
+			return nil
+		}
+		if isMethodInterface(c.typeOf(n.Type)) {
+			c.errorf(n, "type assertion of %s to interface with methods is not supported", c.typeOf(n.X))
+			return nil
+		}
 
 		goTyp := c.typeOf(n.Type)
 		if canConvert(goTyp.String()) {
@@ -1301,6 +1522,39 @@ func (c *codegen) Visit(node ast.Node) ast.Visitor {
 	return c
 }
 
+// getResultTypes returns the list of result types of the function.
+func (c *codegen) getResultTypes(typ *ast.FuncType) []types.Type {
+	var res []types.Type
+	if typ.Results == nil {
+		return nil
+	}
+	for _, fld := range typ.Results.List {
+		t := c.typeOf(fld.Type)
+		res = append(res, t)
+		for i := 1; i < len(fld.Names); i++ {
+			res = append(res, t)
+		}
+	}
+	return res
+}
+
+// paramType returns the type of i-th parameter of the function with the
+// specified signature taking variadic arguments into account.
+func paramType(sig *types.Signature, i int, ellipsis bool) types.Type {
+	n := sig.Params().Len()
+	if sig.Variadic() && i >= n-1 {
+		t := sig.Params().At(n - 1).Type()
+		if !ellipsis {
+			t = t.(*types.Slice).Elem()
+		}
+		return t
+	}
+	if i < n {
+		return sig.Params().At(i).Type()
+	}
+	return nil
+}
+
 // packVarArgs packs variadic arguments into an array
 // and returns amount of arguments packed.
 func (c *codegen) packVarArgs(n *ast.CallExpr, typ *types.Signature) int {
@@ -1398,6 +1652,8 @@ func (c *codegen) emitExplicitConvert(from, to types.Type) {
 		c.emitConvert(stackitem.BufferT)
 	} else if isString(to) && !isString(from) {
 		c.emitConvert(stackitem.ByteArrayT)
+	} else {
+		c.emitConvertToInterface(from, to)
 	}
 }
 
@@ -1406,6 +1662,16 @@ func (c *codegen) isInvalidType(typ types.Type) bool {
 	return typ == nil || ok && tb.Kind() == types.Invalid
 }
 
+// emitStoreRangeVar stores range key or value in the specified variable.
+func (c *codegen) emitStoreRangeVar(tok token.Token, id *ast.Ident) {
+	if tok != token.DEFINE {
+		c.emitStoreVar("", id.Name)
+		return
+	}
+	c.declareLocal(id)
+	c.emitInitVar(id.Name)
+}
+
 func (c *codegen) rangeLoadKey() {
 	emit.Int(c.prog.BinWriter, 2)
 	emit.Opcodes(c.prog.BinWriter,
@@ -1501,9 +1767,15 @@ func (c *codegen) emitBinaryExpr(n *ast.BinaryExpr, needJump bool, cond bool, jm
 		c.setLabel(end)
 
 	default:
+		typ := c.typeOf(n.X)
+		if isRefPointer(typ) {
+			// References are created anew every time an address is taken,
+			// so they can't be compared.
+			c.errorf(n, "comparison of %s pointers with values other than nil is not supported", typ)
+			return
+		}
 		ast.Walk(c, n.X)
 		ast.Walk(c, n.Y)
-		typ := c.typeOf(n.X)
 		if !needJump {
 			c.emitToken(n.Op, typ)
 			return
@@ -1631,7 +1903,7 @@ func (c *codegen) convertSyscall(f *funcScope, expr *ast.CallExpr) {
 	}
 	tv := c.typeAndValueOf(expr.Args[0])
 	if tv.Value == nil || !isString(tv.Type) {
-		c.prog.Err = fmt.Errorf("bad intrinsic argument")
+		c.errorf(expr.Args[0], "bad intrinsic argument")
 		return
 	}
 	arg0Str := constant.StringVal(tv.Value)
@@ -1646,25 +1918,25 @@ func (c *codegen) convertSyscall(f *funcScope, expr *ast.CallExpr) {
 
 		hash, err := util.Uint160DecodeBytesBE([]byte(arg0Str))
 		if err != nil {
-			c.prog.Err = fmt.Errorf("bad callt hash: %w", err)
+			c.errorf(expr.Args[0], "bad callt hash: %w", err)
 			return
 		}
 
 		tv = c.typeAndValueOf(expr.Args[1])
 		if tv.Value == nil || !isString(tv.Type) {
-			c.prog.Err = errors.New("bad callt method")
+			c.errorf(expr.Args[1], "bad callt method")
 			return
 		}
 		method := constant.StringVal(tv.Value)
 
 		tv = c.typeAndValueOf(expr.Args[2])
 		if tv.Value == nil || !isNumber(tv.Type) {
-			c.prog.Err = errors.New("bad callt call flags")
+			c.errorf(expr.Args[2], "bad callt call flags")
 			return
 		}
 		flag, ok := constant.Uint64Val(tv.Value)
 		if !ok || flag > 255 {
-			c.prog.Err = errors.New("invalid callt flag")
+			c.errorf(expr.Args[2], "invalid callt flag")
 			return
 		}
 
@@ -1672,7 +1944,7 @@ func (c *codegen) convertSyscall(f *funcScope, expr *ast.CallExpr) {
 
 		tokNum, err := c.getCallToken(hash, method, len(callArgs), hasRet, callflag.CallFlag(flag))
 		if err != nil {
-			c.prog.Err = err
+			c.errorf(expr, "%w", err)
 			return
 		}
 		tokBuf := make([]byte, 2)
@@ -1681,7 +1953,7 @@ func (c *codegen) convertSyscall(f *funcScope, expr *ast.CallExpr) {
 	} else {
 		op, err := opcode.FromString(arg0Str)
 		if err != nil {
-			c.prog.Err = fmt.Errorf("invalid opcode: %s", op)
+			c.errorf(expr.Args[0], "invalid opcode: %s", op)
 			return
 		}
 		emit.Opcodes(c.prog.BinWriter, op)
@@ -1691,7 +1963,7 @@ func (c *codegen) convertSyscall(f *funcScope, expr *ast.CallExpr) {
 // emitSliceHelper emits 3 items on stack: slice, its first index, and its size.
 func (c *codegen) emitSliceHelper(e ast.Expr) {
 	if !isByteSlice(c.typeOf(e)) {
-		c.prog.Err = fmt.Errorf("copy is supported only for byte-slices")
+		c.errorf(e, "copy is supported only for byte-slices")
 		return
 	}
 	var hasLowIndex bool
@@ -1753,7 +2025,7 @@ func (c *codegen) convertBuiltin(expr *ast.CallExpr) {
 			emit.Opcodes(c.prog.BinWriter, opcode.NEWMAP)
 		default:
 			if len(expr.Args) == 3 {
-				c.prog.Err = fmt.Errorf("`make()` with a capacity argument is not supported")
+				c.errorf(expr.Args[2], "`make()` with a capacity argument is not supported")
 				return
 			}
 			ast.Walk(c, expr.Args[1])
@@ -1772,7 +2044,7 @@ func (c *codegen) convertBuiltin(expr *ast.CallExpr) {
 		emit.Opcodes(c.prog.BinWriter, opcode.DROP, opcode.PUSH0)
 	case "append":
 		arg := expr.Args[0]
-		typ := c.typeInfo.Types[arg].Type
+		typ := c.typeOf(arg)
 		ast.Walk(c, arg)
 		emit.Opcodes(c.prog.BinWriter, opcode.DUP, opcode.ISNULL)
 		if isByteSlice(typ) {
@@ -1811,10 +2083,15 @@ func (c *codegen) convertBuiltin(expr *ast.CallExpr) {
 				for _, e := range expr.Args[1:] {
 					emit.Opcodes(c.prog.BinWriter, opcode.DUP)
 					ast.Walk(c, e)
+					if sl, ok := typ.Underlying().(*types.Slice); ok {
+						c.emitConvertToInterface(c.typeOf(e), sl.Elem())
+					}
 					emit.Opcodes(c.prog.BinWriter, opcode.APPEND)
 				}
 			}
 		}
+	case "new":
+		c.emitNew(c.typeOf(expr.Args[0]))
 	case "panic":
 		emit.Opcodes(c.prog.BinWriter, opcode.THROW)
 	case "recover":
@@ -1833,7 +2110,7 @@ func (c *codegen) convertBuiltin(expr *ast.CallExpr) {
 		addressStr = strings.Replace(addressStr, "\"", "", 2)
 		uint160, err := address.StringToUint160(addressStr)
 		if err != nil {
-			c.prog.Err = err
+			c.errorf(expr.Args[0], "%w", err)
 			return
 		}
 		bytes := uint160.BytesBE()
@@ -1860,7 +2137,7 @@ func transformArgs(fs *funcScope, fun ast.Expr, args []ast.Expr) []ast.Expr {
 		}
 	case *ast.Ident:
 		switch f.Name {
-		case "make", "copy", "append":
+		case "make", "copy", "append", "new":
 			return nil
 		}
 	}
@@ -1903,6 +2180,7 @@ func (c *codegen) convertMap(lit *ast.CompositeLit) {
 	for i := l - 1; i >= 0; i-- {
 		elem := lit.Elts[i].(*ast.KeyValueExpr)
 		ast.Walk(c, elem.Value)
+		c.emitConvertToInterface(c.typeOf(elem.Value), c.typeOf(lit).Underlying().(*types.Map).Elem())
 		ast.Walk(c, elem.Key)
 	}
 	emit.Int(c.prog.BinWriter, int64(l))
@@ -1926,7 +2204,7 @@ func (c *codegen) convertStruct(lit *ast.CompositeLit, ptr bool) {
 	// the positions of its variables.
 	strct, ok := c.typeOf(lit).Underlying().(*types.Struct)
 	if !ok {
-		c.prog.Err = fmt.Errorf("the given literal is not of type struct: %v", lit)
+		c.errorf(lit, "the given literal is not of type struct: %v", lit)
 		return
 	}
 
@@ -1944,6 +2222,7 @@ func (c *codegen) convertStruct(lit *ast.CompositeLit, ptr bool) {
 		if !keyedLit {
 			if len(lit.Elts) > i {
 				ast.Walk(c, lit.Elts[i])
+				c.emitConvertToInterface(c.typeOf(lit.Elts[i]), sField.Type())
 				initialized = true
 			}
 		} else {
@@ -1954,6 +2233,7 @@ func (c *codegen) convertStruct(lit *ast.CompositeLit, ptr bool) {
 
 				if sField.Name() == fieldName {
 					ast.Walk(c, f.Value)
+					c.emitConvertToInterface(c.typeOf(f.Value), sField.Type())
 					initialized = true
 					break
 				}
@@ -1974,7 +2254,7 @@ func (c *codegen) convertStruct(lit *ast.CompositeLit, ptr bool) {
 func (c *codegen) emitToken(tok token.Token, typ types.Type) {
 	op, err := convertToken(tok, typ)
 	if err != nil {
-		c.prog.Err = err
+		c.errorf(nil, "%w", err)
 		return
 	}
 	emit.Opcodes(c.prog.BinWriter, op)
@@ -2066,30 +2346,58 @@ func (c *codegen) getFuncFromIdent(fun *ast.Ident) (*funcScope, bool) {
 func (c *codegen) getFuncNameFromSelector(e *ast.SelectorExpr) (string, bool) {
 	ident := e.X.(*ast.Ident)
 	if c.typeInfo.Selections[e] != nil {
-		typ := c.typeInfo.Types[ident].Type.String()
-		return c.getIdentName(typ, e.Sel.Name), true
+		typ := c.substType(c.typeInfo.Types[ident].Type)
+		if ptr, ok := typ.(*types.Pointer); ok {
+			typ = ptr.Elem()
+		}
+		if named, ok := typ.(*types.Named); ok && named.TypeArgs().Len() != 0 {
+			// Methods of all instances share the same generic declaration.
+			return c.getIdentName(named.Obj().Pkg().Path()+"."+named.Obj().Name(), e.Sel.Name), true
+		}
+		return c.getIdentName(typ.String(), e.Sel.Name), true
 	}
 	return c.getIdentName(ident.Name, e.Sel.Name), false
 }
 
-func (c *codegen) newLambda(u uint16, lit *ast.FuncLit) {
+func (c *codegen) newLambda(u uint16, lit *ast.FuncLit) *funcScope {
 	name := fmt.Sprintf("lambda@%d", u)
 	f := c.newFuncScope(&ast.FuncDecl{
 		Name: ast.NewIdent(name),
 		Type: lit.Type,
 		Body: lit.Body,
 	}, u)
+	f.captured = c.closureVars[lit]
+	if c.scope != nil {
+		f.typeArgs = c.scope.typeArgs
+	}
 	c.lambda[c.getFuncNameFromDecl("", f.decl)] = f
+	return f
+}
+
+// emitCallFuncValue calls function value from the top of the stack. It's
+// either a pointer or a closure array which is unpacked so that boxes of
+// captured variables become the first arguments.
+func (c *codegen) emitCallFuncValue() {
+	l := c.newLabel()
+	emit.Opcodes(c.prog.BinWriter, opcode.DUP)
+	emit.Instruction(c.prog.BinWriter, opcode.ISTYPE, []byte{byte(stackitem.PointerT)})
+	emit.Jmp(c.prog.BinWriter, opcode.JMPIFL, l)
+	emit.Opcodes(c.prog.BinWriter, opcode.UNPACK, opcode.DROP)
+	c.setLabel(l)
+	emit.Opcodes(c.prog.BinWriter, opcode.CALLA)
 }
 
 func (c *codegen) compile(info *buildInfo, pkg *packages.Package) error {
 	c.mainPkg = pkg
 	c.analyzePkgOrder()
 	c.fillDocumentInfo()
+	c.collectTypeTags()
 	funUsage := c.analyzeFuncUsage()
 
 	// Bring all imported functions into scope.
 	c.ForEachFile(c.resolveFuncDecls)
+	c.analyzeClosures()
+	c.analyzeAddresses()
 
 	hasDeploy := c.traverseGlobals()
 
@@ -2124,14 +2432,20 @@ func (c *codegen) compile(info *buildInfo, pkg *packages.Package) error {
 					pkgPath = pkg.Path()
 				}
 				name := c.getFuncNameFromDecl(pkgPath, n)
-				if !isInitFunc(n) && !isDeployFunc(n) && funUsage.funcUsed(name) &&
+				if !isInitFunc(n) && !isDeployFunc(n) && !isGenericDecl(n) && funUsage.funcUsed(name) &&
 					(!isInteropPath(pkg.Path()) && !canInline(pkg.Path(), n.Name.Name)) {
 					c.convertFuncDecl(f, n, pkg)
 				}
 			}
 		}
 	})
+	c.convertInstances()
+	c.emitDispatchers()
 
+	if len(c.diagnostics) != 0 {
+		c.diagnostics.sort()
+		return c.diagnostics
+	}
 	return c.prog.Err
 }
 
@@ -2142,6 +2456,8 @@ func newCodegen(info *buildInfo, pkg *packages.Package) *codegen {
 		l:                []int{},
 		funcs:            map[string]*funcScope{},
 		lambda:           map[string]*funcScope{},
+		captured:         map[types.Object]bool{},
+		closureVars:      map[*ast.FuncLit][]string{},
 		reverseOffsetMap: map[int]nameWithLocals{},
 		globals:          map[string]int{},
 		labels:           map[labelWithType]uint16{},
@@ -2172,9 +2488,18 @@ func codeGen(info *buildInfo) (*nef.File, *DebugInfo, error) {
 	if err != nil {
 		return nil, nil, err
 	}
+	if info.options != nil {
+		buf, err = c.optimize(buf, info.options.Optimize)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
 
 	methods := bitfield.New(len(buf))
 	di := c.emitDebugInfo(buf)
+	if info.options != nil {
+		di.Storage = info.options.Storage
+	}
 	for i := range di.Methods {
 		methods.Set(int(di.Methods[i].Range.Start))
 	}
@@ -2254,8 +2579,19 @@ func (c *codegen) writeJumps(b []byte) ([]byte, error) {
 	// Correct function ip range.
 	// Note: indices are sorted in increasing order.
 	for _, f := range c.funcs {
+		// Functions that weren't converted have an empty range.
+		if f.rng.Start != f.rng.End {
+			f.rng.Start, f.rng.End = correctRange(f.rng.Start, f.rng.End, offsets)
+		}
+	}
+	for _, f := range c.instances {
 		f.rng.Start, f.rng.End = correctRange(f.rng.Start, f.rng.End, offsets)
 	}
+	for _, points := range c.sequencePoints {
+		for i := range points {
+			points[i].Opcode = correctOffset(points[i].Opcode, offsets)
+		}
+	}
 	return shortenJumps(b, offsets), nil
 }
 
@@ -2276,6 +2612,19 @@ loop:
 	return newStart, newEnd
 }
 
+// correctOffset returns the offset of the instruction at ip after shortening
+// instructions at the specified offsets.
+func correctOffset(ip int, offsets []int) int {
+	newIP := ip
+	for _, ind := range offsets {
+		if ind >= ip {
+			break
+		}
+		newIP -= longToShortRemoveCount
+	}
+	return newIP
+}
+
 func (c *codegen) replaceLabelWithOffset(ip int, arg []byte) (int, error) {
 	index := binary.LittleEndian.Uint16(arg)
 	if int(index) > len(c.l) {
@@ -63,6 +63,10 @@ type Options struct {
 	// The list of standards supported by the contract.
 	ContractSupportedStandards []string
 
+	// CustomStandards contains definitions of user-defined standards, contract
+	// is checked against them in addition to the built-in ones.
+	CustomStandards []standard.Definition
+
 	// SafeMethods contains list of methods which will be marked as safe in manifest.
 	SafeMethods []string
 
@@ -75,6 +79,17 @@ type Options struct {
 
 	// BindingsFile contains configuration for smart-contract bindings generator.
 	BindingsFile string
+
+	// Optimize specifies optimizations performed on the compiled program.
+	Optimize OptimizationLevel
+
+	// Storage is the contract storage schema to be written to debug info.
+	Storage []binding.StorageItem
+
+	// Verify is an optional check of the compiled contract performed by
+	// CompileAndSave before any file is written. Manifest is nil if it's not
+	// requested.
+	Verify func(*nef.File, *manifest.Manifest) error
 }
 
 type buildInfo struct {
@@ -185,10 +200,8 @@ func getBuildInfo(name string, src interface{}) (*buildInfo, error) {
 	if err != nil {
 		return nil, err
 	}
-	for _, p := range prog {
-		if len(p.Errors) != 0 {
-			return nil, p.Errors[0]
-		}
+	if err := packageErrors(prog); err != nil {
+		return nil, err
 	}
 	return &buildInfo{
 		config:  conf,
@@ -215,6 +228,11 @@ func CompileWithOptions(name string, r io.Reader, o *Options) (*nef.File, *Debug
 		return nil, nil, err
 	}
 	ctx.options = o
+	if o != nil {
+		if err := binding.ValidateStorage(o.Storage); err != nil {
+			return nil, nil, fmt.Errorf("invalid storage schema: %w", err)
+		}
+	}
 	return codeGen(ctx)
 }
 
@@ -246,6 +264,18 @@ func CompileAndSave(src string, o *Options) ([]byte, error) {
 	if err != nil {
 		return nil, fmt.Errorf("error while serializing .nef file: %w", err)
 	}
+	var m *manifest.Manifest
+	if o.ManifestFile != "" {
+		m, err = CreateManifest(di, o)
+		if err != nil {
+			return f.Script, err
+		}
+	}
+	if o.Verify != nil {
+		if err := o.Verify(f, m); err != nil {
+			return f.Script, err
+		}
+	}
 	out := fmt.Sprintf("%s.%s", o.Outfile, o.Ext)
 	err = os.WriteFile(out, bytes, os.ModePerm)
 	if err != nil {
@@ -305,11 +335,7 @@ func CompileAndSave(src string, o *Options) ([]byte, error) {
 		}
 	}
 
-	if o.ManifestFile != "" {
-		m, err := CreateManifest(di, o)
-		if err != nil {
-			return f.Script, err
-		}
+	if m != nil {
 		mData, err := json.Marshal(m)
 		if err != nil {
 			return f.Script, fmt.Errorf("failed to marshal manifest to JSON: %w", err)
@@ -332,16 +358,20 @@ func CreateManifest(di *DebugInfo, o *Options) (*manifest.Manifest, error) {
 		}
 	}
 	if !o.NoStandardCheck {
-		if err := standard.CheckABI(m, o.ContractSupportedStandards...); err != nil {
+		sts := standard.Builtin()
+		if err := sts.Add(o.CustomStandards...); err != nil {
+			return m, err
+		}
+		if err := sts.CheckABI(m, o.ContractSupportedStandards...); err != nil {
 			return m, err
 		}
 		if m.ABI.GetMethod(manifest.MethodOnNEP11Payment, -1) != nil {
-			if err := standard.CheckABI(m, manifest.NEP11Payable); err != nil {
+			if err := sts.CheckABI(m, manifest.NEP11Payable); err != nil {
 				return m, err
 			}
 		}
 		if m.ABI.GetMethod(manifest.MethodOnNEP17Payment, -1) != nil {
-			if err := standard.CheckABI(m, manifest.NEP17Payable); err != nil {
+			if err := sts.CheckABI(m, manifest.NEP17Payable); err != nil {
 				return m, err
 			}
 		}
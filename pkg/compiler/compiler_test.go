@@ -1,6 +1,7 @@
 package compiler_test
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -14,6 +15,8 @@ import (
 	"github.com/nspcc-dev/neo-go/pkg/interop/native/neo"
 	"github.com/nspcc-dev/neo-go/pkg/smartcontract"
 	"github.com/nspcc-dev/neo-go/pkg/smartcontract/manifest"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/manifest/standard"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/nef"
 	"github.com/nspcc-dev/neo-go/pkg/util"
 	"github.com/stretchr/testify/require"
 )
@@ -78,6 +81,30 @@ func TestCompiler(t *testing.T) {
 				require.NoError(t, err)
 			},
 		},
+		{
+			name: "TestCompileAndSaveVerificationFailed",
+			function: func(t *testing.T) {
+				infos, err := os.ReadDir(exampleCompilePath)
+				require.NoError(t, err)
+				dir := t.TempDir()
+				o := &compiler.Options{
+					Outfile:      filepath.Join(dir, "test.nef"),
+					ManifestFile: filepath.Join(dir, "test.manifest.json"),
+					DebugInfo:    filepath.Join(dir, "test.debug.json"),
+					Verify: func(f *nef.File, m *manifest.Manifest) error {
+						require.NotNil(t, f)
+						require.NotNil(t, m)
+						return errors.New("bad script")
+					},
+				}
+				_, err = compiler.CompileAndSave(exampleCompilePath+"/"+infos[0].Name(), o)
+				require.Error(t, err)
+
+				files, err := os.ReadDir(dir)
+				require.NoError(t, err)
+				require.Empty(t, files)
+			},
+		},
 	}
 
 	for _, tcase := range testCases {
@@ -124,6 +151,49 @@ func TestOnPayableChecks(t *testing.T) {
 	})
 }
 
+func TestCustomStandards(t *testing.T) {
+	src := `package custom
+		import "github.com/nspcc-dev/neo-go/pkg/interop"
+		func Mint(to interop.Hash160, amount int) bool { return true }`
+
+	_, di, err := compiler.CompileWithOptions("custom.go", strings.NewReader(src), nil)
+	require.NoError(t, err)
+
+	def := standard.Definition{
+		Name: "NEP-X",
+		ABI: manifest.ABI{
+			Methods: []manifest.Method{{
+				Name: "mint",
+				Parameters: []manifest.Parameter{
+					{Name: "to", Type: smartcontract.Hash160Type},
+					{Name: "amount", Type: smartcontract.IntegerType},
+				},
+				ReturnType: smartcontract.BoolType,
+			}},
+		},
+	}
+	o := &compiler.Options{
+		Name:                       "custom",
+		ContractSupportedStandards: []string{"NEP-X"},
+		CustomStandards:            []standard.Definition{def},
+	}
+	_, err = compiler.CreateManifest(di, o)
+	require.NoError(t, err)
+
+	def.ABI.Methods[0].ReturnType = smartcontract.VoidType
+	o.CustomStandards = []standard.Definition{def}
+	_, err = compiler.CreateManifest(di, o)
+	require.True(t, errors.Is(err, standard.ErrInvalidReturnType))
+
+	o.CustomStandards = []standard.Definition{{Name: manifest.NEP17StandardName}}
+	_, err = compiler.CreateManifest(di, o)
+	require.Error(t, err)
+
+	o.NoStandardCheck = true
+	_, err = compiler.CreateManifest(di, o)
+	require.NoError(t, err)
+}
+
 func TestSafeMethodWarnings(t *testing.T) {
 	src := `package payable
 		func Main() int { return 1 }`
@@ -31,6 +31,9 @@ type DebugInfo struct {
 	InvokedContracts map[util.Uint160][]string `json:"-"`
 	// StaticVariables contains list of static variable names and types.
 	StaticVariables []string `json:"static-variables"`
+	// Storage contains contract storage schema, it allows to decode
+	// contract storage items.
+	Storage []binding.StorageItem `json:"storage,omitempty"`
 }
 
 // MethodDebugInfo represents smart-contract's method debug information.
@@ -146,7 +149,7 @@ func (c *codegen) emitDebugInfo(contract []byte) *DebugInfo {
 			ReturnType:   "Void",
 			ReturnTypeSC: smartcontract.VoidType,
 			SeqPoints:    c.sequencePoints["init"],
-			Variables:    c.initVariables,
+			Variables:    debugVariables(c.initVariables),
 		})
 	}
 	if c.deployEndOffset >= 0 {
@@ -177,18 +180,25 @@ func (c *codegen) emitDebugInfo(contract []byte) *DebugInfo {
 			ReturnType:   "Void",
 			ReturnTypeSC: smartcontract.VoidType,
 			SeqPoints:    c.sequencePoints[manifest.MethodDeploy],
-			Variables:    c.deployVariables,
+			Variables:    debugVariables(c.deployVariables),
 		})
 	}
 
 	start := len(d.Methods)
-	for name, scope := range c.funcs {
-		m := c.methodInfoFromScope(name, scope)
-		if m.Range.Start == m.Range.End {
+	for _, scope := range c.funcs {
+		m := c.methodInfoFromScope(scope)
+		// Functions consisting of a single instruction have an empty range
+		// too, but unlike the unused ones they have their label set.
+		if m.Range.Start == m.Range.End && c.l[scope.label] < 0 {
 			continue
 		}
 		d.Methods = append(d.Methods, *m)
 	}
+	for _, scope := range c.instances {
+		// Parameter types depend on type arguments of the instance.
+		c.scope = scope
+		d.Methods = append(d.Methods, *c.methodInfoFromScope(scope))
+	}
 	sort.Slice(d.Methods[start:], func(i, j int) bool {
 		return d.Methods[start+i].Name.Name < d.Methods[start+j].Name.Name
 	})
@@ -197,16 +207,44 @@ func (c *codegen) emitDebugInfo(contract []byte) *DebugInfo {
 	return d
 }
 
+// debugVariable is a local variable of the function being compiled.
+type debugVariable struct {
+	// info contains variable name and type in the debug info format.
+	info string
+	// slot is the index of the local slot the variable is stored in,
+	// -1 if there is none.
+	slot int
+}
+
 func (c *codegen) registerDebugVariable(name string, expr ast.Expr) {
 	_, vt, _ := c.scAndVMTypeFromExpr(expr)
 	if c.scope == nil {
 		c.staticVariables = append(c.staticVariables, name+","+vt.String())
 		return
 	}
-	c.scope.variables = append(c.scope.variables, name+","+vt.String())
+	slot := -1
+	if vi := c.scope.vars.getVarInfo(name); vi != nil && vi.refType == varLocal {
+		slot = vi.index
+	}
+	c.scope.variables = append(c.scope.variables, debugVariable{
+		info: name + "," + vt.String(),
+		slot: slot,
+	})
+}
+
+// debugVariables returns variables in the debug info format.
+func debugVariables(vars []debugVariable) []string {
+	if vars == nil {
+		return nil
+	}
+	res := make([]string, len(vars))
+	for i := range vars {
+		res[i] = vars[i].info
+	}
+	return res
 }
 
-func (c *codegen) methodInfoFromScope(name string, scope *funcScope) *MethodDebugInfo {
+func (c *codegen) methodInfoFromScope(scope *funcScope) *MethodDebugInfo {
 	ps := scope.decl.Type.Params
 	params := make([]DebugParam, 0, ps.NumFields())
 	for i := range ps.List {
@@ -220,8 +258,7 @@ func (c *codegen) methodInfoFromScope(name string, scope *funcScope) *MethodDebu
 			})
 		}
 	}
-	ss := strings.Split(name, ".")
-	name = ss[len(ss)-1]
+	name := scope.name
 	r, n := utf8.DecodeRuneInString(name)
 	st, vt, rt := c.scAndVMReturnTypeFromScope(scope)
 
@@ -231,7 +268,7 @@ func (c *codegen) methodInfoFromScope(name string, scope *funcScope) *MethodDebu
 			Name:      string(unicode.ToLower(r)) + name[n:],
 			Namespace: scope.pkg.Name(),
 		},
-		IsExported:     scope.decl.Name.IsExported(),
+		IsExported:     scope.decl.Name.IsExported() && scope.typeArgs == nil,
 		IsFunction:     scope.decl.Recv == nil,
 		Range:          scope.rng,
 		Parameters:     params,
@@ -239,7 +276,7 @@ func (c *codegen) methodInfoFromScope(name string, scope *funcScope) *MethodDebu
 		ReturnTypeReal: rt,
 		ReturnTypeSC:   st,
 		SeqPoints:      c.sequencePoints[name],
-		Variables:      scope.variables,
+		Variables:      debugVariables(scope.variables),
 	}
 }
 
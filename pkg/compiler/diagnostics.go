@@ -0,0 +1,141 @@
+package compiler
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Diagnostic is a compilation error with the position of the code it
+// relates to.
+type Diagnostic struct {
+	// Pos is the position of the erroneous code, it's not valid for errors
+	// not related to any specific place in the source code.
+	Pos token.Position
+	Err error
+}
+
+// diagnosticAux is used for Diagnostic JSON marshaling.
+type diagnosticAux struct {
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+// Diagnostics is a list of compilation errors sorted by their positions.
+type Diagnostics []Diagnostic
+
+// Error implements the error interface. The error is formatted as
+// `file:line:column: message`.
+func (d Diagnostic) Error() string {
+	if !d.Pos.IsValid() {
+		return d.Err.Error()
+	}
+	return d.Pos.String() + ": " + d.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (d Diagnostic) Unwrap() error {
+	return d.Err
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (d Diagnostic) MarshalJSON() ([]byte, error) {
+	return json.Marshal(diagnosticAux{
+		File:    d.Pos.Filename,
+		Line:    d.Pos.Line,
+		Column:  d.Pos.Column,
+		Message: d.Err.Error(),
+	})
+}
+
+// Error implements the error interface. Every diagnostic is printed on
+// a separate line.
+func (ds Diagnostics) Error() string {
+	ss := make([]string, len(ds))
+	for i := range ds {
+		ss[i] = ds[i].Error()
+	}
+	return strings.Join(ss, "\n")
+}
+
+func (ds Diagnostics) sort() {
+	sort.SliceStable(ds, func(i, j int) bool {
+		pi, pj := ds[i].Pos, ds[j].Pos
+		if pi.Filename != pj.Filename {
+			return pi.Filename < pj.Filename
+		}
+		if pi.Line != pj.Line {
+			return pi.Line < pj.Line
+		}
+		return pi.Column < pj.Column
+	})
+}
+
+// positioner is anything having a position in the source code, like AST
+// node or types.Object.
+type positioner interface {
+	Pos() token.Pos
+}
+
+// errorf records the compilation error found at n. If n is nil, the node
+// being converted is used. The rest of the function being converted
+// is skipped after an error, but other functions are still converted to find
+// as many errors as possible.
+func (c *codegen) errorf(n positioner, format string, args ...interface{}) {
+	if n == nil {
+		n = c.currNode
+	}
+	var pos token.Position
+	if n != nil {
+		pos = c.buildInfo.config.Fset.Position(n.Pos())
+	}
+	c.diagnostics = append(c.diagnostics, Diagnostic{
+		Pos: pos,
+		Err: fmt.Errorf(format, args...),
+	})
+	c.failed = true
+}
+
+// packageErrors returns errors found while loading packages of the program.
+func packageErrors(prog []*packages.Package) error {
+	var ds Diagnostics
+	packages.Visit(prog, nil, func(p *packages.Package) {
+		for _, err := range p.Errors {
+			ds = append(ds, Diagnostic{
+				Pos: parsePosition(err.Pos),
+				Err: fmt.Errorf("%s", err.Msg),
+			})
+		}
+	})
+	if len(ds) == 0 {
+		return nil
+	}
+	ds.sort()
+	return ds
+}
+
+// parsePosition parses position in `file:line:column` or `file:line` format.
+func parsePosition(s string) token.Position {
+	i := strings.LastIndexByte(s, ':')
+	if i < 0 {
+		return token.Position{}
+	}
+	n, err := strconv.Atoi(s[i+1:])
+	if err != nil {
+		return token.Position{}
+	}
+	file := s[:i]
+	if j := strings.LastIndexByte(file, ':'); j >= 0 {
+		if line, err := strconv.Atoi(file[j+1:]); err == nil {
+			return token.Position{Filename: file[:j], Line: line, Column: n}
+		}
+	}
+	return token.Position{Filename: file, Line: n}
+}
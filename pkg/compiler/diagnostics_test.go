@@ -0,0 +1,96 @@
+package compiler_test
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/compiler"
+	"github.com/stretchr/testify/require"
+)
+
+func compileDiagnostics(t *testing.T, name string, src string) compiler.Diagnostics {
+	var err error
+	if src != "" {
+		_, err = compiler.Compile(name, strings.NewReader(src))
+	} else {
+		_, err = compiler.Compile(name, nil)
+	}
+	require.Error(t, err)
+
+	var diags compiler.Diagnostics
+	require.True(t, errors.As(err, &diags), "unexpected error: %v", err)
+	return diags
+}
+
+func TestDiagnostics(t *testing.T) {
+	t.Run("codegen", func(t *testing.T) {
+		src := `package foo
+		func Main() int {
+			return f() + g()
+		}
+		func f() int {
+			xs := make([]int, 1, 2)
+			return len(xs)
+		}
+		func g() int {
+			p := &a
+			return *p
+		}
+		var a = 1`
+		diags := compileDiagnostics(t, "foo.go", src)
+		require.Equal(t, 2, len(diags))
+		require.Equal(t, "foo.go", filepath.Base(diags[0].Pos.Filename))
+		require.Equal(t, 6, diags[0].Pos.Line)
+		require.Equal(t, "`make()` with a capacity argument is not supported", diags[0].Err.Error())
+		require.Equal(t, 10, diags[1].Pos.Line)
+		require.Equal(t, "taking address of global variable a is not supported", diags[1].Err.Error())
+		require.True(t, strings.HasSuffix(diags[1].Error(), "foo.go:10:10: taking address of global variable a is not supported"), diags[1].Error())
+	})
+	t.Run("type check", func(t *testing.T) {
+		src := `package foo
+		func Main() int {
+			return "a"
+		}
+		func f() string {
+			return 1
+		}`
+		diags := compileDiagnostics(t, "foo.go", src)
+		require.Equal(t, 2, len(diags))
+		require.Equal(t, 3, diags[0].Pos.Line)
+		require.Equal(t, 6, diags[1].Pos.Line)
+	})
+	t.Run("multiple files", func(t *testing.T) {
+		diags := compileDiagnostics(t, "./testdata/diagnostics", "")
+		require.Equal(t, 2, len(diags))
+		require.Equal(t, "main.go", filepath.Base(diags[0].Pos.Filename))
+		require.Equal(t, 10, diags[0].Pos.Line)
+		require.Equal(t, "subslices are supported only for []byte", diags[0].Err.Error())
+		require.Equal(t, "ptr.go", filepath.Base(diags[1].Pos.Filename))
+		require.Equal(t, 4, diags[1].Pos.Line)
+	})
+}
+
+func TestDiagnosticsJSON(t *testing.T) {
+	diags := compileDiagnostics(t, "foo.go", `package foo
+	func Main() int {
+		xs := []int{1, 2}
+		return len(xs[1:])
+	}`)
+	data, err := json.Marshal(diags)
+	require.NoError(t, err)
+
+	var actual []map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &actual))
+	require.Equal(t, 1, len(actual))
+	require.Equal(t, "foo.go", filepath.Base(actual[0]["file"].(string)))
+	require.Equal(t, float64(4), actual[0]["line"])
+	require.Equal(t, float64(14), actual[0]["column"])
+	require.Equal(t, "subslices are supported only for []byte", actual[0]["message"])
+
+	data, err = json.Marshal(compiler.Diagnostics{{Err: errors.New("no position")}})
+	require.NoError(t, err)
+	require.JSONEq(t, `[{"message":"no position"}]`, string(data))
+}
@@ -29,7 +29,15 @@ type funcScope struct {
 	// Range of opcodes corresponding to the function.
 	rng DebugRange
 	// Variables together with it's type in neo-vm.
-	variables []string
+	variables []debugVariable
+
+	// captured contains names of variables captured by the lambda,
+	// their boxes are passed as the first arguments.
+	captured []string
+
+	// typeArgs contains type arguments for instances of generic functions
+	// (and lambdas declared in them).
+	typeArgs typeArgs
 
 	// deferStack is a stack containing encountered `defer` statements.
 	deferStack []deferInfo
@@ -73,7 +81,7 @@ func (c *codegen) newFuncScope(decl *ast.FuncDecl, label uint16) *funcScope {
 		pkg:       c.currPkg.Types,
 		vars:      newVarScope(),
 		voidCalls: map[*ast.CallExpr]bool{},
-		variables: []string{},
+		variables: []debugVariable{},
 		i:         -1,
 	}
 }
@@ -81,12 +89,8 @@ func (c *codegen) newFuncScope(decl *ast.FuncDecl, label uint16) *funcScope {
 func (c *codegen) getFuncNameFromDecl(pkgPath string, decl *ast.FuncDecl) string {
 	name := decl.Name.Name
 	if decl.Recv != nil {
-		switch t := decl.Recv.List[0].Type.(type) {
-		case *ast.Ident:
-			name = t.Name + "." + name
-		case *ast.StarExpr:
-			name = t.X.(*ast.Ident).Name + "." + name
-		}
+		recv, _ := recvTypeName(decl.Recv.List[0].Type)
+		name = recv + "." + name
 	}
 	return c.getIdentName(pkgPath, name)
 }
@@ -105,7 +109,7 @@ func (c *funcScope) analyzeVoidCalls(node ast.Node) bool {
 }
 
 func (c *funcScope) countArgs() int {
-	n := c.decl.Type.Params.NumFields()
+	n := c.decl.Type.Params.NumFields() + len(c.captured)
 	if c.decl.Recv != nil {
 		n += c.decl.Recv.NumFields()
 	}
@@ -0,0 +1,291 @@
+package compiler
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"strings"
+)
+
+// Generic functions and methods of generic types are compiled via
+// monomorphisation: every instantiation found in the program gets its own
+// copy of the function compiled with type parameters substituted by type
+// arguments. Instances are created when the call is converted and are
+// emitted after all regular functions. Generic declarations themselves
+// are never emitted.
+
+// typeArgs maps type parameters of the generic function to type arguments
+// of the particular instance.
+type typeArgs map[*types.TypeParam]types.Type
+
+// isGenericDecl returns true if decl is a generic function or a method of
+// a generic type.
+func isGenericDecl(decl *ast.FuncDecl) bool {
+	if decl.Type.TypeParams.NumFields() != 0 {
+		return true
+	}
+	if decl.Recv != nil {
+		_, isGeneric := recvTypeName(decl.Recv.List[0].Type)
+		return isGeneric
+	}
+	return false
+}
+
+// recvTypeName returns the name of the receiver base type and true if it's
+// a generic type.
+func recvTypeName(e ast.Expr) (string, bool) {
+	if star, ok := e.(*ast.StarExpr); ok {
+		e = star.X
+	}
+	switch t := e.(type) {
+	case *ast.Ident:
+		return t.Name, false
+	case *ast.IndexExpr:
+		return t.X.(*ast.Ident).Name, true
+	case *ast.IndexListExpr:
+		return t.X.(*ast.Ident).Name, true
+	}
+	return "", false
+}
+
+// unwrapInstance returns generic function expression for an explicit
+// instantiation like `f[int]` and e itself for everything else.
+func (c *codegen) unwrapInstance(e ast.Expr) ast.Expr {
+	var x ast.Expr
+	switch t := e.(type) {
+	case *ast.IndexExpr:
+		x = t.X
+	case *ast.IndexListExpr:
+		x = t.X
+	default:
+		return e
+	}
+	var id *ast.Ident
+	switch t := x.(type) {
+	case *ast.Ident:
+		id = t
+	case *ast.SelectorExpr:
+		id = t.Sel
+	default:
+		return e
+	}
+	if _, ok := c.typeInfo.Instances[id]; ok {
+		return x
+	}
+	return e
+}
+
+// getFuncInstance returns an instance of the generic function f used via id.
+func (c *codegen) getFuncInstance(f *funcScope, id *ast.Ident) *funcScope {
+	inst, ok := c.typeInfo.Instances[id]
+	if !ok {
+		c.errorf(id, "can't infer type arguments for generic function %s", f.name)
+		return f
+	}
+	targs := make([]types.Type, inst.TypeArgs.Len())
+	for i := range targs {
+		targs[i] = c.substType(inst.TypeArgs.At(i))
+	}
+	return c.getInstance(f, targs)
+}
+
+// getMethodInstance returns an instance of the method f of generic type
+// for the receiver of type recv.
+func (c *codegen) getMethodInstance(f *funcScope, recv types.Type) *funcScope {
+	if ptr, ok := recv.(*types.Pointer); ok {
+		recv = ptr.Elem()
+	}
+	named, ok := recv.(*types.Named)
+	if !ok {
+		c.errorf(nil, "invalid receiver type %s for generic method %s", recv, f.name)
+		return f
+	}
+	targs := make([]types.Type, named.TypeArgs().Len())
+	for i := range targs {
+		targs[i] = named.TypeArgs().At(i)
+	}
+	return c.getInstance(f, targs)
+}
+
+// getInstance returns an instance of the generic function or method f with
+// the specified type arguments creating it if needed.
+func (c *codegen) getInstance(f *funcScope, targs []types.Type) *funcScope {
+	name := instanceName(f, targs)
+	for _, inst := range c.instances {
+		if inst.pkg == f.pkg && inst.name == name {
+			return inst
+		}
+	}
+
+	obj := c.packageCache[f.pkg.Path()].TypesInfo.Defs[f.decl.Name].(*types.Func)
+	sig := obj.Type().(*types.Signature)
+	tparams := sig.TypeParams()
+	if f.decl.Recv != nil {
+		tparams = sig.RecvTypeParams()
+	}
+	args := make(typeArgs, len(targs))
+	for i := range targs {
+		tp := tparams.At(i)
+		if err := checkTypeParam(tp, targs[i]); err != nil {
+			c.errorf(nil, "%s: %w", name, err)
+		}
+		args[tp] = targs[i]
+	}
+
+	decl := *f.decl
+	inst := c.newFuncScope(&decl, c.newLabel())
+	inst.name = name
+	inst.pkg = f.pkg
+	inst.file = f.file
+	inst.typeArgs = args
+	c.instances = append(c.instances, inst)
+	return inst
+}
+
+// getInstanceByDecl returns an instance with the specified declaration.
+func (c *codegen) getInstanceByDecl(decl *ast.FuncDecl) (*funcScope, bool) {
+	for _, inst := range c.instances {
+		if inst.decl == decl {
+			return inst, true
+		}
+	}
+	return nil, false
+}
+
+// convertInstances converts all instances of generic functions. Conversion
+// of an instance can create new ones, they're converted too.
+func (c *codegen) convertInstances() {
+	for i := 0; i < len(c.instances); i++ {
+		f := c.instances[i]
+		pkg := c.packageCache[f.pkg.Path()]
+		c.typeInfo = pkg.TypesInfo
+		c.currPkg = pkg
+		c.fillImportMap(f.file, pkg)
+		c.convertFuncDecl(f.file, f.decl, f.pkg)
+	}
+}
+
+// instanceName returns the name of f instance with the specified type
+// arguments, e.g. `Map[int, string]` for function or `Pair[int].Swap`
+// for method.
+func instanceName(f *funcScope, targs []types.Type) string {
+	qualifier := func(p *types.Package) string {
+		if p == f.pkg {
+			return ""
+		}
+		return p.Name()
+	}
+	ss := make([]string, len(targs))
+	for i := range targs {
+		ss[i] = types.TypeString(targs[i], qualifier)
+	}
+	list := "[" + strings.Join(ss, ", ") + "]"
+	if f.decl.Recv != nil {
+		recv, _ := recvTypeName(f.decl.Recv.List[0].Type)
+		return recv + list + "." + f.decl.Name.Name
+	}
+	return f.decl.Name.Name + list
+}
+
+// checkTypeParam checks that both constraint of the type parameter tp and
+// the type argument typ are supported.
+func checkTypeParam(tp *types.TypeParam, typ types.Type) error {
+	if t := unsupportedType(tp.Constraint()); t != nil {
+		return fmt.Errorf("type parameter %s has unsupported constraint %s: %s is not supported",
+			tp.Obj().Name(), tp.Constraint(), t)
+	}
+	if t := unsupportedType(typ); t != nil {
+		return fmt.Errorf("type argument %s for type parameter %s is not supported", typ, tp.Obj().Name())
+	}
+	return nil
+}
+
+// unsupportedType returns typ (or a term of the type set if typ is
+// a constraint) that can't be used in contracts. Nil is returned if there
+// is no such type.
+func unsupportedType(typ types.Type) types.Type {
+	switch t := typ.Underlying().(type) {
+	case *types.Basic:
+		if t.Info()&(types.IsFloat|types.IsComplex) != 0 || t.Kind() == types.UnsafePointer {
+			return typ
+		}
+	case *types.Chan:
+		return typ
+	case *types.Interface:
+		for i := 0; i < t.NumEmbeddeds(); i++ {
+			if u := unsupportedType(t.EmbeddedType(i)); u != nil {
+				return u
+			}
+		}
+	case *types.Union:
+		for i := 0; i < t.Len(); i++ {
+			if u := unsupportedType(t.Term(i).Type()); u != nil {
+				return u
+			}
+		}
+	}
+	return nil
+}
+
+// substType replaces type parameters in typ with type arguments of the
+// instance being converted.
+func (c *codegen) substType(typ types.Type) types.Type {
+	if typ == nil || c.scope == nil || len(c.scope.typeArgs) == 0 {
+		return typ
+	}
+	return c.scope.typeArgs.subst(typ)
+}
+
+func (m typeArgs) subst(typ types.Type) types.Type {
+	switch t := typ.(type) {
+	case *types.TypeParam:
+		if r, ok := m[t]; ok {
+			return r
+		}
+	case *types.Pointer:
+		return types.NewPointer(m.subst(t.Elem()))
+	case *types.Slice:
+		return types.NewSlice(m.subst(t.Elem()))
+	case *types.Array:
+		return types.NewArray(m.subst(t.Elem()), t.Len())
+	case *types.Map:
+		return types.NewMap(m.subst(t.Key()), m.subst(t.Elem()))
+	case *types.Tuple:
+		return m.substTuple(t)
+	case *types.Signature:
+		return types.NewSignatureType(nil, nil, nil, m.substTuple(t.Params()), m.substTuple(t.Results()), t.Variadic())
+	case *types.Struct:
+		fields := make([]*types.Var, t.NumFields())
+		tags := make([]string, t.NumFields())
+		for i := range fields {
+			f := t.Field(i)
+			fields[i] = types.NewField(f.Pos(), f.Pkg(), f.Name(), m.subst(f.Type()), f.Embedded())
+			tags[i] = t.Tag(i)
+		}
+		return types.NewStruct(fields, tags)
+	case *types.Named:
+		if t.TypeArgs().Len() == 0 {
+			return t
+		}
+		targs := make([]types.Type, t.TypeArgs().Len())
+		for i := range targs {
+			targs[i] = m.subst(t.TypeArgs().At(i))
+		}
+		if inst, err := types.Instantiate(nil, t.Origin(), targs, false); err == nil {
+			return inst
+		}
+	}
+	return typ
+}
+
+func (m typeArgs) substTuple(t *types.Tuple) *types.Tuple {
+	if t == nil {
+		return nil
+	}
+	vars := make([]*types.Var, t.Len())
+	for i := range vars {
+		v := t.At(i)
+		vars[i] = types.NewParam(v.Pos(), v.Pkg(), v.Name(), m.subst(v.Type()))
+	}
+	return types.NewTuple(vars...)
+}
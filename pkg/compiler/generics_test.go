@@ -0,0 +1,265 @@
+package compiler_test
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/compiler"
+	"github.com/nspcc-dev/neo-go/pkg/vm/stackitem"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenericFunction(t *testing.T) {
+	t.Run("explicit instantiation", func(t *testing.T) {
+		src := `package foo
+		func Max[T ~int | ~string](a, b T) T {
+			if a > b {
+				return a
+			}
+			return b
+		}
+		func Main() int {
+			return Max[int](3, 7) + Max[int](5, 2)
+		}`
+		eval(t, src, big.NewInt(12))
+	})
+	t.Run("inferred type arguments", func(t *testing.T) {
+		src := `package foo
+		func Sum[T ~int | ~string](xs ...T) T {
+			var s T
+			for _, x := range xs {
+				s += x
+			}
+			return s
+		}
+		func Main() string {
+			n := Sum(1, 2, 3)
+			if n != 6 {
+				return "wrong"
+			}
+			return Sum("a", "b", "c")
+		}`
+		eval(t, src, []byte("abc"))
+	})
+	t.Run("function argument", func(t *testing.T) {
+		src := `package foo
+		func Map[T, U any](xs []T, f func(T) U) []U {
+			res := make([]U, len(xs))
+			for i := range xs {
+				res[i] = f(xs[i])
+			}
+			return res
+		}
+		func Main() []string {
+			return Map([]int{1, 2}, func(x int) string {
+				if x == 1 {
+					return "one"
+				}
+				return "two"
+			})
+		}`
+		eval(t, src, []stackitem.Item{
+			stackitem.NewByteArray([]byte("one")),
+			stackitem.NewByteArray([]byte("two")),
+		})
+	})
+	t.Run("nested instantiation", func(t *testing.T) {
+		src := `package foo
+		func first[T any](xs []T) T {
+			return xs[0]
+		}
+		func last[T any](xs []T) T {
+			return xs[len(xs)-1]
+		}
+		func ends[T any](xs []T) []T {
+			return []T{first(xs), last[T](xs)}
+		}
+		func Main() int {
+			bs := ends([][]byte{[]byte{1}, []byte{2}, []byte{3}})
+			is := ends([]int{4, 5, 6})
+			return int(bs[0][0]) + int(bs[1][0])*10 + is[0]*100 + is[1]*1000
+		}`
+		eval(t, src, big.NewInt(6431))
+	})
+	t.Run("recursion", func(t *testing.T) {
+		src := `package foo
+		func count[T comparable](xs []T, i int, x T) int {
+			if i == len(xs) {
+				return 0
+			}
+			n := count(xs, i+1, x)
+			if xs[i] == x {
+				n++
+			}
+			return n
+		}
+		func Main() int {
+			return count([]string{"a", "b", "a"}, 0, "a")*10 + count([]int{1, 2, 3}, 0, 4)
+		}`
+		eval(t, src, big.NewInt(20))
+	})
+	t.Run("struct argument is copied", func(t *testing.T) {
+		src := `package foo
+		type point struct{ x, y int }
+		func id[T any](v T) T {
+			return v
+		}
+		func Main() int {
+			p := point{x: 1, y: 2}
+			q := id(p)
+			q.x = 10
+			return p.x + q.x
+		}`
+		eval(t, src, big.NewInt(11))
+	})
+}
+
+func TestGenericMethodConstraint(t *testing.T) {
+	src := `package foo
+	type Getter interface {
+		Get() int
+	}
+	type num int
+	func (n num) Get() int { return int(n) }
+	type pair struct{ a, b int }
+	func (p *pair) Get() int { return p.a * p.b }
+	func sumAll[T Getter](xs []T) int {
+		var s int
+		for _, x := range xs {
+			s += x.Get()
+		}
+		return s
+	}
+	func Main() int {
+		var g Getter = num(100)
+		return sumAll([]num{1, 2}) + sumAll([]*pair{&pair{a: 2, b: 5}}) + sumAll([]Getter{g})
+	}`
+	eval(t, src, big.NewInt(113))
+}
+
+func TestGenericType(t *testing.T) {
+	t.Run("pointer receiver", func(t *testing.T) {
+		src := `package foo
+		type Stack[T any] struct {
+			items []T
+			n     int
+		}
+		func (s *Stack[T]) Push(x T) {
+			s.items = append(s.items, x)
+			s.n = s.n + 1
+		}
+		func (s *Stack[T]) Pop() T {
+			s.n = s.n - 1
+			return s.items[s.n]
+		}
+		func Main() int {
+			s := &Stack[int]{}
+			s.Push(1)
+			s.Push(2)
+			ss := &Stack[string]{}
+			ss.Push("abc")
+			return s.Pop()*10 + s.Pop() + len(ss.Pop())*100
+		}`
+		eval(t, src, big.NewInt(321))
+	})
+	t.Run("value receiver", func(t *testing.T) {
+		src := `package foo
+		type Pair[K, V comparable] struct {
+			Key K
+			Val V
+		}
+		func (p Pair[K, V]) Swap() Pair[V, K] {
+			return Pair[V, K]{Key: p.Val, Val: p.Key}
+		}
+		func Main() int {
+			p := Pair[string, int]{Key: "a", Val: 42}
+			q := p.Swap()
+			r := q.Swap()
+			if r.Key != "a" {
+				return -1
+			}
+			return q.Key
+		}`
+		eval(t, src, big.NewInt(42))
+	})
+}
+
+func TestGenericImported(t *testing.T) {
+	src := `package foo
+	import "github.com/nspcc-dev/neo-go/pkg/compiler/testdata/generic"
+	func Main() int {
+		s := generic.NewSet("a", "c")
+		xs := generic.Filter[string]([]string{"a", "b", "c", "d"}, func(x string) bool {
+			return !s.Has(x)
+		})
+		return len(xs)*10 + len(generic.Filter([]int{1, 2, 3}, func(x int) bool { return x > 1 }))
+	}`
+	eval(t, src, big.NewInt(22))
+}
+
+func TestGenericDebugInfo(t *testing.T) {
+	src := `package foo
+	func Max[T ~int | ~string](a, b T) T {
+		if a > b {
+			return a
+		}
+		return b
+	}
+	type Box[T any] struct{ v T }
+	func (b Box[T]) Get() T { return b.v }
+	func Main() int {
+		b := Box[int]{v: 1}
+		return Max(1, b.Get()) + len(Max("a", "b"))
+	}`
+	_, di, err := compiler.CompileWithOptions("foo.go", strings.NewReader(src), nil)
+	require.NoError(t, err)
+
+	methods := make(map[string]compiler.MethodDebugInfo)
+	for _, m := range di.Methods {
+		methods[m.ID] = m
+	}
+	require.NotContains(t, methods, "Max")
+	for _, name := range []string{"Max[int]", "Max[string]", "Box[int].Get"} {
+		m, ok := methods[name]
+		require.True(t, ok, name)
+		require.False(t, m.IsExported)
+		require.NotEmpty(t, m.SeqPoints)
+	}
+	require.Equal(t, "Integer", methods["Max[int]"].ReturnType)
+	require.Equal(t, "ByteString", methods["Max[string]"].ReturnType)
+	require.Equal(t, "Integer", methods["Max[int]"].Parameters[0].Type)
+
+	m, err := compiler.CreateManifest(di, &compiler.Options{Name: "Foo"})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(m.ABI.Methods))
+	require.Equal(t, "main", m.ABI.Methods[0].Name)
+}
+
+func TestGenericUnsupportedConstraint(t *testing.T) {
+	t.Run("constraint", func(t *testing.T) {
+		src := `package foo
+		func add[T ~int | ~float64](a, b T) T {
+			return a + b
+		}
+		func Main() int {
+			return add(1, 2)
+		}`
+		_, err := compiler.Compile("foo.go", strings.NewReader(src))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "add[int]: type parameter T has unsupported constraint")
+	})
+	t.Run("type argument", func(t *testing.T) {
+		src := `package foo
+		func id[T any](v T) T {
+			return v
+		}
+		func Main() int {
+			ch := id(make(chan int))
+			return len(ch)
+		}`
+		_, err := compiler.Compile("foo.go", strings.NewReader(src))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "type argument chan int for type parameter T is not supported")
+	})
+}
@@ -1,7 +1,6 @@
 package compiler
 
 import (
-	"fmt"
 	"go/ast"
 	"go/constant"
 	"go/types"
@@ -132,7 +131,7 @@ func (c *codegen) processStdlibCall(f *funcScope, args []ast.Expr) {
 func (c *codegen) processNotify(f *funcScope, args []ast.Expr) {
 	if c.scope != nil && c.isVerifyFunc(c.scope.decl) &&
 		c.scope.pkg == c.mainPkg.Types && !c.buildInfo.options.NoEventsCheck {
-		c.prog.Err = fmt.Errorf("runtime.%s is not allowed in `Verify`", f.name)
+		c.errorf(nil, "runtime.%s is not allowed in `Verify`", f.name)
 		return
 	}
 
@@ -155,7 +154,7 @@ func (c *codegen) processNotify(f *funcScope, args []ast.Expr) {
 
 	name := constant.StringVal(tv.Value)
 	if len(name) > runtime.MaxEventNameLen {
-		c.prog.Err = fmt.Errorf("event name '%s' should be less than %d",
+		c.errorf(args[0], "event name '%s' should be less than %d",
 			name, runtime.MaxEventNameLen)
 		return
 	}
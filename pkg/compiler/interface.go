@@ -0,0 +1,396 @@
+package compiler
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"github.com/nspcc-dev/neo-go/pkg/vm/emit"
+	"github.com/nspcc-dev/neo-go/pkg/vm/opcode"
+)
+
+// Values of interface types with methods are represented as structs
+// containing type tag and the underlying value. Every named type declared
+// at the package level (and a pointer to it) gets its own tag, tag 0 is
+// reserved for nil. Method calls on such values are performed via dispatch
+// functions comparing type tag with tags of all types implementing the
+// interface and jumping to the appropriate method. Empty interfaces are
+// not affected and work the same way they always did.
+
+// dispatcher is a generated function dispatching interface method call.
+type dispatcher struct {
+	label  uint16
+	method *types.Func
+}
+
+// isMethodInterface returns true if typ is an interface with methods.
+func isMethodInterface(typ types.Type) bool {
+	if typ == nil {
+		return false
+	}
+	iface, ok := typ.Underlying().(*types.Interface)
+	return ok && iface.NumMethods() != 0
+}
+
+// collectTypeTags assigns type tags to all named types of the program.
+func (c *codegen) collectTypeTags() {
+	for _, path := range c.packages {
+		if isInteropPath(path) {
+			continue
+		}
+		scope := c.packageCache[path].Types.Scope()
+		for _, name := range scope.Names() {
+			tn, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok || tn.IsAlias() || types.IsInterface(tn.Type()) {
+				continue
+			}
+			if named, ok := tn.Type().(*types.Named); ok && named.TypeParams().Len() != 0 {
+				continue
+			}
+			c.typeTags = append(c.typeTags, tn.Type(), types.NewPointer(tn.Type()))
+		}
+	}
+}
+
+// getTypeTag returns type tag for typ or 0 if there is none.
+func (c *codegen) getTypeTag(typ types.Type) int {
+	for i := range c.typeTags {
+		if types.Identical(c.typeTags[i], typ) {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// getImplementations returns tags of all types implementing iface.
+func (c *codegen) getImplementations(iface *types.Interface) []int {
+	var tags []int
+	for i := range c.typeTags {
+		if types.Implements(c.typeTags[i], iface) {
+			tags = append(tags, i+1)
+		}
+	}
+	return tags
+}
+
+// getMethodImplementation returns the name of the function implementing method m
+// for the type with the specified tag along with the path of embedded field
+// indices leading to the receiver.
+func (c *codegen) getMethodImplementation(tag int, m *types.Func) (string, []int, error) {
+	obj, index, _ := types.LookupFieldOrMethod(c.typeTags[tag-1], true, m.Pkg(), m.Name())
+	fn, ok := obj.(*types.Func)
+	if !ok {
+		return "", nil, fmt.Errorf("%s doesn't have method %s", c.typeTags[tag-1], m.Name())
+	}
+	recv := fn.Type().(*types.Signature).Recv().Type()
+	if ptr, ok := recv.(*types.Pointer); ok {
+		recv = ptr.Elem()
+	}
+	named, ok := recv.(*types.Named)
+	if !ok {
+		return "", nil, fmt.Errorf("unsupported receiver type %s", recv)
+	}
+	return named.Obj().Pkg().Path() + "." + named.Obj().Name() + "." + m.Name(), index[:len(index)-1], nil
+}
+
+// interfaceMethodUsages returns names of all functions which can be called
+// via interface method call expression e.
+func (c *codegen) interfaceMethodUsages(e *ast.SelectorExpr) []string {
+	sel := c.typeInfo.Selections[e]
+	if sel == nil || !isMethodInterface(sel.Recv()) {
+		return nil
+	}
+	m := sel.Obj().(*types.Func)
+	names := []string{}
+	for _, tag := range c.getImplementations(sel.Recv().Underlying().(*types.Interface)) {
+		if name, _, err := c.getMethodImplementation(tag, m); err == nil {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// emitConvertToInterface converts value of type from on top of the stack
+// to the interface type to if needed. Values of interfaces with methods are
+// unwrapped when converted to an empty interface.
+func (c *codegen) emitConvertToInterface(from, to types.Type) {
+	if from == nil || to == nil {
+		return
+	}
+	if isMethodInterface(from) && types.IsInterface(to) && !isMethodInterface(to) {
+		end := c.newLabel()
+		emit.Opcodes(c.prog.BinWriter, opcode.DUP, opcode.ISNULL)
+		emit.Jmp(c.prog.BinWriter, opcode.JMPIFL, end)
+		c.emitLoadField(1)
+		c.setLabel(end)
+		return
+	}
+	if !isMethodInterface(to) || types.IsInterface(from) {
+		return
+	}
+	if b, ok := from.(*types.Basic); ok && b.Kind() == types.UntypedNil {
+		return
+	}
+	tag := c.getTypeTag(from)
+	if tag == 0 {
+		c.errorf(nil, "can't convert %s to interface %s: only package-level named types are supported", from, to)
+		return
+	}
+	emit.Int(c.prog.BinWriter, int64(tag))
+	emit.Int(c.prog.BinWriter, 2)
+	emit.Opcodes(c.prog.BinWriter, opcode.PACKSTRUCT)
+}
+
+// emitInterfaceCall calls interface method m with receiver and arguments
+// already on stack.
+func (c *codegen) emitInterfaceCall(m *types.Func) {
+	for i := range c.dispatchers {
+		if c.dispatchers[i].method == m {
+			emit.Call(c.prog.BinWriter, opcode.CALLL, c.dispatchers[i].label)
+			return
+		}
+	}
+	l := c.newLabel()
+	c.dispatchers = append(c.dispatchers, dispatcher{label: l, method: m})
+	emit.Call(c.prog.BinWriter, opcode.CALLL, l)
+}
+
+// emitDispatchers emits code for all interface method dispatchers. Dispatcher
+// unpacks receiver and jumps directly to the method implementation, so that
+// it's executed in the context created for dispatcher call.
+func (c *codegen) emitDispatchers() {
+	for _, d := range c.dispatchers {
+		iface := d.method.Type().(*types.Signature).Recv().Type().Underlying().(*types.Interface)
+		tags := c.getImplementations(iface)
+		labels := make([]uint16, len(tags))
+
+		c.setLabel(d.label)
+		emit.Opcodes(c.prog.BinWriter, opcode.UNPACK, opcode.DROP)
+		for i, tag := range tags {
+			labels[i] = c.newLabel()
+			emit.Opcodes(c.prog.BinWriter, opcode.DUP)
+			emit.Int(c.prog.BinWriter, int64(tag))
+			emit.Jmp(c.prog.BinWriter, opcode.JMPEQL, labels[i])
+		}
+		emit.String(c.prog.BinWriter, "unknown type of interface value")
+		emit.Opcodes(c.prog.BinWriter, opcode.THROW)
+		for i, tag := range tags {
+			name, path, err := c.getMethodImplementation(tag, d.method)
+			if err != nil {
+				c.errorf(d.method, "%w", err)
+				return
+			}
+			f, ok := c.funcs[name]
+			if !ok {
+				c.errorf(d.method, "method %s is not found", name)
+				return
+			}
+			c.setLabel(labels[i])
+			emit.Opcodes(c.prog.BinWriter, opcode.DROP)
+			for _, j := range path {
+				c.emitLoadField(j)
+			}
+			if typ := c.typeTags[tag-1]; isRefPointer(typ) {
+				if _, ok := f.decl.Recv.List[0].Type.(*ast.StarExpr); !ok {
+					// Method with value receiver called via pointer.
+					c.emitLoadDeref(typ)
+				}
+			}
+			emit.Jmp(c.prog.BinWriter, opcode.JMPL, f.label)
+		}
+	}
+}
+
+// emitMatchTags replaces type tag on top of the stack with true if it's one
+// of the tags specified and false otherwise.
+func (c *codegen) emitMatchTags(tags []int) {
+	found := c.newLabel()
+	end := c.newLabel()
+	for _, tag := range tags {
+		emit.Opcodes(c.prog.BinWriter, opcode.DUP)
+		emit.Int(c.prog.BinWriter, int64(tag))
+		emit.Jmp(c.prog.BinWriter, opcode.JMPEQL, found)
+	}
+	emit.Opcodes(c.prog.BinWriter, opcode.DROP, opcode.PUSHF)
+	emit.Jmp(c.prog.BinWriter, opcode.JMPL, end)
+	c.setLabel(found)
+	emit.Opcodes(c.prog.BinWriter, opcode.DROP, opcode.PUSHT)
+	c.setLabel(end)
+}
+
+// getTypeTags returns tags of values that can be asserted to typ.
+func (c *codegen) getTypeTags(typ types.Type) ([]int, error) {
+	if iface, ok := typ.Underlying().(*types.Interface); ok {
+		return c.getImplementations(iface), nil
+	}
+	tag := c.getTypeTag(typ)
+	if tag == 0 {
+		return nil, fmt.Errorf("unsupported type in type assertion: %s", typ)
+	}
+	return []int{tag}, nil
+}
+
+// emitUnwrapInterface converts interface value on top of the stack to the
+// value of type typ (which is either a concrete type or an interface).
+func (c *codegen) emitUnwrapInterface(typ types.Type) {
+	if !types.IsInterface(typ) {
+		c.emitLoadField(1)
+	}
+}
+
+// emitInterfaceAssert performs type assertion of the interface value on top
+// of the stack. If commaOk is true, it pushes boolean result below the value,
+// otherwise exception is thrown when value has another type.
+func (c *codegen) emitInterfaceAssert(typ types.Type, commaOk bool) {
+	tags, err := c.getTypeTags(typ)
+	if err != nil {
+		c.errorf(nil, "%w", err)
+		return
+	}
+	var (
+		nilL  = c.newLabel()
+		failL = c.newLabel()
+		end   = c.newLabel()
+	)
+	emit.Opcodes(c.prog.BinWriter, opcode.DUP, opcode.ISNULL)
+	emit.Jmp(c.prog.BinWriter, opcode.JMPIFL, nilL)
+	emit.Opcodes(c.prog.BinWriter, opcode.DUP)
+	c.emitLoadField(0)
+	c.emitMatchTags(tags)
+	emit.Jmp(c.prog.BinWriter, opcode.JMPIFNOTL, failL)
+	c.emitUnwrapInterface(typ)
+	if commaOk {
+		emit.Opcodes(c.prog.BinWriter, opcode.PUSHT, opcode.SWAP)
+	}
+	emit.Jmp(c.prog.BinWriter, opcode.JMPL, end)
+
+	c.setLabel(nilL)
+	c.setLabel(failL)
+	emit.Opcodes(c.prog.BinWriter, opcode.DROP)
+	if commaOk {
+		emit.Opcodes(c.prog.BinWriter, opcode.PUSHF)
+		c.emitDefault(typ)
+	} else {
+		emit.String(c.prog.BinWriter, "invalid type assertion")
+		emit.Opcodes(c.prog.BinWriter, opcode.THROW)
+	}
+	c.setLabel(end)
+}
+
+// convertTypeSwitch converts type switch on interface value.
+func (c *codegen) convertTypeSwitch(n *ast.TypeSwitchStmt) {
+	c.scope.vars.newScope()
+	defer c.scope.vars.dropScope()
+
+	if n.Init != nil {
+		ast.Walk(c, n.Init)
+	}
+
+	var (
+		id *ast.Ident
+		x  ast.Expr
+	)
+	switch s := n.Assign.(type) {
+	case *ast.AssignStmt:
+		id = s.Lhs[0].(*ast.Ident)
+		x = s.Rhs[0].(*ast.TypeAssertExpr).X
+	case *ast.ExprStmt:
+		x = s.X.(*ast.TypeAssertExpr).X
+	}
+	if !isMethodInterface(c.typeOf(x)) {
+		c.errorf(x, "type switch is supported only for interfaces with methods")
+		return
+	}
+
+	// Interface value and its tag are kept on stack during the switch.
+	ast.Walk(c, x)
+	nilL := c.newLabel()
+	tagL := c.newLabel()
+	emit.Opcodes(c.prog.BinWriter, opcode.DUP, opcode.DUP, opcode.ISNULL)
+	emit.Jmp(c.prog.BinWriter, opcode.JMPIFL, nilL)
+	c.emitLoadField(0)
+	emit.Jmp(c.prog.BinWriter, opcode.JMPL, tagL)
+	c.setLabel(nilL)
+	emit.Opcodes(c.prog.BinWriter, opcode.DROP, opcode.PUSH0)
+	c.setLabel(tagL)
+
+	switchEnd, label := c.generateLabel(labelEnd)
+	lastSwitch := c.currentSwitch
+	c.currentSwitch = label
+	c.pushStackLabel(label, 2)
+
+	var defaultL uint16
+	var hasDefault bool
+	startLabels := make([]uint16, len(n.Body.List))
+	for i := range n.Body.List {
+		startLabels[i] = c.newLabel()
+		cc := n.Body.List[i].(*ast.CaseClause)
+		if len(cc.List) == 0 {
+			hasDefault = true
+			defaultL = startLabels[i]
+			continue
+		}
+		for _, e := range cc.List {
+			var tags []int
+			if tv := c.typeAndValueOf(e); tv.IsNil() {
+				tags = []int{0}
+			} else {
+				var err error
+				tags, err = c.getTypeTags(tv.Type)
+				if err != nil {
+					c.errorf(e, "%w", err)
+					return
+				}
+			}
+			for _, tag := range tags {
+				emit.Opcodes(c.prog.BinWriter, opcode.DUP)
+				emit.Int(c.prog.BinWriter, int64(tag))
+				emit.Jmp(c.prog.BinWriter, opcode.JMPEQL, startLabels[i])
+			}
+		}
+	}
+	if hasDefault {
+		emit.Jmp(c.prog.BinWriter, opcode.JMPL, defaultL)
+	} else {
+		emit.Jmp(c.prog.BinWriter, opcode.JMPL, switchEnd)
+	}
+
+	for i := range n.Body.List {
+		cc := n.Body.List[i].(*ast.CaseClause)
+		c.scope.vars.newScope()
+		c.setLabel(startLabels[i])
+		if id != nil && id.Name != "_" {
+			typ := c.typeOf(x)
+			if len(cc.List) == 1 && !c.typeAndValueOf(cc.List[0]).IsNil() {
+				typ = c.typeOf(cc.List[0])
+			}
+			emit.Opcodes(c.prog.BinWriter, opcode.OVER)
+			c.emitUnwrapInterface(typ)
+			c.scope.newLocal(id.Name)
+			if obj := c.typeInfo.Implicits[cc]; obj != nil && c.captured[obj] {
+				c.scope.vars.setBoxed(id.Name)
+			}
+			c.emitInitVar(id.Name)
+		}
+		for _, stmt := range cc.Body {
+			ast.Walk(c, stmt)
+		}
+		emit.Jmp(c.prog.BinWriter, opcode.JMPL, switchEnd)
+		c.scope.vars.dropScope()
+	}
+
+	c.setLabel(switchEnd)
+	c.dropStackLabel()
+	c.currentSwitch = lastSwitch
+}
+
+// isCommaOkAssert returns true if e is a type assertion of interface value
+// used in the form of `v, ok := x.(T)`.
+func (c *codegen) isCommaOkAssert(lhs []ast.Expr, rhs []ast.Expr, tok token.Token) bool {
+	if len(lhs) != 2 || len(rhs) != 1 || (tok != token.DEFINE && tok != token.ASSIGN) {
+		return false
+	}
+	ta, ok := rhs[0].(*ast.TypeAssertExpr)
+	return ok && isMethodInterface(c.typeOf(ta.X))
+}
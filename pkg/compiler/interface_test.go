@@ -0,0 +1,285 @@
+package compiler_test
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/compiler"
+	"github.com/nspcc-dev/neo-go/pkg/vm/stackitem"
+	"github.com/stretchr/testify/require"
+)
+
+const storageIfaceSrc = `package foo
+	type Storage interface {
+		Get(key string) int
+		Put(key string, value int)
+	}
+	type mapStorage struct {
+		m map[string]int
+	}
+	func (s *mapStorage) Get(key string) int { return s.m[key] }
+	func (s *mapStorage) Put(key string, value int) { s.m[key] = value }
+	type constStorage int
+	func (s constStorage) Get(key string) int { return int(s) }
+	func (s constStorage) Put(key string, value int) { panic("read-only") }
+	func newMapStorage() Storage {
+		return &mapStorage{m: map[string]int{"a": 0, "b": 0, "x": 0}}
+	}
+	func incr(s Storage, key string) int {
+		s.Put(key, s.Get(key)+1)
+		return s.Get(key)
+	}
+`
+
+func TestInterfaceMethodCall(t *testing.T) {
+	t.Run("pointer receiver", func(t *testing.T) {
+		src := storageIfaceSrc + `
+		func Main() int {
+			s := newMapStorage()
+			incr(s, "a")
+			incr(s, "a")
+			return incr(s, "b") + s.Get("a")*10
+		}`
+		eval(t, src, big.NewInt(21))
+	})
+	t.Run("value receiver", func(t *testing.T) {
+		src := storageIfaceSrc + `
+		func Main() int {
+			var s Storage = constStorage(42)
+			return s.Get("a")
+		}`
+		eval(t, src, big.NewInt(42))
+	})
+	t.Run("different implementations", func(t *testing.T) {
+		src := storageIfaceSrc + `
+		type holder struct {
+			st Storage
+		}
+		func Main() int {
+			ss := []Storage{constStorage(5), newMapStorage()}
+			ss[1].Put("x", 7)
+			h := holder{st: constStorage(100)}
+			sum := h.st.Get("x")
+			for _, s := range ss {
+				sum += s.Get("x")
+			}
+			return sum
+		}`
+		eval(t, src, big.NewInt(112))
+	})
+	t.Run("embedded", func(t *testing.T) {
+		src := storageIfaceSrc + `
+		type wrapped struct {
+			constStorage
+		}
+		func Main() int {
+			var s Storage = wrapped{constStorage(3)}
+			return s.Get("")
+		}`
+		eval(t, src, big.NewInt(3))
+	})
+	t.Run("embedded interface", func(t *testing.T) {
+		src := storageIfaceSrc + `
+		type Getter interface {
+			Get(key string) int
+		}
+		type Full interface {
+			Getter
+			Put(key string, value int)
+		}
+		func get(g Getter) int { return g.Get("a") }
+		func Main() int {
+			var f Full = constStorage(9)
+			var g Getter = f
+			return get(g) + f.Get("")
+		}`
+		eval(t, src, big.NewInt(18))
+	})
+	t.Run("nil", func(t *testing.T) {
+		src := storageIfaceSrc + `
+		func Main() bool {
+			var s Storage
+			return s == nil
+		}`
+		eval(t, src, true)
+	})
+}
+
+func TestInterfaceStructField(t *testing.T) {
+	src := storageIfaceSrc + `
+	type holder struct {
+		st Storage
+	}
+	func Main() int {
+		h := holder{}
+		res := 0
+		if h.st == nil {
+			res = 100
+		}
+		h.st = constStorage(20)
+		res += h.st.Get("")
+		p := &holder{st: newMapStorage()}
+		p.st.Put("a", 3)
+		h.st = p.st
+		return res + h.st.Get("a")
+	}`
+	eval(t, src, big.NewInt(123))
+}
+
+func TestInterfaceMultiAssign(t *testing.T) {
+	src := storageIfaceSrc + `
+	func two() (int, constStorage) {
+		return 2, constStorage(40)
+	}
+	func three() (Storage, int, *mapStorage) {
+		return constStorage(100), 3, &mapStorage{m: map[string]int{"a": 5}}
+	}
+	func Main() int {
+		var (
+			n    int
+			s, m Storage
+		)
+		n, s = two()
+		res := n + s.Get("")
+		m, n, s = three()
+		return res + m.Get("") + n + s.Get("a")
+	}`
+	eval(t, src, big.NewInt(42+100+3+5))
+}
+
+func TestInterfaceEmptyInterface(t *testing.T) {
+	t.Run("unwrap", func(t *testing.T) {
+		src := storageIfaceSrc + `
+		func id(x interface{}) interface{} { return x }
+		func Main() []interface{} {
+			var s Storage = constStorage(42)
+			var e interface{} = s
+			var n Storage
+			return []interface{}{id(s), e, any(s), n}
+		}`
+		eval(t, src, []stackitem.Item{
+			stackitem.Make(42),
+			stackitem.Make(42),
+			stackitem.Make(42),
+			stackitem.Null{},
+		})
+	})
+	t.Run("assertion", func(t *testing.T) {
+		src := storageIfaceSrc + `
+		func Main() int {
+			var e interface{} = constStorage(1)
+			return e.(Storage).Get("")
+		}`
+		_, err := compiler.Compile("foo.go", strings.NewReader(src))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "to interface with methods is not supported")
+	})
+}
+
+func TestInterfaceTypeAssertion(t *testing.T) {
+	t.Run("concrete", func(t *testing.T) {
+		src := storageIfaceSrc + `
+		func Main() int {
+			var s Storage = constStorage(42)
+			return int(s.(constStorage))
+		}`
+		eval(t, src, big.NewInt(42))
+	})
+	t.Run("invalid", func(t *testing.T) {
+		src := storageIfaceSrc + `
+		func Main() int {
+			s := newMapStorage()
+			return int(s.(constStorage))
+		}`
+		v := vmAndCompile(t, src)
+		err := v.Run()
+		require.Error(t, err)
+		require.True(t, strings.Contains(err.Error(), "invalid type assertion"))
+	})
+	t.Run("comma ok", func(t *testing.T) {
+		src := storageIfaceSrc + `
+		func Main() int {
+			s := newMapStorage()
+			res := 0
+			if _, ok := s.(constStorage); ok {
+				res += 1
+			}
+			if m, ok := s.(*mapStorage); ok {
+				m.m["a"] = 10
+				res += 2
+			}
+			var c constStorage
+			var ok bool
+			c, ok = s.(constStorage)
+			if !ok && c == 0 {
+				res += 4
+			}
+			return res + s.Get("a")
+		}`
+		eval(t, src, big.NewInt(16))
+	})
+	t.Run("interface", func(t *testing.T) {
+		src := storageIfaceSrc + `
+		type Closer interface {
+			Close() int
+		}
+		func (s *mapStorage) Close() int { return 7 }
+		func Main() int {
+			var res int
+			ss := []Storage{constStorage(1), newMapStorage()}
+			for i := range ss {
+				if cl, ok := ss[i].(Closer); ok {
+					res += cl.Close() * 10
+				} else {
+					res += ss[i].Get("")
+				}
+			}
+			return res
+		}`
+		eval(t, src, big.NewInt(71))
+	})
+}
+
+func TestInterfaceTypeSwitch(t *testing.T) {
+	src := storageIfaceSrc + `
+	type other int
+	func (o other) Get(string) int { return int(o) }
+	func (o other) Put(string, int) {}
+	func kind(s Storage) int {
+		switch v := s.(type) {
+		case nil:
+			return 0
+		case constStorage:
+			return int(v) * 10
+		case *mapStorage:
+			v.Put("a", 5)
+			return v.Get("a")
+		default:
+			if v.Get("") > 100 {
+				break
+			}
+			return -1
+		}
+		return 100
+	}
+	func Main() int {
+		var s Storage
+		return kind(s) + kind(constStorage(3)) + kind(newMapStorage()) + kind(other(1))*1000 + kind(other(101))
+	}`
+	eval(t, src, big.NewInt(100+30+5-1000))
+}
+
+func TestInterfaceUnsupportedType(t *testing.T) {
+	src := `package foo
+	type Getter interface { Get() int }
+	type impl struct{}
+	func (i impl) Get() int { return 1 }
+	func Main() int {
+		type local struct{ impl }
+		var g Getter = local{}
+		return g.Get()
+	}`
+	_, err := compiler.Compile("foo.go", strings.NewReader(src))
+	require.Error(t, err)
+}
@@ -2,7 +2,11 @@ package compiler_test
 
 import (
 	"math/big"
+	"strings"
 	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/compiler"
+	"github.com/stretchr/testify/require"
 )
 
 func TestFuncLiteral(t *testing.T) {
@@ -26,3 +30,176 @@ func TestCallInPlace(t *testing.T) {
 	}`
 	eval(t, src, big.NewInt(111))
 }
+
+func TestFuncLiteralArgsOrder(t *testing.T) {
+	src := `package foo
+	func Main() int {
+		sub := func(a, b int) int { return a - b }
+		return sub(10, 3)
+	}`
+	eval(t, src, big.NewInt(7))
+}
+
+func TestClosure(t *testing.T) {
+	t.Run("read", func(t *testing.T) {
+		src := `package foo
+		func Main() int {
+			a := 40
+			add := func(x int) int { return a + x }
+			return add(2)
+		}`
+		eval(t, src, big.NewInt(42))
+	})
+	t.Run("modify inside", func(t *testing.T) {
+		src := `package foo
+		func Main() int {
+			cnt := 0
+			inc := func() { cnt++ }
+			inc()
+			inc()
+			return cnt
+		}`
+		eval(t, src, big.NewInt(2))
+	})
+	t.Run("modify outside", func(t *testing.T) {
+		src := `package foo
+		func Main() int {
+			a := 1
+			get := func() int { return a }
+			a = 10
+			b := get()
+			a += 5
+			return b + get()
+		}`
+		eval(t, src, big.NewInt(25))
+	})
+	t.Run("parameter", func(t *testing.T) {
+		src := `package foo
+		func Main() int {
+			return adder(2)(3) + adder(10)(20)
+		}
+		func adder(n int) func(int) int {
+			return func(x int) int {
+				n += x
+				return n
+			}
+		}`
+		eval(t, src, big.NewInt(35))
+	})
+	t.Run("independent instances", func(t *testing.T) {
+		src := `package foo
+		func Main() int {
+			c1 := counter()
+			c2 := counter()
+			c1()
+			c1()
+			c2()
+			return c1()*10 + c2()
+		}
+		func counter() func() int {
+			var cnt int
+			return func() int {
+				cnt++
+				return cnt
+			}
+		}`
+		eval(t, src, big.NewInt(32))
+	})
+	t.Run("struct", func(t *testing.T) {
+		src := `package foo
+		type pair struct { a, b int }
+		func Main() int {
+			p := pair{a: 1, b: 2}
+			swap := func() {
+				tmp := p.a
+				p.a = p.b
+				p.b = tmp
+			}
+			swap()
+			return p.a*10 + p.b
+		}`
+		eval(t, src, big.NewInt(21))
+	})
+	t.Run("nested", func(t *testing.T) {
+		src := `package foo
+		func Main() int {
+			a := 1
+			f := func() func() int {
+				b := 10
+				return func() int {
+					a++
+					b++
+					return a + b
+				}
+			}
+			g := f()
+			g()
+			return g()*100 + a
+		}`
+		eval(t, src, big.NewInt(1503))
+	})
+	t.Run("call in place", func(t *testing.T) {
+		src := `package foo
+		func Main() int {
+			a := 1
+			func() { a = 7 }()
+			return a
+		}`
+		eval(t, src, big.NewInt(7))
+	})
+	t.Run("range variable", func(t *testing.T) {
+		src := `package foo
+		func Main() int {
+			var fs []func() int
+			for _, v := range []int{1, 2, 3} {
+				fs = append(fs, func() int { return v })
+			}
+			sum := 0
+			for i := range fs {
+				sum = sum*10 + fs[i]()
+			}
+			return sum
+		}`
+		eval(t, src, big.NewInt(123))
+	})
+	t.Run("callback", func(t *testing.T) {
+		src := `package foo
+		func Main() int {
+			sum := 0
+			forEach([]int{1, 2, 3, 4}, func(x int) { sum += x })
+			return sum
+		}
+		func forEach(arr []int, f func(int)) {
+			for i := range arr {
+				f(arr[i])
+			}
+		}`
+		eval(t, src, big.NewInt(10))
+	})
+	t.Run("named result", func(t *testing.T) {
+		src := `package foo
+		func Main() int {
+			return f()
+		}
+		func f() (res int) {
+			set := func(v int) { res = v * 2 }
+			set(21)
+			return
+		}`
+		eval(t, src, big.NewInt(42))
+	})
+	t.Run("struct field", func(t *testing.T) {
+		src := `package foo
+		type S struct {
+			f func() int
+		}
+		func Main() int {
+			a := 42
+			s := S{f: func() int { return a }}
+			return s.f()
+		}`
+		_, err := compiler.Compile("foo.go", strings.NewReader(src))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "calling function stored in struct field f is not supported")
+	})
+}
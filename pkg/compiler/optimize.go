@@ -0,0 +1,802 @@
+package compiler
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+
+	"github.com/nspcc-dev/neo-go/pkg/encoding/bigint"
+	"github.com/nspcc-dev/neo-go/pkg/io"
+	"github.com/nspcc-dev/neo-go/pkg/vm"
+	"github.com/nspcc-dev/neo-go/pkg/vm/emit"
+	"github.com/nspcc-dev/neo-go/pkg/vm/opcode"
+	"github.com/nspcc-dev/neo-go/pkg/vm/stackitem"
+)
+
+// OptimizationLevel specifies optimizations performed on the compiled program.
+type OptimizationLevel byte
+
+const (
+	// OptimizeNone disables all optimizations except for short jumps usage
+	// which is always performed.
+	OptimizeNone OptimizationLevel = iota
+	// OptimizeBasic enables peephole optimizations and unreachable code
+	// elimination.
+	OptimizeBasic
+	// OptimizeFull enables constant folding, dead methods elimination and
+	// local slots renumbering in addition to OptimizeBasic optimizations.
+	OptimizeFull
+)
+
+// instruction is a single instruction of the program being optimized.
+type instruction struct {
+	op opcode.Opcode
+	// operand is a raw instruction operand (including data length prefix
+	// for PUSHDATA*). For jumps it's recalculated when program is encoded.
+	operand []byte
+	// offset is an instruction offset in the original program.
+	offset int
+	// targets contains offsets of jump targets in the original program,
+	// absent TRY targets are -1.
+	targets []int
+	removed bool
+}
+
+// optimizer performs optimizations on the program. The program is decoded
+// into a list of instructions referencing each other via original offsets,
+// so removing an instruction doesn't require any jumps adjustments until
+// the program is encoded again.
+type optimizer struct {
+	c    *codegen
+	prog []instruction
+	// deadFuncs contains functions removed by dead methods elimination.
+	deadFuncs map[*funcScope]bool
+	// slots maps original offsets of INITSLOT instructions to the current
+	// indices of local slots of the function (-1 for the removed ones).
+	slots map[int][]int
+}
+
+// optimize performs optimizations of the specified level on the program b
+// which has all jumps resolved and returns the optimized program. Functions
+// ranges and sequence points are updated accordingly.
+func (c *codegen) optimize(b []byte, level OptimizationLevel) ([]byte, error) {
+	if level == OptimizeNone {
+		return b, nil
+	}
+	if level > OptimizeFull {
+		return nil, fmt.Errorf("invalid optimization level: %d", level)
+	}
+	o := &optimizer{
+		c:         c,
+		deadFuncs: make(map[*funcScope]bool),
+		slots:     make(map[int][]int),
+	}
+	if err := o.decode(b); err != nil {
+		return nil, err
+	}
+	for changed := true; changed; {
+		changed = o.peephole()
+		changed = o.removeUnreachable() || changed
+		if level >= OptimizeFull {
+			changed = o.foldConstants() || changed
+			changed = o.optimizeSlots() || changed
+			changed = o.removeDeadFuncs() || changed
+		}
+	}
+	if level >= OptimizeFull {
+		o.updateVariables()
+	}
+	return o.encode(), nil
+}
+
+func (o *optimizer) decode(b []byte) error {
+	ctx := vm.NewContext(b)
+	for ctx.NextIP() < len(b) {
+		op, param, err := ctx.Next()
+		if err != nil {
+			return fmt.Errorf("can't decode instruction at %d: %w", ctx.IP(), err)
+		}
+		ip := ctx.IP()
+		in := instruction{
+			op:      op,
+			operand: b[ip+1 : ctx.NextIP()],
+			offset:  ip,
+		}
+		switch op {
+		case opcode.JMP, opcode.JMPIF, opcode.JMPIFNOT, opcode.JMPEQ, opcode.JMPNE,
+			opcode.JMPGT, opcode.JMPGE, opcode.JMPLT, opcode.JMPLE,
+			opcode.CALL, opcode.ENDTRY:
+			in.targets = []int{ip + int(int8(param[0]))}
+		case opcode.JMPL, opcode.JMPIFL, opcode.JMPIFNOTL, opcode.JMPEQL, opcode.JMPNEL,
+			opcode.JMPGTL, opcode.JMPGEL, opcode.JMPLTL, opcode.JMPLEL,
+			opcode.CALLL, opcode.PUSHA, opcode.ENDTRYL:
+			in.targets = []int{ip + int(int32(binary.LittleEndian.Uint32(param)))}
+		case opcode.TRY:
+			in.targets = []int{tryTarget(ip, int(int8(param[0]))), tryTarget(ip, int(int8(param[1])))}
+		case opcode.TRYL:
+			in.targets = []int{
+				tryTarget(ip, int(int32(binary.LittleEndian.Uint32(param)))),
+				tryTarget(ip, int(int32(binary.LittleEndian.Uint32(param[4:])))),
+			}
+		}
+		o.prog = append(o.prog, in)
+	}
+	return nil
+}
+
+func tryTarget(ip, offset int) int {
+	if offset == 0 {
+		return -1
+	}
+	return ip + offset
+}
+
+// next returns the index of the next instruction which is not removed
+// or -1 if there is none.
+func (o *optimizer) next(i int) int {
+	for i++; i < len(o.prog); i++ {
+		if !o.prog[i].removed {
+			return i
+		}
+	}
+	return -1
+}
+
+// find returns the index of the first instruction which is not removed and
+// has offset not less than the specified one.
+func (o *optimizer) find(offset int) int {
+	i := sort.Search(len(o.prog), func(i int) bool { return o.prog[i].offset >= offset })
+	if i < len(o.prog) && o.prog[i].removed {
+		i = o.next(i)
+	}
+	if i < 0 {
+		return len(o.prog)
+	}
+	return i
+}
+
+// remove removes the instruction with index i moving its label (if any)
+// to the next instruction.
+func (o *optimizer) remove(i int, labels map[int]bool) {
+	o.prog[i].removed = true
+	if labels[i] {
+		if j := o.next(i); j >= 0 {
+			labels[j] = true
+		}
+	}
+}
+
+// funcs returns all functions which can be called (that is, have their own
+// code and are not removed). Functions inlined into `init` or `_deploy`
+// don't have labels set and are not returned.
+func (o *optimizer) funcs() []*funcScope {
+	var fs []*funcScope
+	for _, f := range o.c.funcs {
+		if o.c.l[f.label] >= 0 && !o.deadFuncs[f] {
+			fs = append(fs, f)
+		}
+	}
+	for _, f := range o.c.instances {
+		if !o.deadFuncs[f] {
+			fs = append(fs, f)
+		}
+	}
+	return fs
+}
+
+// labels returns the set of indices of instructions control can be
+// transferred to not only from the previous instruction.
+func (o *optimizer) labels() map[int]bool {
+	labels := map[int]bool{o.find(0): true}
+	if o.c.deployEndOffset >= 0 {
+		labels[o.find(o.c.initEndOffset+1)] = true
+	}
+	for _, f := range o.funcs() {
+		labels[o.find(int(f.rng.Start))] = true
+	}
+	for i := range o.prog {
+		if o.prog[i].removed {
+			continue
+		}
+		for _, t := range o.prog[i].targets {
+			if t >= 0 {
+				labels[o.find(t)] = true
+			}
+		}
+	}
+	return labels
+}
+
+// peephole replaces short instruction sequences with the more effective ones.
+func (o *optimizer) peephole() bool {
+	var changed bool
+	labels := o.labels()
+	for i := o.next(-1); i >= 0; i = o.next(i) {
+		j := o.next(i)
+		if j < 0 {
+			break
+		}
+		a, b := &o.prog[i], &o.prog[j]
+		switch {
+		case a.op == opcode.NOP:
+			// NOP
+		case isPurePush(a.op) && b.op == opcode.DROP && !labels[j]:
+			// PUSH* DROP, DUP DROP, LDLOC DROP
+			b.removed = true
+		case (a.op == opcode.JMP || a.op == opcode.JMPL) && o.find(a.targets[0]) == j:
+			// JMP to the next instruction
+		default:
+			if !labels[j] && isSlotNoop(a, b) {
+				// LDLOC x STLOC x
+				b.removed = true
+				break
+			}
+			continue
+		}
+		o.remove(i, labels)
+		changed = true
+	}
+	return changed
+}
+
+// removeUnreachable removes instructions following unconditional control
+// transfers which can't be reached via jumps.
+func (o *optimizer) removeUnreachable() bool {
+	var changed bool
+	labels := o.labels()
+	for i := o.next(-1); i >= 0; i = o.next(i) {
+		switch o.prog[i].op {
+		case opcode.RET, opcode.THROW, opcode.ABORT, opcode.JMP, opcode.JMPL,
+			opcode.ENDTRY, opcode.ENDTRYL, opcode.ENDFINALLY:
+		default:
+			continue
+		}
+		for j := o.next(i); j >= 0 && !labels[j]; j = o.next(j) {
+			o.prog[j].removed = true
+			changed = true
+		}
+	}
+	return changed
+}
+
+// foldConstants evaluates arithmetic operations on constant integers.
+func (o *optimizer) foldConstants() bool {
+	var changed bool
+	labels := o.labels()
+	for i := o.next(-1); i >= 0; i = o.next(i) {
+		a, ok := intValue(&o.prog[i])
+		j := o.next(i)
+		if !ok || j < 0 || labels[j] {
+			continue
+		}
+		if res, ok := evalUnary(o.prog[j].op, a); ok {
+			changed = o.replaceWithConst([]int{i, j}, res) || changed
+			continue
+		}
+		b, ok := intValue(&o.prog[j])
+		k := o.next(j)
+		if !ok || k < 0 || labels[k] {
+			continue
+		}
+		if res, ok := evalBinary(o.prog[k].op, a, b); ok {
+			changed = o.replaceWithConst([]int{i, j, k}, res) || changed
+		}
+	}
+	return changed
+}
+
+// replaceWithConst replaces instructions with the specified indices with
+// the code pushing res on stack if it's not longer than the original code.
+func (o *optimizer) replaceWithConst(indices []int, res interface{}) bool {
+	w := io.NewBufBinWriter()
+	switch r := res.(type) {
+	case *big.Int:
+		emit.BigInt(w.BinWriter, r)
+	case bool:
+		emit.Bool(w.BinWriter, r)
+	}
+	if w.Err != nil {
+		return false
+	}
+	code := w.Bytes()
+	var size int
+	for _, i := range indices {
+		size += 1 + len(o.prog[i].operand)
+	}
+	if len(code) > size {
+		return false
+	}
+	var repl []instruction
+	ctx := vm.NewContext(code)
+	for ctx.NextIP() < len(code) {
+		op, _, _ := ctx.Next()
+		repl = append(repl, instruction{op: op, operand: code[ctx.IP()+1 : ctx.NextIP()]})
+	}
+	for n, i := range indices {
+		if n < len(repl) {
+			repl[n].offset = o.prog[i].offset
+			o.prog[i] = repl[n]
+		} else {
+			o.prog[i].removed = true
+		}
+	}
+	return true
+}
+
+// intValue returns integer pushed by the instruction if it's a constant.
+func intValue(in *instruction) (*big.Int, bool) {
+	switch {
+	case in.op == opcode.PUSHM1:
+		return big.NewInt(-1), true
+	case opcode.PUSH0 <= in.op && in.op <= opcode.PUSH16:
+		return big.NewInt(int64(in.op - opcode.PUSH0)), true
+	case opcode.PUSHINT8 <= in.op && in.op <= opcode.PUSHINT256:
+		return bigint.FromBytes(in.operand), true
+	}
+	return nil, false
+}
+
+func evalUnary(op opcode.Opcode, a *big.Int) (interface{}, bool) {
+	var res = new(big.Int)
+	switch op {
+	case opcode.NEGATE:
+		res.Neg(a)
+	case opcode.INC:
+		res.Add(a, big.NewInt(1))
+	case opcode.DEC:
+		res.Sub(a, big.NewInt(1))
+	case opcode.ABS:
+		res.Abs(a)
+	case opcode.SIGN:
+		res.SetInt64(int64(a.Sign()))
+	default:
+		return nil, false
+	}
+	return res, stackitem.CheckIntegerSize(res) == nil
+}
+
+func evalBinary(op opcode.Opcode, a, b *big.Int) (interface{}, bool) {
+	var res = new(big.Int)
+	switch op {
+	case opcode.ADD:
+		res.Add(a, b)
+	case opcode.SUB:
+		res.Sub(a, b)
+	case opcode.MUL:
+		res.Mul(a, b)
+	case opcode.DIV, opcode.MOD:
+		if b.Sign() == 0 {
+			return nil, false
+		}
+		if op == opcode.DIV {
+			res.Quo(a, b)
+		} else {
+			res.Rem(a, b)
+		}
+	case opcode.AND:
+		res.And(a, b)
+	case opcode.OR:
+		res.Or(a, b)
+	case opcode.XOR:
+		res.Xor(a, b)
+	case opcode.SHL, opcode.SHR:
+		if b.Sign() < 0 || b.Cmp(big.NewInt(stackitem.MaxBigIntegerSizeBits)) > 0 {
+			return nil, false
+		}
+		if op == opcode.SHL {
+			res.Lsh(a, uint(b.Uint64()))
+		} else {
+			res.Rsh(a, uint(b.Uint64()))
+		}
+	case opcode.MIN, opcode.MAX:
+		res.Set(a)
+		if (op == opcode.MIN) == (a.Cmp(b) > 0) {
+			res.Set(b)
+		}
+	case opcode.NUMEQUAL:
+		return a.Cmp(b) == 0, true
+	case opcode.NUMNOTEQUAL:
+		return a.Cmp(b) != 0, true
+	case opcode.LT:
+		return a.Cmp(b) < 0, true
+	case opcode.LE:
+		return a.Cmp(b) <= 0, true
+	case opcode.GT:
+		return a.Cmp(b) > 0, true
+	case opcode.GE:
+		return a.Cmp(b) >= 0, true
+	default:
+		return nil, false
+	}
+	return res, stackitem.CheckIntegerSize(res) == nil
+}
+
+// isPurePush returns true if op only pushes an item on stack.
+func isPurePush(op opcode.Opcode) bool {
+	switch {
+	case opcode.PUSHINT8 <= op && op <= opcode.PUSHDATA4,
+		opcode.PUSHM1 <= op && op <= opcode.PUSH16,
+		op == opcode.DUP, op == opcode.OVER:
+		return true
+	}
+	_, _, isStore, ok := slotAccess(op, nil)
+	return ok && !isStore
+}
+
+// slotAccess returns the slot type (local, argument or static), index and
+// the kind of access (load or store) performed by the instruction.
+func slotAccess(op opcode.Opcode, operand []byte) (varType, int, bool, bool) {
+	type slotOps struct {
+		t          varType
+		load, stor opcode.Opcode
+	}
+	for _, s := range []slotOps{
+		{varLocal, opcode.LDLOC0, opcode.STLOC0},
+		{varArgument, opcode.LDARG0, opcode.STARG0},
+		{varGlobal, opcode.LDSFLD0, opcode.STSFLD0},
+	} {
+		for _, base := range []opcode.Opcode{s.load, s.stor} {
+			if base <= op && op < base+7 {
+				return s.t, int(op - base), base == s.stor, true
+			}
+			if op == base+7 {
+				var i = -1
+				if len(operand) != 0 {
+					i = int(operand[0])
+				}
+				return s.t, i, base == s.stor, true
+			}
+		}
+	}
+	return 0, 0, false, false
+}
+
+// isSlotNoop returns true if a loads item from the slot and b stores it back.
+func isSlotNoop(a, b *instruction) bool {
+	ta, ia, sa, oka := slotAccess(a.op, a.operand)
+	tb, ib, sb, okb := slotAccess(b.op, b.operand)
+	return oka && okb && !sa && sb && ta == tb && ia == ib
+}
+
+// localSlotAccess returns the shortest instruction accessing local slot i.
+func localSlotAccess(i int, isStore bool) (opcode.Opcode, []byte) {
+	base := opcode.LDLOC0
+	if isStore {
+		base = opcode.STLOC0
+	}
+	if i < 7 {
+		return base + opcode.Opcode(i), nil
+	}
+	return base + 7, []byte{byte(i)}
+}
+
+// optimizeSlots removes unused local slots and stores into slots which are
+// never loaded and renumbers the remaining slots in every function. Functions
+// are delimited by INITSLOT instructions here as they're the only ones
+// creating local slots.
+func (o *optimizer) optimizeSlots() bool {
+	var changed bool
+	labels := o.labels()
+	for i := o.next(-1); i >= 0; i = o.next(i) {
+		if o.prog[i].op != opcode.INITSLOT {
+			continue
+		}
+		var (
+			end   = i
+			loads = make(map[int]int)
+			refs  = make(map[int]bool)
+		)
+		for j := o.next(i); j >= 0 && o.prog[j].op != opcode.INITSLOT; j = o.next(j) {
+			end = j
+			if t, n, isStore, ok := slotAccess(o.prog[j].op, o.prog[j].operand); ok && t == varLocal {
+				refs[n] = true
+				if !isStore {
+					loads[n]++
+				}
+			}
+		}
+		// Instructions which are still to be processed.
+		body := func(f func(j int, in *instruction, n int, isStore bool)) {
+			for j := o.next(i); j >= 0 && j <= end; j = o.next(j) {
+				in := &o.prog[j]
+				if t, n, isStore, ok := slotAccess(in.op, in.operand); ok && t == varLocal {
+					f(j, in, n, isStore)
+				}
+			}
+		}
+		body(func(j int, in *instruction, n int, isStore bool) {
+			if !isStore || loads[n] != 1 {
+				return
+			}
+			k := o.next(j)
+			if k >= 0 && !labels[k] && isSameLocalLoad(&o.prog[k], n) {
+				o.prog[k].removed = true
+				o.remove(j, labels)
+				loads[n]--
+				changed = true
+			}
+		})
+		body(func(j int, in *instruction, n int, isStore bool) {
+			if isStore && loads[n] == 0 {
+				in.op, in.operand = opcode.DROP, nil
+				changed = true
+			}
+		})
+
+		// Renumber slots.
+		var used []int
+		for n := range refs {
+			if loads[n] != 0 {
+				used = append(used, n)
+			}
+		}
+		sort.Ints(used)
+		index := make(map[int]int, len(used))
+		for k, n := range used {
+			index[n] = k
+		}
+		o.renumberSlots(&o.prog[i], index)
+		body(func(j int, in *instruction, n int, isStore bool) {
+			if k := index[n]; k != n {
+				// Only used slots are left at this point.
+				in.op, in.operand = localSlotAccess(k, isStore)
+				changed = true
+			}
+		})
+		initSlot := &o.prog[i]
+		if int(initSlot.operand[0]) != len(used) {
+			initSlot.operand = []byte{byte(len(used)), initSlot.operand[1]}
+			changed = true
+		}
+		if len(used) == 0 && initSlot.operand[1] == 0 {
+			o.remove(i, labels)
+		}
+	}
+	return changed
+}
+
+// renumberSlots records the new local slot indices of the function starting
+// with the specified INITSLOT instruction, slots absent from index are removed.
+func (o *optimizer) renumberSlots(initSlot *instruction, index map[int]int) {
+	m, ok := o.slots[initSlot.offset]
+	if !ok {
+		m = make([]int, initSlot.operand[0])
+		for n := range m {
+			m[n] = n
+		}
+		o.slots[initSlot.offset] = m
+	}
+	for n := range m {
+		if m[n] < 0 {
+			continue
+		}
+		if k, ok := index[m[n]]; ok {
+			m[n] = k
+		} else {
+			m[n] = -1
+		}
+	}
+}
+
+// updateVariables makes debug info variables match local slots renumbered by
+// optimizeSlots. Variables are ordered by their slots, the ones without slot
+// are removed.
+func (o *optimizer) updateVariables() {
+	update := func(vars []debugVariable, start, end int) []debugVariable {
+		var m []int
+		for i := sort.Search(len(o.prog), func(i int) bool { return o.prog[i].offset >= start }); i < len(o.prog) && o.prog[i].offset <= end; i++ {
+			if o.prog[i].op == opcode.INITSLOT {
+				m = o.slots[o.prog[i].offset]
+				break
+			}
+		}
+		res := vars[:0]
+		for _, v := range vars {
+			if 0 <= v.slot && v.slot < len(m) && m[v.slot] >= 0 {
+				v.slot = m[v.slot]
+				res = append(res, v)
+			}
+		}
+		sort.SliceStable(res, func(i, j int) bool { return res[i].slot < res[j].slot })
+		return res
+	}
+	for _, f := range o.funcs() {
+		f.variables = update(f.variables, int(f.rng.Start), int(f.rng.End))
+	}
+	if o.c.initEndOffset > 0 {
+		o.c.initVariables = update(o.c.initVariables, 0, o.c.initEndOffset)
+	}
+	if o.c.deployEndOffset >= 0 {
+		o.c.deployVariables = update(o.c.deployVariables, o.c.initEndOffset+1, o.c.deployEndOffset)
+	}
+}
+
+func isSameLocalLoad(in *instruction, n int) bool {
+	t, k, isStore, ok := slotAccess(in.op, in.operand)
+	return ok && t == varLocal && !isStore && k == n
+}
+
+// removeDeadFuncs removes functions which are not entry points and can't be
+// called from other functions. All code outside of function ranges (like
+// lambdas, `init` or `_deploy`) is considered to be reachable.
+func (o *optimizer) removeDeadFuncs() bool {
+	funcs := o.funcs()
+	sort.Slice(funcs, func(i, j int) bool { return funcs[i].rng.Start < funcs[j].rng.Start })
+	funcAt := func(offset int) *funcScope {
+		k := sort.Search(len(funcs), func(k int) bool { return int(funcs[k].rng.End) >= offset })
+		if k < len(funcs) && int(funcs[k].rng.Start) <= offset {
+			return funcs[k]
+		}
+		return nil
+	}
+
+	reachable := make(map[*funcScope]bool)
+	var queue []*funcScope
+	for _, f := range funcs {
+		if f.pkg == o.c.mainPkg.Types && f.decl.Name.IsExported() && f.typeArgs == nil {
+			reachable[f] = true
+			queue = append(queue, f)
+		}
+	}
+	visit := func(in *instruction) {
+		for _, t := range in.targets {
+			if f := funcAt(t); t >= 0 && f != nil && !reachable[f] {
+				reachable[f] = true
+				queue = append(queue, f)
+			}
+		}
+	}
+	for i := range o.prog {
+		if !o.prog[i].removed && funcAt(o.prog[i].offset) == nil {
+			visit(&o.prog[i])
+		}
+	}
+	for len(queue) != 0 {
+		f := queue[0]
+		queue = queue[1:]
+		for i := o.find(int(f.rng.Start)); i < len(o.prog) && o.prog[i].offset <= int(f.rng.End); i++ {
+			if !o.prog[i].removed {
+				visit(&o.prog[i])
+			}
+		}
+	}
+
+	var changed bool
+	for _, f := range funcs {
+		if reachable[f] {
+			continue
+		}
+		for i := o.find(int(f.rng.Start)); i < len(o.prog) && o.prog[i].offset <= int(f.rng.End); i++ {
+			o.prog[i].removed = true
+		}
+		o.deadFuncs[f] = true
+		changed = true
+	}
+	return changed
+}
+
+// encode encodes the optimized program and updates offsets of functions
+// and sequence points.
+func (o *optimizer) encode() []byte {
+	var prog []*instruction
+	for i := range o.prog {
+		if !o.prog[i].removed {
+			prog = append(prog, &o.prog[i])
+		}
+	}
+
+	// offsets contains new offsets of all instructions from o.prog (removed
+	// ones get the offset of the next instruction).
+	offsets := make([]int, len(o.prog)+1)
+	newOffset := func(old int) int {
+		return offsets[o.find(old)]
+	}
+	calcOffsets := func() {
+		var n int
+		for i := range o.prog {
+			offsets[i] = n
+			if !o.prog[i].removed {
+				n += 1 + len(o.prog[i].operand)
+			}
+		}
+		offsets[len(o.prog)] = n
+	}
+	fitsShort := func(in *instruction) bool {
+		for _, t := range in.targets {
+			if t < 0 {
+				continue
+			}
+			d := newOffset(t) - newOffset(in.offset)
+			if d < math.MinInt8 || d > math.MaxInt8 {
+				return false
+			}
+		}
+		return true
+	}
+
+	// Removing instructions can make some long jumps short.
+	for shortened := true; shortened; {
+		shortened = false
+		calcOffsets()
+		for _, in := range prog {
+			switch in.op {
+			case opcode.JMPL, opcode.JMPIFL, opcode.JMPIFNOTL, opcode.JMPEQL, opcode.JMPNEL,
+				opcode.JMPGTL, opcode.JMPGEL, opcode.JMPLTL, opcode.JMPLEL,
+				opcode.CALLL, opcode.ENDTRYL:
+				if fitsShort(in) {
+					in.op, in.operand = toShortForm(in.op), make([]byte, 1)
+					shortened = true
+				}
+			case opcode.TRYL:
+				if fitsShort(in) {
+					in.op, in.operand = opcode.TRY, make([]byte, 2)
+					shortened = true
+				}
+			}
+		}
+	}
+
+	w := io.NewBufBinWriter()
+	for _, in := range prog {
+		ip := newOffset(in.offset)
+		rel := make([]int, len(in.targets))
+		for i, t := range in.targets {
+			if t >= 0 {
+				rel[i] = newOffset(t) - ip
+			}
+		}
+		switch len(in.operand) {
+		case 1, 2:
+			for i := range rel {
+				in.operand[i] = byte(rel[i])
+			}
+		case 4, 8:
+			for i := range rel {
+				binary.LittleEndian.PutUint32(in.operand[4*i:], uint32(rel[i]))
+			}
+		}
+		emit.Instruction(w.BinWriter, in.op, in.operand)
+	}
+
+	// lastOffset returns the new offset of the last instruction in the range.
+	lastOffset := func(start, end int) int {
+		i := o.find(start)
+		for j := i; j >= 0 && j < len(o.prog) && o.prog[j].offset <= end; j = o.next(j) {
+			i = j
+		}
+		return offsets[i]
+	}
+	for name, f := range o.c.funcs {
+		if o.deadFuncs[f] {
+			delete(o.c.funcs, name)
+		} else if f.rng.Start != f.rng.End || o.c.l[f.label] >= 0 {
+			end := lastOffset(int(f.rng.Start), int(f.rng.End))
+			f.rng.Start, f.rng.End = uint16(newOffset(int(f.rng.Start))), uint16(end)
+		}
+	}
+	var instances []*funcScope
+	for _, f := range o.c.instances {
+		if !o.deadFuncs[f] {
+			end := lastOffset(int(f.rng.Start), int(f.rng.End))
+			f.rng.Start, f.rng.End = uint16(newOffset(int(f.rng.Start))), uint16(end)
+			instances = append(instances, f)
+		}
+	}
+	o.c.instances = instances
+	if o.c.deployEndOffset >= 0 {
+		end := lastOffset(o.c.initEndOffset+1, o.c.deployEndOffset)
+		o.c.deployEndOffset = end
+	}
+	if o.c.initEndOffset > 0 {
+		end := lastOffset(0, o.c.initEndOffset)
+		o.c.initEndOffset = end
+	}
+	for _, points := range o.c.sequencePoints {
+		for i := range points {
+			points[i].Opcode = newOffset(points[i].Opcode)
+		}
+	}
+	return w.Bytes()
+}
@@ -0,0 +1,275 @@
+package compiler_test
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/compiler"
+	"github.com/nspcc-dev/neo-go/pkg/vm"
+	"github.com/nspcc-dev/neo-go/pkg/vm/opcode"
+	"github.com/stretchr/testify/require"
+)
+
+var optimizationLevels = []compiler.OptimizationLevel{
+	compiler.OptimizeNone,
+	compiler.OptimizeBasic,
+	compiler.OptimizeFull,
+}
+
+func compileOptimized(t *testing.T, src string, level compiler.OptimizationLevel) ([]byte, *compiler.DebugInfo) {
+	ne, di, err := compiler.CompileWithOptions("foo.go", strings.NewReader(src), &compiler.Options{Optimize: level})
+	require.NoError(t, err)
+	return ne.Script, di
+}
+
+func methodOpcodes(t *testing.T, script []byte, di *compiler.DebugInfo, name string) []opcode.Opcode {
+	for _, m := range di.Methods {
+		if m.ID != name {
+			continue
+		}
+		var ops []opcode.Opcode
+		ctx := vm.NewContext(script[:m.Range.End+1])
+		ctx.Jump(int(m.Range.Start))
+		for ctx.NextIP() <= int(m.Range.End) {
+			op, _, err := ctx.Next()
+			require.NoError(t, err)
+			ops = append(ops, op)
+		}
+		return ops
+	}
+	t.Fatalf("method %s not found", name)
+	return nil
+}
+
+func TestOptimize(t *testing.T) {
+	testCases := []testCase{
+		{
+			"loop",
+			`package foo
+			func Main() int {
+				var sum int
+				for i := 0; i < 10; i++ {
+					if i%2 == 0 {
+						continue
+					}
+					sum += i * 2
+				}
+				return sum
+			}`,
+			big.NewInt(50),
+		},
+		{
+			"closure",
+			`package foo
+			func Main() int {
+				x := 1
+				inc := func(n int) { x += n }
+				inc(2)
+				inc(3)
+				return x
+			}`,
+			big.NewInt(6),
+		},
+		{
+			"defer",
+			`package foo
+			var i int
+			func Main() int {
+				return f() + i
+			}
+			func f() int {
+				defer func() {
+					i += 40
+					recover()
+				}()
+				i = 2
+				panic("oops")
+			}`,
+			big.NewInt(42),
+		},
+		{
+			"interface",
+			`package foo
+			type shape interface{ area() int }
+			type rect struct{ w, h int }
+			func (r rect) area() int { return r.w * r.h }
+			type square int
+			func (s square) area() int { return int(s) * int(s) }
+			func Main() int {
+				shapes := []shape{rect{w: 2, h: 3}, square(4)}
+				var total int
+				for _, s := range shapes {
+					total += s.area()
+				}
+				return total
+			}`,
+			big.NewInt(22),
+		},
+		{
+			"generics",
+			`package foo
+			func Max[T ~int | ~string](a, b T) T {
+				if a > b {
+					return a
+				}
+				return b
+			}
+			func Main() int {
+				return Max(3, 7) + len(Max("a", "bc"))
+			}`,
+			big.NewInt(9),
+		},
+		{
+			"dead code",
+			`package foo
+			func helper(a int) int {
+				b := a + 1
+				return b
+			}
+			func Main() int {
+				x := 2 + 3
+				unused := 10
+				_ = unused
+				if x > 0 {
+					return x * 4
+				}
+				return helper(x)
+			}`,
+			big.NewInt(20),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var sizes []int
+			for _, level := range optimizationLevels {
+				script, di := compileOptimized(t, tc.src, level)
+				v := vm.New()
+				invokeMethod(t, testMainIdent, script, v, di)
+				runAndCheck(t, v, tc.result)
+				sizes = append(sizes, len(script))
+			}
+			require.LessOrEqual(t, sizes[1], sizes[0])
+			require.LessOrEqual(t, sizes[2], sizes[1])
+		})
+	}
+}
+
+func TestOptimizeConstantFolding(t *testing.T) {
+	src := `package foo
+	func Main() bool {
+		a := 7
+		b := a * 6
+		return b > 40
+	}`
+	script, di := compileOptimized(t, src, compiler.OptimizeFull)
+	require.Equal(t, []opcode.Opcode{opcode.PUSHT, opcode.CONVERT, opcode.RET},
+		methodOpcodes(t, script, di, "Main"))
+
+	v := vm.New()
+	invokeMethod(t, testMainIdent, script, v, di)
+	runAndCheck(t, v, true)
+}
+
+func TestOptimizeDeadFunctions(t *testing.T) {
+	src := `package foo
+	func helper() int { return 42 }
+	func Main() int {
+		return 1
+		return helper()
+	}
+	func Exported() int { return 2 }`
+	var sizes []int
+	for _, level := range optimizationLevels {
+		script, di := compileOptimized(t, src, level)
+		sizes = append(sizes, len(script))
+		methods := make(map[string]bool)
+		for _, m := range di.Methods {
+			methods[m.ID] = true
+		}
+		require.True(t, methods["Main"])
+		require.True(t, methods["Exported"])
+		require.Equal(t, level != compiler.OptimizeFull, methods["helper"], level)
+	}
+	require.Less(t, sizes[1], sizes[0])
+	require.Less(t, sizes[2], sizes[1])
+}
+
+func TestOptimizeSequencePoints(t *testing.T) {
+	src := `package foo
+	func sum(xs []int) int {
+		var s int
+		for i := range xs {
+			s += xs[i]
+		}
+		return s
+	}
+	func Main() int {
+		a := 1
+		b := a + 2
+		unused := b
+		_ = unused
+		return sum([]int{a, b})
+	}`
+	_, diNone := compileOptimized(t, src, compiler.OptimizeNone)
+	script, di := compileOptimized(t, src, compiler.OptimizeFull)
+
+	boundaries := make(map[int]bool)
+	ctx := vm.NewContext(script)
+	for ctx.NextIP() < len(script) {
+		_, _, err := ctx.Next()
+		require.NoError(t, err)
+		boundaries[ctx.IP()] = true
+	}
+
+	lines := make(map[string]map[int]bool)
+	for _, m := range diNone.Methods {
+		lines[m.ID] = make(map[int]bool)
+		for _, p := range m.SeqPoints {
+			lines[m.ID][p.StartLine] = true
+		}
+	}
+	for _, m := range di.Methods {
+		require.NotEmpty(t, m.SeqPoints, m.ID)
+		for _, p := range m.SeqPoints {
+			require.True(t, boundaries[p.Opcode], "%s: %d", m.ID, p.Opcode)
+			require.True(t, int(m.Range.Start) <= p.Opcode && p.Opcode <= int(m.Range.End), "%s: %d", m.ID, p.Opcode)
+			require.True(t, lines[m.ID][p.StartLine], "%s: line %d", m.ID, p.StartLine)
+		}
+	}
+}
+
+func TestOptimizeVariables(t *testing.T) {
+	src := `package foo
+	func Main(x int) int {
+		a := x * 2
+		unused := x
+		_ = unused
+		b := a + x
+		return a + b
+	}`
+	variables := func(level compiler.OptimizationLevel) ([]string, int) {
+		script, di := compileOptimized(t, src, level)
+		for _, m := range di.Methods {
+			if m.ID == "Main" {
+				require.Equal(t, opcode.INITSLOT, opcode.Opcode(script[m.Range.Start]))
+				return m.Variables, int(script[m.Range.Start+1])
+			}
+		}
+		t.Fatal("Main is missing")
+		return nil, 0
+	}
+	vars, locals := variables(compiler.OptimizeNone)
+	require.Equal(t, []string{"a,Integer", "unused,Integer", "b,Integer"}, vars)
+	require.Equal(t, len(vars), locals)
+
+	vars, locals = variables(compiler.OptimizeFull)
+	require.Equal(t, []string{"a,Integer", "b,Integer"}, vars)
+	require.Equal(t, len(vars), locals)
+}
+
+func TestOptimizeInvalidLevel(t *testing.T) {
+	_, _, err := compiler.CompileWithOptions("foo.go", strings.NewReader(`package foo
+	func Main() int { return 1 }`), &compiler.Options{Optimize: compiler.OptimizeFull + 1})
+	require.Error(t, err)
+}
@@ -0,0 +1,206 @@
+package compiler
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"github.com/nspcc-dev/neo-go/pkg/vm/emit"
+	"github.com/nspcc-dev/neo-go/pkg/vm/opcode"
+	"github.com/nspcc-dev/neo-go/pkg/vm/stackitem"
+)
+
+// Pointers to structs are represented by the structs themselves, because
+// structs are reference types in NeoVM. Pointers to values of any other type
+// are references: two-element arrays containing a key and a container holding
+// the value, so that dereferencing is just a PICKITEM or SETITEM. Local
+// variables having their address taken are stored in boxes (single-element
+// arrays) the same way variables captured by closures are, a pointer to such
+// variable references the first element of its box. Pointers to struct fields
+// and slice elements reference the struct or the slice itself.
+
+// isRefPointer returns true if values of typ are pointers represented as
+// references.
+func isRefPointer(typ types.Type) bool {
+	ptr, ok := typ.Underlying().(*types.Pointer)
+	if !ok {
+		return false
+	}
+	_, ok = ptr.Elem().Underlying().(*types.Struct)
+	return !ok
+}
+
+// isPointer returns true if typ is a pointer type.
+func isPointer(typ types.Type) bool {
+	_, ok := typ.Underlying().(*types.Pointer)
+	return ok
+}
+
+// unparen returns e with any enclosing parentheses stripped.
+func unparen(e ast.Expr) ast.Expr {
+	for {
+		p, ok := e.(*ast.ParenExpr)
+		if !ok {
+			return e
+		}
+		e = p.X
+	}
+}
+
+// analyzeAddresses finds local variables having their address taken either
+// explicitly or implicitly via a method call with a pointer receiver. Such
+// variables are boxed just like the ones captured by closures. Struct
+// variables are not boxed, pointers to them are structs themselves.
+func (c *codegen) analyzeAddresses() {
+	mark := func(e ast.Expr) {
+		id, ok := unparen(e).(*ast.Ident)
+		if !ok {
+			return
+		}
+		v, ok := c.typeInfo.Uses[id].(*types.Var)
+		if !ok || v.IsField() || v.Parent() == nil ||
+			v.Pkg() == nil || v.Parent() == v.Pkg().Scope() {
+			return
+		}
+		if _, ok := v.Type().Underlying().(*types.Struct); !ok {
+			c.captured[v] = true
+		}
+	}
+	c.ForEachFile(func(f *ast.File, _ *types.Package) {
+		ast.Inspect(f, func(node ast.Node) bool {
+			switch n := node.(type) {
+			case *ast.UnaryExpr:
+				if n.Op == token.AND {
+					mark(n.X)
+				}
+			case *ast.SelectorExpr:
+				sel := c.typeInfo.Selections[n]
+				if sel != nil && sel.Kind() == types.MethodVal && len(sel.Index()) == 1 &&
+					isRefPointer(sel.Obj().Type().(*types.Signature).Recv().Type()) &&
+					!isPointer(sel.Recv()) {
+					mark(n.X)
+				}
+			}
+			return true
+		})
+	})
+}
+
+// emitAddressOf loads the pointer to e.
+func (c *codegen) emitAddressOf(e ast.Expr) {
+	e = unparen(e)
+	if _, ok := c.typeOf(e).Underlying().(*types.Struct); ok {
+		if lit, ok := e.(*ast.CompositeLit); ok {
+			c.convertStruct(lit, true)
+		} else {
+			ast.Walk(c, e)
+		}
+		return
+	}
+	switch t := e.(type) {
+	case *ast.CompositeLit:
+		ast.Walk(c, t)
+		emit.Opcodes(c.prog.BinWriter, opcode.PUSH1, opcode.PACK)
+		emit.Opcodes(c.prog.BinWriter, opcode.PUSH0)
+	case *ast.Ident:
+		vi := c.getVarIndex("", t.Name)
+		if vi.refType == varGlobal {
+			c.errorf(t, "taking address of global variable %s is not supported", t.Name)
+			return
+		}
+		if !vi.boxed {
+			c.errorf(t, "can't take address of %s", t.Name)
+			return
+		}
+		c.emitLoadByIndex(vi.refType, vi.index)
+		emit.Opcodes(c.prog.BinWriter, opcode.PUSH0)
+	case *ast.SelectorExpr:
+		typ := c.typeOf(t.X)
+		if c.isInvalidType(typ) {
+			c.errorf(t, "taking address of global variable %s.%s is not supported", t.X, t.Sel.Name)
+			return
+		}
+		strct, ok := c.getStruct(typ)
+		if !ok {
+			c.errorf(t, "selectors are supported only on structs")
+			return
+		}
+		ast.Walk(c, t.X)
+		emit.Int(c.prog.BinWriter, int64(indexOfStruct(strct, t.Sel.Name)))
+	case *ast.IndexExpr:
+		ast.Walk(c, t.X)
+		ast.Walk(c, t.Index)
+	case *ast.StarExpr:
+		// &*p is just p.
+		ast.Walk(c, t.X)
+		return
+	default:
+		c.errorf(e, "can't take address of %T", e)
+		return
+	}
+	emit.Opcodes(c.prog.BinWriter, opcode.PUSH2, opcode.PACK)
+}
+
+// emitNew allocates a new zero value of typ and loads a pointer to it.
+func (c *codegen) emitNew(typ types.Type) {
+	if strct, ok := typ.Underlying().(*types.Struct); ok {
+		for i := strct.NumFields() - 1; i >= 0; i-- {
+			c.emitDefault(strct.Field(i).Type())
+		}
+		emit.Int(c.prog.BinWriter, int64(strct.NumFields()))
+		emit.Opcodes(c.prog.BinWriter, opcode.PACK)
+		return
+	}
+	c.emitDefault(typ)
+	emit.Opcodes(c.prog.BinWriter, opcode.PUSH1, opcode.PACK,
+		opcode.PUSH0, opcode.PUSH2, opcode.PACK)
+}
+
+// emitLoadDeref replaces pointer of type typ on top of the stack with
+// the value it points to.
+func (c *codegen) emitLoadDeref(typ types.Type) {
+	if !isRefPointer(typ) {
+		c.emitConvert(stackitem.StructT)
+		return
+	}
+	emit.Opcodes(c.prog.BinWriter, opcode.UNPACK, opcode.DROP, opcode.PICKITEM)
+}
+
+// emitStoreDeref stores the value from the stack to the location pointed to by
+// the pointer of type typ on top of the stack. Structs are updated field by field,
+// so that all pointers to them see the new value.
+func (c *codegen) emitStoreDeref(typ types.Type) {
+	if isRefPointer(typ) {
+		emit.Opcodes(c.prog.BinWriter, opcode.UNPACK, opcode.DROP, opcode.ROT, opcode.SETITEM)
+		return
+	}
+	strct, _ := c.getStruct(typ)
+	for i := 0; i < strct.NumFields(); i++ {
+		emit.Opcodes(c.prog.BinWriter, opcode.DUP)                // v p p
+		emit.Int(c.prog.BinWriter, int64(i))                      // v p p i
+		emit.Opcodes(c.prog.BinWriter, opcode.PUSH3, opcode.PICK) // v p p i v
+		c.emitLoadField(i)                                        // v p p i v.i
+		emit.Opcodes(c.prog.BinWriter, opcode.SETITEM)            // v p
+	}
+	emit.Opcodes(c.prog.BinWriter, opcode.DROP, opcode.DROP)
+}
+
+// emitReceiver loads the receiver of the method call expression e taking its
+// address or dereferencing it if the method receiver type requires so.
+func (c *codegen) emitReceiver(e *ast.SelectorExpr) {
+	sel := c.typeInfo.Selections[e]
+	if sel != nil && sel.Kind() == types.MethodVal && len(sel.Index()) == 1 {
+		recv := sel.Obj().Type().(*types.Signature).Recv().Type()
+		typ := c.typeOf(e.X)
+		switch {
+		case isRefPointer(recv) && !isPointer(typ):
+			c.emitAddressOf(e.X)
+			return
+		case !isPointer(recv) && isRefPointer(typ):
+			ast.Walk(c, e.X)
+			c.emitLoadDeref(typ)
+			return
+		}
+	}
+	ast.Walk(c, e.X)
+}
@@ -2,7 +2,11 @@ package compiler_test
 
 import (
 	"math/big"
+	"strings"
 	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/compiler"
+	"github.com/stretchr/testify/require"
 )
 
 func TestAddressOfLiteral(t *testing.T) {
@@ -66,3 +70,280 @@ func TestStructArgCopy(t *testing.T) {
 		eval(t, src, big.NewInt(3))
 	})
 }
+
+func TestNew(t *testing.T) {
+	t.Run("Int", func(t *testing.T) {
+		src := `package foo
+		func Main() int {
+			p := new(int)
+			*p = 5
+			*p += 2
+			(*p)++
+			return *p
+		}`
+		eval(t, src, big.NewInt(8))
+	})
+	t.Run("Default", func(t *testing.T) {
+		src := `package foo
+		func Main() bool {
+			p := new(bool)
+			s := new(string)
+			return !*p && len(*s) == 0
+		}`
+		eval(t, src, true)
+	})
+	t.Run("Struct", func(t *testing.T) {
+		src := `package foo
+		type Foo struct { A int; B string }
+		func Main() int {
+			f := new(Foo)
+			setA(f, 3)
+			return f.A + len(f.B)
+		}
+		func setA(s *Foo, a int) { s.A = a }`
+		eval(t, src, big.NewInt(3))
+	})
+	t.Run("PointerToPointer", func(t *testing.T) {
+		src := `package foo
+		func Main() int {
+			pp := new(*int)
+			a := 1
+			*pp = &a
+			**pp = 7
+			return a
+		}`
+		eval(t, src, big.NewInt(7))
+	})
+}
+
+func TestAddressOfVariable(t *testing.T) {
+	t.Run("Local", func(t *testing.T) {
+		src := `package foo
+		func Main() int {
+			a := 1
+			p := &a
+			*p = 2
+			a += 3
+			return *p * 10 + a
+		}`
+		eval(t, src, big.NewInt(55))
+	})
+	t.Run("Swap", func(t *testing.T) {
+		src := `package foo
+		func swap(a, b *int) {
+			t := *a
+			*a = *b
+			*b = t
+		}
+		func Main() int {
+			x, y := 1, 2
+			swap(&x, &y)
+			return x * 10 + y
+		}`
+		eval(t, src, big.NewInt(21))
+	})
+	t.Run("Parameter", func(t *testing.T) {
+		src := `package foo
+		func inc(p *int) { *p++ }
+		func f(a int) int {
+			inc(&a)
+			inc(&a)
+			return a
+		}
+		func Main() int {
+			return f(40)
+		}`
+		eval(t, src, big.NewInt(42))
+	})
+	t.Run("NewBoxInLoop", func(t *testing.T) {
+		src := `package foo
+		func Main() int {
+			var ps []*int
+			for i := 0; i < 3; i++ {
+				v := i
+				ps = append(ps, &v)
+			}
+			*ps[0] = 10
+			return *ps[0] + *ps[1] + *ps[2]
+		}`
+		eval(t, src, big.NewInt(13))
+	})
+	t.Run("Closure", func(t *testing.T) {
+		src := `package foo
+		func Main() int {
+			a := 1
+			p := &a
+			f := func() { a *= 10 }
+			*p = 4
+			f()
+			return *p
+		}`
+		eval(t, src, big.NewInt(40))
+	})
+	t.Run("Struct", func(t *testing.T) {
+		src := `package foo
+		type Foo struct { A int }
+		func Main() int {
+			f := Foo{A: 1}
+			p := &f
+			p.A = 2
+			*p = Foo{A: p.A + 3}
+			return f.A
+		}`
+		eval(t, src, big.NewInt(5))
+	})
+	t.Run("Global", func(t *testing.T) {
+		src := `package foo
+		var a int
+		func Main() int {
+			p := &a
+			return *p
+		}`
+		_, err := compiler.Compile("foo.go", strings.NewReader(src))
+		require.Error(t, err)
+	})
+}
+
+func TestAddressOfField(t *testing.T) {
+	t.Run("Basic", func(t *testing.T) {
+		src := `package foo
+		type Foo struct { A, B int }
+		func Main() int {
+			f := Foo{A: 1, B: 2}
+			p := &f.B
+			*p = 5
+			return f.A * 10 + f.B
+		}`
+		eval(t, src, big.NewInt(15))
+	})
+	t.Run("ViaPointer", func(t *testing.T) {
+		src := `package foo
+		type Foo struct { A, B int }
+		func set(p *int, v int) { *p = v }
+		func Main() int {
+			f := &Foo{}
+			set(&f.A, 3)
+			set(&f.B, 4)
+			return f.A * 10 + f.B
+		}`
+		eval(t, src, big.NewInt(34))
+	})
+	t.Run("NestedStruct", func(t *testing.T) {
+		src := `package foo
+		type Bar struct { A int }
+		type Foo struct { B Bar }
+		func Main() int {
+			f := Foo{}
+			p := &f.B
+			p.A = 6
+			return f.B.A
+		}`
+		eval(t, src, big.NewInt(6))
+	})
+	t.Run("SliceElement", func(t *testing.T) {
+		src := `package foo
+		func Main() int {
+			xs := []int{1, 2, 3}
+			p := &xs[1]
+			*p = 20
+			return xs[0] + xs[1] + xs[2]
+		}`
+		eval(t, src, big.NewInt(24))
+	})
+}
+
+func TestPointerToSlice(t *testing.T) {
+	src := `package foo
+	func push(p *[]int, v int) { *p = append(*p, v) }
+	func Main() int {
+		var xs []int
+		push(&xs, 1)
+		push(&xs, 2)
+		p := &xs
+		(*p)[0] = 5
+		return len(xs) * 100 + xs[0] * 10 + xs[1]
+	}`
+	eval(t, src, big.NewInt(252))
+}
+
+func TestPointerToMap(t *testing.T) {
+	src := `package foo
+	func reset(p *map[string]int) { *p = map[string]int{"b": 2} }
+	func Main() int {
+		m := map[string]int{"a": 1}
+		p := &m
+		(*p)["a"] = 3
+		old := m["a"]
+		reset(&m)
+		return old * 10 + m["b"] + len(*p)
+	}`
+	eval(t, src, big.NewInt(33))
+}
+
+func TestPointerReceiver(t *testing.T) {
+	src := `package foo
+	type Counter int
+	func (c *Counter) Inc() { *c++ }
+	func (c Counter) Get() int { return int(c) }
+	type Getter interface { Get() int }
+	func Main() int {
+		var c Counter
+		c.Inc()
+		c.Inc()
+		p := &c
+		p.Inc()
+		var g Getter = p
+		return c.Get() * 10 + p.Get() + g.Get() * 100
+	}`
+	eval(t, src, big.NewInt(333))
+}
+
+func TestPointerComparison(t *testing.T) {
+	t.Run("Nil", func(t *testing.T) {
+		src := `package foo
+		func Main() int {
+			var p *int
+			x := 1
+			if p == nil {
+				p = &x
+			}
+			if p != nil {
+				return *p
+			}
+			return 0
+		}`
+		eval(t, src, big.NewInt(1))
+	})
+	t.Run("Struct", func(t *testing.T) {
+		src := `package foo
+		type Foo struct{ A int }
+		func Main() bool {
+			f := &Foo{}
+			g := f
+			return f == g && f != &Foo{}
+		}`
+		eval(t, src, true)
+	})
+	t.Run("NonStruct", func(t *testing.T) {
+		src := `package foo
+		func Main() bool {
+			x := 1
+			return &x == &x
+		}`
+		_, err := compiler.Compile("foo.go", strings.NewReader(src))
+		require.Error(t, err)
+	})
+	t.Run("NonStructCondition", func(t *testing.T) {
+		src := `package foo
+		func Main() int {
+			x := 1
+			p, q := &x, &x
+			if p != q {
+				return 1
+			}
+			return 0
+		}`
+		_, err := compiler.Compile("foo.go", strings.NewReader(src))
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,104 @@
+package compiler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/compiler"
+	"github.com/nspcc-dev/neo-go/pkg/neotest"
+	"github.com/nspcc-dev/neo-go/pkg/neotest/chain"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/binding"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/vm/stackitem"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+const schemaDir = "./testdata/schema"
+
+func readStorageSchema(t *testing.T) []binding.StorageItem {
+	data, err := os.ReadFile(filepath.Join(schemaDir, "schema.yml"))
+	require.NoError(t, err)
+
+	var conf struct {
+		Storage []binding.StorageItem `yaml:"storage"`
+	}
+	require.NoError(t, yaml.Unmarshal(data, &conf))
+	return conf.Storage
+}
+
+func TestStorageSchemaGenerate(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, binding.GenerateStorage(binding.StorageConfig{
+		Package: "schema",
+		Items:   readStorageSchema(t),
+		Output:  buf,
+	}))
+
+	expected, err := os.ReadFile(filepath.Join(schemaDir, "storage.go"))
+	require.NoError(t, err)
+	require.Equal(t, string(expected), buf.String())
+}
+
+func TestStorageSchemaDebugInfo(t *testing.T) {
+	items := readStorageSchema(t)
+	_, di, err := compiler.CompileWithOptions(schemaDir, nil, &compiler.Options{Storage: items})
+	require.NoError(t, err)
+	require.Equal(t, items, di.Storage)
+
+	data, err := json.Marshal(di)
+	require.NoError(t, err)
+	var actual compiler.DebugInfo
+	require.NoError(t, json.Unmarshal(data, &actual))
+	require.Equal(t, items, actual.Storage)
+
+	t.Run("invalid schema", func(t *testing.T) {
+		items := append(items, binding.StorageItem{Name: "other", Prefix: binding.StoragePrefix("ba")})
+		_, _, err := compiler.CompileWithOptions(schemaDir, nil, &compiler.Options{Storage: items})
+		require.Error(t, err)
+	})
+}
+
+func TestStorageSchemaAccessors(t *testing.T) {
+	bc, acc := chain.NewSingle(t)
+	e := neotest.NewExecutor(t, bc, acc, acc)
+	ctr := neotest.CompileFile(t, e.CommitteeHash, schemaDir, filepath.Join(schemaDir, "schema.yml"))
+	e.DeployContract(t, ctr, nil)
+	c := e.CommitteeInvoker(ctr.Hash)
+
+	owner := util.Uint160{1, 2, 3}
+	c.Invoke(t, stackitem.Null{}, "put", owner, 42, "label", true, []interface{}{1, "x"})
+	data := stackitem.NewArray([]stackitem.Item{stackitem.Make(1), stackitem.Make("x")})
+	c.Invoke(t, stackitem.NewArray([]stackitem.Item{
+		stackitem.NewBuffer(owner.BytesBE()),
+		stackitem.Make(42),
+		stackitem.Make("label"),
+		stackitem.Make(true),
+		data,
+	}), "get", owner)
+
+	items, err := bc.GetStorageItems(bc.GetContractState(ctr.Hash).ID)
+	require.NoError(t, err)
+	require.Equal(t, 5, len(items))
+
+	decoded := make(map[string]*binding.DecodedStorageItem)
+	for _, it := range items {
+		d, err := binding.DecodeStorage(readStorageSchema(t), it.Key, it.Item)
+		require.NoError(t, err)
+		decoded[d.Name] = d
+	}
+	require.Equal(t, owner, decoded["owner"].Value)
+	require.Equal(t, owner, decoded["balance"].Key)
+	require.Equal(t, big.NewInt(42), decoded["balance"].Value)
+	require.Equal(t, big.NewInt(42), decoded["label"].Key)
+	require.Equal(t, "label", decoded["label"].Value)
+	require.Equal(t, "label", decoded["flag"].Key)
+	require.Equal(t, true, decoded["flag"].Value)
+	require.Equal(t, data, decoded["data"].Value)
+
+	c.Invoke(t, 0, "delete", owner)
+}
@@ -0,0 +1,11 @@
+package diagnostics
+
+// Main is the contract entry point.
+func Main() int {
+	xs := []int{1, 2, 3}
+	return len(tail(xs)) + ptr()
+}
+
+func tail(xs []int) []int {
+	return xs[1:]
+}
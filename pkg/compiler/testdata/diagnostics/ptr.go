@@ -0,0 +1,8 @@
+package diagnostics
+
+func ptr() int {
+	p := &a
+	return *p
+}
+
+var a = 1
@@ -0,0 +1,43 @@
+package generic
+
+// Filter returns elements of xs satisfying f.
+func Filter[T any](xs []T, f func(T) bool) []T {
+	res := []T{}
+	for _, x := range xs {
+		if f(x) {
+			res = append(res, x)
+		}
+	}
+	return res
+}
+
+// Set is a set of comparable values.
+type Set[T comparable] struct {
+	items []T
+}
+
+// NewSet returns new set containing xs.
+func NewSet[T comparable](xs ...T) *Set[T] {
+	s := &Set[T]{items: []T{}}
+	for _, x := range xs {
+		s.Add(x)
+	}
+	return s
+}
+
+// Add adds x to the set.
+func (s *Set[T]) Add(x T) {
+	if !s.Has(x) {
+		s.items = append(s.items, x)
+	}
+}
+
+// Has checks whether x is in the set.
+func (s *Set[T]) Has(x T) bool {
+	for _, y := range s.items {
+		if x == y {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,31 @@
+package schema
+
+import (
+	"github.com/nspcc-dev/neo-go/pkg/interop"
+	"github.com/nspcc-dev/neo-go/pkg/interop/storage"
+)
+
+// Put stores all the values using generated accessors.
+func Put(owner interop.Hash160, amount int, label string, flag bool, data []interface{}) {
+	ctx := storage.GetContext()
+	putOwner(ctx, owner)
+	putBalance(ctx, owner, amount)
+	putLabel(ctx, amount, label)
+	putFlag(ctx, label, flag)
+	putData(ctx, data)
+}
+
+// Get returns all the values stored for the owner.
+func Get(owner interop.Hash160) []interface{} {
+	ctx := storage.GetReadOnlyContext()
+	amount := getBalance(ctx, owner)
+	label := getLabel(ctx, amount)
+	return []interface{}{getOwner(ctx), amount, label, getFlag(ctx, label), getData(ctx)}
+}
+
+// Delete removes the balance of the owner.
+func Delete(owner interop.Hash160) int {
+	ctx := storage.GetContext()
+	deleteBalance(ctx, owner)
+	return getBalance(ctx, owner)
+}
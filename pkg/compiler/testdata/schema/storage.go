@@ -0,0 +1,105 @@
+// Code generated by neo-go contract generate-storage. DO NOT EDIT.
+
+package schema
+
+import (
+	"github.com/nspcc-dev/neo-go/pkg/interop"
+	"github.com/nspcc-dev/neo-go/pkg/interop/convert"
+	"github.com/nspcc-dev/neo-go/pkg/interop/native/std"
+	"github.com/nspcc-dev/neo-go/pkg/interop/storage"
+)
+
+// getOwner returns the value of `owner` storage item.
+func getOwner(ctx storage.Context) interop.Hash160 {
+	v := storage.Get(ctx, "\x01")
+	if v == nil {
+		return nil
+	}
+	return v.(interop.Hash160)
+}
+
+// putOwner stores the value of `owner` storage item.
+func putOwner(ctx storage.Context, value interop.Hash160) {
+	storage.Put(ctx, "\x01", value)
+}
+
+// deleteOwner deletes `owner` storage item.
+func deleteOwner(ctx storage.Context) {
+	storage.Delete(ctx, "\x01")
+}
+
+// getBalance returns the value of `balance` storage item with the specified key.
+func getBalance(ctx storage.Context, key interop.Hash160) int {
+	v := storage.Get(ctx, "\x62"+string(key))
+	if v == nil {
+		return 0
+	}
+	return v.(int)
+}
+
+// putBalance stores the value of `balance` storage item with the specified key.
+func putBalance(ctx storage.Context, key interop.Hash160, value int) {
+	storage.Put(ctx, "\x62"+string(key), value)
+}
+
+// deleteBalance deletes `balance` storage item with the specified key.
+func deleteBalance(ctx storage.Context, key interop.Hash160) {
+	storage.Delete(ctx, "\x62"+string(key))
+}
+
+// getLabel returns the value of `label` storage item with the specified key.
+func getLabel(ctx storage.Context, key int) string {
+	v := storage.Get(ctx, "\x6c\x62"+string(convert.ToBytes(key)))
+	if v == nil {
+		return ""
+	}
+	return v.(string)
+}
+
+// putLabel stores the value of `label` storage item with the specified key.
+func putLabel(ctx storage.Context, key int, value string) {
+	storage.Put(ctx, "\x6c\x62"+string(convert.ToBytes(key)), value)
+}
+
+// deleteLabel deletes `label` storage item with the specified key.
+func deleteLabel(ctx storage.Context, key int) {
+	storage.Delete(ctx, "\x6c\x62"+string(convert.ToBytes(key)))
+}
+
+// getFlag returns the value of `flag` storage item with the specified key.
+func getFlag(ctx storage.Context, key string) bool {
+	v := storage.Get(ctx, "\x66"+key)
+	if v == nil {
+		return false
+	}
+	return v.(bool)
+}
+
+// putFlag stores the value of `flag` storage item with the specified key.
+func putFlag(ctx storage.Context, key string, value bool) {
+	storage.Put(ctx, "\x66"+key, value)
+}
+
+// deleteFlag deletes `flag` storage item with the specified key.
+func deleteFlag(ctx storage.Context, key string) {
+	storage.Delete(ctx, "\x66"+key)
+}
+
+// getData returns the value of `data` storage item.
+func getData(ctx storage.Context) []interface{} {
+	v := storage.Get(ctx, "\x64")
+	if v == nil {
+		return nil
+	}
+	return std.Deserialize(v.([]byte)).([]interface{})
+}
+
+// putData stores the value of `data` storage item.
+func putData(ctx storage.Context, value []interface{}) {
+	storage.Put(ctx, "\x64", std.Serialize(value))
+}
+
+// deleteData deletes `data` storage item.
+func deleteData(ctx storage.Context) {
+	storage.Delete(ctx, "\x64")
+}
@@ -8,6 +8,14 @@ import (
 )
 
 func (c *codegen) typeAndValueOf(e ast.Expr) types.TypeAndValue {
+	tv := c.typeAndValueOfGeneric(e)
+	tv.Type = c.substType(tv.Type)
+	return tv
+}
+
+// typeAndValueOfGeneric is the same as typeAndValueOf, but type parameters
+// are left as is.
+func (c *codegen) typeAndValueOfGeneric(e ast.Expr) types.TypeAndValue {
 	for i := len(c.pkgInfoInline) - 1; i >= 0; i-- {
 		if tv, ok := c.pkgInfoInline[i].TypesInfo.Types[e]; ok {
 			return tv
@@ -28,6 +36,11 @@ func (c *codegen) typeAndValueOf(e ast.Expr) types.TypeAndValue {
 }
 
 func (c *codegen) typeOf(e ast.Expr) types.Type {
+	return c.substType(c.typeOfGeneric(e))
+}
+
+// typeOfGeneric is the same as typeOf, but type parameters are left as is.
+func (c *codegen) typeOfGeneric(e ast.Expr) types.Type {
 	for i := len(c.pkgInfoInline) - 1; i >= 0; i-- {
 		if typ := c.pkgInfoInline[i].TypesInfo.TypeOf(e); typ != nil {
 			return typ
@@ -23,6 +23,9 @@ type varInfo struct {
 	// ctx is set for inline arguments and contains
 	// context for expression traversal.
 	ctx *varContext
+	// boxed is set for variables captured by closures, their values
+	// are stored in single-element arrays shared with closures.
+	boxed bool
 }
 
 const unspecifiedVarIndex = -1
@@ -64,6 +67,23 @@ func (c *varScope) getVarInfo(name string) *varInfo {
 	return nil
 }
 
+// setBoxed marks the variable with the specified name as boxed. Arguments
+// are marked via an alias in the current scope.
+func (c *varScope) setBoxed(name string) {
+	vi := c.getVarInfo(name)
+	if vi == nil {
+		panic("boxed variable is not allocated")
+	}
+	vi.boxed = true
+	for i := len(c.locals) - 1; i >= 0; i-- {
+		if _, ok := c.locals[i][name]; ok {
+			c.locals[i][name] = *vi
+			return
+		}
+	}
+	c.locals[len(c.locals)-1][name] = *vi
+}
+
 // newVariable creates a new local variable or argument in the scope of the function.
 func (c *varScope) newVariable(t varType, name string) int {
 	var n int
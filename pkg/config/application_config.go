@@ -15,6 +15,7 @@ type ApplicationConfiguration struct {
 	DialTimeout       int64                   `yaml:"DialTimeout"`
 	LogPath           string                  `yaml:"LogPath"`
 	MaxPeers          int                     `yaml:"MaxPeers"`
+	MemPool           MemPool                 `yaml:"MemPool"`
 	MinPeers          int                     `yaml:"MinPeers"`
 	NodePort          uint16                  `yaml:"NodePort"`
 	PingInterval      int64                   `yaml:"PingInterval"`
@@ -27,6 +28,9 @@ type ApplicationConfiguration struct {
 	UnlockWallet      Wallet                  `yaml:"UnlockWallet"`
 	Oracle            OracleConfiguration     `yaml:"Oracle"`
 	P2PNotary         P2PNotary               `yaml:"P2PNotary"`
+	P2PEncryption     P2PEncryption           `yaml:"P2PEncryption"`
+	P2PCaptureFile    string                  `yaml:"P2PCaptureFile"`
+	P2PLimits         P2PLimits               `yaml:"P2PLimits"`
 	StateRoot         StateRoot               `yaml:"StateRoot"`
 	// ExtensiblePoolSize is the maximum amount of the extensible payloads from a single sender.
 	ExtensiblePoolSize int `yaml:"ExtensiblePoolSize"`
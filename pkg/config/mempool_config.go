@@ -0,0 +1,16 @@
+package config
+
+// MemPool contains node-local memory pool settings.
+type MemPool struct {
+	// Persist makes the node save its memory pools (including
+	// P2PNotaryRequest pool) to the DB on shutdown and restore them on
+	// startup, restored transactions are verified again.
+	Persist bool `yaml:"Persist"`
+	// ReplaceFeeIncrease is the minimum network fee increase (in percents)
+	// a transaction needs to replace a pooled transaction of the same
+	// sender it conflicts with (via Conflicts attribute).
+	ReplaceFeeIncrease int `yaml:"ReplaceFeeIncrease"`
+	// MaxSenderTransactions is the maximum number of transactions of the
+	// same sender in the memory pool, 0 means no limit.
+	MaxSenderTransactions int `yaml:"MaxSenderTransactions"`
+}
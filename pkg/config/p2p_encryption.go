@@ -0,0 +1,14 @@
+package config
+
+// P2PEncryption contains node-to-node connections encryption configuration.
+type P2PEncryption struct {
+	Enabled bool `yaml:"Enabled"`
+	// Strict mode only allows encrypted connections with peers from
+	// AllowedKeys, plaintext peers are rejected.
+	Strict bool `yaml:"Strict"`
+	// UnlockWallet contains the key used to authenticate the node.
+	UnlockWallet Wallet `yaml:"UnlockWallet"`
+	// AllowedKeys is a list of hex-encoded public keys of trusted nodes,
+	// if it's not empty encrypted connections are only allowed with them.
+	AllowedKeys []string `yaml:"AllowedKeys"`
+}
@@ -0,0 +1,24 @@
+package config
+
+// P2PLimits contains per-peer P2P message rate limits and other DoS
+// protection settings. Zero values mean defaults.
+type P2PLimits struct {
+	// Enabled turns per-peer message rate limiting on.
+	Enabled bool `yaml:"Enabled"`
+	// Rate is the number of cost units every peer gets per second.
+	Rate int `yaml:"Rate"`
+	// Burst is the maximum number of cost units a peer can accumulate.
+	Burst int `yaml:"Burst"`
+	// Costs overrides default per-item message costs, keys are command
+	// names (like "GetData").
+	Costs map[string]int `yaml:"Costs"`
+	// MaxDropped is the number of messages dropped because of the rate
+	// limit after which the peer is disconnected.
+	MaxDropped int `yaml:"MaxDropped"`
+	// MaxReplyItems limits the number of items (blocks, headers,
+	// transactions or hashes) sent in reply to a single request.
+	MaxReplyItems int `yaml:"MaxReplyItems"`
+	// MaxExtensibleSize is the maximum size of extensible payload data
+	// accepted from peers.
+	MaxExtensibleSize int `yaml:"MaxExtensibleSize"`
+}
@@ -19,6 +19,10 @@ type (
 		// starting the next MPT garbage collection cycle when RemoveUntraceableBlocks
 		// option is used.
 		GarbageCollectionPeriod uint32 `yaml:"GarbageCollectionPeriod"`
+		// HeadersFirstSync makes the node download and verify the whole
+		// header chain before fetching blocks, headers are verified even if
+		// VerifyBlocks is not set.
+		HeadersFirstSync bool `yaml:"HeadersFirstSync"`
 
 		Magic       netmode.Magic `yaml:"Magic"`
 		MemPoolSize int           `yaml:"MemPoolSize"`
@@ -67,11 +71,26 @@ type (
 		ValidatorsCount   int `yaml:"ValidatorsCount"`
 		// Validators stores history of changes to consensus node number (height: number).
 		ValidatorsHistory map[uint32]int `yaml:"ValidatorsHistory"`
+		// VMLimitsHistory stores history of changes to VM limits (height: limits).
+		// Limits are applied starting from the specified height, default
+		// ones are used before the first one.
+		VMLimitsHistory map[uint32]VMLimits `yaml:"VMLimits"`
 		// Whether to verify received blocks.
 		VerifyBlocks bool `yaml:"VerifyBlocks"`
 		// Whether to verify transactions in received blocks.
 		VerifyTransactions bool `yaml:"VerifyTransactions"`
 	}
+
+	// VMLimits contains VM execution limits overriding the default (mainnet)
+	// ones, zero values mean that the default value is used.
+	VMLimits struct {
+		MaxStackSize            int `yaml:"MaxStackSize"`
+		MaxInvocationStackSize  int `yaml:"MaxInvocationStackSize"`
+		MaxTryNestingDepth      int `yaml:"MaxTryNestingDepth"`
+		MaxItemSize             int `yaml:"MaxItemSize"`
+		MaxComparableSize       int `yaml:"MaxComparableSize"`
+		MaxComparableNumOfItems int `yaml:"MaxComparableNumOfItems"`
+	}
 )
 
 // heightNumber is an auxiliary structure for configuration checks.
@@ -183,6 +202,22 @@ func (p *ProtocolConfiguration) GetNumOfCNs(height uint32) int {
 	return getBestFromMap(p.ValidatorsHistory, height)
 }
 
+// GetVMLimits returns VM limits overrides for the given height (zero values
+// mean default limits).
+func (p *ProtocolConfiguration) GetVMLimits(height uint32) VMLimits {
+	var (
+		res   VMLimits
+		bestH uint32
+		found bool
+	)
+	for h, l := range p.VMLimitsHistory {
+		if h <= height && (!found || h > bestH) {
+			res, bestH, found = l, h, true
+		}
+	}
+	return res
+}
+
 // ShouldUpdateCommitteeAt answers the question of whether the committee
 // should be updated at the given height.
 func (p *ProtocolConfiguration) ShouldUpdateCommitteeAt(height uint32) bool {
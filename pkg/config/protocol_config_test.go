@@ -137,3 +137,19 @@ func TestGetCommitteeAndCNs(t *testing.T) {
 	require.Equal(t, 4, p.GetNumOfCNs(200))
 	require.Equal(t, 4, p.GetNumOfCNs(201))
 }
+
+func TestGetVMLimits(t *testing.T) {
+	p := &ProtocolConfiguration{}
+	require.Equal(t, VMLimits{}, p.GetVMLimits(100))
+
+	p.VMLimitsHistory = map[uint32]VMLimits{
+		10:  {MaxItemSize: 100},
+		100: {MaxStackSize: 10},
+	}
+	require.NoError(t, p.Validate())
+	require.Equal(t, VMLimits{}, p.GetVMLimits(9))
+	require.Equal(t, VMLimits{MaxItemSize: 100}, p.GetVMLimits(10))
+	require.Equal(t, VMLimits{MaxItemSize: 100}, p.GetVMLimits(99))
+	require.Equal(t, VMLimits{MaxStackSize: 10}, p.GetVMLimits(100))
+	require.Equal(t, VMLimits{MaxStackSize: 10}, p.GetVMLimits(1000))
+}
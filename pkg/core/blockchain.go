@@ -99,6 +99,9 @@ var (
 )
 var (
 	persistInterval = 1 * time.Second
+	// memPoolKey is the DB key mempool is saved under if MemPool.Persist is
+	// enabled, network server uses SYSMemPool+1 for P2PNotaryRequest pool.
+	memPoolKey = []byte{byte(storage.SYSMemPool), 0}
 )
 
 // Blockchain represents the blockchain. It maintans internal state representing
@@ -153,6 +156,8 @@ type Blockchain struct {
 	runToExitCh chan struct{}
 
 	memPool *mempool.Pool
+	// memPoolCfg contains node-local mempool settings.
+	memPoolCfg config.MemPool
 
 	// postBlock is a set of callback methods which should be run under the Blockchain lock after new block is persisted.
 	// Block's transactions are passed via mempool.
@@ -251,6 +256,11 @@ func NewBlockchain(s storage.Store, cfg config.ProtocolConfiguration, log *zap.L
 				zap.Int("StateSyncInterval", cfg.StateSyncInterval))
 		}
 	}
+	for h, l := range cfg.VMLimitsHistory {
+		if err := interop.GetVMLimits(l).IsValid(); err != nil {
+			return nil, fmt.Errorf("invalid VMLimits at %d: %w", h, err)
+		}
+	}
 	if cfg.RemoveUntraceableBlocks && cfg.GarbageCollectionPeriod == 0 {
 		cfg.GarbageCollectionPeriod = defaultGCPeriod
 		log.Info("GarbageCollectionPeriod is not set or wrong, using default value", zap.Uint32("GarbageCollectionPeriod", cfg.GarbageCollectionPeriod))
@@ -280,10 +290,30 @@ func NewBlockchain(s storage.Store, cfg config.ProtocolConfiguration, log *zap.L
 	if err := bc.init(); err != nil {
 		return nil, err
 	}
-
 	return bc, nil
 }
 
+// SetMemPoolConfig applies node-local mempool settings, if mempool is to be
+// persisted transactions saved on the previous shutdown are restored. It
+// doesn't protected by mutex and must be called before `bc.Run()` to avoid
+// data race.
+func (bc *Blockchain) SetMemPoolConfig(cfg config.MemPool) {
+	bc.memPoolCfg = cfg
+	policy := mempool.Policy{
+		ReplaceFeeIncrease:    cfg.ReplaceFeeIncrease,
+		MaxSenderTransactions: cfg.MaxSenderTransactions,
+		// Oracle responses and notary fallbacks are paid by native contracts.
+		UnlimitedSenders: []util.Uint160{bc.contracts.Oracle.Hash},
+	}
+	if bc.contracts.Notary != nil {
+		policy.UnlimitedSenders = append(policy.UnlimitedSenders, bc.contracts.Notary.Hash)
+	}
+	bc.memPool.SetPolicy(policy)
+	if cfg.Persist {
+		bc.restoreMemPool()
+	}
+}
+
 // SetOracle sets oracle module. It doesn't protected by mutex and
 // must be called before `bc.Run()` to avoid data race.
 func (bc *Blockchain) SetOracle(mod services.Oracle) {
@@ -601,6 +631,9 @@ func (bc *Blockchain) Run() {
 	persistTimer := time.NewTimer(persistInterval)
 	defer func() {
 		persistTimer.Stop()
+		if bc.memPoolCfg.Persist {
+			bc.saveMemPool()
+		}
 		if _, err := bc.persist(true); err != nil {
 			bc.log.Warn("failed to persist", zap.Error(err))
 		}
@@ -861,15 +894,23 @@ func (bc *Blockchain) AddBlock(block *block.Block) error {
 	}
 
 	if block.Index == bc.HeaderHeight()+1 {
-		err := bc.addHeaders(bc.config.VerifyBlocks, &block.Header)
+		err := bc.addHeaders(bc.verifyHeaders(), &block.Header)
 		if err != nil {
 			return err
 		}
+	} else if bc.config.HeadersFirstSync {
+		// The header is already known and verified, so the block must match it.
+		if h := bc.GetHeaderHash(int(block.Index)); !h.Equals(block.Hash()) {
+			return fmt.Errorf("%w: %s != %s", ErrHdrBlockMismatch, block.Hash().StringLE(), h.StringLE())
+		}
+		if !bc.config.VerifyBlocks && !block.MerkleRoot.Equals(block.ComputeMerkleRoot()) {
+			return errMerkleRootMismatch
+		}
 	}
 	if bc.config.VerifyBlocks {
 		merkle := block.ComputeMerkleRoot()
 		if !block.MerkleRoot.Equals(merkle) {
-			return errors.New("invalid block: MerkleRoot mismatch")
+			return errMerkleRootMismatch
 		}
 		mp = mempool.New(len(block.Transactions), 0, false)
 		for _, tx := range block.Transactions {
@@ -896,7 +937,12 @@ func (bc *Blockchain) AddBlock(block *block.Block) error {
 // AddHeaders processes the given headers and add them to the
 // HeaderHashList. It expects headers to be sorted by index.
 func (bc *Blockchain) AddHeaders(headers ...*block.Header) error {
-	return bc.addHeaders(bc.config.VerifyBlocks, headers...)
+	return bc.addHeaders(bc.verifyHeaders(), headers...)
+}
+
+// verifyHeaders returns true if incoming headers must be verified.
+func (bc *Blockchain) verifyHeaders() bool {
+	return bc.config.VerifyBlocks || bc.config.HeadersFirstSync
 }
 
 // addHeaders is an internal implementation of AddHeaders (`verify` parameter
@@ -925,6 +971,11 @@ func (bc *Blockchain) addHeaders(verify bool, headers ...*block.Header) error {
 		// Verify that the chain of the headers is consistent.
 		var lastHeader *block.Header
 		if lastHeader, err = bc.GetHeader(headers[0].PrevHash); err != nil {
+			if headers[0].Index == bc.HeaderHeight()+1 {
+				// It's not a gap, the header belongs to some other chain.
+				return fmt.Errorf("%w: header %d doesn't follow the current one",
+					ErrHdrHashMismatch, headers[0].Index)
+			}
 			return fmt.Errorf("previous header was not found: %w", err)
 		}
 		for _, h := range headers {
@@ -979,6 +1030,12 @@ func (bc *Blockchain) GetStateModule() blockchainer.StateRoot {
 	return bc.stateRoot
 }
 
+// GetStore returns the underlying persistent store, it's intended for
+// auxiliary node data that is not a part of the chain state (like peer bans).
+func (bc *Blockchain) GetStore() storage.Store {
+	return bc.store
+}
+
 // GetStateSyncModule returns new state sync service instance.
 func (bc *Blockchain) GetStateSyncModule() *statesync.Module {
 	return statesync.NewModule(bc, bc.stateRoot, bc.log, bc.dao, bc.jumpToState)
@@ -1852,13 +1909,37 @@ func (bc *Blockchain) ApplyPolicyToTxSet(txes []*transaction.Transaction) []*tra
 	return txes
 }
 
+// invalidDataError is an error caused by the header, block or transaction
+// contents, such data can never become valid irrespective of the chain state.
+type invalidDataError struct {
+	msg string
+}
+
+func newInvalidDataError(msg string) error {
+	return &invalidDataError{msg: msg}
+}
+
+// Error implements the error interface.
+func (e *invalidDataError) Error() string {
+	return e.msg
+}
+
+// InvalidData always returns true, it allows to distinguish such errors
+// without checking them one by one.
+func (e *invalidDataError) InvalidData() bool {
+	return true
+}
+
 // Various errors that could be returns upon header verification.
 var (
-	ErrHdrHashMismatch     = errors.New("previous header hash doesn't match")
-	ErrHdrIndexMismatch    = errors.New("previous header index doesn't match")
-	ErrHdrInvalidTimestamp = errors.New("block is not newer than the previous one")
-	ErrHdrStateRootSetting = errors.New("state root setting mismatch")
-	ErrHdrInvalidStateRoot = errors.New("state root for previous block is invalid")
+	ErrHdrHashMismatch     = newInvalidDataError("previous header hash doesn't match")
+	ErrHdrIndexMismatch    = newInvalidDataError("previous header index doesn't match")
+	ErrHdrInvalidTimestamp = newInvalidDataError("block is not newer than the previous one")
+	ErrHdrStateRootSetting = newInvalidDataError("state root setting mismatch")
+	ErrHdrInvalidStateRoot = newInvalidDataError("state root for previous block is invalid")
+	ErrHdrBlockMismatch    = newInvalidDataError("block doesn't match the known header")
+
+	errMerkleRootMismatch = newInvalidDataError("invalid block: MerkleRoot mismatch")
 )
 
 func (bc *Blockchain) verifyHeader(currHeader, prevHeader *block.Header) error {
@@ -1887,10 +1968,10 @@ var (
 	ErrTxExpired         = errors.New("transaction has expired")
 	ErrInsufficientFunds = errors.New("insufficient funds")
 	ErrTxSmallNetworkFee = errors.New("too small network fee")
-	ErrTxTooBig          = errors.New("too big transaction")
+	ErrTxTooBig          = newInvalidDataError("too big transaction")
 	ErrMemPoolConflict   = errors.New("invalid transaction due to conflicts with the memory pool")
-	ErrInvalidScript     = errors.New("invalid script")
-	ErrInvalidAttribute  = errors.New("invalid attribute")
+	ErrInvalidScript     = newInvalidDataError("invalid script")
+	ErrInvalidAttribute  = newInvalidDataError("invalid attribute")
 )
 
 // verifyAndPoolTx verifies whether a transaction is bonafide or not and tries
@@ -1960,6 +2041,8 @@ func (bc *Blockchain) verifyAndPoolTx(t *transaction.Transaction, pool *mempool.
 			return ErrOOM
 		case errors.Is(err, mempool.ErrConflictsAttribute):
 			return fmt.Errorf("mempool: %w: %s", ErrHasConflicts, err)
+		case errors.Is(err, mempool.ErrSenderLimit):
+			return fmt.Errorf("mempool: %w: %s", ErrPolicy, err)
 		default:
 			return err
 		}
@@ -2108,6 +2191,53 @@ func (bc *Blockchain) PoolTx(t *transaction.Transaction, pools ...*mempool.Pool)
 	return bc.verifyAndPoolTx(t, pool, bc)
 }
 
+// saveMemPool writes verified mempool transactions to the DB.
+func (bc *Blockchain) saveMemPool() {
+	txs := bc.memPool.GetVerifiedTransactions()
+	if len(txs) == 0 {
+		return
+	}
+	w := io.NewBufBinWriter()
+	w.WriteArray(txs)
+	if w.Err != nil {
+		bc.log.Warn("failed to save mempool", zap.Error(w.Err))
+		return
+	}
+	err := bc.store.PutChangeSet(map[string][]byte{string(memPoolKey): w.Bytes()}, nil)
+	if err != nil {
+		bc.log.Warn("failed to save mempool", zap.Error(err))
+		return
+	}
+	bc.log.Info("mempool saved", zap.Int("transactions", len(txs)))
+}
+
+// restoreMemPool reads mempool transactions saved on shutdown and pools them
+// again, invalid and expired transactions are dropped.
+func (bc *Blockchain) restoreMemPool() {
+	b, err := bc.store.Get(memPoolKey)
+	if err != nil {
+		return
+	}
+	_ = bc.store.PutChangeSet(map[string][]byte{string(memPoolKey): nil}, nil)
+
+	var txs []*transaction.Transaction
+	r := io.NewBinReaderFromBuf(b)
+	r.ReadArray(&txs)
+	if r.Err != nil {
+		bc.log.Warn("failed to restore mempool", zap.Error(r.Err))
+		return
+	}
+	var restored int
+	for _, tx := range txs {
+		if bc.PoolTx(tx) == nil {
+			restored++
+		}
+	}
+	bc.log.Info("mempool restored",
+		zap.Int("restored", restored),
+		zap.Int("dropped", len(txs)-restored))
+}
+
 // PoolTxWithData verifies and tries to add given transaction with additional data into the mempool.
 func (bc *Blockchain) PoolTxWithData(t *transaction.Transaction, data interface{}, mp *mempool.Pool, feer mempool.Feer, verificationFunction func(tx *transaction.Transaction, data interface{}) error) error {
 	bc.lock.RLock()
@@ -2155,12 +2285,12 @@ func (bc *Blockchain) GetTestVM(t trigger.Type, tx *transaction.Transaction, b *
 
 // Various witness verification errors.
 var (
-	ErrWitnessHashMismatch         = errors.New("witness hash mismatch")
+	ErrWitnessHashMismatch         = newInvalidDataError("witness hash mismatch")
 	ErrNativeContractWitness       = errors.New("native contract witness must have empty verification script")
-	ErrVerificationFailed          = errors.New("signature check failed")
-	ErrInvalidInvocation           = errors.New("invalid invocation script")
+	ErrVerificationFailed          = newInvalidDataError("signature check failed")
+	ErrInvalidInvocation           = newInvalidDataError("invalid invocation script")
 	ErrInvalidSignature            = fmt.Errorf("%w: invalid signature", ErrVerificationFailed)
-	ErrInvalidVerification         = errors.New("invalid verification script")
+	ErrInvalidVerification         = newInvalidDataError("invalid verification script")
 	ErrUnknownVerificationContract = errors.New("unknown verification contract")
 	ErrInvalidVerificationContract = errors.New("verification contract is missing `verify` method")
 )
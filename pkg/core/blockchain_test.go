@@ -99,7 +99,8 @@ func TestAddHeaders(t *testing.T) {
 	h4 := newBlock(bc.config, 4, h3.Hash().Reverse()).Header
 	h5 := newBlock(bc.config, 5, h4.Hash()).Header
 
-	assert.Error(t, bc.AddHeaders(&h4, &h5))
+	// Headers from some other chain.
+	require.True(t, errors.Is(bc.AddHeaders(&h4, &h5), ErrHdrHashMismatch))
 	assert.Equal(t, h3.Index, bc.HeaderHeight())
 	assert.Equal(t, uint32(0), bc.BlockHeight())
 	assert.Equal(t, h3.Hash(), bc.CurrentHeaderHash())
@@ -138,6 +139,44 @@ func TestAddBlock(t *testing.T) {
 	assert.Equal(t, lastBlock.Hash(), bc.CurrentHeaderHash())
 }
 
+func TestAddBlockKnownHeader(t *testing.T) {
+	bc := newTestChainWithCustomCfg(t, func(c *config.Config) {
+		c.ProtocolConfiguration.HeadersFirstSync = true
+	})
+	lastBlock := bc.topBlock.Load().(*block.Block)
+
+	tx := newNEP17Transfer(bc.contracts.NEO.Hash, neoOwner, util.Uint160{}, 1)
+	tx.ValidUntilBlock = bc.BlockHeight() + 1
+	addSigners(neoOwner, tx)
+	require.NoError(t, testchain.SignTx(bc, tx))
+
+	b := newBlock(bc.config, lastBlock.Index+1, lastBlock.Hash(), tx)
+	require.NoError(t, bc.AddHeaders(&b.Header))
+
+	other := newBlock(bc.config, lastBlock.Index+1, lastBlock.Hash())
+	require.True(t, errors.Is(bc.AddBlock(other), ErrHdrBlockMismatch))
+
+	// Same header, different transactions.
+	forged := *b
+	forged.Transactions = nil
+	require.Error(t, bc.AddBlock(&forged))
+	require.Equal(t, lastBlock.Index, bc.BlockHeight())
+
+	require.NoError(t, bc.AddBlock(b))
+}
+
+func TestAddHeadersFirstSync(t *testing.T) {
+	bc := newTestChainWithCustomCfg(t, func(c *config.Config) {
+		c.ProtocolConfiguration.VerifyBlocks = false
+		c.ProtocolConfiguration.HeadersFirstSync = true
+	})
+	lastBlock := bc.topBlock.Load().(*block.Block)
+	h := newBlock(bc.config, lastBlock.Index+1, lastBlock.Hash()).Header
+	h.Script.InvocationScript = nil
+	require.True(t, errors.Is(bc.AddHeaders(&h), ErrVerificationFailed))
+	require.Equal(t, lastBlock.Index, bc.HeaderHeight())
+}
+
 func TestAddBlockStateRoot(t *testing.T) {
 	bc := newTestChainWithCustomCfg(t, func(c *config.Config) {
 		c.ProtocolConfiguration.StateRootInHeader = true
@@ -1312,6 +1351,17 @@ func TestMemPoolRemoval(t *testing.T) {
 	}
 }
 
+func TestMemPoolPolicy(t *testing.T) {
+	bc := newTestChain(t)
+	bc.SetMemPoolConfig(config.MemPool{MaxSenderTransactions: 1})
+	tx := bc.newTestTx(testchain.MultisigScriptHash(), []byte{byte(opcode.PUSH1)})
+	require.NoError(t, testchain.SignTx(bc, tx))
+	require.NoError(t, bc.PoolTx(tx))
+	tx = bc.newTestTx(testchain.MultisigScriptHash(), []byte{byte(opcode.PUSH1)})
+	require.NoError(t, testchain.SignTx(bc, tx))
+	require.ErrorIs(t, bc.PoolTx(tx), ErrPolicy)
+}
+
 func TestHasBlock(t *testing.T) {
 	bc := newTestChain(t)
 	blocks, err := bc.genBlocks(50)
@@ -2231,3 +2281,76 @@ func TestBlockchain_StartFromExistingDB(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+func TestBlockchain_VMLimits(t *testing.T) {
+	bc := newTestChainWithCustomCfg(t, func(c *config.Config) {
+		c.ProtocolConfiguration.VMLimitsHistory = map[uint32]config.VMLimits{
+			5: {MaxStackSize: 16, MaxItemSize: 1024},
+		}
+		require.NoError(t, c.ProtocolConfiguration.Validate())
+	})
+
+	ic := bc.GetTestVM(trigger.Application, nil, nil)
+	require.Equal(t, vm.DefaultLimits(), ic.VM.Limits())
+
+	expected := vm.DefaultLimits()
+	expected.MaxStackSize = 16
+	expected.MaxItemSize = 1024
+	ic = bc.GetTestVM(trigger.Application, nil, &block.Block{Header: block.Header{Index: 5}})
+	require.Equal(t, expected, ic.VM.Limits())
+
+	ic.VM.LoadScript([]byte{byte(opcode.PUSH16), byte(opcode.NEWARRAY)})
+	require.Error(t, ic.VM.Run())
+}
+
+func TestBlockchain_InvalidVMLimits(t *testing.T) {
+	for _, l := range []config.VMLimits{{MaxItemSize: stackitem.MaxSize + 1}, {MaxStackSize: -1}} {
+		_, err := initTestChainNoCheck(t, nil, func(c *config.Config) {
+			c.ProtocolConfiguration.VMLimitsHistory = map[uint32]config.VMLimits{5: l}
+		})
+		require.Error(t, err)
+	}
+}
+
+func TestBlockchain_PersistMemPool(t *testing.T) {
+	ps, path := newLevelDBForTestingWithPath(t, "")
+	bc := initTestChain(t, ps, nil)
+	bc.SetMemPoolConfig(config.MemPool{Persist: true})
+	go bc.Run()
+
+	valid := bc.newTestTx(testchain.MultisigScriptHash(), []byte{byte(opcode.PUSH1)})
+	require.NoError(t, testchain.SignTx(bc, valid))
+	require.NoError(t, bc.PoolTx(valid))
+	expiring := bc.newTestTx(testchain.MultisigScriptHash(), []byte{byte(opcode.PUSH1)})
+	expiring.ValidUntilBlock = 1
+	require.NoError(t, testchain.SignTx(bc, expiring))
+	require.NoError(t, bc.PoolTx(expiring))
+	bc.Close()
+
+	// Mempool is not restored and the snapshot is kept if persistence is disabled.
+	ps, _ = newLevelDBForTestingWithPath(t, path)
+	bc = initTestChain(t, ps, nil)
+	go bc.Run()
+	require.Equal(t, 0, bc.GetMemPool().Count())
+	require.NoError(t, bc.AddBlock(bc.newBlock()))
+	bc.Close()
+
+	ps, _ = newLevelDBForTestingWithPath(t, path)
+	bc = initTestChain(t, ps, nil)
+	bc.SetMemPoolConfig(config.MemPool{Persist: true})
+	go bc.Run()
+	mp := bc.GetMemPool()
+	require.Equal(t, 1, mp.Count())
+	require.True(t, mp.ContainsKey(valid.Hash()))
+	require.False(t, mp.ContainsKey(expiring.Hash()))
+	mp.Remove(valid.Hash(), bc)
+	bc.Close()
+
+	// Snapshot is removed once restored.
+	ps, _ = newLevelDBForTestingWithPath(t, path)
+	bc = initTestChain(t, ps, nil)
+	defer bc.Close()
+	go bc.Run()
+	_, err := ps.Get([]byte{byte(storage.SYSMemPool), 0})
+	require.ErrorIs(t, err, storage.ErrKeyNotFound)
+}
@@ -64,6 +64,9 @@ type Context struct {
 	getContract   func(*dao.Simple, util.Uint160) (*state.Contract, error)
 	baseExecFee   int64
 	signers       []transaction.Signer
+	// limits are VM limits used for spawned VMs, default ones are used
+	// if nil.
+	limits *vm.Limits
 }
 
 // NewContext returns new interop context.
@@ -76,6 +79,15 @@ func NewContext(trigger trigger.Type, bc Ledger, d *dao.Simple,
 	if bc != nil && (block == nil || block.Index != 0) {
 		baseExecFee = bc.GetBaseExecFee()
 	}
+	var limits *vm.Limits
+	if bc != nil {
+		height := bc.BlockHeight() + 1
+		if block != nil {
+			height = block.Index
+		}
+		cfg := bc.GetConfig()
+		limits = GetVMLimits(cfg.GetVMLimits(height))
+	}
 	return &Context{
 		Chain:       bc,
 		Network:     uint32(bc.GetConfig().Magic),
@@ -88,9 +100,27 @@ func NewContext(trigger trigger.Type, bc Ledger, d *dao.Simple,
 		Invocations: make(map[util.Uint160]int),
 		getContract: getContract,
 		baseExecFee: baseExecFee,
+		limits:      limits,
 	}
 }
 
+// GetVMLimits applies configured limits overrides to the default VM limits.
+func GetVMLimits(c config.VMLimits) *vm.Limits {
+	l := vm.DefaultLimits()
+	override := func(dst *int, v int) {
+		if v != 0 {
+			*dst = v
+		}
+	}
+	override(&l.MaxStackSize, c.MaxStackSize)
+	override(&l.MaxInvocationStackSize, c.MaxInvocationStackSize)
+	override(&l.MaxTryNestingDepth, c.MaxTryNestingDepth)
+	override(&l.MaxItemSize, c.MaxItemSize)
+	override(&l.MaxComparableSize, c.MaxComparableSize)
+	override(&l.MaxComparableNumOfItems, c.MaxComparableNumOfItems)
+	return &l
+}
+
 // InitNonceData initializes nonce to be used in `GetRandom` calculations.
 func (ic *Context) InitNonceData() {
 	if tx, ok := ic.Container.(*transaction.Transaction); ok {
@@ -128,7 +158,10 @@ type Function struct {
 	Func func(*Context) error
 	// ParamCount is a number of function parameters.
 	ParamCount int
-	Price      int64
+	// ReturnCount is a number of items pushed onto the stack, it's negative
+	// if it can't be determined statically.
+	ReturnCount int
+	Price       int64
 	// RequiredFlags is a set of flags which must be set during script invocations.
 	// Default value is NoneFlag i.e. no flags are required.
 	RequiredFlags callflag.CallFlag
@@ -314,6 +347,9 @@ func (ic *Context) SpawnVM() *vm.VM {
 	v := vm.NewWithTrigger(ic.Trigger)
 	v.GasLimit = -1
 	v.SyscallHandler = ic.SyscallHandler
+	if ic.limits != nil {
+		v.SetLimits(*ic.limits)
+	}
 	ic.VM = v
 	return v
 }
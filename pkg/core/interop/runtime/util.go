@@ -9,7 +9,6 @@ import (
 	"github.com/nspcc-dev/neo-go/pkg/core/state"
 	"github.com/nspcc-dev/neo-go/pkg/encoding/bigint"
 	"github.com/nspcc-dev/neo-go/pkg/util"
-	"github.com/nspcc-dev/neo-go/pkg/vm"
 	"github.com/nspcc-dev/neo-go/pkg/vm/stackitem"
 	"github.com/twmb/murmur3"
 )
@@ -44,7 +43,7 @@ func GetNotifications(ic *interop.Context) error {
 			}
 		}
 	}
-	if len(notifications) > vm.MaxStackSize {
+	if len(notifications) > ic.VM.Limits().MaxStackSize {
 		return errors.New("too many notifications")
 	}
 	arr := stackitem.NewArray(make([]stackitem.Item, 0, len(notifications)))
@@ -0,0 +1,84 @@
+package interop
+
+import (
+	"github.com/nspcc-dev/neo-go/pkg/core/fee"
+	"github.com/nspcc-dev/neo-go/pkg/core/interop/interopnames"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/callflag"
+	"github.com/nspcc-dev/neo-go/pkg/vm"
+)
+
+// syscalls contains descriptions of all system calls, handlers are set by
+// the core package. All lists are sorted, keep 'em this way, please.
+var syscalls = []Function{
+	{Name: interopnames.SystemContractCall, Price: 1 << 15, RequiredFlags: callflag.ReadStates | callflag.AllowCall,
+		ParamCount: 4, ReturnCount: 1},
+	// The number of results depends on the native method called.
+	{Name: interopnames.SystemContractCallNative, ParamCount: 1, ReturnCount: -1},
+	{Name: interopnames.SystemContractCreateMultisigAccount, Price: 1 << 8, ParamCount: 2, ReturnCount: 1},
+	{Name: interopnames.SystemContractCreateStandardAccount, Price: 1 << 8, ParamCount: 1, ReturnCount: 1},
+	{Name: interopnames.SystemContractGetCallFlags, Price: 1 << 10, ReturnCount: 1},
+	{Name: interopnames.SystemContractNativeOnPersist, RequiredFlags: callflag.States},
+	{Name: interopnames.SystemContractNativePostPersist, RequiredFlags: callflag.States},
+	{Name: interopnames.SystemCryptoCheckMultisig, ParamCount: 2, ReturnCount: 1},
+	{Name: interopnames.SystemCryptoCheckSig, Price: fee.ECDSAVerifyPrice, ParamCount: 2, ReturnCount: 1},
+	{Name: interopnames.SystemIteratorNext, Price: 1 << 15, ParamCount: 1, ReturnCount: 1},
+	{Name: interopnames.SystemIteratorValue, Price: 1 << 4, ParamCount: 1, ReturnCount: 1},
+	{Name: interopnames.SystemRuntimeBurnGas, Price: 1 << 4, ParamCount: 1},
+	{Name: interopnames.SystemRuntimeCheckWitness, Price: 1 << 10, RequiredFlags: callflag.NoneFlag, ParamCount: 1,
+		ReturnCount: 1},
+	{Name: interopnames.SystemRuntimeGasLeft, Price: 1 << 4, ReturnCount: 1},
+	{Name: interopnames.SystemRuntimeGetCallingScriptHash, Price: 1 << 4, ReturnCount: 1},
+	{Name: interopnames.SystemRuntimeGetEntryScriptHash, Price: 1 << 4, ReturnCount: 1},
+	{Name: interopnames.SystemRuntimeGetExecutingScriptHash, Price: 1 << 4, ReturnCount: 1},
+	{Name: interopnames.SystemRuntimeGetInvocationCounter, Price: 1 << 4, ReturnCount: 1},
+	{Name: interopnames.SystemRuntimeGetNetwork, Price: 1 << 3, ReturnCount: 1},
+	{Name: interopnames.SystemRuntimeGetNotifications, Price: 1 << 8, ParamCount: 1, ReturnCount: 1},
+	{Name: interopnames.SystemRuntimeGetRandom, Price: 1 << 4, ReturnCount: 1},
+	{Name: interopnames.SystemRuntimeGetScriptContainer, Price: 1 << 3, ReturnCount: 1},
+	{Name: interopnames.SystemRuntimeGetTime, Price: 1 << 3, RequiredFlags: callflag.ReadStates, ReturnCount: 1},
+	{Name: interopnames.SystemRuntimeGetTrigger, Price: 1 << 3, ReturnCount: 1},
+	{Name: interopnames.SystemRuntimeLog, Price: 1 << 15, RequiredFlags: callflag.AllowNotify, ParamCount: 1},
+	{Name: interopnames.SystemRuntimeNotify, Price: 1 << 15, RequiredFlags: callflag.AllowNotify, ParamCount: 2},
+	{Name: interopnames.SystemRuntimePlatform, Price: 1 << 3, ReturnCount: 1},
+	{Name: interopnames.SystemStorageDelete, Price: 1 << 15, RequiredFlags: callflag.WriteStates, ParamCount: 2},
+	{Name: interopnames.SystemStorageFind, Price: 1 << 15, RequiredFlags: callflag.ReadStates, ParamCount: 3,
+		ReturnCount: 1},
+	{Name: interopnames.SystemStorageGet, Price: 1 << 15, RequiredFlags: callflag.ReadStates, ParamCount: 2,
+		ReturnCount: 1},
+	{Name: interopnames.SystemStorageGetContext, Price: 1 << 4, RequiredFlags: callflag.ReadStates, ReturnCount: 1},
+	{Name: interopnames.SystemStorageGetReadOnlyContext, Price: 1 << 4, RequiredFlags: callflag.ReadStates,
+		ReturnCount: 1},
+	{Name: interopnames.SystemStoragePut, Price: 1 << 15, RequiredFlags: callflag.WriteStates, ParamCount: 3},
+	{Name: interopnames.SystemStorageAsReadOnly, Price: 1 << 4, RequiredFlags: callflag.ReadStates, ParamCount: 1,
+		ReturnCount: 1},
+}
+
+func init() {
+	for i := range syscalls {
+		syscalls[i].ID = interopnames.ToID([]byte(syscalls[i].Name))
+	}
+	Sort(syscalls)
+}
+
+// GetSyscalls returns a copy of all system call descriptions sorted by their
+// IDs. Handlers (Func) are not set there.
+func GetSyscalls() []Function {
+	res := make([]Function, len(syscalls))
+	copy(res, syscalls)
+	return res
+}
+
+// GetSyscallDescs returns static descriptions of all system calls indexed by
+// their IDs, they can be used for script verification (see vm.Verify).
+func GetSyscallDescs() map[uint32]vm.SyscallDesc {
+	res := make(map[uint32]vm.SyscallDesc, len(syscalls))
+	for _, f := range syscalls {
+		res[f.ID] = vm.SyscallDesc{
+			Name:          f.Name,
+			ParamCount:    f.ParamCount,
+			ReturnCount:   f.ReturnCount,
+			RequiredFlags: f.RequiredFlags,
+		}
+	}
+	return res
+}
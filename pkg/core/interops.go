@@ -8,7 +8,6 @@ package core
 */
 
 import (
-	"github.com/nspcc-dev/neo-go/pkg/core/fee"
 	"github.com/nspcc-dev/neo-go/pkg/core/interop"
 	"github.com/nspcc-dev/neo-go/pkg/core/interop/contract"
 	"github.com/nspcc-dev/neo-go/pkg/core/interop/crypto"
@@ -16,7 +15,6 @@ import (
 	"github.com/nspcc-dev/neo-go/pkg/core/interop/iterator"
 	"github.com/nspcc-dev/neo-go/pkg/core/interop/runtime"
 	"github.com/nspcc-dev/neo-go/pkg/core/native"
-	"github.com/nspcc-dev/neo-go/pkg/smartcontract/callflag"
 	"github.com/nspcc-dev/neo-go/pkg/vm"
 )
 
@@ -28,59 +26,51 @@ func SpawnVM(ic *interop.Context) *vm.VM {
 	return vm
 }
 
-// All lists are sorted, keep 'em this way, please.
-var systemInterops = []interop.Function{
-	{Name: interopnames.SystemContractCall, Func: contract.Call, Price: 1 << 15,
-		RequiredFlags: callflag.ReadStates | callflag.AllowCall, ParamCount: 4},
-	{Name: interopnames.SystemContractCallNative, Func: native.Call, Price: 0, ParamCount: 1},
-	{Name: interopnames.SystemContractCreateMultisigAccount, Func: contractCreateMultisigAccount, Price: 1 << 8, ParamCount: 2},
-	{Name: interopnames.SystemContractCreateStandardAccount, Func: contractCreateStandardAccount, Price: 1 << 8, ParamCount: 1},
-	{Name: interopnames.SystemContractGetCallFlags, Func: contractGetCallFlags, Price: 1 << 10},
-	{Name: interopnames.SystemContractNativeOnPersist, Func: native.OnPersist, Price: 0, RequiredFlags: callflag.States},
-	{Name: interopnames.SystemContractNativePostPersist, Func: native.PostPersist, Price: 0, RequiredFlags: callflag.States},
-	{Name: interopnames.SystemCryptoCheckMultisig, Func: crypto.ECDSASecp256r1CheckMultisig, Price: 0, ParamCount: 2},
-	{Name: interopnames.SystemCryptoCheckSig, Func: crypto.ECDSASecp256r1CheckSig, Price: fee.ECDSAVerifyPrice, ParamCount: 2},
-	{Name: interopnames.SystemIteratorNext, Func: iterator.Next, Price: 1 << 15, ParamCount: 1},
-	{Name: interopnames.SystemIteratorValue, Func: iterator.Value, Price: 1 << 4, ParamCount: 1},
-	{Name: interopnames.SystemRuntimeBurnGas, Func: runtime.BurnGas, Price: 1 << 4, ParamCount: 1},
-	{Name: interopnames.SystemRuntimeCheckWitness, Func: runtime.CheckWitness, Price: 1 << 10,
-		RequiredFlags: callflag.NoneFlag, ParamCount: 1},
-	{Name: interopnames.SystemRuntimeGasLeft, Func: runtime.GasLeft, Price: 1 << 4},
-	{Name: interopnames.SystemRuntimeGetCallingScriptHash, Func: runtime.GetCallingScriptHash, Price: 1 << 4},
-	{Name: interopnames.SystemRuntimeGetEntryScriptHash, Func: runtime.GetEntryScriptHash, Price: 1 << 4},
-	{Name: interopnames.SystemRuntimeGetExecutingScriptHash, Func: runtime.GetExecutingScriptHash, Price: 1 << 4},
-	{Name: interopnames.SystemRuntimeGetInvocationCounter, Func: runtime.GetInvocationCounter, Price: 1 << 4},
-	{Name: interopnames.SystemRuntimeGetNetwork, Func: runtime.GetNetwork, Price: 1 << 3},
-	{Name: interopnames.SystemRuntimeGetNotifications, Func: runtime.GetNotifications, Price: 1 << 8, ParamCount: 1},
-	{Name: interopnames.SystemRuntimeGetRandom, Func: runtime.GetRandom, Price: 1 << 4},
-	{Name: interopnames.SystemRuntimeGetScriptContainer, Func: engineGetScriptContainer, Price: 1 << 3},
-	{Name: interopnames.SystemRuntimeGetTime, Func: runtime.GetTime, Price: 1 << 3, RequiredFlags: callflag.ReadStates},
-	{Name: interopnames.SystemRuntimeGetTrigger, Func: runtime.GetTrigger, Price: 1 << 3},
-	{Name: interopnames.SystemRuntimeLog, Func: runtime.Log, Price: 1 << 15, RequiredFlags: callflag.AllowNotify,
-		ParamCount: 1},
-	{Name: interopnames.SystemRuntimeNotify, Func: runtime.Notify, Price: 1 << 15, RequiredFlags: callflag.AllowNotify,
-		ParamCount: 2},
-	{Name: interopnames.SystemRuntimePlatform, Func: runtime.Platform, Price: 1 << 3},
-	{Name: interopnames.SystemStorageDelete, Func: storageDelete, Price: 1 << 15,
-		RequiredFlags: callflag.WriteStates, ParamCount: 2},
-	{Name: interopnames.SystemStorageFind, Func: storageFind, Price: 1 << 15, RequiredFlags: callflag.ReadStates,
-		ParamCount: 3},
-	{Name: interopnames.SystemStorageGet, Func: storageGet, Price: 1 << 15, RequiredFlags: callflag.ReadStates,
-		ParamCount: 2},
-	{Name: interopnames.SystemStorageGetContext, Func: storageGetContext, Price: 1 << 4,
-		RequiredFlags: callflag.ReadStates},
-	{Name: interopnames.SystemStorageGetReadOnlyContext, Func: storageGetReadOnlyContext, Price: 1 << 4,
-		RequiredFlags: callflag.ReadStates},
-	{Name: interopnames.SystemStoragePut, Func: storagePut, Price: 1 << 15, RequiredFlags: callflag.WriteStates,
-		ParamCount: 3},
-	{Name: interopnames.SystemStorageAsReadOnly, Func: storageContextAsReadOnly, Price: 1 << 4,
-		RequiredFlags: callflag.ReadStates, ParamCount: 1},
+// systemHandlers maps system call names to their handlers, all the other
+// system call properties are defined by the interop package.
+var systemHandlers = map[string]func(*interop.Context) error{
+	interopnames.SystemContractCall:                  contract.Call,
+	interopnames.SystemContractCallNative:            native.Call,
+	interopnames.SystemContractCreateMultisigAccount: contractCreateMultisigAccount,
+	interopnames.SystemContractCreateStandardAccount: contractCreateStandardAccount,
+	interopnames.SystemContractGetCallFlags:          contractGetCallFlags,
+	interopnames.SystemContractNativeOnPersist:       native.OnPersist,
+	interopnames.SystemContractNativePostPersist:     native.PostPersist,
+	interopnames.SystemCryptoCheckMultisig:           crypto.ECDSASecp256r1CheckMultisig,
+	interopnames.SystemCryptoCheckSig:                crypto.ECDSASecp256r1CheckSig,
+	interopnames.SystemIteratorNext:                  iterator.Next,
+	interopnames.SystemIteratorValue:                 iterator.Value,
+	interopnames.SystemRuntimeBurnGas:                runtime.BurnGas,
+	interopnames.SystemRuntimeCheckWitness:           runtime.CheckWitness,
+	interopnames.SystemRuntimeGasLeft:                runtime.GasLeft,
+	interopnames.SystemRuntimeGetCallingScriptHash:   runtime.GetCallingScriptHash,
+	interopnames.SystemRuntimeGetEntryScriptHash:     runtime.GetEntryScriptHash,
+	interopnames.SystemRuntimeGetExecutingScriptHash: runtime.GetExecutingScriptHash,
+	interopnames.SystemRuntimeGetInvocationCounter:   runtime.GetInvocationCounter,
+	interopnames.SystemRuntimeGetNetwork:             runtime.GetNetwork,
+	interopnames.SystemRuntimeGetNotifications:       runtime.GetNotifications,
+	interopnames.SystemRuntimeGetRandom:              runtime.GetRandom,
+	interopnames.SystemRuntimeGetScriptContainer:     engineGetScriptContainer,
+	interopnames.SystemRuntimeGetTime:                runtime.GetTime,
+	interopnames.SystemRuntimeGetTrigger:             runtime.GetTrigger,
+	interopnames.SystemRuntimeLog:                    runtime.Log,
+	interopnames.SystemRuntimeNotify:                 runtime.Notify,
+	interopnames.SystemRuntimePlatform:               runtime.Platform,
+	interopnames.SystemStorageDelete:                 storageDelete,
+	interopnames.SystemStorageFind:                   storageFind,
+	interopnames.SystemStorageGet:                    storageGet,
+	interopnames.SystemStorageGetContext:             storageGetContext,
+	interopnames.SystemStorageGetReadOnlyContext:     storageGetReadOnlyContext,
+	interopnames.SystemStoragePut:                    storagePut,
+	interopnames.SystemStorageAsReadOnly:             storageContextAsReadOnly,
 }
 
-// init initializes IDs in the global interop slices.
+// systemInterops contains all system calls with their handlers.
+var systemInterops = interop.GetSyscalls()
+
+// init sets system call handlers.
 func init() {
 	for i := range systemInterops {
-		systemInterops[i].ID = interopnames.ToID([]byte(systemInterops[i].Name))
+		systemInterops[i].Func = systemHandlers[systemInterops[i].Name]
 	}
-	interop.Sort(systemInterops)
 }
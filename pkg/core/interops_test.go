@@ -48,3 +48,20 @@ func TestUnexpectedNonInterops(t *testing.T) {
 		}
 	}
 }
+
+func TestSystemInterops(t *testing.T) {
+	require.Equal(t, len(systemHandlers), len(systemInterops))
+	for _, f := range systemInterops {
+		require.NotNil(t, f.Func, f.Name)
+	}
+	descs := interop.GetSyscallDescs()
+	require.Equal(t, len(systemInterops), len(descs))
+	for _, f := range systemInterops {
+		desc, ok := descs[f.ID]
+		require.True(t, ok, f.Name)
+		require.Equal(t, f.Name, desc.Name)
+		require.Equal(t, f.ParamCount, desc.ParamCount, f.Name)
+		require.Equal(t, f.ReturnCount, desc.ReturnCount, f.Name)
+		require.Equal(t, f.RequiredFlags, desc.RequiredFlags, f.Name)
+	}
+}
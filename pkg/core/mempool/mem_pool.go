@@ -35,8 +35,24 @@ var (
 	// ErrOracleResponse is returned when mempool already contains transaction
 	// with the same oracle response ID and higher network fee.
 	ErrOracleResponse = errors.New("conflicts with memory pool due to OracleResponse attribute")
+	// ErrSenderLimit is returned when the sender already has the maximum
+	// number of transactions allowed by the policy in the pool.
+	ErrSenderLimit = errors.New("too many transactions from the sender")
 )
 
+// Policy contains optional memory pool policies, zero values disable them.
+type Policy struct {
+	// ReplaceFeeIncrease is the minimum network fee increase (in percents)
+	// a transaction needs to replace a pooled transaction of the same sender
+	// it conflicts with.
+	ReplaceFeeIncrease int
+	// MaxSenderTransactions is the maximum number of pooled transactions
+	// per sender.
+	MaxSenderTransactions int
+	// UnlimitedSenders are not affected by MaxSenderTransactions.
+	UnlimitedSenders []util.Uint160
+}
+
 // item represents a transaction in the the Memory pool.
 type item struct {
 	txn        *transaction.Transaction
@@ -47,11 +63,12 @@ type item struct {
 // items is a slice of item.
 type items []item
 
-// utilityBalanceAndFees stores sender's balance and overall fees of
-// sender's transactions which are currently in mempool.
+// utilityBalanceAndFees stores sender's balance, overall fees and the number
+// of sender's transactions which are currently in mempool.
 type utilityBalanceAndFees struct {
 	balance uint256.Int
 	feeSum  uint256.Int
+	count   int
 }
 
 // Pool stores the unconfirms transactions.
@@ -68,6 +85,7 @@ type Pool struct {
 	capacity   int
 	feePerByte int64
 	payerIndex int
+	policy     Policy
 
 	resendThreshold uint32
 	resendFunc      func(*transaction.Transaction, interface{})
@@ -176,6 +194,7 @@ func (mp *Pool) tryAddSendersFee(tx *transaction.Transaction, feer Feer, needChe
 	} else {
 		senderFee.feeSum.AddUint64(&senderFee.feeSum, uint64(tx.SystemFee+tx.NetworkFee))
 	}
+	senderFee.count++
 	mp.fees[payer] = senderFee
 	return true
 }
@@ -251,26 +270,15 @@ func (mp *Pool) Add(t *transaction.Transaction, fee Feer, data ...interface{}) e
 			mp.lock.Unlock()
 			return ErrOOM
 		}
-		// Ditch the last one.
-		unlucky := mp.verifiedTxes[len(mp.verifiedTxes)-1]
-		delete(mp.verifiedMap, unlucky.txn.Hash())
-		if fee.P2PSigExtensionsEnabled() {
-			mp.removeConflictsOf(unlucky.txn)
-		}
-		if attrs := unlucky.txn.GetAttributes(transaction.OracleResponseT); len(attrs) != 0 {
-			delete(mp.oracleResp, attrs[0].Value.(*transaction.OracleResponse).ID)
-		}
-		mp.verifiedTxes[len(mp.verifiedTxes)-1] = pItem
-		if mp.subscriptionsOn.Load() {
-			mp.events <- mempoolevent.Event{
-				Type: mempoolevent.TransactionRemoved,
-				Tx:   unlucky.txn,
-				Data: unlucky.data,
-			}
-		}
-	} else {
-		mp.verifiedTxes = append(mp.verifiedTxes, pItem)
+		// Ditch the last one. Transactions are verified against the chain
+		// state only, so nothing else in the pool depends on it. Items are
+		// shifted, so the position is to be found again.
+		mp.removeInternal(mp.verifiedTxes[len(mp.verifiedTxes)-1].txn.Hash(), fee)
+		n = sort.Search(len(mp.verifiedTxes), func(n int) bool {
+			return pItem.CompareTo(mp.verifiedTxes[n]) > 0
+		})
 	}
+	mp.verifiedTxes = append(mp.verifiedTxes, pItem)
 	if n != len(mp.verifiedTxes)-1 {
 		copy(mp.verifiedTxes[n+1:], mp.verifiedTxes[n:])
 		mp.verifiedTxes[n] = pItem
@@ -326,6 +334,7 @@ func (mp *Pool) removeInternal(hash util.Uint256, feer Feer) {
 		payer := itm.txn.Signers[mp.payerIndex].Account
 		senderFee := mp.fees[payer]
 		senderFee.feeSum.SubUint64(&senderFee.feeSum, uint64(tx.SystemFee+tx.NetworkFee))
+		senderFee.count--
 		mp.fees[payer] = senderFee
 		if feer.P2PSigExtensionsEnabled() {
 			// remove all conflicting hashes from mp.conflicts list
@@ -439,6 +448,65 @@ func New(capacity int, payerIndex int, enableSubscriptions bool) *Pool {
 	return mp
 }
 
+// SetPolicy sets memory pool policies, they only affect transactions added
+// after the call.
+func (mp *Pool) SetPolicy(p Policy) {
+	mp.lock.Lock()
+	defer mp.lock.Unlock()
+	mp.policy = p
+}
+
+// minReplaceFee returns the minimum network fee a transaction needs to pay
+// to replace the given one.
+func (mp *Pool) minReplaceFee(tx *transaction.Transaction) int64 {
+	inc := int64(mp.policy.ReplaceFeeIncrease)
+	if inc <= 0 {
+		return tx.NetworkFee
+	}
+	return tx.NetworkFee + tx.NetworkFee/100*inc + tx.NetworkFee%100*inc/100
+}
+
+// isLimitedSender checks whether MaxSenderTransactions policy is applied to the sender.
+func (mp *Pool) isLimitedSender(sender util.Uint160) bool {
+	if mp.policy.MaxSenderTransactions <= 0 {
+		return false
+	}
+	for _, u := range mp.policy.UnlimitedSenders {
+		if u.Equals(sender) {
+			return false
+		}
+	}
+	return true
+}
+
+// FeePerBytePercentiles returns fee per byte values of pooled transactions
+// at the given percentiles (from 0 to 100, nearest-rank method is used). It
+// returns nil if the pool is empty.
+func (mp *Pool) FeePerBytePercentiles(percentiles ...int) []int64 {
+	mp.lock.RLock()
+	fees := make([]int64, len(mp.verifiedTxes))
+	for i := range mp.verifiedTxes {
+		fees[i] = mp.verifiedTxes[i].txn.FeePerByte()
+	}
+	mp.lock.RUnlock()
+
+	if len(fees) == 0 {
+		return nil
+	}
+	sort.Slice(fees, func(i, j int) bool { return fees[i] < fees[j] })
+	res := make([]int64, len(percentiles))
+	for i, p := range percentiles {
+		idx := (p*len(fees)+99)/100 - 1
+		if idx < 0 {
+			idx = 0
+		} else if idx >= len(fees) {
+			idx = len(fees) - 1
+		}
+		res[i] = fees[idx]
+	}
+	return res
+}
+
 // SetResendThreshold sets threshold after which transaction will be considered stale
 // and returned for retransmission by `GetStaleTransactions`.
 func (mp *Pool) SetResendThreshold(h uint32, f func(*transaction.Transaction, interface{})) {
@@ -520,8 +588,13 @@ func (mp *Pool) checkTxConflicts(tx *transaction.Transaction, fee Feer) ([]*tran
 		if conflictingHashes, ok := mp.conflicts[tx.Hash()]; ok {
 			for _, hash := range conflictingHashes {
 				existingTx := mp.verifiedMap[hash]
-				if existingTx.HasSigner(payer) && existingTx.NetworkFee > tx.NetworkFee {
-					return nil, fmt.Errorf("%w: conflicting transaction %s has bigger network fee", ErrConflictsAttribute, existingTx.Hash().StringBE())
+				if existingTx.HasSigner(payer) {
+					if existingTx.NetworkFee > tx.NetworkFee {
+						return nil, fmt.Errorf("%w: conflicting transaction %s has bigger network fee", ErrConflictsAttribute, existingTx.Hash().StringBE())
+					}
+					if minFee := mp.minReplaceFee(existingTx); tx.NetworkFee < minFee {
+						return nil, fmt.Errorf("%w: network fee of at least %d is required to replace conflicting transaction %s", ErrConflictsAttribute, minFee, existingTx.Hash().StringBE())
+					}
 				}
 				conflictsToBeRemoved = append(conflictsToBeRemoved, existingTx)
 			}
@@ -539,6 +612,9 @@ func (mp *Pool) checkTxConflicts(tx *transaction.Transaction, fee Feer) ([]*tran
 			if existingTx.NetworkFee >= tx.NetworkFee {
 				return nil, fmt.Errorf("%w: conflicting transaction %s has bigger or equal network fee", ErrConflictsAttribute, existingTx.Hash().StringBE())
 			}
+			if minFee := mp.minReplaceFee(existingTx); tx.NetworkFee < minFee {
+				return nil, fmt.Errorf("%w: network fee of at least %d is required to replace conflicting transaction %s", ErrConflictsAttribute, minFee, existingTx.Hash().StringBE())
+			}
 			conflictsToBeRemoved = append(conflictsToBeRemoved, existingTx)
 		}
 		// Step 3: take into account sender's conflicting transactions before balance check.
@@ -546,11 +622,15 @@ func (mp *Pool) checkTxConflicts(tx *transaction.Transaction, fee Feer) ([]*tran
 		for _, conflictingTx := range conflictsToBeRemoved {
 			if conflictingTx.Signers[mp.payerIndex].Account.Equals(payer) {
 				expectedSenderFee.feeSum.SubUint64(&expectedSenderFee.feeSum, uint64(conflictingTx.SystemFee+conflictingTx.NetworkFee))
+				expectedSenderFee.count--
 			}
 		}
 	} else {
 		expectedSenderFee = actualSenderFee
 	}
+	if mp.isLimitedSender(payer) && expectedSenderFee.count >= mp.policy.MaxSenderTransactions {
+		return conflictsToBeRemoved, ErrSenderLimit
+	}
 	_, err := checkBalance(tx, expectedSenderFee)
 	return conflictsToBeRemoved, err
 }
@@ -271,6 +271,7 @@ func TestMemPoolFees(t *testing.T) {
 	require.Equal(t, utilityBalanceAndFees{
 		balance: *uint256.NewInt(uint64(fs.balance)),
 		feeSum:  *uint256.NewInt(uint64(tx1.NetworkFee)),
+		count:   1,
 	}, mp.fees[sender0])
 
 	// balance shouldn't change after adding one more transaction
@@ -283,6 +284,7 @@ func TestMemPoolFees(t *testing.T) {
 	require.Equal(t, utilityBalanceAndFees{
 		balance: *uint256.NewInt(uint64(fs.balance)),
 		feeSum:  *uint256.NewInt(uint64(fs.balance)),
+		count:   2,
 	}, mp.fees[sender0])
 
 	// can't add more transactions as we don't have enough GAS
@@ -295,6 +297,7 @@ func TestMemPoolFees(t *testing.T) {
 	require.Equal(t, utilityBalanceAndFees{
 		balance: *uint256.NewInt(uint64(fs.balance)),
 		feeSum:  *uint256.NewInt(uint64(fs.balance)),
+		count:   2,
 	}, mp.fees[sender0])
 
 	// check whether sender's fee updates correctly
@@ -305,6 +308,7 @@ func TestMemPoolFees(t *testing.T) {
 	require.Equal(t, utilityBalanceAndFees{
 		balance: *uint256.NewInt(uint64(fs.balance)),
 		feeSum:  *uint256.NewInt(uint64(tx2.NetworkFee)),
+		count:   1,
 	}, mp.fees[sender0])
 
 	// there should be nothing left
@@ -656,3 +660,159 @@ func TestMempoolAddWithDataGetData(t *testing.T) {
 	_, ok = mp.TryGetData(r7.FallbackTransaction.Hash())
 	require.False(t, ok)
 }
+
+func TestMempoolReplaceFeeIncrease(t *testing.T) {
+	mp := New(10, 0, false)
+	mp.SetPolicy(Policy{ReplaceFeeIncrease: 10})
+	var (
+		fs     = &FeerStub{p2pSigExt: true, balance: 100000}
+		sender = util.Uint160{1, 2, 3}
+	)
+	newTx := func(nonce uint32, netFee int64, conflicts ...util.Uint256) *transaction.Transaction {
+		tx := transaction.New([]byte{byte(opcode.PUSH1)}, 0)
+		tx.Nonce = nonce
+		tx.NetworkFee = netFee
+		tx.Signers = []transaction.Signer{{Account: sender}}
+		for _, h := range conflicts {
+			tx.Attributes = append(tx.Attributes, transaction.Attribute{
+				Type:  transaction.ConflictsT,
+				Value: &transaction.Conflicts{Hash: h},
+			})
+		}
+		return tx
+	}
+
+	tx1 := newTx(1, 1000)
+	require.NoError(t, mp.Add(tx1, fs))
+	// Step 2, 10% more is required.
+	require.ErrorIs(t, mp.Add(newTx(2, 1099, tx1.Hash()), fs), ErrConflictsAttribute)
+	tx2 := newTx(3, 1100, tx1.Hash())
+	require.NoError(t, mp.Add(tx2, fs))
+	require.False(t, mp.ContainsKey(tx1.Hash()))
+
+	// Step 1, pooled tx4 conflicts with tx3 being added.
+	tx3 := newTx(4, 1200)
+	tx4 := newTx(5, 1100, tx3.Hash())
+	require.NoError(t, mp.Add(tx4, fs))
+	require.ErrorIs(t, mp.Add(tx3, fs), ErrConflictsAttribute)
+	tx5 := newTx(6, 1210)
+	tx6 := newTx(7, 1100, tx5.Hash())
+	require.NoError(t, mp.Add(tx6, fs))
+	require.NoError(t, mp.Add(tx5, fs))
+	require.False(t, mp.ContainsKey(tx6.Hash()))
+	require.Equal(t, 3, mp.Count())
+	require.Equal(t, 3, mp.fees[sender].count)
+}
+
+func TestMempoolMaxSenderTransactions(t *testing.T) {
+	var (
+		fs        = &FeerStub{p2pSigExt: true, balance: 100000}
+		sender    = util.Uint160{1, 2, 3}
+		unlimited = util.Uint160{4, 5, 6}
+		nonce     uint32
+	)
+	mp := New(10, 0, false)
+	mp.SetPolicy(Policy{MaxSenderTransactions: 2, UnlimitedSenders: []util.Uint160{unlimited}})
+	newTx := func(acc util.Uint160, netFee int64, conflicts ...util.Uint256) *transaction.Transaction {
+		tx := transaction.New([]byte{byte(opcode.PUSH1)}, 0)
+		tx.Nonce = nonce
+		nonce++
+		tx.NetworkFee = netFee
+		tx.Signers = []transaction.Signer{{Account: acc}}
+		for _, h := range conflicts {
+			tx.Attributes = append(tx.Attributes, transaction.Attribute{
+				Type:  transaction.ConflictsT,
+				Value: &transaction.Conflicts{Hash: h},
+			})
+		}
+		return tx
+	}
+
+	tx1 := newTx(sender, 10)
+	require.NoError(t, mp.Add(tx1, fs))
+	require.NoError(t, mp.Add(newTx(sender, 10), fs))
+	tx := newTx(sender, 10)
+	require.False(t, mp.Verify(tx, fs))
+	require.ErrorIs(t, mp.Add(tx, fs), ErrSenderLimit)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, mp.Add(newTx(unlimited, 10), fs))
+	}
+
+	// Replacement doesn't change the number of sender's transactions.
+	require.NoError(t, mp.Add(newTx(sender, 20, tx1.Hash()), fs))
+	require.False(t, mp.ContainsKey(tx1.Hash()))
+
+	mp.Remove(tx1.Hash(), fs)
+	require.Equal(t, 2, mp.fees[sender].count)
+	mp.RemoveStale(func(tx *transaction.Transaction) bool {
+		return tx.Sender() != sender || tx.NetworkFee == 20
+	}, fs)
+	require.Equal(t, 1, mp.fees[sender].count)
+	require.NoError(t, mp.Add(tx, fs))
+}
+
+func TestMempoolEvict(t *testing.T) {
+	var (
+		fs    = &FeerStub{p2pSigExt: true, balance: 100000}
+		nonce uint32
+	)
+	const capacity = 4
+	mp := New(capacity, 0, false)
+	newTx := func(netFee int64, attrs ...transaction.Attribute) *transaction.Transaction {
+		tx := transaction.New([]byte{byte(opcode.PUSH1)}, 0)
+		tx.Nonce = nonce
+		nonce++
+		tx.NetworkFee = netFee
+		tx.Signers = []transaction.Signer{{Account: random.Uint160()}}
+		tx.Attributes = attrs
+		return tx
+	}
+	newFallback := func(netFee int64, main util.Uint256) *transaction.Transaction {
+		return newTx(netFee,
+			transaction.Attribute{Type: transaction.ConflictsT, Value: &transaction.Conflicts{Hash: main}},
+			transaction.Attribute{Type: transaction.NotaryAssistedT, Value: &transaction.NotaryAssisted{NKeys: 0}})
+	}
+
+	main := random.Uint256()
+	fb1 := newFallback(10, main)
+	fb2 := newFallback(30, main)
+	other := newTx(20, transaction.Attribute{Type: transaction.ConflictsT, Value: &transaction.Conflicts{Hash: main}})
+	rich := newTx(40)
+	for _, tx := range []*transaction.Transaction{fb1, fb2, other, rich} {
+		require.NoError(t, mp.Add(tx, fs))
+	}
+
+	// Only the least prioritized transaction is evicted, fallbacks of the
+	// same main transaction are independent.
+	tx := newTx(50)
+	require.NoError(t, mp.Add(tx, fs))
+	require.Equal(t, capacity, mp.Count())
+	require.False(t, mp.ContainsKey(fb1.Hash()))
+	for _, tx := range []*transaction.Transaction{fb2, other, rich, tx} {
+		require.True(t, mp.ContainsKey(tx.Hash()))
+	}
+	require.ElementsMatch(t, []util.Uint256{fb2.Hash(), other.Hash()}, mp.conflicts[main])
+	fb1Fees := mp.fees[fb1.Sender()]
+	require.Equal(t, 0, fb1Fees.count)
+	require.True(t, fb1Fees.feeSum.IsZero())
+	require.Equal(t, true, sort.IsSorted(sort.Reverse(mp.verifiedTxes)))
+
+	// Less prioritized than everything in the pool.
+	require.ErrorIs(t, mp.Add(newTx(5), fs), ErrOOM)
+	require.Equal(t, capacity, mp.Count())
+}
+
+func TestFeePerBytePercentiles(t *testing.T) {
+	fs := &FeerStub{balance: 10000000}
+	mp := New(100, 0, false)
+	require.Nil(t, mp.FeePerBytePercentiles(50))
+
+	for i := 1; i <= 10; i++ {
+		tx := transaction.New([]byte{byte(opcode.PUSH1)}, 0)
+		tx.Nonce = uint32(i)
+		tx.Signers = []transaction.Signer{{Account: util.Uint160{1, 2, 3}}}
+		tx.NetworkFee = int64(i * tx.Size())
+		require.NoError(t, mp.Add(tx, fs))
+	}
+	require.Equal(t, []int64{1, 1, 5, 9, 10, 10}, mp.FeePerBytePercentiles(0, 10, 50, 90, 100, 150))
+}
@@ -160,12 +160,12 @@ func newStd() *Std {
 	return s
 }
 
-func (s *Std) serialize(_ *interop.Context, args []stackitem.Item) stackitem.Item {
+func (s *Std) serialize(ic *interop.Context, args []stackitem.Item) stackitem.Item {
 	data, err := stackitem.Serialize(args[0])
 	if err != nil {
 		panic(err)
 	}
-	if len(data) > stackitem.MaxSize {
+	if len(data) > ic.VM.Limits().MaxItemSize {
 		panic(errors.New("too big item"))
 	}
 
@@ -186,12 +186,12 @@ func (s *Std) deserialize(_ *interop.Context, args []stackitem.Item) stackitem.I
 	return item
 }
 
-func (s *Std) jsonSerialize(_ *interop.Context, args []stackitem.Item) stackitem.Item {
+func (s *Std) jsonSerialize(ic *interop.Context, args []stackitem.Item) stackitem.Item {
 	data, err := stackitem.ToJSON(args[0])
 	if err != nil {
 		panic(err)
 	}
-	if len(data) > stackitem.MaxSize {
+	if len(data) > ic.VM.Limits().MaxItemSize {
 		panic(errors.New("too big item"))
 	}
 
@@ -323,7 +323,15 @@ func (s *Module) AddBlock(block *block.Block) error {
 	if s.bc.GetConfig().StateRootInHeader != block.StateRootEnabled {
 		return fmt.Errorf("stateroot setting mismatch: %v != %v", s.bc.GetConfig().StateRootInHeader, block.StateRootEnabled)
 	}
-	if s.bc.GetConfig().VerifyBlocks {
+	cfg := s.bc.GetConfig()
+	if cfg.HeadersFirstSync {
+		// Headers are already synchronized and verified, so blocks are
+		// checked against them.
+		if h := s.bc.GetHeaderHash(int(block.Index)); !h.Equals(block.Hash()) {
+			return fmt.Errorf("block %d doesn't match the header: %s != %s", block.Index, block.Hash().StringLE(), h.StringLE())
+		}
+	}
+	if cfg.VerifyBlocks || cfg.HeadersFirstSync {
 		merkle := block.ComputeMerkleRoot()
 		if !block.MerkleRoot.Equals(merkle) {
 			return errors.New("invalid block: MerkleRoot mismatch")
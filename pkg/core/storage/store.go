@@ -31,6 +31,8 @@ const (
 	SYSStateSyncCurrentBlockHeight KeyPrefix = 0xc2
 	SYSStateSyncPoint              KeyPrefix = 0xc3
 	SYSStateJumpStage              KeyPrefix = 0xc4
+	SYSPeerBan                     KeyPrefix = 0xc5
+	SYSMemPool                     KeyPrefix = 0xc6
 	SYSVersion                     KeyPrefix = 0xf0
 )
 
@@ -25,9 +25,13 @@ func NewMerkleTree(hashes []util.Uint256) (*MerkleTree, error) {
 		}
 	}
 
+	depth := 1
+	for n := len(hashes); n > 1; n = (n + 1) / 2 {
+		depth++
+	}
 	return &MerkleTree{
 		root:  buildMerkleTree(nodes),
-		depth: 1,
+		depth: depth,
 	}, nil
 }
 
@@ -66,6 +70,50 @@ func buildMerkleTree(leaves []*MerkleTreeNode) *MerkleTreeNode {
 	return buildMerkleTree(parents)
 }
 
+// Trim prunes the tree leaving only branches that lead to leaves marked in
+// flags (flags are indexed by leaf number). Subtrees without marked leaves are
+// replaced by their root hashes, so that ToHashArray returns a partial Merkle
+// tree for the marked leaves. It's compatible with the C# implementation.
+func (t *MerkleTree) Trim(flags []bool) {
+	f := make([]bool, 1<<(t.depth-1))
+	copy(f, flags)
+	trim(t.root, 0, t.depth, f)
+}
+
+func trim(node *MerkleTreeNode, index int, depth int, flags []bool) {
+	if depth == 1 || node.leftChild == nil {
+		return
+	}
+	if depth == 2 {
+		if !flags[index*2] && !flags[index*2+1] {
+			node.leftChild = nil
+			node.rightChild = nil
+		}
+		return
+	}
+	trim(node.leftChild, index*2, depth-1, flags)
+	trim(node.rightChild, index*2+1, depth-1, flags)
+	if node.leftChild.leftChild == nil && node.rightChild.rightChild == nil {
+		node.leftChild = nil
+		node.rightChild = nil
+	}
+}
+
+// ToHashArray returns hashes of the tree leaves (or of the pruned subtree
+// roots if the tree was trimmed) in depth-first order.
+func (t *MerkleTree) ToHashArray() []util.Uint256 {
+	var res []util.Uint256
+	return appendHashes(res, t.root)
+}
+
+func appendHashes(hashes []util.Uint256, node *MerkleTreeNode) []util.Uint256 {
+	if node.leftChild == nil {
+		return append(hashes, node.hash)
+	}
+	hashes = appendHashes(hashes, node.leftChild)
+	return appendHashes(hashes, node.rightChild)
+}
+
 // CalcMerkleRoot calculcates Merkle root hash value for a given slice of hashes.
 // It doesn't create a full MerkleTree structure and it uses given slice as a
 // scratchpad, so it will destroy its contents in the process. But it's much more
@@ -101,3 +101,34 @@ func TestBuildMerkleTreeWithoutNodes(t *testing.T) {
 	leaves = make([]*MerkleTreeNode, 0)
 	require.Panics(t, func() { buildMerkleTree(leaves) })
 }
+
+func TestMerkleTreeTrim(t *testing.T) {
+	h1, h2, h3 := DoubleSha256([]byte{1}), DoubleSha256([]byte{2}), DoubleSha256([]byte{3})
+	hashes := []util.Uint256{h1, h2, h3}
+
+	t.Run("untrimmed", func(t *testing.T) {
+		tree, err := NewMerkleTree(hashes)
+		require.NoError(t, err)
+		// The last hash is duplicated to make a full level.
+		require.Equal(t, []util.Uint256{h1, h2, h3, h3}, tree.ToHashArray())
+	})
+	t.Run("nothing matched", func(t *testing.T) {
+		tree, err := NewMerkleTree(hashes)
+		require.NoError(t, err)
+		tree.Trim([]bool{false, false, false})
+		require.Equal(t, []util.Uint256{CalcMerkleRoot([]util.Uint256{h1, h2, h3})}, tree.ToHashArray())
+	})
+	t.Run("one matched", func(t *testing.T) {
+		tree, err := NewMerkleTree(hashes)
+		require.NoError(t, err)
+		tree.Trim([]bool{false, true, false})
+		h33 := DoubleSha256(append(h3.BytesBE(), h3.BytesBE()...))
+		require.Equal(t, []util.Uint256{h1, h2, h33}, tree.ToHashArray())
+	})
+	t.Run("single leaf", func(t *testing.T) {
+		tree, err := NewMerkleTree([]util.Uint256{h1})
+		require.NoError(t, err)
+		tree.Trim([]bool{false})
+		require.Equal(t, []util.Uint256{h1}, tree.ToHashArray())
+	})
+}
@@ -1,3 +1,3 @@
 module github.com/nspcc-dev/neo-go/pkg/interop
 
-go 1.16
\ No newline at end of file
+go 1.18
\ No newline at end of file
@@ -0,0 +1,244 @@
+package network
+
+import (
+	"sync"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/network/payload"
+)
+
+// Parallel block download parameters.
+const (
+	// blockRangeSize is the maximum number of blocks requested from a peer
+	// at once.
+	blockRangeSize = payload.MaxHashesCount
+	// maxPeerRanges is the number of ranges that can be requested from a
+	// single peer simultaneously.
+	maxPeerRanges = 2
+	// blockRangeTimeout is the time a peer has to deliver the requested
+	// range, after that the range is requested from some other peer.
+	blockRangeTimeout = 20 * time.Second
+)
+
+// blockRange is a range of blocks requested from some peer.
+type blockRange struct {
+	start uint32
+	count uint32
+	// peer is the one the range is currently requested from, it's nil if
+	// the range is done or needs to be rerequested.
+	peer      Peer
+	requested time.Time
+	received  []bool
+	left      uint32
+	done      time.Time
+}
+
+func (r *blockRange) end() uint32 {
+	return r.start + r.count - 1
+}
+
+func (r *blockRange) isDone() bool {
+	return r.left == 0
+}
+
+// blockFetcher splits blocks above the current height into disjoint ranges
+// and distributes them between peers, so that blocks are downloaded from
+// several peers in parallel. Block queue then feeds them to the chain in
+// order. Ranges not delivered in time are requested from other peers.
+type blockFetcher struct {
+	lock    sync.Mutex
+	timeout time.Duration
+	// ranges are sorted by start index and don't intersect.
+	ranges []*blockRange
+	// active is the number of ranges being fetched from each peer.
+	active map[Peer]int
+}
+
+func newBlockFetcher() *blockFetcher {
+	return &blockFetcher{
+		timeout: blockRangeTimeout,
+		active:  make(map[Peer]int),
+	}
+}
+
+// next returns the request for the next range of blocks to fetch from the
+// peer or nil if there is nothing to request from it now.
+func (f *blockFetcher) next(p Peer, height uint32) *payload.GetBlockByIndex {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.prune(height)
+	if f.active[p] >= maxPeerRanges {
+		return nil
+	}
+	var (
+		now   = time.Now()
+		limit = height + blockCacheSize
+		pos   = height + 1
+	)
+	if ph := p.LastBlockIndex(); ph < limit {
+		limit = ph
+	}
+	for i := 0; pos <= limit; i++ {
+		var r *blockRange
+		if i < len(f.ranges) {
+			r = f.ranges[i]
+		}
+		if r == nil || pos < r.start {
+			end := (pos-1)/blockRangeSize*blockRangeSize + blockRangeSize
+			if end > limit {
+				end = limit
+			}
+			if r != nil && end >= r.start {
+				end = r.start - 1
+			}
+			r = &blockRange{
+				start:    pos,
+				count:    end - pos + 1,
+				received: make([]bool, end-pos+1),
+				left:     end - pos + 1,
+			}
+			f.ranges = append(f.ranges, nil)
+			copy(f.ranges[i+1:], f.ranges[i:])
+			f.ranges[i] = r
+			return f.assign(r, p, now)
+		}
+		if r.end() <= limit && f.available(r, p, height, now) {
+			if r.peer != nil {
+				f.active[r.peer]--
+				blockRangeRetries.Inc()
+			}
+			if r.isDone() {
+				// Stalled, blocks were lost somehow.
+				for j := range r.received {
+					r.received[j] = false
+				}
+				r.left = r.count
+			}
+			return f.assign(r, p, now)
+		}
+		pos = r.end() + 1
+	}
+	return nil
+}
+
+// available checks whether the range can be requested from the peer.
+func (f *blockFetcher) available(r *blockRange, p Peer, height uint32, now time.Time) bool {
+	if r.isDone() {
+		// All blocks are received, but the chain is stuck at them for
+		// too long.
+		return r.start == height+1 && now.Sub(r.done) > f.timeout
+	}
+	if r.peer == nil {
+		return true
+	}
+	late := now.Sub(r.requested)
+	if r.peer != p {
+		return late > f.timeout
+	}
+	// Retry with the same peer only if nobody else has picked it up.
+	return late > 2*f.timeout
+}
+
+// assign must be called with the lock held.
+func (f *blockFetcher) assign(r *blockRange, p Peer, now time.Time) *payload.GetBlockByIndex {
+	r.peer = p
+	r.requested = now
+	f.active[p]++
+	return payload.NewGetBlockByIndex(r.start, int16(r.count))
+}
+
+// prune drops ranges that are already in the chain, it must be called with
+// the lock held.
+func (f *blockFetcher) prune(height uint32) {
+	var i int
+	for i < len(f.ranges) && f.ranges[i].end() <= height {
+		if p := f.ranges[i].peer; p != nil {
+			f.active[p]--
+		}
+		i++
+	}
+	f.ranges = f.ranges[i:]
+}
+
+// add marks the block as received from the peer. It returns true if this
+// block has completed the range requested from this peer, so that it can be
+// given a new one.
+func (f *blockFetcher) add(p Peer, index uint32) bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	r := f.find(index)
+	if r == nil || r.received[index-r.start] {
+		return false
+	}
+	r.received[index-r.start] = true
+	r.left--
+	if !r.isDone() {
+		return false
+	}
+	r.done = time.Now()
+	if r.peer == nil {
+		return false
+	}
+	f.active[r.peer]--
+	if r.peer != p {
+		r.peer = nil
+		return false
+	}
+	updatePeerBlockThroughputMetric(p, float64(r.count)/r.done.Sub(r.requested).Seconds())
+	r.peer = nil
+	return true
+}
+
+// fail makes the block to be requested again (from some other peer).
+func (f *blockFetcher) fail(index uint32) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	r := f.find(index)
+	if r == nil || !r.received[index-r.start] {
+		return
+	}
+	r.received[index-r.start] = false
+	r.left++
+	if r.peer != nil {
+		f.active[r.peer]--
+		r.peer = nil
+	}
+}
+
+// find returns the range containing the index, it must be called with the
+// lock held.
+func (f *blockFetcher) find(index uint32) *blockRange {
+	for _, r := range f.ranges {
+		if r.start <= index && index <= r.end() {
+			return r
+		}
+	}
+	return nil
+}
+
+// dropPeer makes all ranges fetched from the peer available to others.
+func (f *blockFetcher) dropPeer(p Peer) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	for _, r := range f.ranges {
+		if r.peer == p {
+			r.peer = nil
+		}
+	}
+	delete(f.active, p)
+	removePeerBlockThroughputMetric(p)
+}
+
+// reset forgets all ranges, it's used when blocks that were received are
+// thrown away.
+func (f *blockFetcher) reset() {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.ranges = nil
+	f.active = make(map[Peer]int)
+}
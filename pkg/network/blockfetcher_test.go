@@ -0,0 +1,118 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/network/payload"
+	"github.com/stretchr/testify/require"
+)
+
+func newFetcherPeer(t *testing.T, height uint32, ip byte) *localPeer {
+	p := newLocalPeer(t, nil)
+	p.lastBlockIndex = height
+	p.netaddr.IP = []byte{10, 0, 0, ip}
+	return p
+}
+
+func requireRange(t *testing.T, pl *payload.GetBlockByIndex, start uint32, count int16) {
+	require.NotNil(t, pl)
+	require.Equal(t, start, pl.IndexStart)
+	require.Equal(t, count, pl.Count)
+}
+
+func TestBlockFetcherDistribution(t *testing.T) {
+	f := newBlockFetcher()
+	p1 := newFetcherPeer(t, 10000, 1)
+	p2 := newFetcherPeer(t, 10000, 2)
+	p3 := newFetcherPeer(t, 700, 3)
+
+	// Ranges are aligned and disjoint.
+	requireRange(t, f.next(p3, 10), 11, blockRangeSize-10)
+	requireRange(t, f.next(p1, 10), blockRangeSize+1, blockRangeSize)
+	requireRange(t, f.next(p2, 10), 2*blockRangeSize+1, blockRangeSize)
+	requireRange(t, f.next(p1, 10), 3*blockRangeSize+1, blockRangeSize)
+	// Per-peer limit.
+	require.Nil(t, f.next(p1, 10))
+	// p3 has nothing more to offer.
+	require.Nil(t, f.next(p3, 10))
+
+	// Completing a range frees the peer.
+	for i := uint32(2*blockRangeSize + 1); i < 3*blockRangeSize; i++ {
+		require.False(t, f.add(p2, i))
+	}
+	require.False(t, f.add(p2, 2*blockRangeSize+1)) // Duplicate.
+	require.True(t, f.add(p2, 3*blockRangeSize))
+	requireRange(t, f.next(p2, 10), 4*blockRangeSize+1, blockRangeSize)
+
+	// Window limit.
+	for i := byte(10); ; i++ {
+		if f.next(newFetcherPeer(t, 10000, i), 10) == nil {
+			break
+		}
+	}
+	last := f.ranges[len(f.ranges)-1]
+	require.Equal(t, uint32(10+blockCacheSize), last.end())
+
+	// Pruning.
+	f.next(p2, 2*blockRangeSize)
+	require.Equal(t, uint32(2*blockRangeSize+1), f.ranges[0].start)
+	require.Equal(t, 0, f.active[p3])
+	require.Equal(t, 1, f.active[p1])
+}
+
+func TestBlockFetcherRetry(t *testing.T) {
+	f := newBlockFetcher()
+	f.timeout = 50 * time.Millisecond
+	p1 := newFetcherPeer(t, 1000, 1)
+	p2 := newFetcherPeer(t, 1000, 2)
+
+	requireRange(t, f.next(p1, 0), 1, blockRangeSize)
+	requireRange(t, f.next(p2, 0), blockRangeSize+1, blockRangeSize)
+	require.Nil(t, f.next(p2, 0))
+
+	t.Run("slow peer", func(t *testing.T) {
+		for i := uint32(blockRangeSize + 1); i < 2*blockRangeSize; i++ {
+			require.False(t, f.add(p2, i))
+		}
+		require.True(t, f.add(p2, 2*blockRangeSize))
+		time.Sleep(f.timeout)
+		// p1 range is given to p2.
+		requireRange(t, f.next(p2, 0), 1, blockRangeSize)
+		require.Equal(t, 0, f.active[p1])
+		// Late blocks from p1 are still counted.
+		for i := uint32(1); i <= blockRangeSize; i++ {
+			require.False(t, f.add(p1, i))
+		}
+		require.True(t, f.ranges[0].isDone())
+		require.Equal(t, 0, f.active[p2])
+	})
+	t.Run("disconnected peer", func(t *testing.T) {
+		f.reset()
+		requireRange(t, f.next(p1, 0), 1, blockRangeSize)
+		f.dropPeer(p1)
+		requireRange(t, f.next(p2, 0), 1, blockRangeSize)
+	})
+	t.Run("invalid block", func(t *testing.T) {
+		f.reset()
+		requireRange(t, f.next(p1, 0), 1, blockRangeSize)
+		for i := uint32(1); i < blockRangeSize; i++ {
+			require.False(t, f.add(p1, i))
+		}
+		require.True(t, f.add(p1, blockRangeSize))
+		f.fail(1)
+		requireRange(t, f.next(p2, 0), 1, blockRangeSize)
+	})
+	t.Run("stalled", func(t *testing.T) {
+		f.reset()
+		requireRange(t, f.next(p1, 0), 1, blockRangeSize)
+		for i := uint32(1); i <= blockRangeSize; i++ {
+			f.add(p1, i)
+		}
+		requireRange(t, f.next(p1, 0), blockRangeSize+1, blockRangeSize)
+		require.Nil(t, f.next(p1, 0))
+		time.Sleep(f.timeout)
+		f.dropPeer(p1)
+		requireRange(t, f.next(p2, 0), 1, blockRangeSize)
+	})
+}
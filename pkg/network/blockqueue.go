@@ -19,10 +19,12 @@ type blockQueue struct {
 	log         *zap.Logger
 	queueLock   sync.RWMutex
 	queue       []*block.Block
+	sources     []Peer
 	lastQ       uint32
 	checkBlocks chan struct{}
 	chain       Blockqueuer
 	relayF      func(*block.Block)
+	failF       func(Peer, *block.Block, error)
 	discarded   *atomic.Bool
 	len         int
 }
@@ -30,14 +32,17 @@ type blockQueue struct {
 const (
 	// blockCacheSize is the amount of blocks above current height
 	// which are stored in queue.
-	blockCacheSize = 2000
+	blockCacheSize = 5000
 )
 
 func indexToPosition(i uint32) int {
 	return int(i) % blockCacheSize
 }
 
-func newBlockQueue(capacity int, bc Blockqueuer, log *zap.Logger, relayer func(*block.Block)) *blockQueue {
+// newBlockQueue creates a queue for blocks received from peers. relayer is
+// called for every successfully added block and failer is called for blocks
+// that can't be added to the chain along with the peer they were received from.
+func newBlockQueue(capacity int, bc Blockqueuer, log *zap.Logger, relayer func(*block.Block), failer func(Peer, *block.Block, error)) *blockQueue {
 	if log == nil {
 		return nil
 	}
@@ -45,9 +50,11 @@ func newBlockQueue(capacity int, bc Blockqueuer, log *zap.Logger, relayer func(*
 	return &blockQueue{
 		log:         log,
 		queue:       make([]*block.Block, blockCacheSize),
+		sources:     make([]Peer, blockCacheSize),
 		checkBlocks: make(chan struct{}, 1),
 		chain:       bc,
 		relayF:      relayer,
+		failF:       failer,
 		discarded:   atomic.NewBool(false),
 	}
 }
@@ -64,12 +71,14 @@ func (bq *blockQueue) run() {
 			pos := indexToPosition(h + 1)
 			bq.queueLock.Lock()
 			b := bq.queue[pos]
+			src := bq.sources[pos]
 			// The chain moved forward using blocks from other sources (consensus).
 			for i := lastHeight; i < h; i++ {
 				old := indexToPosition(i + 1)
 				if bq.queue[old] != nil && bq.queue[old].Index == i {
 					bq.len--
 					bq.queue[old] = nil
+					bq.sources[old] = nil
 				}
 			}
 			bq.queueLock.Unlock()
@@ -86,6 +95,9 @@ func (bq *blockQueue) run() {
 						zap.String("error", err.Error()),
 						zap.Uint32("blockHeight", bq.chain.BlockHeight()),
 						zap.Uint32("nextIndex", b.Index))
+					if bq.failF != nil && src != nil {
+						bq.failF(src, b, err)
+					}
 				}
 			} else if bq.relayF != nil {
 				bq.relayF(b)
@@ -95,6 +107,7 @@ func (bq *blockQueue) run() {
 			l := bq.len
 			if bq.queue[pos] == b {
 				bq.queue[pos] = nil
+				bq.sources[pos] = nil
 			}
 			bq.queueLock.Unlock()
 			updateBlockQueueLenMetric(l)
@@ -102,7 +115,9 @@ func (bq *blockQueue) run() {
 	}
 }
 
-func (bq *blockQueue) putBlock(block *block.Block) error {
+// putBlock adds the block received from the peer (which can be nil if the
+// block is not received from the network) to the queue.
+func (bq *blockQueue) putBlock(p Peer, block *block.Block) error {
 	h := bq.chain.BlockHeight()
 	bq.queueLock.Lock()
 	if block.Index <= h || h+blockCacheSize < block.Index {
@@ -116,6 +131,7 @@ func (bq *blockQueue) putBlock(block *block.Block) error {
 	if bq.queue[pos] == nil || bq.queue[pos].Index < block.Index {
 		bq.len++
 		bq.queue[pos] = block
+		bq.sources[pos] = p
 		for pos < blockCacheSize && bq.queue[pos] != nil && bq.lastQ+1 == bq.queue[pos].Index {
 			bq.lastQ = bq.queue[pos].Index
 			pos++
@@ -140,7 +156,9 @@ func (bq *blockQueue) lastQueued() uint32 {
 	return bq.lastQ
 }
 
-func (bq *blockQueue) discard() {
+// discard stops the queue and drops all blocks from it, it returns false if
+// the queue is already discarded.
+func (bq *blockQueue) discard() bool {
 	if bq.discarded.CAS(false, true) {
 		close(bq.checkBlocks)
 		bq.queueLock.Lock()
@@ -148,8 +166,11 @@ func (bq *blockQueue) discard() {
 		// another if in run().
 		for i := 0; i < len(bq.queue); i++ {
 			bq.queue[i] = nil
+			bq.sources[i] = nil
 		}
 		bq.len = 0
 		bq.queueLock.Unlock()
+		return true
 	}
+	return false
 }
@@ -0,0 +1,96 @@
+/*
+Package bloom implements bloom filter used by SPV clients to get only the data
+they're interested in from the node. It's compatible with the C# node
+implementation (it uses Murmur3 hash function with K seeds derived from the
+tweak).
+*/
+package bloom
+
+import (
+	"sync"
+
+	"github.com/twmb/murmur3"
+)
+
+// seedMultiplier is used to derive hash function seeds from the tweak.
+const seedMultiplier = 0xFBA4C795
+
+// Filter is a bloom filter, it's safe for concurrent use.
+type Filter struct {
+	lock  sync.RWMutex
+	bits  []byte
+	m     uint32
+	seeds []uint32
+	tweak uint32
+}
+
+// New returns an empty filter of m bits with k hash functions.
+func New(m int, k int, tweak uint32) *Filter {
+	f := NewFromBytes(make([]byte, (m+7)/8), k, tweak)
+	f.m = uint32(m)
+	return f
+}
+
+// NewFromBytes returns a filter with the given bits (8*len(bits) of them) and
+// k hash functions. The slice is used directly, so it must not be modified by
+// the caller afterwards.
+func NewFromBytes(bits []byte, k int, tweak uint32) *Filter {
+	f := &Filter{
+		bits:  bits,
+		m:     uint32(len(bits) * 8),
+		seeds: make([]uint32, k),
+		tweak: tweak,
+	}
+	for i := range f.seeds {
+		f.seeds[i] = uint32(i)*seedMultiplier + tweak
+	}
+	return f
+}
+
+// K returns the number of hash functions used by the filter.
+func (f *Filter) K() int {
+	return len(f.seeds)
+}
+
+// Tweak returns the filter tweak.
+func (f *Filter) Tweak() uint32 {
+	return f.tweak
+}
+
+// Bytes returns a copy of the filter bits.
+func (f *Filter) Bytes() []byte {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	return append([]byte(nil), f.bits...)
+}
+
+// Add adds the element to the filter.
+func (f *Filter) Add(data []byte) {
+	if f.m == 0 {
+		return
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	for _, seed := range f.seeds {
+		i := murmur3.SeedSum32(seed, data) % f.m
+		f.bits[i/8] |= 1 << (i % 8)
+	}
+}
+
+// Check returns true if the element can be in the filter (false positives
+// are possible) and false if it definitely isn't there. Empty filter never
+// matches anything.
+func (f *Filter) Check(data []byte) bool {
+	if f.m == 0 {
+		return false
+	}
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	for _, seed := range f.seeds {
+		i := murmur3.SeedSum32(seed, data) % f.m
+		if f.bits[i/8]&(1<<(i%8)) == 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,47 @@
+package bloom
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilter(t *testing.T) {
+	f := New(7, 10, 123456)
+	require.Equal(t, 10, f.K())
+	require.EqualValues(t, 123456, f.Tweak())
+
+	elements := []byte{0, 1, 2, 3, 4}
+	require.False(t, f.Check(elements))
+	f.Add(elements)
+	require.True(t, f.Check(elements))
+	require.False(t, f.Check([]byte{5, 6, 7, 8, 9}))
+
+	t.Run("from bytes", func(t *testing.T) {
+		f := New(1024, 5, 42)
+		f.Add([]byte("one"))
+		f.Add([]byte("two"))
+
+		restored := NewFromBytes(f.Bytes(), f.K(), f.Tweak())
+		require.Equal(t, f.Bytes(), restored.Bytes())
+		require.True(t, restored.Check([]byte("one")))
+		require.True(t, restored.Check([]byte("two")))
+		require.False(t, restored.Check([]byte("three")))
+	})
+	t.Run("empty", func(t *testing.T) {
+		f := NewFromBytes(nil, 3, 0)
+		f.Add([]byte("one"))
+		require.False(t, f.Check([]byte("one")))
+	})
+	t.Run("bits", func(t *testing.T) {
+		f := NewFromBytes(make([]byte, 2), 3, 0)
+		f.Add([]byte{1, 2, 3})
+		var set int
+		for _, b := range f.Bytes() {
+			for ; b != 0; b &= b - 1 {
+				set++
+			}
+		}
+		require.True(t, set > 0 && set <= 3)
+	})
+}
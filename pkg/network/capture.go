@@ -0,0 +1,184 @@
+package network
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	gio "io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/config/netmode"
+	"github.com/nspcc-dev/neo-go/pkg/io"
+	"github.com/nspcc-dev/neo-go/pkg/network/payload"
+)
+
+// Capture file consists of a header (captureMagic, network magic and
+// StateRootInHeader setting needed to decode messages) followed by message
+// records. Every record contains a timestamp (nanoseconds since the epoch),
+// direction, peer address and the message exactly as it was sent over the
+// wire.
+var captureMagic = []byte("NGP2PCAP")
+
+// maxCapturedSize is the maximum size of the captured message (payload plus
+// flags, command and payload length).
+const maxCapturedSize = payload.MaxSize + 16
+
+// CaptureDirection is the direction of the captured message.
+type CaptureDirection byte
+
+// Captured message directions.
+const (
+	CaptureIn CaptureDirection = iota
+	CaptureOut
+)
+
+// String implements the fmt.Stringer interface.
+func (d CaptureDirection) String() string {
+	switch d {
+	case CaptureIn:
+		return "in"
+	case CaptureOut:
+		return "out"
+	default:
+		return fmt.Sprintf("unknown (%d)", byte(d))
+	}
+}
+
+// CapturedMessage is a single P2P message record from the capture file.
+type CapturedMessage struct {
+	Time      time.Time
+	Direction CaptureDirection
+	Peer      string
+	// Size is the size of the message on the wire.
+	Size    int
+	Message *Message
+}
+
+// Capturer writes P2P messages to the capture file, it's safe for concurrent
+// use.
+type Capturer struct {
+	lock   sync.Mutex
+	f      *os.File
+	w      *bufio.Writer
+	closed bool
+}
+
+// NewCapturer creates a new capture file (truncating the existing one) for
+// the network with the given parameters.
+func NewCapturer(path string, magic netmode.Magic, stateRootInHeader bool) (*Capturer, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	c := &Capturer{f: f, w: bufio.NewWriter(f)}
+	bw := io.NewBinWriterFromIO(c.w)
+	bw.WriteBytes(captureMagic)
+	bw.WriteU32LE(uint32(magic))
+	bw.WriteBool(stateRootInHeader)
+	if bw.Err == nil {
+		bw.Err = c.w.Flush()
+	}
+	if bw.Err != nil {
+		f.Close()
+		return nil, bw.Err
+	}
+	return c, nil
+}
+
+// Capture writes the message (in its wire format) to the file, it does
+// nothing after the capturer is closed.
+func (c *Capturer) Capture(dir CaptureDirection, peer string, msg []byte) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.closed {
+		return nil
+	}
+	bw := io.NewBinWriterFromIO(c.w)
+	bw.WriteU64LE(uint64(time.Now().UnixNano()))
+	bw.WriteB(byte(dir))
+	bw.WriteString(peer)
+	bw.WriteVarBytes(msg)
+	if bw.Err != nil {
+		return bw.Err
+	}
+	return c.w.Flush()
+}
+
+// Close closes the capture file.
+func (c *Capturer) Close() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	err := c.w.Flush()
+	if cerr := c.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// CaptureReader reads messages from the capture file.
+type CaptureReader struct {
+	r                 *io.BinReader
+	Magic             netmode.Magic
+	StateRootInHeader bool
+}
+
+// NewCaptureReader reads capture file header and returns the reader for its
+// messages.
+func NewCaptureReader(r gio.Reader) (*CaptureReader, error) {
+	br := io.NewBinReaderFromIO(bufio.NewReader(r))
+	magic := make([]byte, len(captureMagic))
+	br.ReadBytes(magic)
+	cr := &CaptureReader{
+		r:                 br,
+		Magic:             netmode.Magic(br.ReadU32LE()),
+		StateRootInHeader: br.ReadBool(),
+	}
+	if br.Err != nil {
+		return nil, fmt.Errorf("failed to read capture header: %w", br.Err)
+	}
+	if !bytes.Equal(magic, captureMagic) {
+		return nil, errors.New("not a P2P capture file")
+	}
+	return cr, nil
+}
+
+// Next returns the next message from the capture, io.EOF is returned when
+// there are no more messages.
+func (r *CaptureReader) Next() (*CapturedMessage, error) {
+	ts := r.r.ReadU64LE()
+	if errors.Is(r.r.Err, gio.EOF) {
+		return nil, gio.EOF
+	}
+	res := &CapturedMessage{
+		Time:      time.Unix(0, int64(ts)),
+		Direction: CaptureDirection(r.r.ReadB()),
+		Peer:      r.r.ReadString(),
+	}
+	raw := r.r.ReadVarBytes(maxCapturedSize)
+	if r.r.Err != nil {
+		return nil, fmt.Errorf("failed to read capture record: %w", r.r.Err)
+	}
+	res.Size = len(raw)
+	res.Message = &Message{StateRootInHeader: r.StateRootInHeader}
+	err := res.Message.Decode(io.NewBinReaderFromBuf(raw))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s message from %s: %w", res.Direction, res.Peer, err)
+	}
+	return res, nil
+}
+
+// wireBytes returns the message in the form it was received in.
+func (m *Message) wireBytes() []byte {
+	w := io.NewBufBinWriter()
+	w.WriteB(byte(m.Flags))
+	w.WriteB(byte(m.Command))
+	w.WriteVarBytes(m.compressedPayload)
+	return w.Bytes()
+}
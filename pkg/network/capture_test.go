@@ -0,0 +1,150 @@
+package network
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/config/netmode"
+	"github.com/nspcc-dev/neo-go/pkg/network/payload"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCapture(t *testing.T, s *Server, msgs ...*Message) string {
+	path := filepath.Join(t.TempDir(), "p2p.cap")
+	c, err := NewCapturer(path, s.config.Magic, s.config.StateRootInHeader)
+	require.NoError(t, err)
+	for _, m := range msgs {
+		b, err := m.Bytes()
+		require.NoError(t, err)
+		require.NoError(t, c.Capture(CaptureIn, "10.0.0.1:20333", b))
+	}
+	require.NoError(t, c.Close())
+	return path
+}
+
+func TestCapture(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "p2p.cap")
+	c, err := NewCapturer(path, netmode.UnitTestNet, true)
+	require.NoError(t, err)
+
+	ping, err := NewMessage(CMDPing, payload.NewPing(42, 7)).Bytes()
+	require.NoError(t, err)
+	getAddr, err := NewMessage(CMDGetAddr, payload.NewNullPayload()).Bytes()
+	require.NoError(t, err)
+	// Big enough to be compressed.
+	addrs := payload.NewAddressList(200)
+	for i := range addrs.Addrs {
+		addrs.Addrs[i] = payload.NewAddressAndTime(&net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 20333}, time.Now(), nil)
+	}
+	addrMsg := NewMessage(CMDAddr, addrs)
+	addr, err := addrMsg.Bytes()
+	require.NoError(t, err)
+	require.Equal(t, Compressed, addrMsg.Flags)
+
+	start := time.Now()
+	require.NoError(t, c.Capture(CaptureIn, "10.0.0.1:20333", ping))
+	require.NoError(t, c.Capture(CaptureOut, "10.0.0.2:20333", getAddr))
+	require.NoError(t, c.Capture(CaptureIn, "10.0.0.1:20333", addr))
+	require.NoError(t, c.Close())
+	require.NoError(t, c.Close())
+	require.NoError(t, c.Capture(CaptureIn, "10.0.0.1:20333", ping)) // Ignored.
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	r, err := NewCaptureReader(f)
+	require.NoError(t, err)
+	require.Equal(t, netmode.UnitTestNet, r.Magic)
+	require.True(t, r.StateRootInHeader)
+
+	m, err := r.Next()
+	require.NoError(t, err)
+	require.Equal(t, CaptureIn, m.Direction)
+	require.Equal(t, "10.0.0.1:20333", m.Peer)
+	require.Equal(t, len(ping), m.Size)
+	require.False(t, m.Time.Before(start))
+	require.Equal(t, CMDPing, m.Message.Command)
+	require.Equal(t, uint32(42), m.Message.Payload.(*payload.Ping).LastBlockIndex)
+
+	m, err = r.Next()
+	require.NoError(t, err)
+	require.Equal(t, CaptureOut, m.Direction)
+	require.Equal(t, "10.0.0.2:20333", m.Peer)
+	require.Equal(t, CMDGetAddr, m.Message.Command)
+
+	m, err = r.Next()
+	require.NoError(t, err)
+	require.Equal(t, CMDAddr, m.Message.Command)
+	require.Equal(t, len(addrs.Addrs), len(m.Message.Payload.(*payload.AddressList).Addrs))
+	require.Equal(t, addr, m.Message.wireBytes())
+
+	_, err = r.Next()
+	require.True(t, errors.Is(err, io.EOF))
+
+	t.Run("bad header", func(t *testing.T) {
+		_, err := NewCaptureReader(bytes.NewReader([]byte("NGP2P")))
+		require.Error(t, err)
+		_, err = NewCaptureReader(bytes.NewReader([]byte("NOTACAPTURE\x00\x00\x00")))
+		require.Error(t, err)
+	})
+	t.Run("truncated", func(t *testing.T) {
+		raw, err := os.ReadFile(path)
+		require.NoError(t, err)
+		r, err := NewCaptureReader(bytes.NewReader(raw[:len(raw)-1]))
+		require.NoError(t, err)
+		for err == nil {
+			_, err = r.Next()
+		}
+		require.False(t, errors.Is(err, io.EOF))
+	})
+}
+
+func TestCaptureTCPPeer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "p2p.cap")
+	s := newTestServer(t, ServerConfig{CaptureFile: path})
+	require.NotNil(t, s.capturer)
+
+	server, client := net.Pipe()
+	p := NewTCPPeer(server, s)
+	go connReadStub(client)
+	require.NoError(t, p.SendVersion())
+	require.NoError(t, s.capturer.Close())
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	r, err := NewCaptureReader(f)
+	require.NoError(t, err)
+	m, err := r.Next()
+	require.NoError(t, err)
+	require.Equal(t, CaptureOut, m.Direction)
+	require.Equal(t, server.RemoteAddr().String(), m.Peer)
+	require.Equal(t, CMDVersion, m.Message.Command)
+	_, err = r.Next()
+	require.True(t, errors.Is(err, io.EOF))
+}
+
+func TestCaptureReplay(t *testing.T) {
+	s := newTestServer(t, ServerConfig{})
+	path := newTestCapture(t, s,
+		NewMessage(CMDVersion, payload.NewVersion(s.config.Magic, 1, "/test/", nil)),
+		NewMessage(CMDVerack, payload.NewNullPayload()),
+		NewMessage(CMDPing, payload.NewPing(123, 1)),
+	)
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	peers := replayCapture(t, s, f)
+	require.Equal(t, 1, len(peers))
+	p := peers["10.0.0.1:20333"]
+	require.NotNil(t, p)
+	require.Equal(t, "/test/", string(p.Version().UserAgent))
+	require.Equal(t, uint32(123), p.LastBlockIndex())
+}
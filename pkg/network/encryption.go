@@ -0,0 +1,272 @@
+package network
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	gio "io"
+	"math/big"
+	"net"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/config"
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neo-go/pkg/wallet"
+)
+
+const (
+	// tlsHandshakeRecord is the first byte of any TLS handshake, it can't be
+	// the first byte of a plaintext P2P message (which is message flags).
+	tlsHandshakeRecord = 0x16
+
+	// connSetupTimeout limits the time spent on accepted connection
+	// protocol detection and TLS handshake.
+	connSetupTimeout = 10 * time.Second
+
+	// plainRetryInterval is the time after which peers not supporting
+	// encryption are tried with TLS again, they could've been restarted
+	// with encryption enabled or the failure could be a spurious one.
+	plainRetryInterval = time.Hour
+)
+
+var (
+	errPlaintextRejected = errors.New("plaintext connection rejected in strict mode")
+	errTLSNotSupported   = errors.New("encrypted connections are not enabled")
+	errKeyNotAllowed     = errors.New("peer key is not allowed")
+)
+
+// encryption wraps P2P connections into TLS 1.3 with mutual authentication
+// by node keys. Each node uses a self-signed certificate made from its key, so
+// peers are identified by certificate public keys rather than by any CA.
+type encryption struct {
+	strict  bool
+	allowed map[string]bool
+	server  *tls.Config
+	client  *tls.Config
+
+	// plain contains addresses known to not support encryption with the
+	// time of the last TLS failure, they're dialed without TLS (in
+	// non-strict mode only) for plainRetryInterval.
+	plainLock sync.Mutex
+	plain     map[string]time.Time
+}
+
+// newEncryption creates encryption setup from the configuration, it returns
+// nil if encryption is disabled.
+func newEncryption(cfg config.P2PEncryption) (*encryption, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	allowed := make(keys.PublicKeys, 0, len(cfg.AllowedKeys))
+	for _, s := range cfg.AllowedKeys {
+		pub, err := keys.NewPublicKeyFromString(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed key %s: %w", s, err)
+		}
+		allowed = append(allowed, pub)
+	}
+	if cfg.Strict && len(allowed) == 0 {
+		return nil, errors.New("strict mode requires AllowedKeys")
+	}
+	w, err := wallet.NewWalletFromFile(cfg.UnlockWallet.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer w.Close()
+
+	var key *keys.PrivateKey
+	for _, acc := range w.Accounts {
+		if err := acc.Decrypt(cfg.UnlockWallet.Password, w.Scrypt); err == nil {
+			key = acc.PrivateKey()
+			break
+		}
+	}
+	if key == nil {
+		return nil, errors.New("no wallet account could be unlocked")
+	}
+	return newEncryptionFromKey(key, cfg.Strict, allowed)
+}
+
+func newEncryptionFromKey(key *keys.PrivateKey, strict bool, allowed keys.PublicKeys) (*encryption, error) {
+	cert, err := selfSignedCert(key)
+	if err != nil {
+		return nil, fmt.Errorf("can't create certificate: %w", err)
+	}
+	e := &encryption{
+		strict:  strict,
+		allowed: make(map[string]bool, len(allowed)),
+		plain:   make(map[string]time.Time),
+	}
+	for _, pub := range allowed {
+		e.allowed[string(pub.Bytes())] = true
+	}
+	e.server = &tls.Config{
+		MinVersion:            tls.VersionTLS13,
+		Certificates:          []tls.Certificate{cert},
+		ClientAuth:            tls.RequireAnyClientCert,
+		VerifyPeerCertificate: e.verify,
+	}
+	e.client = &tls.Config{
+		MinVersion:   tls.VersionTLS13,
+		Certificates: []tls.Certificate{cert},
+		// Certificates are self-signed, they're checked by verify.
+		InsecureSkipVerify:    true, //nolint:gosec
+		VerifyPeerCertificate: e.verify,
+	}
+	return e, nil
+}
+
+// selfSignedCert creates a TLS certificate for the key.
+func selfSignedCert(key *keys.PrivateKey) (tls.Certificate, error) {
+	now := time.Now()
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: hex.EncodeToString(key.PublicKey().Bytes())},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.AddDate(10, 0, 0),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PrivateKey.PublicKey, &key.PrivateKey)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.Certificate{
+		Certificate: [][]byte{der},
+		PrivateKey:  &key.PrivateKey,
+	}, nil
+}
+
+// peerKey returns the public key of the peer certificate. TLS handshake
+// proves that the peer owns the corresponding private key.
+func peerKey(rawCerts [][]byte) (*keys.PublicKey, error) {
+	if len(rawCerts) == 0 {
+		return nil, errors.New("no peer certificate")
+	}
+	cert, err := x509.ParseCertificate(rawCerts[0])
+	if err != nil {
+		return nil, err
+	}
+	pub, ok := cert.PublicKey.(*ecdsa.PublicKey)
+	if !ok || pub.Curve != elliptic.P256() {
+		return nil, errors.New("peer certificate key is not a secp256r1 key")
+	}
+	return (*keys.PublicKey)(pub), nil
+}
+
+// verify checks the peer certificate, its key must be allowed if the list of
+// allowed keys is not empty.
+func (e *encryption) verify(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+	pub, err := peerKey(rawCerts)
+	if err != nil {
+		return err
+	}
+	if len(e.allowed) != 0 && !e.allowed[string(pub.Bytes())] {
+		return fmt.Errorf("%w: %s", errKeyNotAllowed, hex.EncodeToString(pub.Bytes()))
+	}
+	return nil
+}
+
+// isPlain checks whether the address should be dialed without TLS, expired
+// marks are removed.
+func (e *encryption) isPlain(addr string) bool {
+	e.plainLock.Lock()
+	defer e.plainLock.Unlock()
+	t, ok := e.plain[addr]
+	if ok && time.Since(t) >= plainRetryInterval {
+		delete(e.plain, addr)
+		return false
+	}
+	return ok
+}
+
+// dial connects to the address trying TLS first. Peers not supporting
+// encryption are connected to in plaintext unless strict mode is enabled,
+// TLS is retried for them every plainRetryInterval.
+func (e *encryption) dial(addr string, timeout time.Duration) (net.Conn, error) {
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return nil, err
+	}
+	if !e.strict && e.isPlain(addr) {
+		return conn, nil
+	}
+	if timeout <= 0 {
+		timeout = connSetupTimeout
+	}
+	tc := tls.Client(conn, e.client)
+	_ = tc.SetDeadline(time.Now().Add(timeout))
+	err = tc.Handshake()
+	if err == nil {
+		_ = tc.SetDeadline(time.Time{})
+		return tc, nil
+	}
+	conn.Close()
+	if e.strict || !isNotTLS(err) {
+		return nil, err
+	}
+	e.plainLock.Lock()
+	e.plain[addr] = time.Now()
+	e.plainLock.Unlock()
+	return net.DialTimeout("tcp", addr, timeout)
+}
+
+// isNotTLS checks whether the handshake error means that the other side
+// doesn't speak TLS: it either sends plaintext P2P messages or just drops
+// the connection.
+func isNotTLS(err error) bool {
+	var rhe tls.RecordHeaderError
+	return errors.As(err, &rhe) ||
+		errors.Is(err, gio.EOF) ||
+		errors.Is(err, syscall.ECONNRESET)
+}
+
+// peekedConn is a connection with some data already read into the buffer.
+type peekedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+// Read implements net.Conn interface.
+func (c *peekedConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
+
+// acceptConn detects whether the incoming connection is a TLS or a plaintext
+// one and sets it up accordingly. The encryption can be nil, then TLS
+// connections are dropped (so that dialing side can reconnect in plaintext).
+func acceptConn(conn net.Conn, e *encryption) (net.Conn, error) {
+	_ = conn.SetReadDeadline(time.Now().Add(connSetupTimeout))
+	r := bufio.NewReader(conn)
+	b, err := r.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+	_ = conn.SetReadDeadline(time.Time{})
+	pc := &peekedConn{Conn: conn, r: r}
+	if b[0] != tlsHandshakeRecord {
+		if e != nil && e.strict {
+			return nil, errPlaintextRejected
+		}
+		return pc, nil
+	}
+	if e == nil {
+		return nil, errTLSNotSupported
+	}
+	tc := tls.Server(pc, e.server)
+	_ = tc.SetDeadline(time.Now().Add(connSetupTimeout))
+	if err := tc.Handshake(); err != nil {
+		return nil, err
+	}
+	_ = tc.SetDeadline(time.Time{})
+	return tc, nil
+}
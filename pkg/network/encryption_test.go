@@ -0,0 +1,238 @@
+package network
+
+import (
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/config"
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestEncryption(t *testing.T, strict bool, allowed ...*keys.PublicKey) (*encryption, *keys.PrivateKey) {
+	key, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	e, err := newEncryptionFromKey(key, strict, allowed)
+	require.NoError(t, err)
+	return e, key
+}
+
+type acceptResult struct {
+	conn net.Conn
+	err  error
+}
+
+// listenTest accepts a single connection with the given encryption settings
+// and echoes the first byte back.
+func listenTest(t *testing.T, e *encryption) (string, <-chan acceptResult) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+
+	ch := make(chan acceptResult, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			ch <- acceptResult{err: err}
+			return
+		}
+		c, err := acceptConn(conn, e)
+		if err != nil {
+			conn.Close()
+			ch <- acceptResult{err: err}
+			return
+		}
+		b := make([]byte, 1)
+		if _, err = c.Read(b); err == nil {
+			_, err = c.Write(b)
+		}
+		ch <- acceptResult{conn: c, err: err}
+	}()
+	return l.Addr().String(), ch
+}
+
+func checkEcho(t *testing.T, c net.Conn, ch <-chan acceptResult) net.Conn {
+	_, err := c.Write([]byte{0x01})
+	require.NoError(t, err)
+	b := make([]byte, 1)
+	_, err = c.Read(b)
+	require.NoError(t, err)
+	require.Equal(t, byte(0x01), b[0])
+	res := <-ch
+	require.NoError(t, res.err)
+	t.Cleanup(func() { res.conn.Close() })
+	return res.conn
+}
+
+func TestNewEncryption(t *testing.T) {
+	e, err := newEncryption(config.P2PEncryption{})
+	require.NoError(t, err)
+	require.Nil(t, e)
+
+	cfg := config.P2PEncryption{
+		Enabled: true,
+		UnlockWallet: config.Wallet{
+			Path:     "../../.docker/wallets/wallet1.json",
+			Password: "one",
+		},
+	}
+	e, err = newEncryption(cfg)
+	require.NoError(t, err)
+	require.NotNil(t, e)
+	require.False(t, e.strict)
+
+	cfg.Strict = true
+	_, err = newEncryption(cfg)
+	require.Error(t, err)
+
+	cfg.AllowedKeys = []string{"bad"}
+	_, err = newEncryption(cfg)
+	require.Error(t, err)
+
+	key, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	cfg.AllowedKeys = []string{hex.EncodeToString(key.PublicKey().Bytes())}
+	e, err = newEncryption(cfg)
+	require.NoError(t, err)
+	require.True(t, e.strict)
+	require.Equal(t, 1, len(e.allowed))
+
+	cfg.UnlockWallet.Password = "wrong"
+	_, err = newEncryption(cfg)
+	require.Error(t, err)
+}
+
+func TestEncryptedConnection(t *testing.T) {
+	t.Run("encrypted", func(t *testing.T) {
+		srv, srvKey := newTestEncryption(t, false)
+		cl, clKey := newTestEncryption(t, false)
+		addr, ch := listenTest(t, srv)
+
+		c, err := cl.dial(addr, 0)
+		require.NoError(t, err)
+		defer c.Close()
+		tc, ok := c.(*tls.Conn)
+		require.True(t, ok)
+		_, err = peerKey(nil)
+		require.Error(t, err)
+		pub, err := peerKey([][]byte{tc.ConnectionState().PeerCertificates[0].Raw})
+		require.NoError(t, err)
+		require.True(t, srvKey.PublicKey().Equal(pub))
+
+		sc := checkEcho(t, c, ch)
+		stc, ok := sc.(*tls.Conn)
+		require.True(t, ok)
+		pub, err = peerKey([][]byte{stc.ConnectionState().PeerCertificates[0].Raw})
+		require.NoError(t, err)
+		require.True(t, clKey.PublicKey().Equal(pub))
+	})
+	t.Run("strict, allowed", func(t *testing.T) {
+		_, clKey := newTestEncryption(t, false)
+		srv, srvKey := newTestEncryption(t, true, clKey.PublicKey())
+		cl, err := newEncryptionFromKey(clKey, true, keys.PublicKeys{srvKey.PublicKey()})
+		require.NoError(t, err)
+		addr, ch := listenTest(t, srv)
+
+		c, err := cl.dial(addr, 0)
+		require.NoError(t, err)
+		defer c.Close()
+		checkEcho(t, c, ch)
+	})
+	t.Run("strict, client not allowed", func(t *testing.T) {
+		_, otherKey := newTestEncryption(t, false)
+		srv, _ := newTestEncryption(t, true, otherKey.PublicKey())
+		cl, _ := newTestEncryption(t, false)
+		addr, ch := listenTest(t, srv)
+
+		c, err := cl.dial(addr, 0)
+		if err == nil {
+			// TLS 1.3 client may finish its handshake before the server
+			// checks its certificate, the connection is broken anyway.
+			defer c.Close()
+			_, _ = c.Write([]byte{0x01})
+			_, err = c.Read(make([]byte, 1))
+			require.Error(t, err)
+		}
+		res := <-ch
+		require.True(t, errors.Is(res.err, errKeyNotAllowed), res.err)
+	})
+	t.Run("strict, server not allowed", func(t *testing.T) {
+		_, otherKey := newTestEncryption(t, false)
+		srv, _ := newTestEncryption(t, false)
+		cl, _ := newTestEncryption(t, true, otherKey.PublicKey())
+		addr, ch := listenTest(t, srv)
+
+		_, err := cl.dial(addr, 0)
+		require.True(t, errors.Is(err, errKeyNotAllowed), err)
+		require.Error(t, (<-ch).err)
+	})
+	t.Run("non-strict, client not allowed", func(t *testing.T) {
+		_, otherKey := newTestEncryption(t, false)
+		srv, _ := newTestEncryption(t, false, otherKey.PublicKey())
+		cl, _ := newTestEncryption(t, false)
+		addr, ch := listenTest(t, srv)
+
+		c, err := cl.dial(addr, 0)
+		if err == nil {
+			defer c.Close()
+			_, _ = c.Write([]byte{0x01})
+			_, err = c.Read(make([]byte, 1))
+			require.Error(t, err)
+		}
+		res := <-ch
+		require.True(t, errors.Is(res.err, errKeyNotAllowed), res.err)
+	})
+	t.Run("strict rejects plaintext", func(t *testing.T) {
+		_, otherKey := newTestEncryption(t, false)
+		srv, _ := newTestEncryption(t, true, otherKey.PublicKey())
+		addr, ch := listenTest(t, srv)
+
+		c, err := net.Dial("tcp", addr)
+		require.NoError(t, err)
+		defer c.Close()
+		_, err = c.Write([]byte{0x01})
+		require.NoError(t, err)
+		require.True(t, errors.Is((<-ch).err, errPlaintextRejected))
+	})
+	t.Run("plaintext server", func(t *testing.T) {
+		cl, _ := newTestEncryption(t, false)
+		addr, ch := listenTest(t, nil)
+
+		c, err := cl.dial(addr, 0)
+		require.NoError(t, err)
+		defer c.Close()
+		_, ok := c.(*tls.Conn)
+		require.False(t, ok)
+		require.True(t, errors.Is((<-ch).err, errTLSNotSupported))
+		require.True(t, cl.isPlain(addr))
+
+		cl.plain[addr] = time.Now().Add(-plainRetryInterval)
+		require.False(t, cl.isPlain(addr))
+		require.Equal(t, 0, len(cl.plain))
+	})
+	t.Run("plaintext server, strict", func(t *testing.T) {
+		_, otherKey := newTestEncryption(t, false)
+		cl, _ := newTestEncryption(t, true, otherKey.PublicKey())
+		addr, ch := listenTest(t, nil)
+
+		_, err := cl.dial(addr, 0)
+		require.Error(t, err)
+		require.True(t, errors.Is((<-ch).err, errTLSNotSupported))
+		require.False(t, cl.isPlain(addr))
+	})
+	t.Run("plaintext client", func(t *testing.T) {
+		srv, _ := newTestEncryption(t, false)
+		addr, ch := listenTest(t, srv)
+
+		c, err := net.Dial("tcp", addr)
+		require.NoError(t, err)
+		defer c.Close()
+		sc := checkEcho(t, c, ch)
+		_, ok := sc.(*tls.Conn)
+		require.False(t, ok)
+	})
+}
@@ -0,0 +1,66 @@
+package network
+
+import (
+	"sync"
+
+	"github.com/nspcc-dev/neo-go/pkg/core/block"
+)
+
+// headerBatch is a batch of headers received from some peer.
+type headerBatch struct {
+	peer Peer
+	hdrs []*block.Header
+}
+
+// headerBuffer keeps header batches received out of order (headers are
+// requested from several peers in parallel) until the chain reaches them.
+type headerBuffer struct {
+	lock    sync.Mutex
+	batches map[uint32]headerBatch
+}
+
+func newHeaderBuffer() *headerBuffer {
+	return &headerBuffer{batches: make(map[uint32]headerBatch)}
+}
+
+// put stores the batch, batches are identified by their first index, so the
+// new one replaces the old one for the same index.
+func (b *headerBuffer) put(p Peer, hdrs []*block.Header) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.batches[hdrs[0].Index] = headerBatch{peer: p, hdrs: hdrs}
+}
+
+// next returns the batch that can be added to the chain of the given header
+// height (if any), batches that are already below the height are dropped.
+func (b *headerBuffer) next(height uint32) (Peer, []*block.Header) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	var res *headerBatch
+	for i, batch := range b.batches {
+		if batch.hdrs[len(batch.hdrs)-1].Index <= height {
+			delete(b.batches, i)
+			continue
+		}
+		if i <= height+1 && res == nil {
+			batch := batch
+			res = &batch
+			delete(b.batches, i)
+		}
+	}
+	if res == nil {
+		return nil, nil
+	}
+	return res.peer, res.hdrs
+}
+
+// dropPeer removes all batches received from the peer.
+func (b *headerBuffer) dropPeer(p Peer) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	for i, batch := range b.batches {
+		if batch.peer == p {
+			delete(b.batches, i)
+		}
+	}
+}
@@ -1,7 +1,9 @@
 package network
 
 import (
+	"errors"
 	"fmt"
+	gio "io"
 	"net"
 	"sync"
 	"sync/atomic"
@@ -12,6 +14,7 @@ import (
 	"github.com/nspcc-dev/neo-go/pkg/config"
 	"github.com/nspcc-dev/neo-go/pkg/consensus"
 	"github.com/nspcc-dev/neo-go/pkg/io"
+	"github.com/nspcc-dev/neo-go/pkg/network/bloom"
 	"github.com/nspcc-dev/neo-go/pkg/network/capability"
 	"github.com/nspcc-dev/neo-go/pkg/network/payload"
 	"github.com/stretchr/testify/require"
@@ -78,6 +81,7 @@ type localPeer struct {
 	pingSent       int
 	getAddrSent    int
 	droppedWith    atomic.Value
+	filter         *bloom.Filter
 }
 
 func newLocalPeer(t *testing.T, s *Server) *localPeer {
@@ -187,6 +191,12 @@ func (p *localPeer) CanProcessAddr() bool {
 	p.getAddrSent--
 	return p.getAddrSent >= 0
 }
+func (p *localPeer) SetFilter(f *bloom.Filter) {
+	p.filter = f
+}
+func (p *localPeer) Filter() *bloom.Filter {
+	return p.filter
+}
 
 func newTestServer(t *testing.T, serverConfig ServerConfig) *Server {
 	return newTestServerWithCustomCfg(t, serverConfig, nil)
@@ -203,3 +213,41 @@ func newTestServerWithCustomCfg(t *testing.T, serverConfig ServerConfig, protoco
 	t.Cleanup(s.discovery.Close)
 	return s
 }
+
+// replayCapture feeds inbound messages from the capture into the server as if
+// they were received from handshaked local peers (one per captured peer
+// address). Handshake messages only update peer state. It returns replayed
+// peers by address.
+func replayCapture(t *testing.T, s *Server, r gio.Reader) map[string]*localPeer {
+	cr, err := NewCaptureReader(r)
+	require.NoError(t, err)
+	require.Equal(t, s.config.Magic, cr.Magic)
+
+	peers := make(map[string]*localPeer)
+	for {
+		m, err := cr.Next()
+		if errors.Is(err, gio.EOF) {
+			return peers
+		}
+		require.NoError(t, err)
+		if m.Direction != CaptureIn {
+			continue
+		}
+		p, ok := peers[m.Peer]
+		if !ok {
+			p = newLocalPeer(t, s)
+			p.handshaked = true
+			addr, err := net.ResolveTCPAddr("tcp", m.Peer)
+			require.NoError(t, err)
+			p.netaddr = *addr
+			peers[m.Peer] = p
+		}
+		switch m.Message.Command {
+		case CMDVersion:
+			require.NoError(t, p.HandleVersion(m.Message.Payload.(*payload.Version)))
+		case CMDVerack:
+		default:
+			require.NoError(t, s.handleMessage(p, m.Message), "replaying %s from %s", m.Message.Command, m.Peer)
+		}
+	}
+}
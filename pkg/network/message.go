@@ -167,6 +167,10 @@ func (m *Message) decodePayload() error {
 		return nil
 	case CMDMerkleBlock:
 		p = &payload.MerkleBlock{}
+	case CMDFilterLoad:
+		p = &payload.FilterLoad{}
+	case CMDFilterAdd:
+		p = &payload.FilterAdd{}
 	case CMDPing, CMDPong:
 		p = &payload.Ping{}
 	case CMDNotFound:
@@ -241,14 +241,38 @@ func TestEncodeDecodeMerkleBlock(t *testing.T) {
 			Flags:   []byte{0},
 		})
 	})
-	t.Run("bad, invalid TxCount", func(t *testing.T) {
-		testEncodeDecodeFail(t, CMDMerkleBlock, &payload.MerkleBlock{
+	t.Run("good, partial tree", func(t *testing.T) {
+		testEncodeDecode(t, CMDMerkleBlock, &payload.MerkleBlock{
 			Header:  base,
 			TxCount: 2,
 			Hashes:  []util.Uint256{random.Uint256()},
 			Flags:   []byte{0},
 		})
 	})
+	t.Run("bad, too many hashes", func(t *testing.T) {
+		testEncodeDecodeFail(t, CMDMerkleBlock, &payload.MerkleBlock{
+			Header:  base,
+			TxCount: 3,
+			Hashes:  []util.Uint256{random.Uint256(), random.Uint256(), random.Uint256(), random.Uint256(), random.Uint256()},
+			Flags:   []byte{0},
+		})
+	})
+}
+
+func TestEncodeDecodeFilterLoad(t *testing.T) {
+	testEncodeDecode(t, CMDFilterLoad, &payload.FilterLoad{
+		Filter: random.Bytes(16),
+		K:      3,
+		Tweak:  42,
+	})
+}
+
+func TestEncodeDecodeFilterAdd(t *testing.T) {
+	testEncodeDecode(t, CMDFilterAdd, &payload.FilterAdd{Data: random.Bytes(20)})
+}
+
+func TestEncodeDecodeFilterClear(t *testing.T) {
+	testEncodeDecode(t, CMDFilterClear, payload.NewNullPayload())
 }
 
 func TestEncodeDecodeNotFound(t *testing.T) {
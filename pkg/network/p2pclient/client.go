@@ -0,0 +1,436 @@
+/*
+Package p2pclient implements a lightweight P2P protocol client. It can be used
+by tools that need to fetch data from the network (or watch for it) without
+running a full node with its own chain.
+*/
+package p2pclient
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/config"
+	"github.com/nspcc-dev/neo-go/pkg/config/netmode"
+	"github.com/nspcc-dev/neo-go/pkg/core/block"
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/nspcc-dev/neo-go/pkg/io"
+	"github.com/nspcc-dev/neo-go/pkg/network"
+	"github.com/nspcc-dev/neo-go/pkg/network/capability"
+	"github.com/nspcc-dev/neo-go/pkg/network/payload"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+)
+
+const (
+	defaultDialTimeout    = 4 * time.Second
+	defaultRequestTimeout = 10 * time.Second
+)
+
+var (
+	// ErrClosed is returned for requests made after the connection is closed.
+	ErrClosed = errors.New("connection closed")
+	// ErrTimeout is returned when the node doesn't answer in time.
+	ErrTimeout = errors.New("request timeout")
+)
+
+// Options defines options for the P2P client.
+type Options struct {
+	// Magic is the network magic, it's mandatory.
+	Magic netmode.Magic
+	// StateRootInHeader must match the network setting for blocks and
+	// headers to be decoded properly.
+	StateRootInHeader bool
+	// UserAgent is sent to the node in the version message, NeoGo user
+	// agent is used by default.
+	UserAgent string
+	// DialTimeout is the connection timeout, 4 seconds by default.
+	DialTimeout time.Duration
+	// RequestTimeout limits the handshake and every request, 10 seconds by
+	// default.
+	RequestTimeout time.Duration
+}
+
+// Client is a P2P protocol client. It connects to a single node and can
+// request chain data from it without any local ledger. Client is thread-safe,
+// but requests are processed one by one.
+//
+// The P2P protocol has no request identifiers and some requests have no reply
+// if there is nothing to return, so every request is followed by a ping. The
+// node handles messages in order, so when the pong arrives all the data sent
+// in reply to the request has already arrived too.
+type Client struct {
+	conn    net.Conn
+	r       *io.BinReader
+	opts    Options
+	nonce   uint32
+	version *payload.Version
+
+	writeLock sync.Mutex
+	// reqLock serializes requests.
+	reqLock sync.Mutex
+
+	lock      sync.Mutex
+	pingsSent uint64
+	pongs     uint64
+	req       *request
+	inv       chan *payload.Inventory
+	closed    bool
+
+	quitOnce sync.Once
+	quit     chan struct{}
+	// done is closed when the connection is closed.
+	done chan struct{}
+}
+
+// request is an active request waiting for the pong.
+type request struct {
+	// seq is the number of the ping sent after the request.
+	seq    uint64
+	accept func(*network.Message) bool
+	msgs   []*network.Message
+	pong   *payload.Ping
+	done   chan struct{}
+}
+
+// Dial connects to the node at the given address and performs the handshake.
+func Dial(addr string, opts Options) (*Client, error) {
+	if opts.DialTimeout <= 0 {
+		opts.DialTimeout = defaultDialTimeout
+	}
+	conn, err := net.DialTimeout("tcp", addr, opts.DialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	c, err := New(conn, opts)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// New performs the handshake over the given connection and returns a client
+// using it.
+func New(conn net.Conn, opts Options) (*Client, error) {
+	if opts.UserAgent == "" {
+		opts.UserAgent = config.Config{}.GenerateUserAgent()
+	}
+	if opts.RequestTimeout <= 0 {
+		opts.RequestTimeout = defaultRequestTimeout
+	}
+	var buf [4]byte
+	_, _ = rand.Read(buf[:])
+	c := &Client{
+		conn:  conn,
+		r:     io.NewBinReaderFromIO(bufio.NewReader(conn)),
+		opts:  opts,
+		nonce: binary.LittleEndian.Uint32(buf[:]),
+		quit:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	err := c.handshake()
+	if err != nil {
+		return nil, fmt.Errorf("handshake failed: %w", err)
+	}
+	go c.readLoop()
+	return c, nil
+}
+
+// handshake exchanges version and verack messages with the node.
+func (c *Client) handshake() error {
+	_ = c.conn.SetDeadline(time.Now().Add(c.opts.RequestTimeout))
+	defer func() { _ = c.conn.SetDeadline(time.Time{}) }()
+
+	// Light clients have nothing to share, but the node only relays
+	// transactions to full nodes.
+	caps := []capability.Capability{{
+		Type: capability.FullNode,
+		Data: &capability.Node{StartHeight: 0},
+	}}
+	err := c.send(network.NewMessage(network.CMDVersion, payload.NewVersion(c.opts.Magic, c.nonce, c.opts.UserAgent, caps)))
+	if err != nil {
+		return err
+	}
+	for {
+		msg, err := c.receive()
+		if err != nil {
+			return err
+		}
+		switch msg.Command {
+		case network.CMDVersion:
+			if c.version != nil {
+				return errors.New("duplicate version")
+			}
+			v := msg.Payload.(*payload.Version)
+			if v.Magic != c.opts.Magic {
+				return fmt.Errorf("node network %s doesn't match %s", v.Magic, c.opts.Magic)
+			}
+			c.version = v
+			err = c.send(network.NewMessage(network.CMDVerack, payload.NewNullPayload()))
+			if err != nil {
+				return err
+			}
+		case network.CMDVerack:
+			if c.version == nil {
+				return errors.New("verack before version")
+			}
+			return nil
+		default:
+			return fmt.Errorf("unexpected %s during handshake", msg.Command)
+		}
+	}
+}
+
+func (c *Client) send(msg *network.Message) error {
+	b, err := msg.Bytes()
+	if err != nil {
+		return err
+	}
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+	_, err = c.conn.Write(b)
+	return err
+}
+
+func (c *Client) receive() (*network.Message, error) {
+	msg := &network.Message{StateRootInHeader: c.opts.StateRootInHeader}
+	err := msg.Decode(c.r)
+	if err != nil && !errors.Is(err, payload.ErrTooManyHeaders) {
+		return nil, err
+	}
+	c.r.Err = nil
+	return msg, nil
+}
+
+func (c *Client) readLoop() {
+	for {
+		msg, err := c.receive()
+		if err != nil {
+			break
+		}
+		switch msg.Command {
+		case network.CMDPing:
+			err = c.send(network.NewMessage(network.CMDPong, payload.NewPing(0, c.nonce)))
+		case network.CMDPong:
+			c.handlePong(msg.Payload.(*payload.Ping))
+		default:
+			c.handleMessage(msg)
+		}
+		if err != nil {
+			break
+		}
+	}
+	c.conn.Close()
+	c.lock.Lock()
+	c.closed = true
+	if c.inv != nil {
+		close(c.inv)
+	}
+	c.req = nil
+	c.lock.Unlock()
+	close(c.done)
+}
+
+func (c *Client) handlePong(pong *payload.Ping) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.pongs++
+	if r := c.req; r != nil && r.seq == c.pongs {
+		r.pong = pong
+		close(r.done)
+		c.req = nil
+	}
+}
+
+func (c *Client) handleMessage(msg *network.Message) {
+	c.lock.Lock()
+	// Replies to timed out requests arrive before their pongs, they're
+	// dropped.
+	if r := c.req; r != nil && r.seq == c.pongs+1 && r.accept != nil && r.accept(msg) {
+		r.msgs = append(r.msgs, msg)
+		c.lock.Unlock()
+		return
+	}
+	ch := c.inv
+	c.lock.Unlock()
+	if inv, ok := msg.Payload.(*payload.Inventory); ok && msg.Command == network.CMDInv && ch != nil {
+		select {
+		case ch <- inv:
+		case <-c.quit:
+		}
+	}
+}
+
+// request sends the message to the node and collects accepted messages until
+// the pong is received.
+func (c *Client) request(msg *network.Message, accept func(*network.Message) bool) ([]*network.Message, *payload.Ping, error) {
+	c.reqLock.Lock()
+	defer c.reqLock.Unlock()
+
+	c.lock.Lock()
+	if c.closed {
+		c.lock.Unlock()
+		return nil, nil, ErrClosed
+	}
+	c.pingsSent++
+	r := &request{
+		seq:    c.pingsSent,
+		accept: accept,
+		done:   make(chan struct{}),
+	}
+	c.req = r
+	c.lock.Unlock()
+
+	var err error
+	if msg != nil {
+		err = c.send(msg)
+	}
+	if err == nil {
+		err = c.send(network.NewMessage(network.CMDPing, payload.NewPing(0, c.nonce)))
+	}
+	if err != nil {
+		// Pings and pongs can't be matched anymore.
+		_ = c.conn.Close()
+		c.dropRequest(r)
+		return nil, nil, err
+	}
+	t := time.NewTimer(c.opts.RequestTimeout)
+	defer t.Stop()
+	select {
+	case <-r.done:
+		return r.msgs, r.pong, nil
+	case <-c.done:
+		return nil, nil, ErrClosed
+	case <-t.C:
+		c.dropRequest(r)
+		return nil, nil, ErrTimeout
+	}
+}
+
+func (c *Client) dropRequest(r *request) {
+	c.lock.Lock()
+	if c.req == r {
+		c.req = nil
+	}
+	c.lock.Unlock()
+}
+
+// Version returns the version payload received from the node.
+func (c *Client) Version() *payload.Version {
+	return c.version
+}
+
+// Ping pings the node and returns its reply containing its current height.
+func (c *Client) Ping() (*payload.Ping, error) {
+	_, pong, err := c.request(nil, nil)
+	return pong, err
+}
+
+// GetHeaders requests up to count (payload.MaxHeadersAllowed at most)
+// headers starting from the given index. Less headers (or none at all) are
+// returned if the node doesn't have them.
+func (c *Client) GetHeaders(start uint32, count int16) ([]*block.Header, error) {
+	msgs, _, err := c.request(network.NewMessage(network.CMDGetHeaders, payload.NewGetBlockByIndex(start, count)),
+		func(m *network.Message) bool { return m.Command == network.CMDHeaders })
+	if err != nil {
+		return nil, err
+	}
+	var res []*block.Header
+	for _, m := range msgs {
+		res = append(res, m.Payload.(*payload.Headers).Hdrs...)
+	}
+	return res, nil
+}
+
+// GetBlocks requests up to count (payload.MaxHashesCount at most) blocks
+// starting from the given index. Less blocks (or none at all) are returned if
+// the node doesn't have them.
+func (c *Client) GetBlocks(start uint32, count int16) ([]*block.Block, error) {
+	msgs, _, err := c.request(network.NewMessage(network.CMDGetBlockByIndex, payload.NewGetBlockByIndex(start, count)),
+		func(m *network.Message) bool { return m.Command == network.CMDBlock })
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*block.Block, 0, len(msgs))
+	for _, m := range msgs {
+		res = append(res, m.Payload.(*block.Block))
+	}
+	return res, nil
+}
+
+// GetTransactions requests transactions (from the mempool or from the chain)
+// by their hashes. Transactions not known to the node are omitted from the
+// result.
+func (c *Client) GetTransactions(hashes ...util.Uint256) ([]*transaction.Transaction, error) {
+	if len(hashes) == 0 {
+		return nil, nil
+	}
+	if len(hashes) > payload.MaxHashesCount {
+		return nil, fmt.Errorf("too many hashes: %d > %d", len(hashes), payload.MaxHashesCount)
+	}
+	requested := make(map[util.Uint256]bool, len(hashes))
+	for _, h := range hashes {
+		requested[h] = true
+	}
+	msgs, _, err := c.request(network.NewMessage(network.CMDGetData, payload.NewInventory(payload.TXType, hashes)),
+		func(m *network.Message) bool {
+			tx, ok := m.Payload.(*transaction.Transaction)
+			return ok && m.Command == network.CMDTX && requested[tx.Hash()]
+		})
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*transaction.Transaction, 0, len(msgs))
+	for _, m := range msgs {
+		res = append(res, m.Payload.(*transaction.Transaction))
+	}
+	return res, nil
+}
+
+// GetMempool returns hashes of transactions in the node's mempool.
+func (c *Client) GetMempool() ([]util.Uint256, error) {
+	msgs, _, err := c.request(network.NewMessage(network.CMDMempool, payload.NewNullPayload()),
+		func(m *network.Message) bool {
+			inv, ok := m.Payload.(*payload.Inventory)
+			return ok && m.Command == network.CMDInv && inv.Type == payload.TXType
+		})
+	if err != nil {
+		return nil, err
+	}
+	var res []util.Uint256
+	for _, m := range msgs {
+		res = append(res, m.Payload.(*payload.Inventory).Hashes...)
+	}
+	return res, nil
+}
+
+// SubscribeForInventory returns a channel receiving all inventory
+// announcements made by the node (new blocks, transactions and extensible
+// payloads). Subsequent calls return the same channel. Client's code is
+// supposed to be reading from this channel, failing to do so will block
+// requests. The channel is closed when the connection is closed.
+func (c *Client) SubscribeForInventory() <-chan *payload.Inventory {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.inv == nil {
+		c.inv = make(chan *payload.Inventory)
+		if c.closed {
+			close(c.inv)
+		}
+	}
+	return c.inv
+}
+
+// Close closes the connection to the node.
+func (c *Client) Close() {
+	c.quitOnce.Do(func() {
+		close(c.quit)
+		_ = c.conn.Close()
+	})
+	<-c.done
+}
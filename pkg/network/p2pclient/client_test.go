@@ -0,0 +1,243 @@
+package p2pclient
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/config/netmode"
+	"github.com/nspcc-dev/neo-go/pkg/core/block"
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/nspcc-dev/neo-go/pkg/io"
+	"github.com/nspcc-dev/neo-go/pkg/network"
+	"github.com/nspcc-dev/neo-go/pkg/network/payload"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/vm/opcode"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testMagic  = netmode.UnitTestNet
+	testHeight = 10
+	// slowIndex makes fake node reply to block requests late.
+	slowIndex = 1000
+)
+
+// fakeNode is a minimal P2P node serving testHeight blocks and a mempool.
+type fakeNode struct {
+	t    *testing.T
+	conn net.Conn
+	// out makes writes asynchronous like they are with real TCP buffers,
+	// nil delays subsequent writes.
+	out     chan []byte
+	mempool []*transaction.Transaction
+	// announce is sent before every pong.
+	announce *payload.Inventory
+}
+
+func newTestTx(nonce uint32) *transaction.Transaction {
+	tx := transaction.New([]byte{byte(opcode.PUSH1)}, 0)
+	tx.Nonce = nonce
+	tx.Signers = []transaction.Signer{{Account: util.Uint160{1}}}
+	tx.Scripts = []transaction.Witness{{}}
+	return tx
+}
+
+func newTestBlock(index uint32) *block.Block {
+	b := block.New(false)
+	b.Index = index
+	b.Transactions = []*transaction.Transaction{newTestTx(index)}
+	return b
+}
+
+func (n *fakeNode) send(msg *network.Message) {
+	b, err := msg.Bytes()
+	require.NoError(n.t, err)
+	n.out <- b
+}
+
+func (n *fakeNode) run() {
+	n.out = make(chan []byte, 100)
+	go func() {
+		for b := range n.out {
+			if b == nil {
+				time.Sleep(100 * time.Millisecond)
+				continue
+			}
+			_, _ = n.conn.Write(b)
+		}
+	}()
+	defer close(n.out)
+	r := io.NewBinReaderFromIO(n.conn)
+	n.send(network.NewMessage(network.CMDVersion, payload.NewVersion(testMagic, 1, "/fake/", nil)))
+	for {
+		msg := &network.Message{}
+		if msg.Decode(r) != nil {
+			return
+		}
+		switch msg.Command {
+		case network.CMDVersion:
+			n.send(network.NewMessage(network.CMDVerack, payload.NewNullPayload()))
+		case network.CMDGetHeaders:
+			req := msg.Payload.(*payload.GetBlockByIndex)
+			hdrs := &payload.Headers{}
+			for i := req.IndexStart; i < req.IndexStart+uint32(req.Count) && i <= testHeight; i++ {
+				hdrs.Hdrs = append(hdrs.Hdrs, &newTestBlock(i).Header)
+			}
+			if len(hdrs.Hdrs) != 0 {
+				n.send(network.NewMessage(network.CMDHeaders, hdrs))
+			}
+		case network.CMDGetBlockByIndex:
+			req := msg.Payload.(*payload.GetBlockByIndex)
+			if req.IndexStart == slowIndex {
+				n.out <- nil
+				n.send(network.NewMessage(network.CMDBlock, newTestBlock(slowIndex)))
+			}
+			for i := req.IndexStart; i < req.IndexStart+uint32(req.Count) && i <= testHeight; i++ {
+				n.send(network.NewMessage(network.CMDBlock, newTestBlock(i)))
+			}
+		case network.CMDGetData:
+			inv := msg.Payload.(*payload.Inventory)
+			var notFound []util.Uint256
+		hashes:
+			for _, h := range inv.Hashes {
+				for _, tx := range n.mempool {
+					if tx.Hash().Equals(h) {
+						n.send(network.NewMessage(network.CMDTX, tx))
+						continue hashes
+					}
+				}
+				notFound = append(notFound, h)
+			}
+			if len(notFound) != 0 {
+				n.send(network.NewMessage(network.CMDNotFound, payload.NewInventory(inv.Type, notFound)))
+			}
+		case network.CMDMempool:
+			hs := make([]util.Uint256, 0, len(n.mempool))
+			for _, tx := range n.mempool {
+				hs = append(hs, tx.Hash())
+			}
+			n.send(network.NewMessage(network.CMDInv, payload.NewInventory(payload.TXType, hs)))
+		case network.CMDPing:
+			if n.announce != nil {
+				n.send(network.NewMessage(network.CMDInv, n.announce))
+			}
+			n.send(network.NewMessage(network.CMDPong, payload.NewPing(testHeight, 1)))
+		}
+	}
+}
+
+func newTestClient(t *testing.T, node *fakeNode) *Client {
+	srv, cl := net.Pipe()
+	node.t = t
+	node.conn = srv
+	go node.run()
+	c, err := New(cl, Options{Magic: testMagic, RequestTimeout: 50 * time.Millisecond})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		c.Close()
+		srv.Close()
+	})
+	return c
+}
+
+func TestHandshake(t *testing.T) {
+	c := newTestClient(t, new(fakeNode))
+	require.Equal(t, "/fake/", string(c.Version().UserAgent))
+
+	t.Run("wrong magic", func(t *testing.T) {
+		srv, cl := net.Pipe()
+		node := &fakeNode{t: t, conn: srv}
+		go node.run()
+		defer srv.Close()
+		_, err := New(cl, Options{Magic: netmode.TestNet})
+		require.Error(t, err)
+	})
+	t.Run("no reply", func(t *testing.T) {
+		srv, cl := net.Pipe()
+		defer srv.Close()
+		go func() { _, _ = srv.Read(make([]byte, 1024)) }()
+		_, err := New(cl, Options{Magic: testMagic, RequestTimeout: 50 * time.Millisecond})
+		require.Error(t, err)
+	})
+}
+
+func TestRequests(t *testing.T) {
+	node := &fakeNode{mempool: []*transaction.Transaction{newTestTx(100), newTestTx(101)}}
+	c := newTestClient(t, node)
+
+	pong, err := c.Ping()
+	require.NoError(t, err)
+	require.Equal(t, uint32(testHeight), pong.LastBlockIndex)
+
+	hdrs, err := c.GetHeaders(5, 100)
+	require.NoError(t, err)
+	require.Equal(t, 6, len(hdrs))
+	require.Equal(t, uint32(5), hdrs[0].Index)
+
+	hdrs, err = c.GetHeaders(testHeight+1, 100)
+	require.NoError(t, err)
+	require.Equal(t, 0, len(hdrs))
+
+	blocks, err := c.GetBlocks(1, 3)
+	require.NoError(t, err)
+	require.Equal(t, 3, len(blocks))
+	for i, b := range blocks {
+		require.Equal(t, uint32(i+1), b.Index)
+		require.Equal(t, 1, len(b.Transactions))
+	}
+
+	hs, err := c.GetMempool()
+	require.NoError(t, err)
+	require.Equal(t, []util.Uint256{node.mempool[0].Hash(), node.mempool[1].Hash()}, hs)
+
+	txs, err := c.GetTransactions(node.mempool[1].Hash(), util.Uint256{1, 2, 3})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(txs))
+	require.Equal(t, node.mempool[1].Hash(), txs[0].Hash())
+
+	txs, err = c.GetTransactions()
+	require.NoError(t, err)
+	require.Nil(t, txs)
+	_, err = c.GetTransactions(make([]util.Uint256, payload.MaxHashesCount+1)...)
+	require.Error(t, err)
+
+	t.Run("timeout", func(t *testing.T) {
+		_, err := c.GetBlocks(slowIndex, 1)
+		require.ErrorIs(t, err, ErrTimeout)
+		// Late reply is not mixed into the next one.
+		c.opts.RequestTimeout = time.Second
+		blocks, err := c.GetBlocks(testHeight, 1)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(blocks))
+		require.Equal(t, uint32(testHeight), blocks[0].Index)
+	})
+	t.Run("closed", func(t *testing.T) {
+		c.Close()
+		c.Close()
+		_, err := c.Ping()
+		require.ErrorIs(t, err, ErrClosed)
+	})
+}
+
+func TestSubscribeForInventory(t *testing.T) {
+	ann := payload.NewInventory(payload.BlockType, []util.Uint256{{1}})
+	node := &fakeNode{announce: ann}
+	c := newTestClient(t, node)
+
+	ch := c.SubscribeForInventory()
+	require.Equal(t, ch, c.SubscribeForInventory())
+	errCh := make(chan error)
+	go func() {
+		_, err := c.Ping()
+		errCh <- err
+	}()
+	require.Equal(t, ann, <-ch)
+	require.NoError(t, <-errCh)
+
+	c.Close()
+	_, ok := <-ch
+	require.False(t, ok)
+	_, ok = <-c.SubscribeForInventory()
+	require.False(t, ok)
+}
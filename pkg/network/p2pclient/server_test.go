@@ -0,0 +1,95 @@
+package p2pclient
+
+import (
+	"math/big"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/internal/fakechain"
+	"github.com/nspcc-dev/neo-go/pkg/config"
+	"github.com/nspcc-dev/neo-go/pkg/network"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+type feerStub struct{}
+
+func (f feerStub) FeePerByte() int64                            { return 1 }
+func (f feerStub) GetUtilityTokenBalance(util.Uint160) *big.Int { return big.NewInt(100000000) }
+func (f feerStub) BlockHeight() uint32                          { return 0 }
+func (f feerStub) P2PSigExtensionsEnabled() bool                { return false }
+
+// startServer starts a real network.Server over the given chain
+// and returns its address.
+func startServer(t *testing.T, chain *fakechain.FakeChain) string {
+	s, err := network.NewServer(network.ServerConfig{
+		UserAgent:         "/test/",
+		Address:           "127.0.0.1",
+		Net:               testMagic,
+		DialTimeout:       time.Second,
+		ProtoTickInterval: time.Second,
+		PingInterval:      time.Minute,
+		PingTimeout:       time.Minute,
+		MaxPeers:          10,
+	}, chain, new(fakechain.FakeStateSync), zaptest.NewLogger(t))
+	require.NoError(t, err)
+	done := make(chan struct{})
+	go func() {
+		s.Start(make(chan error, 1))
+		close(done)
+	}()
+	t.Cleanup(func() {
+		s.Shutdown()
+		<-done
+	})
+	var port uint16
+	require.Eventually(t, func() bool {
+		port, err = s.Port()
+		return err == nil && port != 0
+	}, time.Second, 10*time.Millisecond)
+	return net.JoinHostPort("127.0.0.1", strconv.Itoa(int(port)))
+}
+
+func TestRealServer(t *testing.T) {
+	chain := fakechain.NewFakeChainWithCustomCfg(func(c *config.ProtocolConfiguration) {
+		c.SecondsPerBlock = 15 // Used for write timeouts.
+	})
+	for i := uint32(0); i <= testHeight; i++ {
+		chain.PutBlock(newTestBlock(i))
+	}
+	txs := []util.Uint256{}
+	for i := uint32(100); i < 102; i++ {
+		tx := newTestTx(i)
+		tx.NetworkFee = 1000
+		require.NoError(t, chain.Pool.Add(tx, feerStub{}))
+		txs = append(txs, tx.Hash())
+	}
+	addr := startServer(t, chain)
+
+	c, err := Dial(addr, Options{Magic: testMagic, RequestTimeout: time.Second})
+	require.NoError(t, err)
+	t.Cleanup(c.Close)
+	require.Equal(t, "/test/", string(c.Version().UserAgent))
+
+	hdrs, err := c.GetHeaders(5, 100)
+	require.NoError(t, err)
+	require.Equal(t, testHeight-4, len(hdrs))
+	for i, h := range hdrs {
+		require.Equal(t, chain.GetHeaderHash(i+5), h.Hash())
+	}
+
+	blocks, err := c.GetBlocks(1, 3)
+	require.NoError(t, err)
+	require.Equal(t, 3, len(blocks))
+	for i, b := range blocks {
+		require.Equal(t, chain.GetHeaderHash(i+1), b.Hash())
+		require.Equal(t, 1, len(b.Transactions))
+	}
+
+	hs, err := c.GetMempool()
+	require.NoError(t, err)
+	require.ElementsMatch(t, txs, hs)
+}
@@ -0,0 +1,64 @@
+package payload
+
+import (
+	"errors"
+
+	"github.com/nspcc-dev/neo-go/pkg/io"
+)
+
+const (
+	// MaxFilterSize is the maximum size of the bloom filter in bytes.
+	MaxFilterSize = 36000
+	// MaxFilterHashFuncs is the maximum number of hash functions used by
+	// the bloom filter.
+	MaxFilterHashFuncs = 50
+	// MaxFilterAddDataSize is the maximum size of the element added to
+	// the bloom filter.
+	MaxFilterAddDataSize = 520
+)
+
+// errTooManyHashFuncs is returned when filter has too many hash functions.
+var errTooManyHashFuncs = errors.New("too many hash functions")
+
+// FilterLoad payload sets the bloom filter for the connection.
+type FilterLoad struct {
+	// Filter contains filter bits.
+	Filter []byte
+	// K is the number of hash functions.
+	K byte
+	// Tweak is used to derive hash function seeds.
+	Tweak uint32
+}
+
+// DecodeBinary implements Serializable interface.
+func (f *FilterLoad) DecodeBinary(br *io.BinReader) {
+	f.Filter = br.ReadVarBytes(MaxFilterSize)
+	f.K = br.ReadB()
+	if br.Err == nil && f.K > MaxFilterHashFuncs {
+		br.Err = errTooManyHashFuncs
+		return
+	}
+	f.Tweak = br.ReadU32LE()
+}
+
+// EncodeBinary implements Serializable interface.
+func (f *FilterLoad) EncodeBinary(bw *io.BinWriter) {
+	bw.WriteVarBytes(f.Filter)
+	bw.WriteB(f.K)
+	bw.WriteU32LE(f.Tweak)
+}
+
+// FilterAdd payload adds an element to the bloom filter of the connection.
+type FilterAdd struct {
+	Data []byte
+}
+
+// DecodeBinary implements Serializable interface.
+func (f *FilterAdd) DecodeBinary(br *io.BinReader) {
+	f.Data = br.ReadVarBytes(MaxFilterAddDataSize)
+}
+
+// EncodeBinary implements Serializable interface.
+func (f *FilterAdd) EncodeBinary(bw *io.BinWriter) {
+	bw.WriteVarBytes(f.Data)
+}
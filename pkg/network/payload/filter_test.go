@@ -0,0 +1,37 @@
+package payload
+
+import (
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/internal/testserdes"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterLoad_EncodeDecodeBinary(t *testing.T) {
+	t.Run("good", func(t *testing.T) {
+		f := &FilterLoad{
+			Filter: []byte{1, 2, 3},
+			K:      MaxFilterHashFuncs,
+			Tweak:  123,
+		}
+		testserdes.EncodeDecodeBinary(t, f, new(FilterLoad))
+	})
+	t.Run("too many hash functions", func(t *testing.T) {
+		data, err := testserdes.EncodeBinary(&FilterLoad{K: MaxFilterHashFuncs + 1})
+		require.NoError(t, err)
+		require.Error(t, testserdes.DecodeBinary(data, new(FilterLoad)))
+	})
+	t.Run("big filter", func(t *testing.T) {
+		data, err := testserdes.EncodeBinary(&FilterLoad{Filter: make([]byte, MaxFilterSize+1)})
+		require.NoError(t, err)
+		require.Error(t, testserdes.DecodeBinary(data, new(FilterLoad)))
+	})
+}
+
+func TestFilterAdd_EncodeDecodeBinary(t *testing.T) {
+	testserdes.EncodeDecodeBinary(t, &FilterAdd{Data: []byte{1, 2, 3}}, new(FilterAdd))
+
+	data, err := testserdes.EncodeBinary(&FilterAdd{Data: make([]byte, MaxFilterAddDataSize+1)})
+	require.NoError(t, err)
+	require.Error(t, testserdes.DecodeBinary(data, new(FilterAdd)))
+}
@@ -1,9 +1,8 @@
 package payload
 
 import (
-	"errors"
-
 	"github.com/nspcc-dev/neo-go/pkg/core/block"
+	"github.com/nspcc-dev/neo-go/pkg/crypto/hash"
 	"github.com/nspcc-dev/neo-go/pkg/io"
 	"github.com/nspcc-dev/neo-go/pkg/util"
 )
@@ -16,6 +15,33 @@ type MerkleBlock struct {
 	Flags   []byte
 }
 
+// NewMerkleBlock creates a MerkleBlock for the given block. Flags mark
+// transactions (by their index in the block) the receiver is interested in,
+// Hashes contain a partial Merkle tree for them.
+func NewMerkleBlock(b *block.Block, flags []bool) *MerkleBlock {
+	m := &MerkleBlock{
+		Header:  &b.Header,
+		TxCount: len(b.Transactions),
+		Hashes:  []util.Uint256{},
+		Flags:   make([]byte, (len(flags)+7)/8),
+	}
+	for i := range flags {
+		if flags[i] {
+			m.Flags[i/8] |= 1 << (i % 8)
+		}
+	}
+	if len(b.Transactions) != 0 {
+		hashes := make([]util.Uint256, len(b.Transactions))
+		for i := range b.Transactions {
+			hashes[i] = b.Transactions[i].Hash()
+		}
+		tree, _ := hash.NewMerkleTree(hashes) // Can only fail for empty list.
+		tree.Trim(flags)
+		m.Hashes = tree.ToHashArray()
+	}
+	return m
+}
+
 // DecodeBinary implements Serializable interface.
 func (m *MerkleBlock) DecodeBinary(br *io.BinReader) {
 	m.Header = &block.Header{}
@@ -27,11 +53,18 @@ func (m *MerkleBlock) DecodeBinary(br *io.BinReader) {
 		return
 	}
 	m.TxCount = txCount
-	br.ReadArray(&m.Hashes, m.TxCount)
-	if txCount != len(m.Hashes) {
-		br.Err = errors.New("invalid tx count")
+	// The last hash is duplicated on every level with odd number of nodes,
+	// so a partial tree can have as many hashes as the full bottom level.
+	maxHashes := 1
+	for maxHashes < txCount {
+		maxHashes *= 2
+	}
+	br.ReadArray(&m.Hashes, maxHashes)
+	maxFlags := txCount
+	if maxFlags == 0 {
+		maxFlags = 1
 	}
-	m.Flags = br.ReadVarBytes((txCount + 7) / 8)
+	m.Flags = br.ReadVarBytes((maxFlags + 7) / 8)
 }
 
 // EncodeBinary implements Serializable interface.
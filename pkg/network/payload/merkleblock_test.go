@@ -69,3 +69,34 @@ func TestMerkleBlock_EncodeDecodeBinary(t *testing.T) {
 		require.Error(t, testserdes.DecodeBinary(data, new(MerkleBlock)))
 	})
 }
+
+func TestNewMerkleBlock(t *testing.T) {
+	b := &block.Block{Header: *newDumbBlock()}
+	_ = b.Hash()
+	t.Run("empty", func(t *testing.T) {
+		m := NewMerkleBlock(b, nil)
+		require.Equal(t, 0, m.TxCount)
+		require.Equal(t, []util.Uint256{}, m.Hashes)
+		require.Equal(t, []byte{}, m.Flags)
+		testserdes.EncodeDecodeBinary(t, m, new(MerkleBlock))
+	})
+
+	for i := 0; i < 3; i++ {
+		tx := transaction.New([]byte{byte(i)}, 0)
+		tx.Signers = []transaction.Signer{{}}
+		tx.Scripts = []transaction.Witness{{}}
+		b.Transactions = append(b.Transactions, tx)
+	}
+	h1, h2, h3 := b.Transactions[0].Hash(), b.Transactions[1].Hash(), b.Transactions[2].Hash()
+	h33 := hash.DoubleSha256(append(h3.BytesBE(), h3.BytesBE()...))
+
+	m := NewMerkleBlock(b, []bool{false, true, false})
+	require.Equal(t, 3, m.TxCount)
+	require.Equal(t, []util.Uint256{h1, h2, h33}, m.Hashes)
+	require.Equal(t, []byte{0x02}, m.Flags)
+	testserdes.EncodeDecodeBinary(t, m, new(MerkleBlock))
+
+	m = NewMerkleBlock(b, []bool{false, false, false})
+	require.Equal(t, []util.Uint256{hash.CalcMerkleRoot([]util.Uint256{h1, h2, h3})}, m.Hashes)
+	require.Equal(t, []byte{0}, m.Flags)
+}
@@ -3,6 +3,7 @@ package network
 import (
 	"net"
 
+	"github.com/nspcc-dev/neo-go/pkg/network/bloom"
 	"github.com/nspcc-dev/neo-go/pkg/network/payload"
 )
 
@@ -81,4 +82,11 @@ type Peer interface {
 	// CanProcessAddr checks whether an addr command is expected to come from
 	// this peer and can be processed.
 	CanProcessAddr() bool
+
+	// SetFilter sets bloom filter for the peer, nil removes it. Blocks and
+	// transaction inventories sent to the peer are filtered with it.
+	SetFilter(*bloom.Filter)
+
+	// Filter returns current bloom filter of the peer, nil if there is none.
+	Filter() *bloom.Filter
 }
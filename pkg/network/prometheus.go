@@ -38,6 +38,32 @@ var (
 			Namespace: "neogo",
 		},
 	)
+
+	peerBlockThroughput = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Help:      "Blocks per second received from the peer for the last requested range",
+			Name:      "peer_block_throughput",
+			Namespace: "neogo",
+		},
+		[]string{"peer"},
+	)
+
+	blockRangeRetries = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Help:      "Number of block ranges requested again because of slow or failed peers",
+			Name:      "block_range_retries",
+			Namespace: "neogo",
+		},
+	)
+
+	droppedMessages = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Help:      "Number of P2P messages dropped because of rate or size limits",
+			Name:      "p2p_dropped_messages",
+			Namespace: "neogo",
+		},
+		[]string{"command", "reason"},
+	)
 )
 
 func init() {
@@ -46,6 +72,9 @@ func init() {
 		servAndNodeVersion,
 		poolCount,
 		blockQueueLength,
+		peerBlockThroughput,
+		blockRangeRetries,
+		droppedMessages,
 	)
 }
 
@@ -53,6 +82,14 @@ func updateBlockQueueLenMetric(bqLen int) {
 	blockQueueLength.Set(float64(bqLen))
 }
 
+func updatePeerBlockThroughputMetric(p Peer, rate float64) {
+	peerBlockThroughput.WithLabelValues(p.RemoteAddr().String()).Set(rate)
+}
+
+func removePeerBlockThroughputMetric(p Peer) {
+	peerBlockThroughput.DeleteLabelValues(p.RemoteAddr().String())
+}
+
 func updatePoolCountMetric(pCount int) {
 	poolCount.Set(float64(pCount))
 }
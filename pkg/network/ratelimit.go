@@ -0,0 +1,171 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/config"
+	"github.com/nspcc-dev/neo-go/pkg/network/payload"
+)
+
+// Default rate limiting parameters.
+const (
+	defaultLimitRate       = 5000
+	defaultLimitBurst      = 20000
+	defaultLimitMaxDropped = 50
+)
+
+var errRateLimited = errors.New("message rate limit exceeded")
+
+// defaultMessageCosts are per-item costs of messages that are expensive to
+// handle, all other messages cost 1.
+var defaultMessageCosts = map[CommandType]int{
+	CMDGetAddr:         100,
+	CMDMempool:         1000,
+	CMDGetBlocks:       10,
+	CMDGetBlockByIndex: 2,
+	CMDGetMPTData:      10,
+	CMDFilterLoad:      100,
+}
+
+// rateLimiter accounts message costs per peer using token buckets. Every
+// peer gets rate cost units per second up to burst and messages it can't pay
+// for are dropped.
+type rateLimiter struct {
+	rate       float64
+	burst      float64
+	maxDropped int
+	costs      [256]int
+	now        func() time.Time
+
+	lock  sync.Mutex
+	peers map[Peer]*peerBucket
+}
+
+type peerBucket struct {
+	tokens  float64
+	last    time.Time
+	dropped int
+}
+
+// newRateLimiter creates a limiter from the configuration, it returns nil if
+// rate limiting is disabled.
+func newRateLimiter(cfg config.P2PLimits) (*rateLimiter, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	l := &rateLimiter{
+		rate:       defaultLimitRate,
+		burst:      defaultLimitBurst,
+		maxDropped: defaultLimitMaxDropped,
+		now:        time.Now,
+		peers:      make(map[Peer]*peerBucket),
+	}
+	if cfg.Rate > 0 {
+		l.rate = float64(cfg.Rate)
+	}
+	if cfg.Burst > 0 {
+		l.burst = float64(cfg.Burst)
+	}
+	if cfg.MaxDropped > 0 {
+		l.maxDropped = cfg.MaxDropped
+	}
+	for i := range l.costs {
+		l.costs[i] = 1
+	}
+	for cmd, c := range defaultMessageCosts {
+		l.costs[cmd] = c
+	}
+	for name, c := range cfg.Costs {
+		cmd, err := commandFromString(name)
+		if err != nil {
+			return nil, err
+		}
+		if c < 0 {
+			return nil, fmt.Errorf("negative %s message cost", name)
+		}
+		l.costs[cmd] = c
+	}
+	return l, nil
+}
+
+// commandFromString returns the command by its name given either with or
+// without "CMD" prefix, case-insensitive.
+func commandFromString(name string) (CommandType, error) {
+	for i := 0; i < 256; i++ {
+		cmd := CommandType(i)
+		s := cmd.String()
+		if strings.HasPrefix(s, "CommandType(") {
+			continue
+		}
+		if strings.EqualFold(name, s) || strings.EqualFold(name, strings.TrimPrefix(s, "CMD")) {
+			return cmd, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown command %s", name)
+}
+
+// cost returns the cost of the message which is its command cost multiplied by
+// the number of items requested or announced in it.
+func (l *rateLimiter) cost(msg *Message) float64 {
+	items := 1
+	switch pl := msg.Payload.(type) {
+	case *payload.Inventory:
+		items = len(pl.Hashes)
+	case *payload.MPTInventory:
+		items = len(pl.Hashes)
+	case *payload.GetBlockByIndex:
+		max := payload.MaxHashesCount
+		if msg.Command == CMDGetHeaders {
+			max = payload.MaxHeadersAllowed
+		}
+		items = int(pl.Count)
+		if items < 0 || items > max {
+			items = max
+		}
+	}
+	if items < 1 {
+		items = 1
+	}
+	return float64(l.costs[msg.Command] * items)
+}
+
+// allow checks whether the peer can pay for the message. It returns false if
+// the message should be dropped and errRateLimited if the peer has exceeded
+// the limit too many times.
+func (l *rateLimiter) allow(p Peer, msg *Message) (bool, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := l.now()
+	b := l.peers[p]
+	if b == nil {
+		b = &peerBucket{tokens: l.burst, last: now}
+		l.peers[p] = b
+	}
+	b.tokens += now.Sub(b.last).Seconds() * l.rate
+	if b.tokens > l.burst {
+		b.tokens = l.burst
+	}
+	b.last = now
+	cost := l.cost(msg)
+	if cost <= b.tokens {
+		b.tokens -= cost
+		return true, nil
+	}
+	b.dropped++
+	if b.dropped > l.maxDropped {
+		return false, errRateLimited
+	}
+	return false, nil
+}
+
+// dropPeer forgets the peer state.
+func (l *rateLimiter) dropPeer(p Peer) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	delete(l.peers, p)
+}
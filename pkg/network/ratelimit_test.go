@@ -0,0 +1,94 @@
+package network
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/config"
+	"github.com/nspcc-dev/neo-go/pkg/network/payload"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandFromString(t *testing.T) {
+	for _, name := range []string{"GetData", "getdata", "CMDGetData"} {
+		cmd, err := commandFromString(name)
+		require.NoError(t, err)
+		require.Equal(t, CMDGetData, cmd)
+	}
+	_, err := commandFromString("unknown")
+	require.Error(t, err)
+}
+
+func TestNewRateLimiter(t *testing.T) {
+	l, err := newRateLimiter(config.P2PLimits{})
+	require.NoError(t, err)
+	require.Nil(t, l)
+
+	l, err = newRateLimiter(config.P2PLimits{Enabled: true})
+	require.NoError(t, err)
+	require.Equal(t, float64(defaultLimitRate), l.rate)
+	require.Equal(t, float64(defaultLimitBurst), l.burst)
+	require.Equal(t, defaultLimitMaxDropped, l.maxDropped)
+	require.Equal(t, defaultMessageCosts[CMDGetAddr], l.costs[CMDGetAddr])
+	require.Equal(t, 1, l.costs[CMDPing])
+
+	l, err = newRateLimiter(config.P2PLimits{Enabled: true, Costs: map[string]int{"Ping": 5, "GetAddr": 0}})
+	require.NoError(t, err)
+	require.Equal(t, 5, l.costs[CMDPing])
+	require.Equal(t, 0, l.costs[CMDGetAddr])
+
+	_, err = newRateLimiter(config.P2PLimits{Enabled: true, Costs: map[string]int{"Bad": 5}})
+	require.Error(t, err)
+	_, err = newRateLimiter(config.P2PLimits{Enabled: true, Costs: map[string]int{"Ping": -1}})
+	require.Error(t, err)
+}
+
+func TestRateLimiter(t *testing.T) {
+	l, err := newRateLimiter(config.P2PLimits{Enabled: true, Rate: 10, Burst: 100, MaxDropped: 2})
+	require.NoError(t, err)
+	now := time.Unix(1000, 0)
+	l.now = func() time.Time { return now }
+	p1 := newFetcherPeer(t, 0, 1)
+	p2 := newFetcherPeer(t, 0, 2)
+
+	getData := func(n int) *Message {
+		return NewMessage(CMDGetData, payload.NewInventory(payload.TXType, make([]util.Uint256, n)))
+	}
+	require.Equal(t, float64(7), l.cost(getData(7)))
+	require.Equal(t, float64(2*payload.MaxHashesCount), l.cost(NewMessage(CMDGetBlockByIndex, payload.NewGetBlockByIndex(0, -1))))
+	require.Equal(t, float64(payload.MaxHeadersAllowed), l.cost(NewMessage(CMDGetHeaders, payload.NewGetBlockByIndex(0, -1))))
+	require.Equal(t, float64(20), l.cost(NewMessage(CMDGetBlockByIndex, payload.NewGetBlockByIndex(0, 10))))
+
+	ok, err := l.allow(p1, getData(60))
+	require.True(t, ok)
+	require.NoError(t, err)
+	ok, err = l.allow(p1, getData(60))
+	require.False(t, ok)
+	require.NoError(t, err)
+
+	// Other peers have their own budget.
+	ok, _ = l.allow(p2, getData(100))
+	require.True(t, ok)
+
+	// Budget is restored with time, but not above the burst.
+	now = now.Add(2 * time.Second)
+	ok, _ = l.allow(p1, getData(60))
+	require.True(t, ok)
+	now = now.Add(time.Hour)
+	ok, _ = l.allow(p1, getData(100))
+	require.True(t, ok)
+	ok, err = l.allow(p1, getData(1))
+	require.False(t, ok)
+	require.NoError(t, err)
+
+	ok, err = l.allow(p1, getData(1))
+	require.False(t, ok)
+	require.True(t, errors.Is(err, errRateLimited))
+
+	l.dropPeer(p1)
+	ok, err = l.allow(p1, getData(1))
+	require.True(t, ok)
+	require.NoError(t, err)
+}
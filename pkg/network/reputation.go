@@ -0,0 +1,241 @@
+package network
+
+import (
+	"errors"
+	gio "io"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/core/storage"
+	"github.com/nspcc-dev/neo-go/pkg/io"
+)
+
+// Peer reputation scores and their changes. Scores are kept per host (IP
+// address), every peer starts with zero and gets banned once its score drops
+// to banScore. Negative scores recover by one point every scoreRecoveryTime,
+// so that occasional problems of honest peers don't add up to a ban.
+const (
+	maxScore = 100
+	banScore = -100
+
+	scoreRecoveryTime = time.Minute
+
+	// DefaultBanDuration is the time a misbehaving peer is banned for.
+	DefaultBanDuration = 24 * time.Hour
+
+	rewardBlock         = 1
+	penaltyRateLimit    = -1
+	penaltySlowResponse = -10
+	penaltyInvalidTx    = -10
+	penaltyOversized    = -10
+	penaltyInvalidBlock = -20
+	penaltyProtocol     = -50
+	penaltyInvalidData  = banScore
+)
+
+var errBanned = errors.New("peer is banned")
+
+// PeerBan is a ban record for some peer host.
+type PeerBan struct {
+	Address string
+	Until   time.Time
+	Reason  string
+}
+
+// reputation tracks peer scores and bans, bans are persisted in the store
+// (if it's provided).
+type reputation struct {
+	lock   sync.Mutex
+	scores map[string]hostScore
+	bans   map[string]PeerBan
+	store  storage.Store
+}
+
+// hostScore is the host score along with the time of its last change.
+type hostScore struct {
+	value   int
+	updated time.Time
+}
+
+// current returns the score value with the recovery applied.
+func (s hostScore) current(now time.Time) int {
+	if s.value >= 0 {
+		return s.value
+	}
+	v := s.value + int(now.Sub(s.updated)/scoreRecoveryTime)
+	if v > 0 {
+		v = 0
+	}
+	return v
+}
+
+func newReputation(store storage.Store) *reputation {
+	r := &reputation{
+		scores: make(map[string]hostScore),
+		bans:   make(map[string]PeerBan),
+		store:  store,
+	}
+	if store == nil {
+		return r
+	}
+	var (
+		now     = time.Now()
+		expired = make(map[string][]byte)
+	)
+	store.Seek(storage.SeekRange{Prefix: []byte{byte(storage.SYSPeerBan)}}, func(k, v []byte) bool {
+		b := PeerBan{Address: string(k[1:])}
+		br := io.NewBinReaderFromBuf(v)
+		b.Until = time.Unix(int64(br.ReadU64LE()), 0)
+		b.Reason = br.ReadString()
+		if br.Err != nil || !b.Until.After(now) {
+			expired[string(k)] = nil
+		} else {
+			r.bans[b.Address] = b
+		}
+		return true
+	})
+	if len(expired) != 0 {
+		_ = store.PutChangeSet(expired, nil)
+	}
+	return r
+}
+
+// isPeerFault checks whether the error that broke the connection is caused by
+// the peer misbehaviour, contrary to network problems or our own decisions to
+// drop the connection.
+func isPeerFault(err error) bool {
+	var netErr net.Error
+	return err != nil &&
+		!errors.Is(err, errGone) &&
+		!errors.Is(err, errStateMismatch) &&
+		!errors.Is(err, errIdenticalID) &&
+		!errors.Is(err, errAlreadyConnected) &&
+		!errors.Is(err, errPingPong) &&
+		!errors.Is(err, gio.EOF) &&
+		!errors.Is(err, gio.ErrUnexpectedEOF) &&
+		!errors.As(err, &netErr)
+}
+
+// hostFromAddr returns the host part of the address given either as host or
+// as host:port pair.
+func hostFromAddr(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return ip.String()
+	}
+	return host
+}
+
+func banKey(host string) string {
+	return string(append([]byte{byte(storage.SYSPeerBan)}, host...))
+}
+
+// change adjusts the score of the host and bans it if the score drops too low.
+// It returns true if the host got banned.
+func (r *reputation) change(host string, delta int, reason string) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if _, ok := r.bans[host]; ok {
+		return false
+	}
+	now := time.Now()
+	score := r.scores[host].current(now) + delta
+	if score > maxScore {
+		score = maxScore
+	}
+	if score > banScore {
+		r.scores[host] = hostScore{value: score, updated: now}
+		return false
+	}
+	r.ban(host, DefaultBanDuration, reason)
+	return true
+}
+
+// score returns the current score of the host.
+func (r *reputation) score(host string) int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.scores[host].current(time.Now())
+}
+
+// isBanned checks whether the host is currently banned.
+func (r *reputation) isBanned(host string) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	b, ok := r.bans[host]
+	if ok && !b.Until.After(time.Now()) {
+		r.unban(host)
+		return false
+	}
+	return ok
+}
+
+// addBan bans the host for the specified duration.
+func (r *reputation) addBan(host string, d time.Duration, reason string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.ban(host, d, reason)
+}
+
+// removeBan lifts the ban (if any) from the host, it returns false if the
+// host wasn't banned.
+func (r *reputation) removeBan(host string) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	_, ok := r.bans[host]
+	if ok {
+		r.unban(host)
+	}
+	return ok
+}
+
+// banned returns the list of current bans sorted by address.
+func (r *reputation) banned() []PeerBan {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	var (
+		now = time.Now()
+		res = make([]PeerBan, 0, len(r.bans))
+	)
+	for host, b := range r.bans {
+		if !b.Until.After(now) {
+			r.unban(host)
+			continue
+		}
+		res = append(res, b)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Address < res[j].Address })
+	return res
+}
+
+// ban must be called with the lock held.
+func (r *reputation) ban(host string, d time.Duration, reason string) {
+	b := PeerBan{
+		Address: host,
+		Until:   time.Now().Add(d),
+		Reason:  reason,
+	}
+	r.bans[host] = b
+	// The peer starts from scratch after the ban.
+	delete(r.scores, host)
+	if r.store != nil {
+		w := io.NewBufBinWriter()
+		w.WriteU64LE(uint64(b.Until.Unix()))
+		w.WriteString(b.Reason)
+		_ = r.store.PutChangeSet(map[string][]byte{banKey(host): w.Bytes()}, nil)
+	}
+}
+
+// unban must be called with the lock held.
+func (r *reputation) unban(host string) {
+	delete(r.bans, host)
+	delete(r.scores, host)
+	if r.store != nil {
+		_ = r.store.PutChangeSet(map[string][]byte{banKey(host): nil}, nil)
+	}
+}
@@ -0,0 +1,127 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/core/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostFromAddr(t *testing.T) {
+	require.Equal(t, "1.2.3.4", hostFromAddr("1.2.3.4"))
+	require.Equal(t, "1.2.3.4", hostFromAddr("1.2.3.4:10333"))
+	require.Equal(t, "::1", hostFromAddr("[::1]:10333"))
+	require.Equal(t, "::1", hostFromAddr("::1"))
+	require.Equal(t, "seed1.neo.org", hostFromAddr("seed1.neo.org:10333"))
+}
+
+func TestIsPeerFault(t *testing.T) {
+	require.False(t, isPeerFault(nil))
+	require.False(t, isPeerFault(io.EOF))
+	require.False(t, isPeerFault(fmt.Errorf("handling ping message: %w", errGone)))
+	require.False(t, isPeerFault(errPingPong))
+	require.False(t, isPeerFault(&net.OpError{Op: "read", Err: errors.New("connection reset")}))
+	require.True(t, isPeerFault(errors.New("invalid payload")))
+	require.True(t, isPeerFault(fmt.Errorf("handling inv message: %w", errInvalidInvType)))
+}
+
+func TestReputationScore(t *testing.T) {
+	r := newReputation(nil)
+	const host = "1.2.3.4"
+
+	for i := 0; i < 2*maxScore; i++ {
+		require.False(t, r.change(host, rewardBlock, ""))
+	}
+	require.Equal(t, maxScore, r.score(host))
+
+	require.False(t, r.change(host, penaltyProtocol, "bad"))
+	require.Equal(t, maxScore+penaltyProtocol, r.score(host))
+	require.False(t, r.isBanned(host))
+
+	require.False(t, r.change(host, penaltyInvalidData, "bad"))
+	require.False(t, r.isBanned(host))
+	require.True(t, r.change(host, penaltyProtocol, "very bad"))
+	require.True(t, r.isBanned(host))
+	require.Equal(t, 0, r.score(host))
+
+	// Banned hosts are not scored.
+	require.False(t, r.change(host, penaltyInvalidBlock, "bad"))
+
+	bans := r.banned()
+	require.Equal(t, 1, len(bans))
+	require.Equal(t, host, bans[0].Address)
+	require.Equal(t, "very bad", bans[0].Reason)
+	require.True(t, bans[0].Until.After(time.Now().Add(DefaultBanDuration-time.Minute)))
+
+	require.True(t, r.removeBan(host))
+	require.False(t, r.removeBan(host))
+	require.False(t, r.isBanned(host))
+	require.Equal(t, 0, len(r.banned()))
+}
+
+func TestReputationScoreRecovery(t *testing.T) {
+	r := newReputation(nil)
+	const host = "1.2.3.4"
+
+	require.False(t, r.change(host, penaltyProtocol, "bad"))
+	require.Equal(t, penaltyProtocol, r.score(host))
+
+	// Pretend the penalty was received some time ago.
+	s := r.scores[host]
+	s.updated = s.updated.Add(-10 * scoreRecoveryTime)
+	r.scores[host] = s
+	require.Equal(t, penaltyProtocol+10, r.score(host))
+	require.False(t, r.change(host, penaltyProtocol, "bad"))
+	require.Equal(t, 2*penaltyProtocol+10, r.score(host))
+
+	s = r.scores[host]
+	s.updated = s.updated.Add(-1000 * scoreRecoveryTime)
+	r.scores[host] = s
+	require.Equal(t, 0, r.score(host))
+
+	// Positive scores don't change over time.
+	require.False(t, r.change(host, rewardBlock, ""))
+	s = r.scores[host]
+	s.updated = s.updated.Add(-1000 * scoreRecoveryTime)
+	r.scores[host] = s
+	require.Equal(t, rewardBlock, r.score(host))
+}
+
+func TestReputationBanExpiry(t *testing.T) {
+	r := newReputation(nil)
+	r.addBan("1.2.3.4", time.Hour, "")
+	r.addBan("4.3.2.1", time.Nanosecond, "")
+	time.Sleep(time.Millisecond)
+	require.True(t, r.isBanned("1.2.3.4"))
+	require.False(t, r.isBanned("4.3.2.1"))
+	bans := r.banned()
+	require.Equal(t, 1, len(bans))
+	require.Equal(t, "1.2.3.4", bans[0].Address)
+}
+
+func TestReputationPersistence(t *testing.T) {
+	st := storage.NewMemoryStore()
+	r := newReputation(st)
+	r.addBan("1.2.3.4", time.Hour, "reason")
+	r.addBan("2.3.4.5", time.Hour, "")
+	r.addBan("4.3.2.1", time.Hour, "")
+	require.True(t, r.removeBan("2.3.4.5"))
+
+	// Expired ban is dropped on load.
+	expired := newReputation(st)
+	expired.addBan("3.4.5.6", -time.Hour, "")
+
+	r = newReputation(st)
+	bans := r.banned()
+	require.Equal(t, 2, len(bans))
+	require.Equal(t, "1.2.3.4", bans[0].Address)
+	require.Equal(t, "reason", bans[0].Reason)
+	require.Equal(t, "4.3.2.1", bans[1].Address)
+	_, err := st.Get([]byte(banKey("3.4.5.6")))
+	require.Error(t, err)
+}
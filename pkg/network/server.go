@@ -18,8 +18,10 @@ import (
 	"github.com/nspcc-dev/neo-go/pkg/core/mempool"
 	"github.com/nspcc-dev/neo-go/pkg/core/mempoolevent"
 	"github.com/nspcc-dev/neo-go/pkg/core/mpt"
+	"github.com/nspcc-dev/neo-go/pkg/core/storage"
 	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
 	"github.com/nspcc-dev/neo-go/pkg/io"
+	"github.com/nspcc-dev/neo-go/pkg/network/bloom"
 	"github.com/nspcc-dev/neo-go/pkg/network/capability"
 	"github.com/nspcc-dev/neo-go/pkg/network/extpool"
 	"github.com/nspcc-dev/neo-go/pkg/network/payload"
@@ -45,6 +47,10 @@ var (
 	errMaxPeers         = errors.New("max peers reached")
 	errServerShutdown   = errors.New("server shutdown")
 	errInvalidInvType   = errors.New("invalid inventory type")
+
+	// notaryPoolKey is the DB key P2PNotaryRequest pool is saved under if
+	// MemPool.Persist is enabled (the main pool is saved by the chain).
+	notaryPoolKey = []byte{byte(storage.SYSMemPool), 1}
 )
 
 type (
@@ -62,6 +68,7 @@ type (
 		GetNotaryBalance(acc util.Uint160) *big.Int
 		GetNotaryContractScriptHash() util.Uint160
 		GetNotaryDepositExpiration(acc util.Uint160) uint32
+		GetStore() storage.Store
 		GetTransaction(util.Uint256) (*transaction.Transaction, uint32, error)
 		HasBlock(util.Uint256) bool
 		HeaderHeight() uint32
@@ -111,8 +118,18 @@ type (
 		lock  sync.RWMutex
 		peers map[Peer]bool
 
-		// lastRequestedBlock contains a height of the last requested block.
-		lastRequestedBlock atomic.Uint32
+		reputation *reputation
+		// encryption is nil if P2P connections are not encrypted.
+		encryption *encryption
+		// capturer is nil if P2P messages are not captured.
+		capturer *Capturer
+		// limiter is nil if message rate is not limited.
+		limiter *rateLimiter
+
+		// fetcher distributes block requests between peers.
+		fetcher *blockFetcher
+		// headers contains headers received out of order.
+		headers *headerBuffer
 		// lastRequestedHeader contains a height of the last requested header.
 		lastRequestedHeader atomic.Uint32
 
@@ -179,6 +196,9 @@ func newServerFromConstructors(config ServerConfig, chain Ledger, stSync StateSy
 		transactions:   make(chan *transaction.Transaction, 64),
 		extensHandlers: make(map[string]func(*payload.Extensible) error),
 		stateSync:      stSync,
+		reputation:     newReputation(chain.GetStore()),
+		fetcher:        newBlockFetcher(),
+		headers:        newHeaderBuffer(),
 	}
 	if chain.P2PSigExtensionsEnabled() {
 		s.notaryFeer = NewNotaryFeer(chain)
@@ -188,12 +208,15 @@ func newServerFromConstructors(config ServerConfig, chain Ledger, stSync StateSy
 				return isRelevant(t, txpool, true)
 			}, s.notaryFeer)
 		})
+		if s.PersistMemPool {
+			s.restoreNotaryRequests()
+		}
 	}
 	s.bQueue = newBlockQueue(maxBlockBatch, chain, log, func(b *block.Block) {
 		s.tryStartServices()
-	})
+	}, s.handleInvalidBlock)
 
-	s.bSyncQueue = newBlockQueue(maxBlockBatch, s.stateSync, log, nil)
+	s.bSyncQueue = newBlockQueue(maxBlockBatch, s.stateSync, log, nil, s.handleInvalidBlock)
 
 	if s.MinPeers < 0 {
 		s.log.Info("bad MinPeers configured, using the default value",
@@ -216,6 +239,24 @@ func newServerFromConstructors(config ServerConfig, chain Ledger, stSync StateSy
 		s.AttemptConnPeers = defaultAttemptConnPeers
 	}
 
+	enc, err := newEncryption(s.EncryptionCfg)
+	if err != nil {
+		return nil, fmt.Errorf("P2P encryption: %w", err)
+	}
+	s.encryption = enc
+
+	s.limiter, err = newRateLimiter(s.Limits)
+	if err != nil {
+		return nil, fmt.Errorf("P2P limits: %w", err)
+	}
+
+	if s.CaptureFile != "" {
+		s.capturer, err = NewCapturer(s.CaptureFile, s.config.Magic, s.config.StateRootInHeader)
+		if err != nil {
+			return nil, fmt.Errorf("P2P capture: %w", err)
+		}
+	}
+
 	s.transport = newTransport(s)
 	s.discovery = newDiscovery(
 		s.Seeds,
@@ -264,10 +305,59 @@ func (s *Server) Shutdown() {
 	}
 	if s.chain.P2PSigExtensionsEnabled() {
 		s.notaryRequestPool.StopSubscriptions()
+		if s.PersistMemPool {
+			s.saveNotaryRequests()
+		}
+	}
+	if s.capturer != nil {
+		if err := s.capturer.Close(); err != nil {
+			s.log.Warn("failed to close P2P capture file", zap.Error(err))
+		}
 	}
 	close(s.quit)
 }
 
+// captureMessage writes the message to the capture file if capturing is
+// enabled.
+func (s *Server) captureMessage(dir CaptureDirection, p Peer, msg []byte) {
+	if s.capturer == nil {
+		return
+	}
+	if err := s.capturer.Capture(dir, p.RemoteAddr().String(), msg); err != nil {
+		s.log.Warn("failed to capture P2P message", zap.Error(err))
+	}
+}
+
+// checkLimits checks the message against configured limits. It returns
+// false if the message should be dropped and an error if the peer should be
+// disconnected.
+func (s *Server) checkLimits(p Peer, msg *Message) (bool, error) {
+	if ext, ok := msg.Payload.(*payload.Extensible); ok &&
+		s.Limits.MaxExtensibleSize > 0 && len(ext.Data) > s.Limits.MaxExtensibleSize {
+		droppedMessages.WithLabelValues(msg.Command.String(), "size").Inc()
+		s.changeScore(p, penaltyOversized, "oversized extensible payload")
+		return false, nil
+	}
+	if s.limiter == nil {
+		return true, nil
+	}
+	ok, err := s.limiter.allow(p, msg)
+	if !ok {
+		droppedMessages.WithLabelValues(msg.Command.String(), "rate").Inc()
+		s.changeScore(p, penaltyRateLimit, errRateLimited.Error())
+	}
+	return ok, err
+}
+
+// replyLimit returns the number of items that can be sent in reply to a
+// request for n items.
+func (s *Server) replyLimit(n int) int {
+	if m := s.Limits.MaxReplyItems; m > 0 && n > m {
+		return m
+	}
+	return n
+}
+
 // AddService allows to add a service to be started/stopped by Server.
 func (s *Server) AddService(svc Service) {
 	s.services = append(s.services, svc)
@@ -315,6 +405,56 @@ func (s *Server) ConnectedPeers() []string {
 	return peers
 }
 
+// BannedPeers returns a list of currently banned peer hosts.
+func (s *Server) BannedPeers() []PeerBan {
+	return s.reputation.banned()
+}
+
+// BanPeer bans the given host (port is ignored if specified) for the given
+// duration and disconnects all of its peers.
+func (s *Server) BanPeer(addr string, d time.Duration, reason string) error {
+	host := hostFromAddr(addr)
+	if len(host) == 0 {
+		return errors.New("empty address")
+	}
+	if d <= 0 {
+		return errors.New("ban duration must be positive")
+	}
+	s.reputation.addBan(host, d, reason)
+	s.disconnectHost(host)
+	return nil
+}
+
+// UnbanPeer lifts the ban from the given host (port is ignored if specified),
+// it returns false if the host wasn't banned.
+func (s *Server) UnbanPeer(addr string) bool {
+	return s.reputation.removeBan(hostFromAddr(addr))
+}
+
+// changeScore adjusts the reputation of the peer's host, all peers from this
+// host are disconnected if it gets banned.
+func (s *Server) changeScore(p Peer, delta int, reason string) {
+	host := hostFromAddr(p.RemoteAddr().String())
+	if !s.reputation.change(host, delta, reason) {
+		return
+	}
+	s.log.Warn("peer banned",
+		zap.String("host", host),
+		zap.String("reason", reason))
+	s.disconnectHost(host)
+}
+
+// disconnectHost drops all connections to the host.
+func (s *Server) disconnectHost(host string) {
+	peers := s.getPeers(func(p Peer) bool {
+		return hostFromAddr(p.RemoteAddr().String()) == host
+	})
+	for _, p := range peers {
+		// It will send us unregister signal.
+		go p.Disconnect(errBanned)
+	}
+}
+
 // run is a goroutine that starts another goroutine to manage protocol specifics
 // while itself dealing with peers management (handling connects/disconnects).
 func (s *Server) run() {
@@ -330,6 +470,11 @@ func (s *Server) run() {
 		case <-s.quit:
 			return
 		case p := <-s.register:
+			if s.reputation.isBanned(hostFromAddr(p.RemoteAddr().String())) {
+				// It's not registered, so unregister signal will be ignored.
+				go p.Disconnect(errBanned)
+				continue
+			}
 			s.lock.Lock()
 			s.peers[p] = true
 			s.lock.Unlock()
@@ -351,13 +496,18 @@ func (s *Server) run() {
 			s.lock.Lock()
 			if s.peers[drop.peer] {
 				delete(s.peers, drop.peer)
+				s.fetcher.dropPeer(drop.peer)
+				s.headers.dropPeer(drop.peer)
+				if s.limiter != nil {
+					s.limiter.dropPeer(drop.peer)
+				}
 				s.lock.Unlock()
 				s.log.Warn("peer disconnected",
 					zap.Stringer("addr", drop.peer.RemoteAddr()),
 					zap.Error(drop.reason),
 					zap.Int("peerCount", s.PeerCount()))
 				addr := drop.peer.PeerAddr().String()
-				if drop.reason == errIdenticalID {
+				if drop.reason == errIdenticalID || drop.reason == errBanned {
 					s.discovery.RegisterBadAddr(addr)
 				} else if drop.reason == errAlreadyConnected {
 					// There is a race condition when peer can be disconnected twice for the this reason
@@ -599,10 +749,38 @@ func (s *Server) handleVersionCmd(p Peer, version *payload.Version) error {
 
 // handleBlockCmd processes the received block received from its peer.
 func (s *Server) handleBlockCmd(p Peer, block *block.Block) error {
+	var (
+		bq                = s.bQueue
+		chain Blockqueuer = s.chain
+	)
 	if s.stateSync.IsActive() {
-		return s.bSyncQueue.putBlock(block)
+		bq = s.bSyncQueue
+		chain = s.stateSync
+	}
+	// Blocks we already have are not penalised, they're either requested
+	// from several peers or just announced by several peers at once.
+	if h := chain.BlockHeight(); h < block.Index && block.Index <= h+blockCacheSize {
+		s.changeScore(p, rewardBlock, "")
+	}
+	err := bq.putBlock(p, block)
+	if err != nil {
+		return err
+	}
+	if s.fetcher.add(p, block.Index) {
+		return s.requestBlocks(chain, p)
+	}
+	return nil
+}
+
+// handleInvalidBlock is called by block queues for blocks that can't be added
+// to the chain.
+func (s *Server) handleInvalidBlock(p Peer, b *block.Block, err error) {
+	s.fetcher.fail(b.Index)
+	penalty := penaltyInvalidBlock
+	if isInvalidData(err) {
+		penalty = penaltyInvalidData
 	}
-	return s.bQueue.putBlock(block)
+	s.changeScore(p, penalty, fmt.Sprintf("invalid block %d: %s", b.Index, err))
 }
 
 // handlePing processes ping request.
@@ -625,6 +803,10 @@ func (s *Server) requestBlocksOrHeaders(p Peer) error {
 		}
 		return nil
 	}
+	// Blocks are only requested when there are verified headers for them.
+	if s.config.HeadersFirstSync && s.chain.HeaderHeight() < p.LastBlockIndex() {
+		return s.requestHeaders(p)
+	}
 	var (
 		bq              Blockqueuer = s.chain
 		requestMPTNodes bool
@@ -699,6 +881,10 @@ func (s *Server) handleInvCmd(p Peer, inv *payload.Inventory) error {
 // handleMempoolCmd handles getmempool command.
 func (s *Server) handleMempoolCmd(p Peer) error {
 	txs := s.mempool.GetVerifiedTransactions()
+	if f := p.Filter(); f != nil {
+		txs = filterTxs(f, txs)
+	}
+	txs = txs[:s.replyLimit(len(txs))]
 	hs := make([]util.Uint256, 0, payload.MaxHashesCount)
 	for i := range txs {
 		hs = append(hs, txs[i].Hash())
@@ -718,7 +904,7 @@ func (s *Server) handleMempoolCmd(p Peer) error {
 // handleInvCmd processes the received inventory.
 func (s *Server) handleGetDataCmd(p Peer, inv *payload.Inventory) error {
 	var notFound []util.Uint256
-	for _, hash := range inv.Hashes {
+	for _, hash := range inv.Hashes[:s.replyLimit(len(inv.Hashes))] {
 		var msg *Message
 
 		switch inv.Type {
@@ -732,7 +918,7 @@ func (s *Server) handleGetDataCmd(p Peer, inv *payload.Inventory) error {
 		case payload.BlockType:
 			b, err := s.chain.GetBlock(hash)
 			if err == nil {
-				msg = NewMessage(CMDBlock, b)
+				msg = blockMessage(p, b)
 			} else {
 				notFound = append(notFound, hash)
 			}
@@ -836,6 +1022,7 @@ func (s *Server) handleGetBlocksCmd(p Peer, gb *payload.GetBlocks) error {
 	if gb.Count < 0 || gb.Count > payload.MaxHashesCount {
 		count = payload.MaxHashesCount
 	}
+	count = int16(s.replyLimit(int(count)))
 	start, err := s.chain.GetHeader(gb.HashStart)
 	if err != nil {
 		return err
@@ -863,6 +1050,7 @@ func (s *Server) handleGetBlockByIndexCmd(p Peer, gbd *payload.GetBlockByIndex)
 	if gbd.Count < 0 || gbd.Count > payload.MaxHashesCount {
 		count = payload.MaxHashesCount
 	}
+	count = int16(s.replyLimit(int(count)))
 	for i := gbd.IndexStart; i < gbd.IndexStart+uint32(count); i++ {
 		hash := s.chain.GetHeaderHash(int(i))
 		if hash.Equals(util.Uint256{}) {
@@ -872,7 +1060,7 @@ func (s *Server) handleGetBlockByIndexCmd(p Peer, gbd *payload.GetBlockByIndex)
 		if err != nil {
 			break
 		}
-		msg := NewMessage(CMDBlock, b)
+		msg := blockMessage(p, b)
 		if err = p.EnqueueP2PMessage(msg); err != nil {
 			return err
 		}
@@ -880,6 +1068,66 @@ func (s *Server) handleGetBlockByIndexCmd(p Peer, gbd *payload.GetBlockByIndex)
 	return nil
 }
 
+// blockMessage returns a message with the block for the peer. If the peer has
+// a bloom filter set, it's a MerkleBlock marking transactions matching the
+// filter.
+func blockMessage(p Peer, b *block.Block) *Message {
+	f := p.Filter()
+	if f == nil {
+		return NewMessage(CMDBlock, b)
+	}
+	flags := make([]bool, len(b.Transactions))
+	for i := range b.Transactions {
+		flags[i] = matchTx(f, b.Transactions[i])
+	}
+	return NewMessage(CMDMerkleBlock, payload.NewMerkleBlock(b, flags))
+}
+
+// matchTx checks whether the transaction hash or any of its signers match
+// the filter.
+func matchTx(f *bloom.Filter, tx *transaction.Transaction) bool {
+	if f.Check(tx.Hash().BytesBE()) {
+		return true
+	}
+	for i := range tx.Signers {
+		if f.Check(tx.Signers[i].Account.BytesBE()) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterTxs returns transactions matching the filter.
+func filterTxs(f *bloom.Filter, txs []*transaction.Transaction) []*transaction.Transaction {
+	var res []*transaction.Transaction
+	for _, tx := range txs {
+		if matchTx(f, tx) {
+			res = append(res, tx)
+		}
+	}
+	return res
+}
+
+// handleFilterLoadCmd sets the bloom filter for the peer.
+func (s *Server) handleFilterLoadCmd(p Peer, fl *payload.FilterLoad) error {
+	p.SetFilter(bloom.NewFromBytes(fl.Filter, int(fl.K), fl.Tweak))
+	return nil
+}
+
+// handleFilterAddCmd adds an element to the bloom filter of the peer.
+func (s *Server) handleFilterAddCmd(p Peer, fa *payload.FilterAdd) error {
+	if f := p.Filter(); f != nil {
+		f.Add(fa.Data)
+	}
+	return nil
+}
+
+// handleFilterClearCmd removes the bloom filter of the peer.
+func (s *Server) handleFilterClearCmd(p Peer) error {
+	p.SetFilter(nil)
+	return nil
+}
+
 // handleGetHeadersCmd processes the getheaders request.
 func (s *Server) handleGetHeadersCmd(p Peer, gh *payload.GetBlockByIndex) error {
 	if gh.IndexStart > s.chain.HeaderHeight() {
@@ -889,6 +1137,7 @@ func (s *Server) handleGetHeadersCmd(p Peer, gh *payload.GetBlockByIndex) error
 	if gh.Count < 0 || gh.Count > payload.MaxHeadersAllowed {
 		count = payload.MaxHeadersAllowed
 	}
+	count = int16(s.replyLimit(int(count)))
 	resp := payload.Headers{}
 	resp.Hdrs = make([]*block.Header, 0, count)
 	for i := gh.IndexStart; i < gh.IndexStart+uint32(count); i++ {
@@ -911,9 +1160,47 @@ func (s *Server) handleGetHeadersCmd(p Peer, gh *payload.GetBlockByIndex) error
 
 // handleHeadersCmd processes headers payload.
 func (s *Server) handleHeadersCmd(p Peer, h *payload.Headers) error {
+	if s.stateSync.NeedHeaders() {
+		return s.addHeaders(p, s.stateSync, h.Hdrs)
+	}
+	if s.config.HeadersFirstSync {
+		return s.addHeaders(p, s.chain, h.Hdrs)
+	}
 	return s.stateSync.AddHeaders(h.Hdrs...)
 }
 
+// addHeaders adds headers to the chain in order, batches that can't be added
+// yet are buffered. Peers sending headers that fail verification (being on
+// some other chain, for example) are penalized.
+func (s *Server) addHeaders(p Peer, bq Blockqueuer, hdrs []*block.Header) error {
+	if len(hdrs) == 0 {
+		return nil
+	}
+	height := s.chain.HeaderHeight()
+	if hdrs[0].Index > height+1 {
+		if hdrs[0].Index <= height+headersRequestWindow {
+			s.headers.put(p, hdrs)
+		}
+		return nil
+	}
+	for hdrs != nil {
+		err := bq.AddHeaders(hdrs...)
+		if err != nil {
+			if !isInvalidData(err) {
+				return err
+			}
+			s.log.Warn("peer's header chain doesn't match ours",
+				zap.Stringer("addr", p.RemoteAddr()),
+				zap.Uint32("index", hdrs[0].Index),
+				zap.Error(err))
+			s.headers.dropPeer(p)
+			s.changeScore(p, penaltyInvalidData, fmt.Sprintf("invalid headers: %s", err))
+		}
+		p, hdrs = s.headers.next(s.chain.HeaderHeight())
+	}
+	return nil
+}
+
 // handleExtensibleCmd processes received extensible payload.
 func (s *Server) handleExtensibleCmd(e *payload.Extensible) error {
 	if !s.syncReached.Load() {
@@ -950,7 +1237,7 @@ func (s *Server) advertiseExtensible(e *payload.Extensible) {
 
 // handleTxCmd processes received transaction.
 // It never returns an error.
-func (s *Server) handleTxCmd(tx *transaction.Transaction) error {
+func (s *Server) handleTxCmd(p Peer, tx *transaction.Transaction) error {
 	// It's OK for it to fail for various reasons like tx already existing
 	// in the pool.
 	s.txInLock.Lock()
@@ -964,8 +1251,11 @@ func (s *Server) handleTxCmd(tx *transaction.Transaction) error {
 	if s.txCallback != nil {
 		s.txCallback(tx)
 	}
-	if s.verifyAndPoolTX(tx) == nil {
+	err := s.verifyAndPoolTX(tx)
+	if err == nil {
 		s.broadcastTX(tx, nil)
+	} else if isInvalidData(err) {
+		s.changeScore(p, penaltyInvalidTx, "invalid transactions")
 	}
 	s.txInLock.Lock()
 	delete(s.txInMap, tx.Hash())
@@ -973,6 +1263,21 @@ func (s *Server) handleTxCmd(tx *transaction.Transaction) error {
 	return nil
 }
 
+// invalidDataError is implemented by the Ledger errors caused by the data
+// which can never be valid irrespective of the chain state.
+type invalidDataError interface {
+	error
+	InvalidData() bool
+}
+
+// isInvalidData checks whether the header, block or transaction can never be
+// valid, contrary to the ones that can't be accepted because of the current
+// chain or mempool state. Only such errors prove peer misbehaviour.
+func isInvalidData(err error) bool {
+	var e invalidDataError
+	return errors.As(err, &e) && e.InvalidData()
+}
+
 // handleP2PNotaryRequestCmd process received P2PNotaryRequest payload.
 func (s *Server) handleP2PNotaryRequestCmd(r *payload.P2PNotaryRequest) error {
 	if !s.chain.P2PSigExtensionsEnabled() {
@@ -994,6 +1299,62 @@ func (s *Server) RelayP2PNotaryRequest(r *payload.P2PNotaryRequest) error {
 	return err
 }
 
+// saveNotaryRequests writes P2PNotaryRequest pool contents to the DB, so that
+// they can be restored after restart. It must be called before the chain is
+// closed.
+func (s *Server) saveNotaryRequests() {
+	txs := s.notaryRequestPool.GetVerifiedTransactions()
+	if len(txs) == 0 {
+		return
+	}
+	reqs := make([]*payload.P2PNotaryRequest, 0, len(txs))
+	for _, tx := range txs {
+		if r, ok := s.notaryRequestPool.TryGetData(tx.Hash()); ok {
+			reqs = append(reqs, r.(*payload.P2PNotaryRequest))
+		}
+	}
+	w := io.NewBufBinWriter()
+	w.WriteArray(reqs)
+	if w.Err != nil {
+		s.log.Warn("failed to save notary requests", zap.Error(w.Err))
+		return
+	}
+	err := s.chain.GetStore().PutChangeSet(map[string][]byte{string(notaryPoolKey): w.Bytes()}, nil)
+	if err != nil {
+		s.log.Warn("failed to save notary requests", zap.Error(err))
+		return
+	}
+	s.log.Info("notary requests saved", zap.Int("requests", len(reqs)))
+}
+
+// restoreNotaryRequests verifies and pools P2PNotaryRequests saved on
+// shutdown, invalid and expired ones are dropped.
+func (s *Server) restoreNotaryRequests() {
+	store := s.chain.GetStore()
+	b, err := store.Get(notaryPoolKey)
+	if err != nil {
+		return
+	}
+	_ = store.PutChangeSet(map[string][]byte{string(notaryPoolKey): nil}, nil)
+
+	var reqs []*payload.P2PNotaryRequest
+	r := io.NewBinReaderFromBuf(b)
+	r.ReadArray(&reqs)
+	if r.Err != nil {
+		s.log.Warn("failed to restore notary requests", zap.Error(r.Err))
+		return
+	}
+	var restored int
+	for _, req := range reqs {
+		if s.verifyAndPoolNotaryRequest(req) == nil {
+			restored++
+		}
+	}
+	s.log.Info("notary requests restored",
+		zap.Int("restored", restored),
+		zap.Int("dropped", len(reqs)-restored))
+}
+
 // verifyAndPoolNotaryRequest verifies NotaryRequest payload and adds it to the payload mempool.
 func (s *Server) verifyAndPoolNotaryRequest(r *payload.P2PNotaryRequest) error {
 	return s.chain.PoolTxWithData(r.FallbackTransaction, r, s.notaryRequestPool, s.notaryFeer, s.verifyNotaryRequest)
@@ -1031,7 +1392,7 @@ func (s *Server) handleAddrCmd(p Peer, addrs *payload.AddressList) error {
 	dups := make(map[string]bool)
 	for _, a := range addrs.Addrs {
 		addr, err := a.GetTCPAddress()
-		if err == nil && !dups[addr] {
+		if err == nil && !dups[addr] && !s.reputation.isBanned(hostFromAddr(addr)) {
 			dups[addr] = true
 			s.discovery.BackFill(addr)
 		}
@@ -1055,30 +1416,45 @@ func (s *Server) handleGetAddrCmd(p Peer) error {
 	return p.EnqueueP2PMessage(NewMessage(CMDAddr, alist))
 }
 
-// requestBlocks sends a CMDGetBlockByIndex message to the peer
-// to sync up in blocks. A maximum of maxBlockBatch will
-// send at once. Two things we need to take care of:
-// 1. If possible, blocks should be fetched in parallel.
-//    height..+500 to one peer, height+500..+1000 to another etc.
-// 2. Every block must eventually be fetched even if peer sends no answer.
-// Thus the following algorithm is used:
-// 1. Block range is divided into chunks of payload.MaxHashesCount.
-// 2. Send requests for chunk in increasing order.
-// 3. After all requests were sent, request random height.
+// requestBlocks sends a CMDGetBlockByIndex message to the peer to sync up in
+// blocks. Blocks above the current height are split into ranges fetched from
+// different peers in parallel (see blockFetcher), every peer gets the next
+// range that is not yet requested from anyone else. Requests are sent to the
+// peer given unless it has negative reputation, then some better-scored peer
+// is used.
 func (s *Server) requestBlocks(bq Blockqueuer, p Peer) error {
 	h := bq.BlockHeight()
-	pl := getRequestBlocksPayload(p, h, &s.lastRequestedBlock)
-	lq := s.bQueue.lastQueued()
-	if lq > pl.IndexStart {
-		c := int16(h + blockCacheSize - lq)
-		if c < payload.MaxHashesCount {
-			pl.Count = c
-		}
-		pl.IndexStart = lq + 1
+	p = s.blockSource(p, h)
+	pl := s.fetcher.next(p, h)
+	if pl == nil {
+		return nil
 	}
 	return p.EnqueueP2PMessage(NewMessage(CMDGetBlockByIndex, pl))
 }
 
+// blockSource returns the peer to request blocks above the height given from,
+// it's p unless p has negative score and there is a better one.
+func (s *Server) blockSource(p Peer, height uint32) Peer {
+	var bestScore = s.reputation.score(hostFromAddr(p.RemoteAddr().String()))
+	if bestScore >= 0 {
+		return p
+	}
+	peers := s.getPeers(func(peer Peer) bool {
+		return peer.Handshaked() && peer.LastBlockIndex() > height
+	})
+	for _, peer := range peers {
+		score := s.reputation.score(hostFromAddr(peer.RemoteAddr().String()))
+		if score > bestScore {
+			p, bestScore = peer, score
+		}
+	}
+	return p
+}
+
+// headersRequestWindow is the number of headers above the current header
+// height that can be requested from different peers.
+const headersRequestWindow = 2000
+
 func getRequestBlocksPayload(p Peer, currHeight uint32, lastRequestedHeight *atomic.Uint32) *payload.GetBlockByIndex {
 	var peerHeight = p.LastBlockIndex()
 	var needHeight uint32
@@ -1090,7 +1466,7 @@ func getRequestBlocksPayload(p Peer, currHeight uint32, lastRequestedHeight *ato
 			if !lastRequestedHeight.CAS(old, needHeight) {
 				continue
 			}
-		} else if old < currHeight+(blockCacheSize-payload.MaxHashesCount) {
+		} else if old < currHeight+(headersRequestWindow-payload.MaxHashesCount) {
 			needHeight = currHeight + 1
 			if peerHeight > old+payload.MaxHashesCount {
 				needHeight = old + payload.MaxHashesCount
@@ -1099,7 +1475,7 @@ func getRequestBlocksPayload(p Peer, currHeight uint32, lastRequestedHeight *ato
 				}
 			}
 		} else {
-			index := mrand.Intn(blockCacheSize / payload.MaxHashesCount)
+			index := mrand.Intn(headersRequestWindow / payload.MaxHashesCount)
 			needHeight = currHeight + 1 + uint32(index*payload.MaxHashesCount)
 		}
 		break
@@ -1119,6 +1495,9 @@ func (s *Server) handleMessage(peer Peer, msg *Message) error {
 				return errInvalidInvType
 			}
 		}
+		if ok, err := s.checkLimits(peer, msg); !ok {
+			return err
+		}
 		switch msg.Command {
 		case CMDAddr:
 			addrs := msg.Payload.(*payload.AddressList)
@@ -1161,7 +1540,7 @@ func (s *Server) handleMessage(peer Peer, msg *Message) error {
 			return s.handleExtensibleCmd(cp)
 		case CMDTX:
 			tx := msg.Payload.(*transaction.Transaction)
-			return s.handleTxCmd(tx)
+			return s.handleTxCmd(peer, tx)
 		case CMDP2PNotaryRequest:
 			r := msg.Payload.(*payload.P2PNotaryRequest)
 			return s.handleP2PNotaryRequestCmd(r)
@@ -1171,6 +1550,15 @@ func (s *Server) handleMessage(peer Peer, msg *Message) error {
 		case CMDPong:
 			pong := msg.Payload.(*payload.Ping)
 			return s.handlePong(peer, pong)
+		case CMDFilterLoad:
+			fl := msg.Payload.(*payload.FilterLoad)
+			return s.handleFilterLoadCmd(peer, fl)
+		case CMDFilterAdd:
+			fa := msg.Payload.(*payload.FilterAdd)
+			return s.handleFilterAddCmd(peer, fa)
+		case CMDFilterClear:
+			// no payload
+			return s.handleFilterClearCmd(peer)
 		case CMDVersion, CMDVerack:
 			return fmt.Errorf("received '%s' after the handshake", msg.Command.String())
 		}
@@ -1197,7 +1585,7 @@ func (s *Server) handleMessage(peer Peer, msg *Message) error {
 
 func (s *Server) tryInitStateSync() {
 	if !s.stateSync.IsActive() {
-		s.bSyncQueue.discard()
+		s.discardSyncQueue()
 		return
 	}
 
@@ -1237,11 +1625,19 @@ func (s *Server) tryInitStateSync() {
 
 		// module can be inactive after init (i.e. full state is collected and ordinary block processing is needed)
 		if !s.stateSync.IsActive() {
-			s.bSyncQueue.discard()
+			s.discardSyncQueue()
 		}
 	}
 }
 
+// discardSyncQueue drops state sync block queue, blocks that were received
+// for it have to be requested again.
+func (s *Server) discardSyncQueue() {
+	if s.bSyncQueue.discard() {
+		s.fetcher.reset()
+	}
+}
+
 // BroadcastExtensible add locally-generated Extensible payload to the pool
 // and advertises it to peers.
 func (s *Server) BroadcastExtensible(p *payload.Extensible) {
@@ -1382,12 +1778,43 @@ func (s *Server) broadcastTX(t *transaction.Transaction, _ interface{}) {
 	}
 }
 
-func (s *Server) broadcastTxHashes(hs []util.Uint256) {
+func (s *Server) broadcastTxHashes(txs []*transaction.Transaction) {
+	hs := make([]util.Uint256, len(txs))
+	for i := range txs {
+		hs[i] = txs[i].Hash()
+	}
 	msg := NewMessage(CMDInv, payload.NewInventory(payload.TXType, hs))
 
 	// We need to filter out non-relaying nodes, so plain broadcast
-	// functions don't fit here.
-	s.iteratePeersWithSendMsg(msg, Peer.EnqueuePacket, Peer.IsFullNode)
+	// functions don't fit here. Peers with bloom filters get only
+	// transactions they're interested in.
+	s.iteratePeersWithSendMsg(msg, Peer.EnqueuePacket, func(p Peer) bool {
+		return p.IsFullNode() && p.Filter() == nil
+	})
+	s.relayFilteredTxs(txs)
+}
+
+// relayFilteredTxs sends inventories of the transactions matching bloom
+// filters to the peers that have them set.
+func (s *Server) relayFilteredTxs(txs []*transaction.Transaction) {
+	peers := s.getPeers(func(p Peer) bool {
+		return p.IsFullNode() && p.Filter() != nil
+	})
+	for _, p := range peers {
+		matched := filterTxs(p.Filter(), txs)
+		if len(matched) == 0 {
+			continue
+		}
+		hs := make([]util.Uint256, len(matched))
+		for i := range matched {
+			hs[i] = matched[i].Hash()
+		}
+		pkt, err := NewMessage(CMDInv, payload.NewInventory(payload.TXType, hs)).Bytes()
+		if err != nil {
+			return
+		}
+		_ = p.EnqueuePacket(true, pkt)
+	}
 }
 
 // initStaleMemPools initializes mempools for stale tx/payload processing.
@@ -1413,7 +1840,7 @@ func (s *Server) broadcastTxLoop() {
 		batchSize = 32
 	)
 
-	txs := make([]util.Uint256, 0, batchSize)
+	txs := make([]*transaction.Transaction, 0, batchSize)
 	var timer *time.Timer
 
 	timerCh := func() <-chan time.Time {
@@ -1452,7 +1879,7 @@ func (s *Server) broadcastTxLoop() {
 				timer = time.NewTimer(batchTime)
 			}
 
-			txs = append(txs, tx.Hash())
+			txs = append(txs, tx)
 			if len(txs) == batchSize {
 				broadcast()
 			}
@@ -79,8 +79,22 @@ type (
 		// StateRootCfg is stateroot module configuration.
 		StateRootCfg config.StateRoot
 
+		// EncryptionCfg is P2P connections encryption configuration.
+		EncryptionCfg config.P2PEncryption
+
+		// CaptureFile is the file to write all P2P messages to, capturing is
+		// disabled if it's empty.
+		CaptureFile string
+
+		// Limits are per-peer message rate limits and other DoS protections.
+		Limits config.P2PLimits
+
 		// ExtensiblePoolSize is size of the pool for extensible payloads from a single sender.
 		ExtensiblePoolSize int
+
+		// PersistMemPool makes the server save P2PNotaryRequest pool to the
+		// DB on shutdown and restore it on startup.
+		PersistMemPool bool
 	}
 )
 
@@ -115,6 +129,10 @@ func NewServerConfig(cfg config.Config) ServerConfig {
 		OracleCfg:          appConfig.Oracle,
 		P2PNotaryCfg:       appConfig.P2PNotary,
 		StateRootCfg:       appConfig.StateRoot,
+		EncryptionCfg:      appConfig.P2PEncryption,
+		CaptureFile:        appConfig.P2PCaptureFile,
+		Limits:             appConfig.P2PLimits,
 		ExtensiblePoolSize: appConfig.ExtensiblePoolSize,
+		PersistMemPool:     appConfig.MemPool.Persist,
 	}
 }
@@ -17,12 +17,16 @@ import (
 	"github.com/nspcc-dev/neo-go/pkg/core"
 	"github.com/nspcc-dev/neo-go/pkg/core/block"
 	"github.com/nspcc-dev/neo-go/pkg/core/interop"
+	"github.com/nspcc-dev/neo-go/pkg/core/mempool"
 	"github.com/nspcc-dev/neo-go/pkg/core/mpt"
+	"github.com/nspcc-dev/neo-go/pkg/core/storage"
 	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/nspcc-dev/neo-go/pkg/network/bloom"
 	"github.com/nspcc-dev/neo-go/pkg/network/capability"
 	"github.com/nspcc-dev/neo-go/pkg/network/payload"
 	"github.com/nspcc-dev/neo-go/pkg/util"
 	"github.com/nspcc-dev/neo-go/pkg/vm/opcode"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/atomic"
@@ -174,16 +178,71 @@ func TestServerRegisterPeer(t *testing.T) {
 }
 
 func TestGetBlocksByIndex(t *testing.T) {
-	testGetBlocksByIndex(t, CMDGetBlockByIndex)
+	s := newTestServer(t, ServerConfig{Port: 0, UserAgent: "/test/"})
+	ps := make([]*localPeer, 10)
+	requested := make([]*payload.GetBlockByIndex, 10)
+	for i := range ps {
+		i := i
+		ps[i] = newLocalPeer(t, s)
+		ps[i].messageHandler = func(t *testing.T, msg *Message) {
+			if msg.Command == CMDGetBlockByIndex {
+				requested[i] = msg.Payload.(*payload.GetBlockByIndex)
+			}
+		}
+	}
+	go s.transport.Accept()
+
+	nonce := uint32(0)
+	checkPingRespond := func(t *testing.T, peerIndex int, peerHeight uint32, start uint32, count int16) {
+		nonce++
+		requested[peerIndex] = nil
+		require.NoError(t, s.handlePing(ps[peerIndex], payload.NewPing(peerHeight, nonce)))
+		if count == 0 {
+			require.Nil(t, requested[peerIndex])
+			return
+		}
+		require.NotNil(t, requested[peerIndex])
+		require.Equal(t, start, requested[peerIndex].IndexStart)
+		require.Equal(t, count, requested[peerIndex].Count)
+	}
+
+	// Every peer gets its own range.
+	checkPingRespond(t, 0, 5000, 1, payload.MaxHashesCount)
+	checkPingRespond(t, 1, 5000, 1+payload.MaxHashesCount, payload.MaxHashesCount)
+	checkPingRespond(t, 2, 5000, 1+2*payload.MaxHashesCount, payload.MaxHashesCount)
+	checkPingRespond(t, 3, 5000, 1+3*payload.MaxHashesCount, payload.MaxHashesCount)
+
+	// Receive some blocks.
+	s.chain.(*fakechain.FakeChain).Blockheight = 2123
+
+	// Minimum range has priority.
+	checkPingRespond(t, 5, 5000, 2124, 2500-2123)
+	checkPingRespond(t, 6, 5000, 2501, payload.MaxHashesCount)
+	// Peers behind get what they have.
+	checkPingRespond(t, 7, 3100, 3001, 100)
+	checkPingRespond(t, 8, 5000, 3101, 400)
+	checkPingRespond(t, 9, 5000, 3501, payload.MaxHashesCount)
+
+	// Peer that has delivered its range gets the next one.
+	requested[9] = nil
+	for i := uint32(3501); i <= 4000; i++ {
+		require.NoError(t, s.handleBlockCmd(ps[9], &block.Block{Header: block.Header{Index: i}}))
+	}
+	require.NotNil(t, requested[9])
+	require.Equal(t, uint32(4001), requested[9].IndexStart)
+
+	// Several ranges can be requested from a single peer.
+	checkPingRespond(t, 1, 5000, 4501, payload.MaxHashesCount)
+	checkPingRespond(t, 1, 5000, 0, 0)
+	// Everything is requested already.
+	checkPingRespond(t, 2, 5000, 0, 0)
 }
 
-func testGetBlocksByIndex(t *testing.T, cmd CommandType) {
+func testGetHeadersByIndex(t *testing.T) {
+	const cmd = CMDGetHeaders
 	s := newTestServer(t, ServerConfig{Port: 0, UserAgent: "/test/"})
-	start := s.chain.BlockHeight()
-	if cmd == CMDGetHeaders {
-		start = s.chain.HeaderHeight()
-		s.stateSync.(*fakechain.FakeStateSync).RequestHeaders.Store(true)
-	}
+	start := s.chain.HeaderHeight()
+	s.stateSync.(*fakechain.FakeStateSync).RequestHeaders.Store(true)
 	ps := make([]*localPeer, 10)
 	expectsCmd := make([]CommandType, 10)
 	expectedHeight := make([][]uint32, 10)
@@ -672,7 +731,57 @@ func TestGetHeaders(t *testing.T) {
 		require.Nil(t, actual)
 	})
 	t.Run("distribute requests between peers", func(t *testing.T) {
-		testGetBlocksByIndex(t, CMDGetHeaders)
+		testGetHeadersByIndex(t)
+	})
+}
+
+func TestHeadersFirstSync(t *testing.T) {
+	s := newTestServerWithCustomCfg(t, ServerConfig{Port: 0, UserAgent: "/test/"}, func(c *config.ProtocolConfiguration) {
+		c.HeadersFirstSync = true
+	})
+	chain := s.chain.(*fakechain.FakeChain)
+	var requested []CommandType
+	p := newLocalPeer(t, s)
+	p.handshaked = true
+	p.messageHandler = func(t *testing.T, msg *Message) {
+		if msg.Command != CMDPong {
+			requested = append(requested, msg.Command)
+		}
+	}
+	p2 := newLocalPeer(t, s)
+	p2.handshaked = true
+
+	hdrs := make([]*block.Header, 11)
+	for i := range hdrs {
+		hdrs[i] = &block.Header{Index: uint32(i), Timestamp: uint64(i)}
+	}
+
+	// Headers are requested first.
+	require.NoError(t, s.handlePing(p, payload.NewPing(10, 1)))
+	require.Equal(t, []CommandType{CMDGetHeaders}, requested)
+
+	// Out of order batches are buffered.
+	s.testHandleMessage(t, p2, CMDHeaders, &payload.Headers{Hdrs: hdrs[6:]})
+	require.Equal(t, uint32(0), chain.HeaderHeight())
+	s.testHandleMessage(t, p, CMDHeaders, &payload.Headers{Hdrs: hdrs[1:7]})
+	require.Equal(t, uint32(10), chain.HeaderHeight())
+	require.Equal(t, hdrs[10].Hash(), chain.GetHeaderHash(10))
+
+	// Then blocks.
+	requested = nil
+	require.NoError(t, s.handlePing(p, payload.NewPing(10, 2)))
+	require.Equal(t, []CommandType{CMDGetBlockByIndex}, requested)
+
+	t.Run("dropped peer", func(t *testing.T) {
+		s.testHandleMessage(t, p2, CMDHeaders, &payload.Headers{Hdrs: []*block.Header{{Index: 12}}})
+		s.headers.dropPeer(p2)
+		s.testHandleMessage(t, p, CMDHeaders, &payload.Headers{Hdrs: []*block.Header{{Index: 11}}})
+		require.Equal(t, uint32(11), chain.HeaderHeight())
+	})
+	t.Run("invalid header", func(t *testing.T) {
+		require.True(t, isInvalidData(fmt.Errorf("%w: fork", core.ErrHdrHashMismatch)))
+		require.True(t, isInvalidData(fmt.Errorf("%w: bad witness", core.ErrVerificationFailed)))
+		require.False(t, isInvalidData(errors.New("previous header was not found")))
 	})
 }
 
@@ -1003,6 +1112,203 @@ func TestMemPool(t *testing.T) {
 	require.ElementsMatch(t, expected, actual)
 }
 
+func TestBloomFilter(t *testing.T) {
+	s := startTestServer(t)
+	bc := s.chain.(*fakechain.FakeChain)
+
+	b := newDummyBlock(2, 3)
+	bc.PutBlock(b)
+	txs := b.Transactions
+
+	var received []*Message
+	p := newLocalPeer(t, s)
+	p.handshaked = true
+	p.isFullNode = true
+	p.messageHandler = func(t *testing.T, msg *Message) {
+		// Pings and address requests are sent by the server asynchronously.
+		if msg.Command == CMDPing || msg.Command == CMDGetAddr {
+			return
+		}
+		received = append(received, msg)
+	}
+
+	f := bloom.New(1024, 5, 42)
+	f.Add(txs[1].Hash().BytesBE())
+	s.testHandleMessage(t, p, CMDFilterLoad, &payload.FilterLoad{
+		Filter: f.Bytes(),
+		K:      byte(f.K()),
+		Tweak:  f.Tweak(),
+	})
+	require.NotNil(t, p.Filter())
+
+	t.Run("merkle block", func(t *testing.T) {
+		received = nil
+		s.testHandleMessage(t, p, CMDGetData, payload.NewInventory(payload.BlockType, []util.Uint256{b.Hash()}))
+		s.testHandleMessage(t, p, CMDGetBlockByIndex, &payload.GetBlockByIndex{IndexStart: b.Index, Count: 1})
+		require.Equal(t, 2, len(received))
+		expected := payload.NewMerkleBlock(b, []bool{false, true, false})
+		for _, msg := range received {
+			require.Equal(t, CMDMerkleBlock, msg.Command)
+			require.Equal(t, expected.Hashes, msg.Payload.(*payload.MerkleBlock).Hashes)
+			require.Equal(t, []byte{0x02}, msg.Payload.(*payload.MerkleBlock).Flags)
+		}
+	})
+	t.Run("filter add", func(t *testing.T) {
+		// Signers are checked too.
+		s.testHandleMessage(t, p, CMDFilterAdd, &payload.FilterAdd{Data: txs[2].Signers[0].Account.BytesBE()})
+
+		received = nil
+		s.testHandleMessage(t, p, CMDGetData, payload.NewInventory(payload.BlockType, []util.Uint256{b.Hash()}))
+		require.Equal(t, 1, len(received))
+		require.Equal(t, []byte{0x06}, received[0].Payload.(*payload.MerkleBlock).Flags)
+	})
+	t.Run("mempool", func(t *testing.T) {
+		for _, tx := range txs {
+			require.NoError(t, bc.Pool.Add(tx, &feerStub{blockHeight: 10}))
+		}
+		received = nil
+		s.testHandleMessage(t, p, CMDMempool, payload.NullPayload{})
+		require.Equal(t, 1, len(received))
+		require.ElementsMatch(t, []util.Uint256{txs[1].Hash(), txs[2].Hash()}, received[0].Payload.(*payload.Inventory).Hashes)
+	})
+	t.Run("relay", func(t *testing.T) {
+		s.register <- p
+		require.Eventually(t, func() bool { return s.PeerCount() == 1 }, time.Second, time.Millisecond*10)
+
+		received = nil
+		s.broadcastTxHashes(txs)
+		require.Equal(t, 1, len(received))
+		require.Equal(t, []util.Uint256{txs[1].Hash(), txs[2].Hash()}, received[0].Payload.(*payload.Inventory).Hashes)
+
+		received = nil
+		s.broadcastTxHashes(txs[:1])
+		require.Equal(t, 0, len(received))
+	})
+	t.Run("clear", func(t *testing.T) {
+		s.testHandleMessage(t, p, CMDFilterClear, payload.NullPayload{})
+		require.Nil(t, p.Filter())
+
+		received = nil
+		s.testHandleMessage(t, p, CMDGetData, payload.NewInventory(payload.BlockType, []util.Uint256{b.Hash()}))
+		require.Equal(t, 1, len(received))
+		require.Equal(t, CMDBlock, received[0].Command)
+	})
+}
+
+func TestPeerReputation(t *testing.T) {
+	s := startTestServer(t)
+	bc := s.chain.(*fakechain.FakeChain)
+	atomic2.StoreUint32(&bc.Blockheight, 10)
+
+	newPeer := func(ip string, port int) *localPeer {
+		p := newLocalPeer(t, s)
+		p.netaddr.IP = net.ParseIP(ip)
+		p.netaddr.Port = port
+		p.handshaked = true
+		return p
+	}
+	score := func(p Peer) int {
+		return s.reputation.score(hostFromAddr(p.RemoteAddr().String()))
+	}
+
+	t.Run("blocks", func(t *testing.T) {
+		p := newPeer("1.1.1.1", 1)
+		b := block.New(false)
+		b.Index = 5
+		s.testHandleMessage(t, p, CMDBlock, b)
+		require.Equal(t, 0, score(p))
+
+		b = block.New(false)
+		b.Index = 12
+		s.testHandleMessage(t, p, CMDBlock, b)
+		require.Equal(t, rewardBlock, score(p))
+	})
+	t.Run("transactions", func(t *testing.T) {
+		p := newPeer("2.2.2.2", 1)
+		bc.PoolTxF = func(*transaction.Transaction) error { return core.ErrInsufficientFunds }
+		s.testHandleMessage(t, p, CMDTX, newDummyTx())
+		require.Equal(t, 0, score(p))
+
+		bc.PoolTxF = func(*transaction.Transaction) error {
+			return fmt.Errorf("witness #0: %w", core.ErrInvalidSignature)
+		}
+		s.testHandleMessage(t, p, CMDTX, newDummyTx())
+		require.Equal(t, penaltyInvalidTx, score(p))
+		bc.PoolTxF = func(*transaction.Transaction) error { return nil }
+	})
+	t.Run("block source", func(t *testing.T) {
+		bad := newPeer("3.3.3.3", 1)
+		good := newPeer("4.4.4.4", 1)
+		good.lastBlockIndex = 100
+		s.register <- bad
+		s.register <- good
+		require.Eventually(t, func() bool { return s.PeerCount() == 2 }, time.Second, time.Millisecond*10)
+
+		require.Equal(t, bad, s.blockSource(bad, 10))
+		s.changeScore(bad, penaltySlowResponse, "")
+		require.Equal(t, Peer(good), s.blockSource(bad, 10))
+		// The good one doesn't have blocks needed.
+		require.Equal(t, bad, s.blockSource(bad, 100))
+	})
+	t.Run("invalid block", func(t *testing.T) {
+		p := newPeer("5.5.5.5", 1)
+		s.register <- p
+		require.Eventually(t, func() bool { return s.PeerCount() == 3 }, time.Second, time.Millisecond*10)
+
+		// Blocks can fail for reasons not related to the peer.
+		s.handleInvalidBlock(p, block.New(false), errors.New("bad block"))
+		require.Equal(t, penaltyInvalidBlock, score(p))
+		require.Equal(t, 3, s.PeerCount())
+
+		s.handleInvalidBlock(p, block.New(false), fmt.Errorf("%w: bad witness", core.ErrVerificationFailed))
+		require.Eventually(t, func() bool { return s.PeerCount() == 2 }, time.Second, time.Millisecond*10)
+		require.Equal(t, errBanned, p.droppedWith.Load())
+		bans := s.BannedPeers()
+		require.Equal(t, 1, len(bans))
+		require.Equal(t, "5.5.5.5", bans[0].Address)
+
+		// Banned host can't connect.
+		p = newPeer("5.5.5.5", 2)
+		s.register <- p
+		require.Eventually(t, func() bool { return p.droppedWith.Load() != nil }, time.Second, time.Millisecond*10)
+		require.Equal(t, errBanned, p.droppedWith.Load())
+		require.Equal(t, 2, s.PeerCount())
+	})
+	t.Run("manual ban", func(t *testing.T) {
+		require.Error(t, s.BanPeer("", time.Hour, ""))
+		require.Error(t, s.BanPeer("4.4.4.4", 0, ""))
+
+		require.NoError(t, s.BanPeer("4.4.4.4:10333", time.Hour, "manual"))
+		require.Eventually(t, func() bool { return s.PeerCount() == 1 }, time.Second, time.Millisecond*10)
+		bans := s.BannedPeers()
+		require.Equal(t, 2, len(bans))
+		require.Equal(t, PeerBan{Address: "4.4.4.4", Until: bans[0].Until, Reason: "manual"}, bans[0])
+
+		require.True(t, s.UnbanPeer("4.4.4.4"))
+		require.True(t, s.UnbanPeer("5.5.5.5"))
+		require.False(t, s.UnbanPeer("5.5.5.5"))
+		require.Equal(t, 0, len(s.BannedPeers()))
+
+		p := newPeer("5.5.5.5", 3)
+		s.register <- p
+		require.Eventually(t, func() bool { return s.PeerCount() == 2 }, time.Second, time.Millisecond*10)
+	})
+	t.Run("addr", func(t *testing.T) {
+		require.NoError(t, s.BanPeer("6.6.6.6", time.Hour, ""))
+		p := newPeer("7.7.7.7", 1)
+		p.getAddrSent = 1
+		caps := capability.Capabilities{{
+			Type: capability.TCPServer,
+			Data: &capability.Server{Port: 10333},
+		}}
+		pl := payload.NewAddressList(2)
+		pl.Addrs[0] = payload.NewAddressAndTime(&net.TCPAddr{IP: net.ParseIP("6.6.6.6")}, time.Now(), caps)
+		pl.Addrs[1] = payload.NewAddressAndTime(&net.TCPAddr{IP: net.ParseIP("8.8.8.8")}, time.Now(), caps)
+		s.testHandleMessage(t, p, CMDAddr, pl)
+		require.Equal(t, []string{"8.8.8.8:10333"}, s.discovery.(*testDiscovery).backfill)
+	})
+}
+
 func TestVerifyNotaryRequest(t *testing.T) {
 	bc := fakechain.NewFakeChain()
 	bc.MaxVerificationGAS = 10
@@ -1046,6 +1352,70 @@ func TestVerifyNotaryRequest(t *testing.T) {
 	})
 }
 
+func TestPersistNotaryRequests(t *testing.T) {
+	bc := fakechain.NewFakeChain()
+	bc.UtilityTokenBalance = big.NewInt(1_0000_0000)
+	var bad util.Uint256
+	bc.PoolTxWithDataF = func(tx *transaction.Transaction, data interface{}, mp *mempool.Pool) error {
+		if tx.Hash() == bad {
+			return errors.New("bad request")
+		}
+		return mp.Add(tx, bc, data)
+	}
+	newServer := func() *Server {
+		s, err := newServerFromConstructors(ServerConfig{PersistMemPool: true}, bc, new(fakechain.FakeStateSync), zaptest.NewLogger(t), newFakeTransp, newTestDiscovery)
+		require.NoError(t, err)
+		return s
+	}
+	newNotaryRequest := func(nonce uint32) *payload.P2PNotaryRequest {
+		mainTx := &transaction.Transaction{
+			Attributes:      []transaction.Attribute{{Type: transaction.NotaryAssistedT, Value: &transaction.NotaryAssisted{NKeys: 1}}},
+			Script:          []byte{0, 1, 2},
+			Nonce:           nonce,
+			ValidUntilBlock: 123,
+			Signers:         []transaction.Signer{{Account: random.Uint160()}},
+			Scripts:         []transaction.Witness{{InvocationScript: []byte{1, 2, 3}, VerificationScript: []byte{1, 2, 3}}},
+		}
+		fallbackTx := &transaction.Transaction{
+			Script:          []byte{1, 2, 3},
+			ValidUntilBlock: 123,
+			Attributes: []transaction.Attribute{
+				{Type: transaction.NotValidBeforeT, Value: &transaction.NotValidBefore{Height: 123}},
+				{Type: transaction.ConflictsT, Value: &transaction.Conflicts{Hash: mainTx.Hash()}},
+				{Type: transaction.NotaryAssistedT, Value: &transaction.NotaryAssisted{NKeys: 0}},
+			},
+			Signers: []transaction.Signer{{Account: random.Uint160()}, {Account: random.Uint160()}},
+			Scripts: []transaction.Witness{{InvocationScript: append([]byte{byte(opcode.PUSHDATA1), 64}, make([]byte, 64)...), VerificationScript: make([]byte, 0)}, {InvocationScript: []byte{}, VerificationScript: []byte{}}},
+		}
+		return &payload.P2PNotaryRequest{
+			MainTransaction:     mainTx,
+			FallbackTransaction: fallbackTx,
+			Witness: transaction.Witness{
+				InvocationScript:   []byte{1, 2, 3},
+				VerificationScript: []byte{1, 2, 3},
+			},
+		}
+	}
+
+	s := newServer()
+	good, invalid := newNotaryRequest(1), newNotaryRequest(2)
+	require.NoError(t, s.verifyAndPoolNotaryRequest(good))
+	require.NoError(t, s.verifyAndPoolNotaryRequest(invalid))
+	s.Shutdown()
+	_, err := bc.Store.Get(notaryPoolKey)
+	require.NoError(t, err)
+
+	bad = invalid.FallbackTransaction.Hash()
+	s = newServer()
+	t.Cleanup(s.Shutdown)
+	require.Equal(t, 1, s.notaryRequestPool.Count())
+	r, ok := s.notaryRequestPool.TryGetData(good.FallbackTransaction.Hash())
+	require.True(t, ok)
+	require.Equal(t, good.Hash(), r.(*payload.P2PNotaryRequest).Hash())
+	_, err = bc.Store.Get(notaryPoolKey)
+	require.ErrorIs(t, err, storage.ErrKeyNotFound)
+}
+
 func TestTryInitStateSync(t *testing.T) {
 	t.Run("module inactive", func(t *testing.T) {
 		s := startTestServer(t)
@@ -1087,3 +1457,56 @@ func TestTryInitStateSync(t *testing.T) {
 		s.tryInitStateSync()
 	})
 }
+
+func TestMessageLimits(t *testing.T) {
+	s := newTestServer(t, ServerConfig{Limits: config.P2PLimits{
+		Enabled:           true,
+		Rate:              1,
+		Burst:             100,
+		MaxDropped:        2,
+		MaxReplyItems:     2,
+		MaxExtensibleSize: 10,
+	}})
+	startWithCleanup(t, s)
+	var blocks []*block.Block
+	for i := uint32(12); i <= 15; i++ {
+		b := newDummyBlock(i, 3)
+		s.chain.(*fakechain.FakeChain).PutBlock(b)
+		blocks = append(blocks, b)
+	}
+
+	t.Run("reply size", func(t *testing.T) {
+		var actual []*block.Block
+		p := newLocalPeer(t, s)
+		p.handshaked = true
+		p.messageHandler = func(t *testing.T, msg *Message) {
+			if msg.Command == CMDBlock {
+				actual = append(actual, msg.Payload.(*block.Block))
+			}
+		}
+		s.testHandleMessage(t, p, CMDGetBlockByIndex, &payload.GetBlockByIndex{IndexStart: blocks[0].Index, Count: 4})
+		require.Equal(t, blocks[:2], actual)
+	})
+	t.Run("extensible size", func(t *testing.T) {
+		p := newLocalPeer(t, s)
+		p.handshaked = true
+		dropped := testutil.ToFloat64(droppedMessages.WithLabelValues(CMDExtensible.String(), "size"))
+		ext := payload.NewExtensible()
+		ext.Data = make([]byte, 11)
+		s.testHandleMessage(t, p, CMDExtensible, ext)
+		require.Equal(t, dropped+1, testutil.ToFloat64(droppedMessages.WithLabelValues(CMDExtensible.String(), "size")))
+	})
+	t.Run("rate", func(t *testing.T) {
+		p := newLocalPeer(t, s)
+		p.handshaked = true
+		dropped := testutil.ToFloat64(droppedMessages.WithLabelValues(CMDGetAddr.String(), "rate"))
+		score := s.reputation.score(hostFromAddr(p.RemoteAddr().String()))
+		getAddr := NewMessage(CMDGetAddr, payload.NewNullPayload())
+		require.NoError(t, s.handleMessage(p, getAddr))
+		require.NoError(t, s.handleMessage(p, getAddr))
+		require.NoError(t, s.handleMessage(p, getAddr))
+		require.True(t, errors.Is(s.handleMessage(p, getAddr), errRateLimited))
+		require.Equal(t, dropped+3, testutil.ToFloat64(droppedMessages.WithLabelValues(CMDGetAddr.String(), "rate")))
+		require.Equal(t, score+3*penaltyRateLimit, s.reputation.score(hostFromAddr(p.RemoteAddr().String())))
+	})
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/nspcc-dev/neo-go/pkg/io"
+	"github.com/nspcc-dev/neo-go/pkg/network/bloom"
 	"github.com/nspcc-dev/neo-go/pkg/network/capability"
 	"github.com/nspcc-dev/neo-go/pkg/network/payload"
 	"go.uber.org/atomic"
@@ -53,6 +54,7 @@ type TCPPeer struct {
 	finale     sync.Once
 	handShake  handShakeStage
 	isFullNode bool
+	filter     *bloom.Filter
 
 	done     chan struct{}
 	sendQ    chan []byte
@@ -149,7 +151,9 @@ func (p *TCPPeer) writeMsg(msg *Message) error {
 	}
 
 	_, err = p.conn.Write(b)
-
+	if err == nil {
+		p.server.captureMessage(CaptureOut, p, b)
+	}
 	return err
 }
 
@@ -174,8 +178,14 @@ func (p *TCPPeer) handleConn() {
 				p.server.log.Warn("not all headers were processed")
 				r.Err = nil
 			} else if err != nil {
+				if isPeerFault(err) {
+					p.server.changeScore(p, penaltyProtocol, err.Error())
+				}
 				break
 			}
+			if p.server.capturer != nil {
+				p.server.captureMessage(CaptureIn, p, msg.wireBytes())
+			}
 			p.incoming <- msg
 		}
 	}
@@ -191,6 +201,9 @@ func (p *TCPPeer) handleIncoming() {
 			if p.Handshaked() {
 				err = fmt.Errorf("handling %s message: %w", msg.Command.String(), err)
 			}
+			if isPeerFault(err) {
+				p.server.changeScore(p, penaltyProtocol, err.Error())
+			}
 			break
 		}
 	}
@@ -249,6 +262,7 @@ func (p *TCPPeer) handleQueues() {
 		if err != nil {
 			break
 		}
+		p.server.captureMessage(CaptureOut, p, msg)
 		p2pSkipCounter++
 	}
 	p.Disconnect(err)
@@ -263,7 +277,7 @@ func (p *TCPPeer) StartProtocol() {
 	p.server.log.Info("started protocol",
 		zap.Stringer("addr", p.RemoteAddr()),
 		zap.ByteString("userAgent", p.Version().UserAgent),
-		zap.Uint32("startHeight", p.lastBlockIndex),
+		zap.Uint32("startHeight", p.LastBlockIndex()),
 		zap.Uint32("id", p.Version().Nonce))
 
 	p.server.discovery.RegisterGoodAddr(p.PeerAddr().String(), p.version.Capabilities)
@@ -452,6 +466,7 @@ func (p *TCPPeer) SendPing(msg *Message) error {
 	p.pingSent++
 	if p.pingTimer == nil {
 		p.pingTimer = time.AfterFunc(p.server.PingTimeout, func() {
+			p.server.changeScore(p, penaltySlowResponse, errPingPong.Error())
 			p.Disconnect(errPingPong)
 		})
 	}
@@ -496,3 +511,17 @@ func (p *TCPPeer) CanProcessAddr() bool {
 	v := p.getAddrSent.Dec()
 	return v >= 0
 }
+
+// SetFilter implements the Peer interface.
+func (p *TCPPeer) SetFilter(f *bloom.Filter) {
+	p.lock.Lock()
+	p.filter = f
+	p.lock.Unlock()
+}
+
+// Filter implements the Peer interface.
+func (p *TCPPeer) Filter() *bloom.Filter {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	return p.filter
+}
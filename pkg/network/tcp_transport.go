@@ -31,7 +31,15 @@ func NewTCPTransport(s *Server, bindAddr string, log *zap.Logger) *TCPTransport
 
 // Dial implements the Transporter interface.
 func (t *TCPTransport) Dial(addr string, timeout time.Duration) error {
-	conn, err := net.DialTimeout("tcp", addr, timeout)
+	var (
+		conn net.Conn
+		err  error
+	)
+	if t.server.encryption != nil {
+		conn, err = t.server.encryption.dial(addr, timeout)
+	} else {
+		conn, err = net.DialTimeout("tcp", addr, timeout)
+	}
 	if err != nil {
 		return err
 	}
@@ -69,9 +77,22 @@ func (t *TCPTransport) Accept() {
 			t.log.Warn("TCP accept error", zap.Error(err))
 			continue
 		}
-		p := NewTCPPeer(conn, t.server)
-		go p.handleConn()
+		go t.setupConn(conn)
+	}
+}
+
+// setupConn handles encryption setup for the accepted connection and starts
+// the peer.
+func (t *TCPTransport) setupConn(conn net.Conn) {
+	c, err := acceptConn(conn, t.server.encryption)
+	if err != nil {
+		t.log.Debug("incoming connection rejected",
+			zap.Stringer("addr", conn.RemoteAddr()), zap.Error(err))
+		conn.Close()
+		return
 	}
+	p := NewTCPPeer(c, t.server)
+	p.handleConn()
 }
 
 // Close implements the Transporter interface.
@@ -56,8 +56,12 @@ Supported methods
 
 Extensions:
 
+	banpeer
+	getbannedpeers
 	getblocksysfee
+	getmempoolfeestats
 	submitnotaryrequest
+	unbanpeer
 
 Unsupported methods
 
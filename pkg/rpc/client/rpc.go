@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/nspcc-dev/neo-go/pkg/config/netmode"
 	"github.com/nspcc-dev/neo-go/pkg/core/block"
@@ -58,6 +59,18 @@ func (c *Client) GetApplicationLog(hash util.Uint256, trig *trigger.Type) (*resu
 	return resp, nil
 }
 
+// GetBannedPeers returns the list of peer hosts banned by the node.
+func (c *Client) GetBannedPeers() ([]result.BannedPeer, error) {
+	var (
+		params = request.NewRawParams()
+		resp   []result.BannedPeer
+	)
+	if err := c.performRequest("getbannedpeers", params, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
 // GetBestBlockHash returns the hash of the tallest block in the main chain.
 func (c *Client) GetBestBlockHash() (util.Uint256, error) {
 	var resp = util.Uint256{}
@@ -400,6 +413,59 @@ func (c *Client) GetPeers() (*result.GetPeers, error) {
 	return resp, nil
 }
 
+// BanPeer bans the peer host (port is ignored if specified) for the given
+// duration (rounded down to seconds, node default is used if it's zero) with
+// an optional reason. It requires EnablePeerManagement setting to be enabled
+// on the server side.
+func (c *Client) BanPeer(addr string, d time.Duration, reason string) error {
+	var (
+		params = request.NewRawParams(addr, int64(d/time.Second))
+		resp   bool
+	)
+	if len(reason) != 0 {
+		params.Values = append(params.Values, reason)
+	}
+	if err := c.performRequest("banpeer", params, &resp); err != nil {
+		return err
+	}
+	if !resp {
+		return errors.New("banpeer returned false")
+	}
+	return nil
+}
+
+// UnbanPeer lifts the ban from the peer host (port is ignored if specified),
+// it returns false if the host wasn't banned. It requires EnablePeerManagement
+// setting to be enabled on the server side.
+func (c *Client) UnbanPeer(addr string) (bool, error) {
+	var (
+		params = request.NewRawParams(addr)
+		resp   bool
+	)
+	if err := c.performRequest("unbanpeer", params, &resp); err != nil {
+		return false, err
+	}
+	return resp, nil
+}
+
+// GetMempoolFeeStats returns fee per byte statistics of transactions in the
+// node's memory pool for the given percentiles (from 0 to 100), the default
+// server-side set (10, 25, 50, 75 and 90) is used if none are given. This
+// method is a NeoGo extension.
+func (c *Client) GetMempoolFeeStats(percentiles ...int) (*result.MempoolFeeStats, error) {
+	var (
+		params = request.NewRawParams()
+		resp   = new(result.MempoolFeeStats)
+	)
+	for _, p := range percentiles {
+		params.Values = append(params.Values, p)
+	}
+	if err := c.performRequest("getmempoolfeestats", params, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
 // GetRawMemPool returns the list of unconfirmed transactions in memory.
 func (c *Client) GetRawMemPool() ([]util.Uint256, error) {
 	var (
@@ -0,0 +1,233 @@
+/*
+Package unwrap provides functions converting invocation results and stack items
+to Go values. Functions accepting *result.Invoke check VM state and the result
+stack, they also accept an error, so that they can wrap invocation calls
+directly:
+
+	supply, err := unwrap.BigInt(c.InvokeFunction(hash, "totalSupply", nil, nil))
+
+Functions with `To` prefix convert single stack items.
+*/
+package unwrap
+
+import (
+	"crypto/elliptic"
+	"errors"
+	"fmt"
+	"math/big"
+	"unicode/utf8"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neo-go/pkg/rpc/response/result"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/vm/stackitem"
+)
+
+// Item returns the only item from the result stack. An error is returned if
+// err is not nil, VM state is not HALT or there is not exactly one item on
+// the stack.
+func Item(r *result.Invoke, err error) (stackitem.Item, error) {
+	if err != nil {
+		return nil, err
+	}
+	if r.State != "HALT" {
+		return nil, fmt.Errorf("invocation failed: %s", r.FaultException)
+	}
+	if len(r.Stack) != 1 {
+		return nil, fmt.Errorf("result stack contains %d items, expected 1", len(r.Stack))
+	}
+	return r.Stack[0], nil
+}
+
+// Nothing checks that invocation succeeded and returned Null (which is what
+// methods with Void return type do).
+func Nothing(r *result.Invoke, err error) error {
+	itm, err := Item(r, err)
+	if err != nil {
+		return err
+	}
+	if _, ok := itm.(stackitem.Null); !ok {
+		return fmt.Errorf("unexpected %s result, Null expected", itm.Type())
+	}
+	return nil
+}
+
+// BigInt returns integer result of the invocation.
+func BigInt(r *result.Invoke, err error) (*big.Int, error) {
+	itm, err := Item(r, err)
+	if err != nil {
+		return nil, err
+	}
+	return ToBigInt(itm)
+}
+
+// Bool returns boolean result of the invocation.
+func Bool(r *result.Invoke, err error) (bool, error) {
+	itm, err := Item(r, err)
+	if err != nil {
+		return false, err
+	}
+	return ToBool(itm)
+}
+
+// Bytes returns byte slice result of the invocation.
+func Bytes(r *result.Invoke, err error) ([]byte, error) {
+	itm, err := Item(r, err)
+	if err != nil {
+		return nil, err
+	}
+	return ToBytes(itm)
+}
+
+// String returns UTF-8 string result of the invocation.
+func String(r *result.Invoke, err error) (string, error) {
+	itm, err := Item(r, err)
+	if err != nil {
+		return "", err
+	}
+	return ToString(itm)
+}
+
+// Uint160 returns util.Uint160 result of the invocation.
+func Uint160(r *result.Invoke, err error) (util.Uint160, error) {
+	itm, err := Item(r, err)
+	if err != nil {
+		return util.Uint160{}, err
+	}
+	return ToUint160(itm)
+}
+
+// Uint256 returns util.Uint256 result of the invocation.
+func Uint256(r *result.Invoke, err error) (util.Uint256, error) {
+	itm, err := Item(r, err)
+	if err != nil {
+		return util.Uint256{}, err
+	}
+	return ToUint256(itm)
+}
+
+// PublicKey returns public key result of the invocation.
+func PublicKey(r *result.Invoke, err error) (*keys.PublicKey, error) {
+	itm, err := Item(r, err)
+	if err != nil {
+		return nil, err
+	}
+	return ToPublicKey(itm)
+}
+
+// Array returns array (or struct) result of the invocation.
+func Array(r *result.Invoke, err error) ([]stackitem.Item, error) {
+	itm, err := Item(r, err)
+	if err != nil {
+		return nil, err
+	}
+	return ToArray(itm)
+}
+
+// Map returns map result of the invocation.
+func Map(r *result.Invoke, err error) (*stackitem.Map, error) {
+	itm, err := Item(r, err)
+	if err != nil {
+		return nil, err
+	}
+	return ToMap(itm)
+}
+
+// Iterator returns values of the iterator returned by the invocation (they're
+// expanded by the RPC server, so the list can be truncated).
+func Iterator(r *result.Invoke, err error) ([]stackitem.Item, error) {
+	itm, err := Item(r, err)
+	if err != nil {
+		return nil, err
+	}
+	return ToIterator(itm)
+}
+
+// ToBigInt converts stack item to integer.
+func ToBigInt(itm stackitem.Item) (*big.Int, error) {
+	return itm.TryInteger()
+}
+
+// ToBool converts stack item to boolean.
+func ToBool(itm stackitem.Item) (bool, error) {
+	return itm.TryBool()
+}
+
+// ToBytes converts stack item to byte slice, Null is converted to nil.
+func ToBytes(itm stackitem.Item) ([]byte, error) {
+	if _, ok := itm.(stackitem.Null); ok {
+		return nil, nil
+	}
+	return itm.TryBytes()
+}
+
+// ToString converts stack item to UTF-8 string.
+func ToString(itm stackitem.Item) (string, error) {
+	b, err := itm.TryBytes()
+	if err != nil {
+		return "", err
+	}
+	if !utf8.Valid(b) {
+		return "", errors.New("not a UTF-8 string")
+	}
+	return string(b), nil
+}
+
+// ToUint160 converts stack item to util.Uint160.
+func ToUint160(itm stackitem.Item) (util.Uint160, error) {
+	b, err := itm.TryBytes()
+	if err != nil {
+		return util.Uint160{}, err
+	}
+	return util.Uint160DecodeBytesBE(b)
+}
+
+// ToUint256 converts stack item to util.Uint256.
+func ToUint256(itm stackitem.Item) (util.Uint256, error) {
+	b, err := itm.TryBytes()
+	if err != nil {
+		return util.Uint256{}, err
+	}
+	return util.Uint256DecodeBytesBE(b)
+}
+
+// ToPublicKey converts stack item to public key.
+func ToPublicKey(itm stackitem.Item) (*keys.PublicKey, error) {
+	b, err := itm.TryBytes()
+	if err != nil {
+		return nil, err
+	}
+	return keys.NewPublicKeyFromBytes(b, elliptic.P256())
+}
+
+// ToArray converts array or struct stack item to a list of items.
+func ToArray(itm stackitem.Item) ([]stackitem.Item, error) {
+	switch t := itm.(type) {
+	case *stackitem.Array, *stackitem.Struct:
+		return t.Value().([]stackitem.Item), nil
+	default:
+		return nil, fmt.Errorf("unexpected %s item, Array expected", itm.Type())
+	}
+}
+
+// ToMap converts stack item to map.
+func ToMap(itm stackitem.Item) (*stackitem.Map, error) {
+	m, ok := itm.(*stackitem.Map)
+	if !ok {
+		return nil, fmt.Errorf("unexpected %s item, Map expected", itm.Type())
+	}
+	return m, nil
+}
+
+// ToIterator returns values of the iterator stack item as expanded by the RPC
+// server.
+func ToIterator(itm stackitem.Item) ([]stackitem.Item, error) {
+	if itm.Type() != stackitem.InteropT {
+		return nil, fmt.Errorf("unexpected %s item, InteropInterface expected", itm.Type())
+	}
+	iter, ok := itm.Value().(result.Iterator)
+	if !ok {
+		return nil, errors.New("not an iterator")
+	}
+	return iter.Values, nil
+}
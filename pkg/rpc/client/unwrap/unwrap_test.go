@@ -0,0 +1,123 @@
+package unwrap
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neo-go/pkg/rpc/response/result"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/vm/stackitem"
+	"github.com/stretchr/testify/require"
+)
+
+func halt(items ...stackitem.Item) *result.Invoke {
+	return &result.Invoke{State: "HALT", Stack: items}
+}
+
+func TestItem(t *testing.T) {
+	_, err := Item(nil, errors.New("some"))
+	require.Error(t, err)
+
+	_, err = Item(&result.Invoke{State: "FAULT", FaultException: "boom"}, nil)
+	require.Error(t, err)
+
+	_, err = Item(halt(), nil)
+	require.Error(t, err)
+
+	_, err = Item(halt(stackitem.Make(1), stackitem.Make(2)), nil)
+	require.Error(t, err)
+
+	itm, err := Item(halt(stackitem.Make(1)), nil)
+	require.NoError(t, err)
+	require.Equal(t, stackitem.Make(1), itm)
+
+	require.NoError(t, Nothing(halt(stackitem.Null{}), nil))
+	require.Error(t, Nothing(halt(stackitem.Make(1)), nil))
+	require.Error(t, Nothing(nil, errors.New("some")))
+}
+
+func TestResults(t *testing.T) {
+	bi, err := BigInt(halt(stackitem.Make(42)), nil)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(42), bi)
+	_, err = BigInt(halt(stackitem.NewArray(nil)), nil)
+	require.Error(t, err)
+
+	b, err := Bool(halt(stackitem.Make(true)), nil)
+	require.NoError(t, err)
+	require.True(t, b)
+
+	bs, err := Bytes(halt(stackitem.Make([]byte{1, 2})), nil)
+	require.NoError(t, err)
+	require.Equal(t, []byte{1, 2}, bs)
+	bs, err = Bytes(halt(stackitem.Null{}), nil)
+	require.NoError(t, err)
+	require.Nil(t, bs)
+
+	s, err := String(halt(stackitem.Make("str")), nil)
+	require.NoError(t, err)
+	require.Equal(t, "str", s)
+	_, err = String(halt(stackitem.Make([]byte{0xff})), nil)
+	require.Error(t, err)
+
+	u160 := util.Uint160{1, 2, 3}
+	h160, err := Uint160(halt(stackitem.Make(u160.BytesBE())), nil)
+	require.NoError(t, err)
+	require.Equal(t, u160, h160)
+	_, err = Uint160(halt(stackitem.Make([]byte{1})), nil)
+	require.Error(t, err)
+
+	u256 := util.Uint256{1, 2, 3}
+	h256, err := Uint256(halt(stackitem.Make(u256.BytesBE())), nil)
+	require.NoError(t, err)
+	require.Equal(t, u256, h256)
+
+	priv, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	pub, err := PublicKey(halt(stackitem.Make(priv.PublicKey().Bytes())), nil)
+	require.NoError(t, err)
+	require.Equal(t, priv.PublicKey(), pub)
+	_, err = PublicKey(halt(stackitem.Make([]byte{1})), nil)
+	require.Error(t, err)
+
+	arr, err := Array(halt(stackitem.NewStruct([]stackitem.Item{stackitem.Make(1)})), nil)
+	require.NoError(t, err)
+	require.Equal(t, []stackitem.Item{stackitem.Make(1)}, arr)
+	_, err = Array(halt(stackitem.Make(1)), nil)
+	require.Error(t, err)
+
+	m := stackitem.NewMap()
+	m.Add(stackitem.Make(1), stackitem.Make(2))
+	actualMap, err := Map(halt(m), nil)
+	require.NoError(t, err)
+	require.Equal(t, m, actualMap)
+	_, err = Map(halt(stackitem.Make(1)), nil)
+	require.Error(t, err)
+
+	iter := stackitem.NewInterop(result.Iterator{Values: []stackitem.Item{stackitem.Make(1)}})
+	vals, err := Iterator(halt(iter), nil)
+	require.NoError(t, err)
+	require.Equal(t, []stackitem.Item{stackitem.Make(1)}, vals)
+	_, err = Iterator(halt(stackitem.NewInterop(1)), nil)
+	require.Error(t, err)
+	_, err = Iterator(halt(stackitem.Make(1)), nil)
+	require.Error(t, err)
+
+	for _, f := range []func(*result.Invoke, error) (interface{}, error){
+		func(r *result.Invoke, err error) (interface{}, error) { return BigInt(r, err) },
+		func(r *result.Invoke, err error) (interface{}, error) { return Bool(r, err) },
+		func(r *result.Invoke, err error) (interface{}, error) { return Bytes(r, err) },
+		func(r *result.Invoke, err error) (interface{}, error) { return String(r, err) },
+		func(r *result.Invoke, err error) (interface{}, error) { return Uint160(r, err) },
+		func(r *result.Invoke, err error) (interface{}, error) { return Uint256(r, err) },
+		func(r *result.Invoke, err error) (interface{}, error) { return PublicKey(r, err) },
+		func(r *result.Invoke, err error) (interface{}, error) { return Array(r, err) },
+		func(r *result.Invoke, err error) (interface{}, error) { return Map(r, err) },
+		func(r *result.Invoke, err error) (interface{}, error) { return Iterator(r, err) },
+	} {
+		_, err := f(nil, errors.New("some"))
+		require.Error(t, err)
+	}
+}
@@ -20,6 +20,14 @@ type (
 		Address string `json:"address"`
 		Port    string `json:"port"`
 	}
+
+	// BannedPeer represents the banned peer host in `getbannedpeers` RPC call.
+	BannedPeer struct {
+		Address string `json:"address"`
+		// Until is a ban expiration time (Unix timestamp in milliseconds).
+		Until  int64  `json:"until"`
+		Reason string `json:"reason,omitempty"`
+	}
 )
 
 // NewGetPeers creates a new GetPeers structure.
@@ -8,3 +8,19 @@ type RawMempool struct {
 	Verified   []util.Uint256 `json:"verified"`
 	Unverified []util.Uint256 `json:"unverified"`
 }
+
+// MempoolFeeStats represents a result of getmempoolfeestats RPC call.
+type MempoolFeeStats struct {
+	// Count is the number of transactions in the memory pool.
+	Count int `json:"count"`
+	// MinFeePerByte is the minimum fee per byte required by the policy.
+	MinFeePerByte int64           `json:"minfeeperbyte,string"`
+	Percentiles   []FeePercentile `json:"percentiles"`
+}
+
+// FeePercentile is a fee per byte value at the given percentile of
+// transactions in the memory pool.
+type FeePercentile struct {
+	Percentile int   `json:"percentile"`
+	FeePerByte int64 `json:"feeperbyte,string"`
+}
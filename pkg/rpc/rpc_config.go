@@ -10,6 +10,9 @@ type (
 		Address              string `yaml:"Address"`
 		Enabled              bool   `yaml:"Enabled"`
 		EnableCORSWorkaround bool   `yaml:"EnableCORSWorkaround"`
+		// EnablePeerManagement allows to ban and unban peers via RPC,
+		// it's not intended for public RPC nodes.
+		EnablePeerManagement bool `yaml:"EnablePeerManagement"`
 		// MaxGasInvoke is a maximum amount of gas which
 		// can be spent during RPC call.
 		MaxGasInvoke           fixedn.Fixed8 `yaml:"MaxGasInvoke"`
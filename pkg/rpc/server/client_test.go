@@ -4,11 +4,14 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/hex"
+	"math/big"
 	"testing"
+	"time"
 
 	"github.com/nspcc-dev/neo-go/internal/testchain"
 	"github.com/nspcc-dev/neo-go/pkg/core/fee"
 	"github.com/nspcc-dev/neo-go/pkg/core/native/nativenames"
+	"github.com/nspcc-dev/neo-go/pkg/core/state"
 	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
 	"github.com/nspcc-dev/neo-go/pkg/crypto/hash"
 	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
@@ -16,6 +19,8 @@ import (
 	"github.com/nspcc-dev/neo-go/pkg/io"
 	"github.com/nspcc-dev/neo-go/pkg/rpc/client"
 	"github.com/nspcc-dev/neo-go/pkg/rpc/client/nns"
+	"github.com/nspcc-dev/neo-go/pkg/rpc/response/result"
+	"github.com/nspcc-dev/neo-go/pkg/rpc/server/testdata/rubl"
 	"github.com/nspcc-dev/neo-go/pkg/smartcontract"
 	"github.com/nspcc-dev/neo-go/pkg/smartcontract/callflag"
 	"github.com/nspcc-dev/neo-go/pkg/smartcontract/manifest"
@@ -72,6 +77,66 @@ func TestClient_NEP17(t *testing.T) {
 	})
 }
 
+func TestClient_RPCWrapper(t *testing.T) {
+	chain, rpcSrv, httpSrv := initServerWithInMemoryChain(t)
+	defer chain.Close()
+	defer func() { _ = rpcSrv.Shutdown() }()
+
+	c, err := client.New(context.Background(), httpSrv.URL, client.Options{})
+	require.NoError(t, err)
+	require.NoError(t, c.Init())
+
+	priv := testchain.PrivateKeyByID(0)
+	acc := wallet.NewAccountFromPrivateKey(priv)
+	to := testchain.PrivateKeyByID(1).GetScriptHash()
+
+	t.Run("reader", func(t *testing.T) {
+		r := rubl.NewReader(c)
+
+		sym, err := r.Symbol()
+		require.NoError(t, err)
+		require.Equal(t, "RUB", sym)
+
+		d, err := r.Decimals()
+		require.NoError(t, err)
+		require.EqualValues(t, 2, d.Int64())
+
+		s, err := r.TotalSupply()
+		require.NoError(t, err)
+		require.EqualValues(t, 1_000_000, s.Int64())
+
+		b, err := r.BalanceOf(priv.GetScriptHash())
+		require.NoError(t, err)
+		require.EqualValues(t, 877, b.Int64())
+	})
+	t.Run("transfer", func(t *testing.T) {
+		ctr := rubl.New(c, acc, nil)
+		tx, err := ctr.Transfer(priv.GetScriptHash(), to, big.NewInt(10), nil)
+		require.NoError(t, err)
+		require.NoError(t, acc.SignTx(testchain.Network(), tx))
+		require.NoError(t, chain.VerifyTx(tx))
+
+		ic := chain.GetTestVM(trigger.Application, tx, nil)
+		ic.VM.LoadScriptWithFlags(tx.Script, callflag.All)
+		require.NoError(t, ic.VM.Run())
+		require.Equal(t, 1, len(ic.Notifications))
+
+		ev, err := rubl.ParseTransferEvent(&ic.Notifications[0])
+		require.NoError(t, err)
+		require.Equal(t, &rubl.TransferEvent{
+			From:   priv.GetScriptHash(),
+			To:     to,
+			Amount: big.NewInt(10),
+		}, ev)
+
+		evs, err := rubl.TransferEventsFromApplicationLog(&result.ApplicationLog{
+			Executions: []state.Execution{{Events: ic.Notifications}},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []*rubl.TransferEvent{ev}, evs)
+	})
+}
+
 func TestAddNetworkFeeCalculateNetworkFee(t *testing.T) {
 	chain, rpcSrv, httpSrv := initServerWithInMemoryChain(t)
 	defer chain.Close()
@@ -802,6 +867,101 @@ func TestClient_GetNativeContracts(t *testing.T) {
 	require.Equal(t, chain.GetNatives(), cs)
 }
 
+func TestClient_PeerBans(t *testing.T) {
+	chain, rpcSrv, httpSrv := initClearServerWithInMemoryChain(t)
+	defer chain.Close()
+	defer func() { _ = rpcSrv.Shutdown() }()
+
+	c, err := client.New(context.Background(), httpSrv.URL, client.Options{})
+	require.NoError(t, err)
+	require.NoError(t, c.Init())
+
+	bans, err := c.GetBannedPeers()
+	require.NoError(t, err)
+	require.Equal(t, 0, len(bans))
+
+	t.Run("disabled", func(t *testing.T) {
+		require.Error(t, c.BanPeer("10.0.0.1", time.Hour, ""))
+		_, err := c.UnbanPeer("10.0.0.1")
+		require.Error(t, err)
+	})
+
+	rpcSrv.config.EnablePeerManagement = true
+	require.Error(t, c.BanPeer("", 0, ""))
+	require.Error(t, c.BanPeer("10.0.0.1", -time.Hour, ""))
+	require.NoError(t, c.BanPeer("10.0.0.1:10333", time.Hour, "spam"))
+	require.NoError(t, c.BanPeer("10.0.0.2", 0, ""))
+
+	bans, err = c.GetBannedPeers()
+	require.NoError(t, err)
+	require.Equal(t, 2, len(bans))
+	require.Equal(t, "10.0.0.1", bans[0].Address)
+	require.Equal(t, "spam", bans[0].Reason)
+	require.InDelta(t, time.Now().Add(time.Hour).UnixNano()/int64(time.Millisecond), bans[0].Until, float64(time.Minute/time.Millisecond))
+	require.Equal(t, "10.0.0.2", bans[1].Address)
+	require.Equal(t, "banned via RPC", bans[1].Reason)
+	require.InDelta(t, time.Now().Add(24*time.Hour).UnixNano()/int64(time.Millisecond), bans[1].Until, float64(time.Minute/time.Millisecond))
+
+	ok, err := c.UnbanPeer("10.0.0.1")
+	require.NoError(t, err)
+	require.True(t, ok)
+	ok, err = c.UnbanPeer("10.0.0.1")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	bans, err = c.GetBannedPeers()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(bans))
+	require.Equal(t, "10.0.0.2", bans[0].Address)
+}
+
+func TestClient_GetMempoolFeeStats(t *testing.T) {
+	chain, rpcSrv, httpSrv := initClearServerWithInMemoryChain(t)
+	defer chain.Close()
+	defer func() { _ = rpcSrv.Shutdown() }()
+
+	c, err := client.New(context.Background(), httpSrv.URL, client.Options{})
+	require.NoError(t, err)
+	require.NoError(t, c.Init())
+
+	stats, err := c.GetMempoolFeeStats()
+	require.NoError(t, err)
+	require.Equal(t, &result.MempoolFeeStats{
+		MinFeePerByte: chain.FeePerByte(),
+		Percentiles:   []result.FeePercentile{},
+	}, stats)
+
+	mp := chain.GetMemPool()
+	for i := 1; i <= 4; i++ {
+		tx := transaction.New([]byte{byte(opcode.PUSH1)}, 0)
+		tx.Signers = []transaction.Signer{{Account: util.Uint160{1, 2, 3}}}
+		tx.NetworkFee = int64(i * 100 * tx.Size())
+		require.NoError(t, mp.Add(tx, &FeerStub{}))
+	}
+	stats, err = c.GetMempoolFeeStats()
+	require.NoError(t, err)
+	require.Equal(t, 4, stats.Count)
+	require.Equal(t, []result.FeePercentile{
+		{Percentile: 10, FeePerByte: 100},
+		{Percentile: 25, FeePerByte: 100},
+		{Percentile: 50, FeePerByte: 200},
+		{Percentile: 75, FeePerByte: 300},
+		{Percentile: 90, FeePerByte: 400},
+	}, stats.Percentiles)
+
+	stats, err = c.GetMempoolFeeStats(100, 0)
+	require.NoError(t, err)
+	require.Equal(t, []result.FeePercentile{
+		{Percentile: 100, FeePerByte: 400},
+		{Percentile: 0, FeePerByte: 100},
+	}, stats.Percentiles)
+
+	_, err = c.GetMempoolFeeStats(101)
+	require.Error(t, err)
+	_, err = c.GetMempoolFeeStats(-1)
+	require.Error(t, err)
+}
+
 func TestClient_NEP11_ND(t *testing.T) {
 	chain, rpcSrv, httpSrv := initServerWithInMemoryChain(t)
 	defer chain.Close()
@@ -103,10 +103,16 @@ const (
 	maxTransfersLimit = 1000
 )
 
+// defaultFeePercentiles are returned by getmempoolfeestats call if no
+// percentiles are requested.
+var defaultFeePercentiles = []int{10, 25, 50, 75, 90}
+
 var rpcHandlers = map[string]func(*Server, request.Params) (interface{}, *response.Error){
+	"banpeer":                (*Server).banPeer,
 	"calculatenetworkfee":    (*Server).calculateNetworkFee,
 	"findstates":             (*Server).findStates,
 	"getapplicationlog":      (*Server).getApplicationLog,
+	"getbannedpeers":         (*Server).getBannedPeers,
 	"getbestblockhash":       (*Server).getBestBlockHash,
 	"getblock":               (*Server).getBlock,
 	"getblockcount":          (*Server).getBlockCount,
@@ -117,6 +123,7 @@ var rpcHandlers = map[string]func(*Server, request.Params) (interface{}, *respon
 	"getcommittee":           (*Server).getCommittee,
 	"getconnectioncount":     (*Server).getConnectionCount,
 	"getcontractstate":       (*Server).getContractState,
+	"getmempoolfeestats":     (*Server).getMempoolFeeStats,
 	"getnativecontracts":     (*Server).getNativeContracts,
 	"getnep11balances":       (*Server).getNEP11Balances,
 	"getnep11properties":     (*Server).getNEP11Properties,
@@ -142,6 +149,7 @@ var rpcHandlers = map[string]func(*Server, request.Params) (interface{}, *respon
 	"submitblock":            (*Server).submitBlock,
 	"submitnotaryrequest":    (*Server).submitNotaryRequest,
 	"submitoracleresponse":   (*Server).submitOracleResponse,
+	"unbanpeer":              (*Server).unbanPeer,
 	"validateaddress":        (*Server).validateAddress,
 	"verifyproof":            (*Server).verifyProof,
 }
@@ -548,6 +556,66 @@ func (s *Server) getPeers(_ request.Params) (interface{}, *response.Error) {
 	return peers, nil
 }
 
+func (s *Server) getBannedPeers(_ request.Params) (interface{}, *response.Error) {
+	bans := s.coreServer.BannedPeers()
+	res := make([]result.BannedPeer, len(bans))
+	for i := range bans {
+		res[i] = result.BannedPeer{
+			Address: bans[i].Address,
+			Until:   bans[i].Until.UnixNano() / int64(time.Millisecond),
+			Reason:  bans[i].Reason,
+		}
+	}
+	return res, nil
+}
+
+var errPeerManagementDisabled = errors.New("'EnablePeerManagement' setting is disabled")
+
+func (s *Server) banPeer(ps request.Params) (interface{}, *response.Error) {
+	if !s.config.EnablePeerManagement {
+		return nil, response.NewInvalidRequestError("'banpeer' is not supported", errPeerManagementDisabled)
+	}
+	addr, err := ps.Value(0).GetString()
+	if err != nil {
+		return nil, response.ErrInvalidParams
+	}
+	var (
+		dur    = network.DefaultBanDuration
+		reason = "banned via RPC"
+	)
+	if p := ps.Value(1); p != nil {
+		secs, err := p.GetInt()
+		if err != nil || secs < 0 {
+			return nil, response.WrapErrorWithData(response.ErrInvalidParams, errors.New("invalid ban duration"))
+		}
+		if secs != 0 {
+			dur = time.Duration(secs) * time.Second
+		}
+	}
+	if p := ps.Value(2); p != nil {
+		reason, err = p.GetString()
+		if err != nil {
+			return nil, response.ErrInvalidParams
+		}
+	}
+	err = s.coreServer.BanPeer(addr, dur, reason)
+	if err != nil {
+		return nil, response.WrapErrorWithData(response.ErrInvalidParams, err)
+	}
+	return true, nil
+}
+
+func (s *Server) unbanPeer(ps request.Params) (interface{}, *response.Error) {
+	if !s.config.EnablePeerManagement {
+		return nil, response.NewInvalidRequestError("'unbanpeer' is not supported", errPeerManagementDisabled)
+	}
+	addr, err := ps.Value(0).GetString()
+	if err != nil {
+		return nil, response.ErrInvalidParams
+	}
+	return s.coreServer.UnbanPeer(addr), nil
+}
+
 func (s *Server) getRawMempool(reqParams request.Params) (interface{}, *response.Error) {
 	verbose, _ := reqParams.Value(0).GetBoolean()
 	mp := s.chain.GetMemPool()
@@ -565,6 +633,39 @@ func (s *Server) getRawMempool(reqParams request.Params) (interface{}, *response
 	}, nil
 }
 
+// getMempoolFeeStats returns fee per byte percentiles of the pooled
+// transactions, they're given as parameters or defaultFeePercentiles are used.
+func (s *Server) getMempoolFeeStats(reqParams request.Params) (interface{}, *response.Error) {
+	percentiles := defaultFeePercentiles
+	if len(reqParams) != 0 {
+		percentiles = make([]int, len(reqParams))
+		for i := range reqParams {
+			p, err := reqParams[i].GetInt()
+			if err != nil {
+				return nil, response.WrapErrorWithData(response.ErrInvalidParams, err)
+			}
+			if p < 0 || p > 100 {
+				return nil, response.WrapErrorWithData(response.ErrInvalidParams, fmt.Errorf("invalid percentile %d", p))
+			}
+			percentiles[i] = p
+		}
+	}
+	mp := s.chain.GetMemPool()
+	fees := mp.FeePerBytePercentiles(percentiles...)
+	res := result.MempoolFeeStats{
+		Count:         mp.Count(),
+		MinFeePerByte: s.chain.FeePerByte(),
+		Percentiles:   make([]result.FeePercentile, len(fees)), // avoid `null` result
+	}
+	for i := range fees {
+		res.Percentiles[i] = result.FeePercentile{
+			Percentile: percentiles[i],
+			FeePerByte: fees[i],
+		}
+	}
+	return res, nil
+}
+
 func (s *Server) validateAddress(reqParams request.Params) (interface{}, *response.Error) {
 	param, err := reqParams.Value(0).GetString()
 	if err != nil {
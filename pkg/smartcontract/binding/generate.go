@@ -1,14 +1,11 @@
 package binding
 
 import (
-	"bytes"
 	"fmt"
 	"io"
 	"sort"
-	"strconv"
 	"strings"
 	"text/template"
-	"unicode"
 
 	"github.com/nspcc-dev/neo-go/pkg/smartcontract"
 	"github.com/nspcc-dev/neo-go/pkg/smartcontract/callflag"
@@ -149,22 +146,12 @@ func templateFromManifest(cfg Config) (contractTmpl, error) {
 		Hash:         hStr,
 	}
 	if ctr.PackageName == "" {
-		buf := bytes.NewBuffer(make([]byte, 0, len(cfg.Manifest.Name)))
-		for _, r := range cfg.Manifest.Name {
-			if unicode.IsLetter(r) {
-				buf.WriteRune(unicode.ToLower(r))
-			}
-		}
-
-		ctr.PackageName = buf.String()
+		ctr.PackageName = packageFromName(cfg.Manifest.Name)
 	}
 
 	imports := make(map[string]struct{})
-	seen := make(map[string]bool)
-	for _, m := range cfg.Manifest.ABI.Methods {
-		seen[m.Name] = false
-	}
-	for _, m := range cfg.Manifest.ABI.Methods {
+	names := methodNames(cfg.Manifest.ABI.Methods)
+	for i, m := range cfg.Manifest.ABI.Methods {
 		if m.Name[0] == '_' {
 			continue
 		}
@@ -172,23 +159,8 @@ func templateFromManifest(cfg Config) (contractTmpl, error) {
 		imports["github.com/nspcc-dev/neo-go/pkg/interop/contract"] = struct{}{}
 		imports["github.com/nspcc-dev/neo-go/pkg/interop/neogointernal"] = struct{}{}
 
-		// Consider `perform(a)` and `perform(a, b)` methods.
-		// First, try to export the second method with `Perform2` name.
-		// If `perform2` is already in the manifest, use `perform_2` with as many underscores
-		// as needed to eliminate name conflicts. It will produce long names in certain circumstances,
-		// but if the manifest contains lots of similar names with trailing underscores, delicate naming
-		// was probably not the goal.
-		name := m.Name
-		if v, ok := seen[name]; !ok || v {
-			suffix := strconv.Itoa(len(m.Parameters))
-			for ; seen[name]; name = m.Name + suffix {
-				suffix = "_" + suffix
-			}
-		}
-		seen[name] = true
-
 		mtd := methodTmpl{
-			Name:     upperFirst(name),
+			Name:     upperFirst(names[i]),
 			NameABI:  m.Name,
 			CallFlag: callflag.All.String(),
 			Comment:  fmt.Sprintf("invokes `%s` method of contract.", m.Name),
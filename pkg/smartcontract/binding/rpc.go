@@ -0,0 +1,487 @@
+package binding
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/callflag"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/manifest"
+)
+
+const rpcTmpl = `// Code generated by neo-go contract generate-rpcwrapper. DO NOT EDIT.
+
+// Package {{.PackageName}} contains RPC wrappers for {{.ContractName}} contract.
+package {{.PackageName}}
+
+import (
+{{range $m := .StdImports}}	"{{ $m }}"
+{{end}}
+{{range $m := .Imports}}	"{{ $m }}"
+{{end}})
+
+// Hash contains contract hash.
+var Hash = util.Uint160{ {{- .Hash -}} }
+
+// Invoker is used by ContractReader to perform test invocations,
+// *client.Client implements it.
+type Invoker interface {
+	InvokeScript(script []byte, signers []transaction.Signer) (*result.Invoke, error)
+}
+
+// Actor is used by Contract to create transactions, *client.Client
+// implements it.
+type Actor interface {
+	Invoker
+	CreateTxFromScript(script []byte, acc *wallet.Account, sysFee, netFee int64,
+		cosigners []client.SignerAccount) (*transaction.Transaction, error)
+}
+
+// ContractReader implements safe methods of the contract.
+type ContractReader struct {
+	invoker Invoker
+	hash    util.Uint160
+}
+
+// Contract implements all methods of the contract, safe ones are invoked in
+// test mode and other ones create transactions.
+type Contract struct {
+	ContractReader
+	actor     Actor
+	acc       *wallet.Account
+	cosigners []client.SignerAccount
+}
+{{range $e := .Events}}
+// {{.Name}} represents ` + "`{{.NameABI}}`" + ` event emitted by the contract.
+type {{.Name}} struct {
+{{- range $f := .Fields}}
+	{{.Name}} {{.Type}}
+{{- end}}
+}
+{{end}}
+// NewReader creates ContractReader using the given invoker.
+func NewReader(invoker Invoker) *ContractReader {
+	return &ContractReader{invoker: invoker, hash: Hash}
+}
+
+// New creates Contract using the given actor. Transactions are created with
+// acc as a sender (with CalledByEntry scope unless other scope is specified in
+// cosigners) and with additional cosigners.
+func New(actor Actor, acc *wallet.Account, cosigners []client.SignerAccount) *Contract {
+	return &Contract{
+		ContractReader: ContractReader{invoker: actor, hash: Hash},
+		actor:          actor,
+		acc:            acc,
+		cosigners:      cosigners,
+	}
+}
+
+func script(hash util.Uint160, method string, f callflag.CallFlag, args ...interface{}) ([]byte, error) {
+	w := io.NewBufBinWriter()
+	emit.AppCall(w.BinWriter, hash, method, f, args...)
+	if w.Err != nil {
+		return nil, fmt.Errorf("failed to create script: %w", w.Err)
+	}
+	return w.Bytes(), nil
+}
+
+func (c *ContractReader) call(method string, f callflag.CallFlag, args ...interface{}) (*result.Invoke, error) {
+	s, err := script(c.hash, method, f, args...)
+	if err != nil {
+		return nil, err
+	}
+	return c.invoker.InvokeScript(s, nil)
+}
+
+func (c *Contract) tx(method string, f callflag.CallFlag, args ...interface{}) (*transaction.Transaction, error) {
+	s, err := script(c.hash, method, f, args...)
+	if err != nil {
+		return nil, err
+	}
+	sender, err := address.StringToUint160(c.acc.Address)
+	if err != nil {
+		return nil, fmt.Errorf("bad account address: %w", err)
+	}
+	signer := client.SignerAccount{
+		Signer: transaction.Signer{
+			Account: sender,
+			Scopes:  transaction.CalledByEntry,
+		},
+		Account: c.acc,
+	}
+	return c.actor.CreateTxFromScript(s, c.acc, -1, 0, append([]client.SignerAccount{signer}, c.cosigners...))
+}
+{{range $m := .Safe}}
+// {{.Name}} {{.Comment}}
+func (c *ContractReader) {{.Name}}({{range $index, $arg := .Arguments -}}
+	{{- if ne $index 0}}, {{end}}{{.Name}} {{.Type}}
+	{{- end}}) {{if .ReturnType}}({{.ReturnType}}, error){{else}}error{{end}} {
+	return unwrap.{{.Unwrap}}(c.call("{{.NameABI}}", callflag.{{.CallFlag}}
+		{{- range $arg := .Arguments -}}, {{.Expr}}{{end}}))
+}
+{{end}}{{range $m := .Unsafe}}
+// {{.Name}} {{.Comment}}
+func (c *Contract) {{.Name}}({{range $index, $arg := .Arguments -}}
+	{{- if ne $index 0}}, {{end}}{{.Name}} {{.Type}}
+	{{- end}}) (*transaction.Transaction, error) {
+	return c.tx("{{.NameABI}}", callflag.{{.CallFlag}}
+		{{- range $arg := .Arguments -}}, {{.Expr}}{{end}})
+}
+{{end}}{{range $e := .Events}}
+// Parse{{.Name}} parses ` + "`{{.NameABI}}`" + ` event from the notification.
+func Parse{{.Name}}(e *state.NotificationEvent) (*{{.Name}}, error) {
+	if e.Name != "{{.NameABI}}" {
+		return nil, fmt.Errorf("unexpected event name: %s", e.Name)
+	}
+	items := e.Item.Value().([]stackitem.Item)
+	if len(items) != {{len .Fields}} {
+		return nil, fmt.Errorf("wrong number of event parameters: %d, expected {{len .Fields}}", len(items))
+	}
+	res := new({{.Name}})
+{{- if .NeedErr}}
+	var err error
+{{- end}}
+{{- range $i, $f := .Fields}}
+{{- if .Unwrap}}
+	res.{{.Name}}, err = unwrap.{{.Unwrap}}(items[{{$i}}])
+	if err != nil {
+		return nil, fmt.Errorf("invalid {{.NameABI}} parameter: %w", err)
+	}
+{{- else}}
+	res.{{.Name}} = items[{{$i}}]
+{{- end}}
+{{- end}}
+	return res, nil
+}
+
+// {{.Name}}sFromApplicationLog returns all ` + "`{{.NameABI}}`" + ` events emitted
+// by the contract from the application log.
+func {{.Name}}sFromApplicationLog(log *result.ApplicationLog) ([]*{{.Name}}, error) {
+	var res []*{{.Name}}
+	for _, ex := range log.Executions {
+		for i := range ex.Events {
+			if ex.Events[i].ScriptHash != Hash || ex.Events[i].Name != "{{.NameABI}}" {
+				continue
+			}
+			ev, err := Parse{{.Name}}(&ex.Events[i])
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, ev)
+		}
+	}
+	return res, nil
+}
+{{end}}`
+
+type (
+	rpcTmplData struct {
+		PackageName  string
+		ContractName string
+		StdImports   []string
+		Imports      []string
+		Hash         string
+		Safe         []rpcMethodTmpl
+		Unsafe       []rpcMethodTmpl
+		Events       []rpcEventTmpl
+	}
+
+	rpcMethodTmpl struct {
+		Name       string
+		NameABI    string
+		CallFlag   string
+		Comment    string
+		Arguments  []rpcParamTmpl
+		ReturnType string
+		Unwrap     string
+	}
+
+	rpcParamTmpl struct {
+		Name string
+		Type string
+		Expr string
+	}
+
+	rpcEventTmpl struct {
+		Name    string
+		NameABI string
+		NeedErr bool
+		Fields  []rpcFieldTmpl
+	}
+
+	rpcFieldTmpl struct {
+		Name    string
+		NameABI string
+		Type    string
+		Unwrap  string
+	}
+)
+
+const (
+	rpcClientPkg = "github.com/nspcc-dev/neo-go/pkg/rpc/client"
+	unwrapPkg    = rpcClientPkg + "/unwrap"
+)
+
+// rpcReserved contains names that can't be used for method parameters in the
+// generated code.
+var rpcReserved = map[string]bool{
+	"c": true, "unwrap": true, "callflag": true, "util": true, "big": true,
+	"keys": true, "stackitem": true, "transaction": true, "client": true,
+	"result": true, "state": true, "wallet": true, "emit": true, "io": true,
+	"fmt": true, "script": true, "address": true,
+}
+
+// GenerateRPC writes Go file containing RPC client bindings for the contract
+// to the `cfg.Output`. Safe methods are invoked in test mode and have their
+// results converted to Go types, other methods create transactions. Every
+// event gets a structure and a parser.
+func GenerateRPC(cfg Config) error {
+	data := rpcTmplData{
+		PackageName:  cfg.Package,
+		ContractName: cfg.Manifest.Name,
+	}
+	if data.PackageName == "" {
+		data.PackageName = packageFromName(cfg.Manifest.Name)
+	}
+	hash := make([]string, len(cfg.Hash))
+	for i, b := range cfg.Hash.BytesBE() {
+		hash[i] = fmt.Sprintf("0x%02x", b)
+	}
+	data.Hash = strings.Join(hash, ", ")
+
+	imports := map[string]bool{
+		"fmt": true,
+		"github.com/nspcc-dev/neo-go/pkg/core/transaction":       true,
+		"github.com/nspcc-dev/neo-go/pkg/encoding/address":       true,
+		"github.com/nspcc-dev/neo-go/pkg/io":                     true,
+		rpcClientPkg:                                             true,
+		"github.com/nspcc-dev/neo-go/pkg/rpc/response/result":    true,
+		"github.com/nspcc-dev/neo-go/pkg/smartcontract/callflag": true,
+		"github.com/nspcc-dev/neo-go/pkg/util":                   true,
+		"github.com/nspcc-dev/neo-go/pkg/vm/emit":                true,
+		"github.com/nspcc-dev/neo-go/pkg/wallet":                 true,
+	}
+	names := methodNames(cfg.Manifest.ABI.Methods)
+	for i, m := range cfg.Manifest.ABI.Methods {
+		if m.Name[0] == '_' {
+			continue
+		}
+		mtd := rpcMethodTmpl{
+			Name:     upperFirst(names[i]),
+			NameABI:  m.Name,
+			CallFlag: callflag.All.String(),
+		}
+		if f, ok := cfg.CallFlags[m.Name]; ok {
+			mtd.CallFlag = f.String()
+		} else if m.Safe {
+			mtd.CallFlag = callflag.ReadOnly.String()
+		}
+		for j, p := range m.Parameters {
+			name := p.Name
+			if name == "" {
+				name = "arg" + strconv.Itoa(j)
+			}
+			if token.IsKeyword(name) || rpcReserved[name] {
+				name += "Arg"
+			}
+			typ, pkg := scTypeToRPCParam(p.Type)
+			if pkg != "" {
+				imports[pkg] = true
+			}
+			expr := name
+			if p.Type == smartcontract.PublicKeyType {
+				expr += ".Bytes()"
+			}
+			mtd.Arguments = append(mtd.Arguments, rpcParamTmpl{Name: name, Type: typ, Expr: expr})
+		}
+		if m.Safe {
+			mtd.Comment = fmt.Sprintf("invokes `%s` method of the contract in test mode.", m.Name)
+			var pkg string
+			mtd.ReturnType, mtd.Unwrap, pkg = scTypeToRPCResult(m.ReturnType)
+			if pkg != "" {
+				imports[pkg] = true
+			}
+			imports[unwrapPkg] = true
+			data.Safe = append(data.Safe, mtd)
+		} else {
+			mtd.Comment = fmt.Sprintf("creates a transaction invoking `%s` method of the contract.\n// The transaction is not signed.", m.Name)
+			data.Unsafe = append(data.Unsafe, mtd)
+		}
+	}
+	seen := make(map[string]bool)
+	for _, e := range cfg.Manifest.ABI.Events {
+		name := toIdentifier(e.Name) + "Event"
+		for seen[name] {
+			name += "_"
+		}
+		seen[name] = true
+		ev := rpcEventTmpl{Name: name, NameABI: e.Name}
+		fields := make(map[string]bool)
+		for j, p := range e.Parameters {
+			fName := toIdentifier(p.Name)
+			if fName == "" || fields[fName] {
+				fName += "Arg" + strconv.Itoa(j)
+			}
+			fields[fName] = true
+			typ, unwrap, pkg := scTypeToRPCResult(p.Type)
+			if pkg != "" {
+				imports[pkg] = true
+			}
+			if unwrap != "Item" {
+				unwrap = "To" + unwrap
+				imports[unwrapPkg] = true
+				ev.NeedErr = true
+			} else {
+				unwrap = ""
+			}
+			ev.Fields = append(ev.Fields, rpcFieldTmpl{Name: fName, NameABI: p.Name, Type: typ, Unwrap: unwrap})
+		}
+		imports["github.com/nspcc-dev/neo-go/pkg/core/state"] = true
+		imports["github.com/nspcc-dev/neo-go/pkg/vm/stackitem"] = true
+		data.Events = append(data.Events, ev)
+	}
+	for imp := range imports {
+		if strings.Contains(imp, ".") {
+			data.Imports = append(data.Imports, imp)
+		} else {
+			data.StdImports = append(data.StdImports, imp)
+		}
+	}
+	sort.Strings(data.StdImports)
+	sort.Strings(data.Imports)
+
+	tmp, err := template.New("rpc").Parse(rpcTmpl)
+	if err != nil {
+		return err
+	}
+	buf := bytes.NewBuffer(nil)
+	if err := tmp.Execute(buf, data); err != nil {
+		return err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = cfg.Output.Write(src)
+	return err
+}
+
+// scTypeToRPCParam returns Go type used for method parameters of the given
+// type along with the package to import.
+func scTypeToRPCParam(typ smartcontract.ParamType) (string, string) {
+	switch typ {
+	case smartcontract.BoolType:
+		return "bool", ""
+	case smartcontract.IntegerType:
+		return "*big.Int", "math/big"
+	case smartcontract.ByteArrayType, smartcontract.SignatureType:
+		return "[]byte", ""
+	case smartcontract.StringType:
+		return "string", ""
+	case smartcontract.Hash160Type:
+		return "util.Uint160", ""
+	case smartcontract.Hash256Type:
+		return "util.Uint256", ""
+	case smartcontract.PublicKeyType:
+		return "*keys.PublicKey", "github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	case smartcontract.ArrayType:
+		return "[]interface{}", ""
+	default:
+		return "interface{}", ""
+	}
+}
+
+// scTypeToRPCResult returns Go type used for method results and event
+// parameters of the given type, the name of unwrap function converting to it
+// and the package to import.
+func scTypeToRPCResult(typ smartcontract.ParamType) (string, string, string) {
+	switch typ {
+	case smartcontract.BoolType:
+		return "bool", "Bool", ""
+	case smartcontract.IntegerType:
+		return "*big.Int", "BigInt", "math/big"
+	case smartcontract.ByteArrayType, smartcontract.SignatureType:
+		return "[]byte", "Bytes", ""
+	case smartcontract.StringType:
+		return "string", "String", ""
+	case smartcontract.Hash160Type:
+		return "util.Uint160", "Uint160", ""
+	case smartcontract.Hash256Type:
+		return "util.Uint256", "Uint256", ""
+	case smartcontract.PublicKeyType:
+		return "*keys.PublicKey", "PublicKey", "github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	case smartcontract.ArrayType:
+		return "[]stackitem.Item", "Array", "github.com/nspcc-dev/neo-go/pkg/vm/stackitem"
+	case smartcontract.MapType:
+		return "*stackitem.Map", "Map", "github.com/nspcc-dev/neo-go/pkg/vm/stackitem"
+	case smartcontract.InteropInterfaceType:
+		return "[]stackitem.Item", "Iterator", "github.com/nspcc-dev/neo-go/pkg/vm/stackitem"
+	case smartcontract.VoidType:
+		return "", "Nothing", ""
+	default:
+		return "stackitem.Item", "Item", "github.com/nspcc-dev/neo-go/pkg/vm/stackitem"
+	}
+}
+
+// methodNames returns Go names for the methods. Consider `perform(a)` and
+// `perform(a, b)` methods. First, try to export the second method with
+// `Perform2` name. If `perform2` is already in the manifest, use `perform_2`
+// with as many underscores as needed to eliminate name conflicts. It will
+// produce long names in certain circumstances, but if the manifest contains
+// lots of similar names with trailing underscores, delicate naming was
+// probably not the goal.
+func methodNames(methods []manifest.Method) []string {
+	res := make([]string, len(methods))
+	seen := make(map[string]bool)
+	for _, m := range methods {
+		seen[m.Name] = false
+	}
+	for i, m := range methods {
+		if m.Name[0] == '_' {
+			continue
+		}
+		name := m.Name
+		if v, ok := seen[name]; !ok || v {
+			suffix := strconv.Itoa(len(m.Parameters))
+			for ; seen[name]; name = m.Name + suffix {
+				suffix = "_" + suffix
+			}
+		}
+		seen[name] = true
+		res[i] = name
+	}
+	return res
+}
+
+// packageFromName makes package name from the contract name.
+func packageFromName(name string) string {
+	buf := bytes.NewBuffer(make([]byte, 0, len(name)))
+	for _, r := range name {
+		if unicode.IsLetter(r) {
+			buf.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return buf.String()
+}
+
+// toIdentifier makes exported Go identifier from s dropping all characters
+// that can't be used in identifiers.
+func toIdentifier(s string) string {
+	buf := bytes.NewBuffer(make([]byte, 0, len(s)))
+	for _, r := range s {
+		if unicode.IsLetter(r) || r == '_' || (unicode.IsDigit(r) && buf.Len() != 0) {
+			buf.WriteRune(r)
+		}
+	}
+	if buf.Len() == 0 {
+		return ""
+	}
+	return upperFirst(buf.String())
+}
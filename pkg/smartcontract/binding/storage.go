@@ -0,0 +1,348 @@
+package binding
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neo-go/pkg/encoding/bigint"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/vm/stackitem"
+)
+
+const storageTmpl = `// Code generated by neo-go contract generate-storage. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+{{range $m := .Imports}}	"{{ $m }}"
+{{end}})
+{{range $s := .Items}}
+// get{{.Name}} returns the value of ` + "`{{.ID}}`" + ` storage item{{if .KeyType}} with the specified key{{end}}.
+func get{{.Name}}(ctx storage.Context{{if .KeyType}}, key {{.KeyType}}{{end}}) {{.ValueType}} {
+	v := storage.Get(ctx, {{.KeyExpr}})
+	if v == nil {
+		return {{.Zero}}
+	}
+	return {{.GetExpr}}
+}
+
+// put{{.Name}} stores the value of ` + "`{{.ID}}`" + ` storage item{{if .KeyType}} with the specified key{{end}}.
+func put{{.Name}}(ctx storage.Context{{if .KeyType}}, key {{.KeyType}}{{end}}, value {{.ValueType}}) {
+	storage.Put(ctx, {{.KeyExpr}}, {{.PutExpr}})
+}
+
+// delete{{.Name}} deletes ` + "`{{.ID}}`" + ` storage item{{if .KeyType}} with the specified key{{end}}.
+func delete{{.Name}}(ctx storage.Context{{if .KeyType}}, key {{.KeyType}}{{end}}) {
+	storage.Delete(ctx, {{.KeyExpr}})
+}
+{{end}}`
+
+type (
+	// StoragePrefix is a prefix of contract storage keys. In YAML it can be
+	// specified either as a number (single byte prefix) or as a string. Strings
+	// starting with `0x` are hex-encoded. In JSON it's always a hex string.
+	StoragePrefix []byte
+
+	// StorageItem describes a group of contract storage items sharing the
+	// same key prefix.
+	StorageItem struct {
+		// Name is used to name accessor functions.
+		Name   string        `json:"name" yaml:"name"`
+		Prefix StoragePrefix `json:"prefix" yaml:"prefix"`
+		// Key is the type of key following the prefix. AnyType (the default)
+		// means there is no key and the prefix is the whole storage key.
+		Key smartcontract.ParamType `json:"key,omitempty" yaml:"key,omitempty"`
+		// Value is the type of stored value. Values of Any, Array and Map
+		// types are serialized with StdLib.
+		Value smartcontract.ParamType `json:"value" yaml:"value"`
+	}
+
+	// StorageConfig contains parameters for the generated storage accessors.
+	StorageConfig struct {
+		Package string
+		Items   []StorageItem
+		Output  io.Writer
+	}
+
+	// DecodedStorageItem is a contract storage item decoded according to
+	// the storage schema. Integers are decoded to *big.Int, booleans to bool,
+	// strings to string, byte arrays and signatures to []byte, hashes to
+	// util.Uint160 and util.Uint256, public keys to *keys.PublicKey and
+	// serialized values to stackitem.Item.
+	DecodedStorageItem struct {
+		Name  string
+		Key   interface{}
+		Value interface{}
+	}
+
+	storageTmplData struct {
+		Package string
+		Imports []string
+		Items   []storageItemTmpl
+	}
+
+	storageItemTmpl struct {
+		ID        string
+		Name      string
+		KeyType   string
+		KeyExpr   string
+		ValueType string
+		Zero      string
+		GetExpr   string
+		PutExpr   string
+	}
+)
+
+const interopPkg = "github.com/nspcc-dev/neo-go/pkg/interop"
+
+// UnmarshalYAML implements the YAML Unmarshaler interface.
+func (p *StoragePrefix) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var n int
+	if err := unmarshal(&n); err == nil {
+		if n < 0 || n > 255 {
+			return fmt.Errorf("prefix %d doesn't fit into a byte", n)
+		}
+		*p = StoragePrefix{byte(n)}
+		return nil
+	}
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	if strings.HasPrefix(s, "0x") {
+		b, err := hex.DecodeString(s[2:])
+		if err != nil {
+			return fmt.Errorf("invalid hex prefix: %w", err)
+		}
+		*p = b
+		return nil
+	}
+	*p = StoragePrefix(s)
+	return nil
+}
+
+// MarshalYAML implements the YAML Marshaler interface.
+func (p StoragePrefix) MarshalYAML() (interface{}, error) {
+	return "0x" + hex.EncodeToString(p), nil
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (p StoragePrefix) MarshalJSON() ([]byte, error) {
+	return json.Marshal(hex.EncodeToString(p))
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (p *StoragePrefix) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return err
+	}
+	*p = b
+	return nil
+}
+
+// isValidStorageKey returns true if typ can be used as a storage key type.
+func isValidStorageKey(typ smartcontract.ParamType) bool {
+	switch typ {
+	case smartcontract.AnyType, smartcontract.BoolType, smartcontract.IntegerType,
+		smartcontract.ByteArrayType, smartcontract.StringType, smartcontract.Hash160Type,
+		smartcontract.Hash256Type, smartcontract.PublicKeyType:
+		return true
+	default:
+		return false
+	}
+}
+
+// isValidStorageValue returns true if typ can be used as a storage value type.
+func isValidStorageValue(typ smartcontract.ParamType) bool {
+	switch typ {
+	case smartcontract.InteropInterfaceType, smartcontract.VoidType:
+		return false
+	default:
+		return typ.String() != ""
+	}
+}
+
+// isSerialized returns true if values of typ are stored serialized.
+func isSerialized(typ smartcontract.ParamType) bool {
+	return typ == smartcontract.AnyType || typ == smartcontract.ArrayType || typ == smartcontract.MapType
+}
+
+// ValidateStorage checks storage schema for errors. Item names must be valid
+// Go identifiers, prefixes must be non-empty and none of them can be a prefix
+// of another one.
+func ValidateStorage(items []StorageItem) error {
+	names := make(map[string]bool, len(items))
+	for i, it := range items {
+		if !token.IsIdentifier(it.Name) {
+			return fmt.Errorf("storage item #%d: invalid name %q", i, it.Name)
+		}
+		if names[it.Name] {
+			return fmt.Errorf("storage item %s: duplicate name", it.Name)
+		}
+		names[it.Name] = true
+		if len(it.Prefix) == 0 {
+			return fmt.Errorf("storage item %s: empty prefix", it.Name)
+		}
+		if !isValidStorageKey(it.Key) {
+			return fmt.Errorf("storage item %s: unsupported key type %s", it.Name, it.Key)
+		}
+		if !isValidStorageValue(it.Value) {
+			return fmt.Errorf("storage item %s: unsupported value type %s", it.Name, it.Value)
+		}
+		for _, other := range items[:i] {
+			if bytes.HasPrefix(it.Prefix, other.Prefix) || bytes.HasPrefix(other.Prefix, it.Prefix) {
+				return fmt.Errorf("storage item %s: prefix conflicts with %s", it.Name, other.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// GenerateStorage writes Go file containing typed accessors for the storage
+// items to the `cfg.Output`. Accessors are unexported, so that they don't
+// become contract methods.
+func GenerateStorage(cfg StorageConfig) error {
+	if err := ValidateStorage(cfg.Items); err != nil {
+		return err
+	}
+	data := storageTmplData{Package: cfg.Package}
+	imports := map[string]bool{interopPkg + "/storage": true}
+	for _, it := range cfg.Items {
+		var prefix string
+		for _, b := range it.Prefix {
+			prefix += fmt.Sprintf("\\x%02x", b)
+		}
+		item := storageItemTmpl{
+			ID:        it.Name,
+			Name:      upperFirst(it.Name),
+			KeyExpr:   `"` + prefix + `"`,
+			ValueType: scTypeToGo(it.Value),
+			GetExpr:   "v.(" + scTypeToGo(it.Value) + ")",
+			PutExpr:   "value",
+		}
+		if it.Key != smartcontract.AnyType {
+			item.KeyType = scTypeToGo(it.Key)
+			switch it.Key {
+			case smartcontract.StringType:
+				item.KeyExpr += " + key"
+			case smartcontract.BoolType, smartcontract.IntegerType:
+				item.KeyExpr += " + string(convert.ToBytes(key))"
+				imports[interopPkg+"/convert"] = true
+			default:
+				item.KeyExpr += " + string(key)"
+			}
+		}
+		switch it.Value {
+		case smartcontract.BoolType:
+			item.Zero = "false"
+		case smartcontract.IntegerType:
+			item.Zero = "0"
+		case smartcontract.StringType:
+			item.Zero = `""`
+		default:
+			item.Zero = "nil"
+		}
+		if isSerialized(it.Value) {
+			item.GetExpr = "std.Deserialize(v.([]byte))"
+			if it.Value != smartcontract.AnyType {
+				item.GetExpr += ".(" + item.ValueType + ")"
+			}
+			item.PutExpr = "std.Serialize(value)"
+			imports[interopPkg+"/native/std"] = true
+		}
+		for _, typ := range []smartcontract.ParamType{it.Key, it.Value} {
+			if strings.HasPrefix(scTypeToGo(typ), "interop.") {
+				imports[interopPkg] = true
+			}
+		}
+		data.Items = append(data.Items, item)
+	}
+	for imp := range imports {
+		data.Imports = append(data.Imports, imp)
+	}
+	sort.Strings(data.Imports)
+
+	tmp, err := template.New("storage").Parse(storageTmpl)
+	if err != nil {
+		return err
+	}
+	buf := bytes.NewBuffer(nil)
+	if err := tmp.Execute(buf, data); err != nil {
+		return err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = cfg.Output.Write(src)
+	return err
+}
+
+// DecodeStorage decodes contract storage item with the specified key and value
+// according to the storage schema.
+func DecodeStorage(items []StorageItem, key, value []byte) (*DecodedStorageItem, error) {
+	for _, it := range items {
+		if !bytes.HasPrefix(key, it.Prefix) {
+			continue
+		}
+		res := &DecodedStorageItem{Name: it.Name}
+		var err error
+		if it.Key != smartcontract.AnyType {
+			res.Key, err = decodeStorageValue(it.Key, key[len(it.Prefix):])
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s key: %w", it.Name, err)
+			}
+		} else if len(key) != len(it.Prefix) {
+			return nil, fmt.Errorf("unexpected %s key length: %d", it.Name, len(key))
+		}
+		res.Value, err = decodeStorageValue(it.Value, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value: %w", it.Name, err)
+		}
+		return res, nil
+	}
+	return nil, errors.New("storage item doesn't match any schema prefix")
+}
+
+func decodeStorageValue(typ smartcontract.ParamType, data []byte) (interface{}, error) {
+	switch typ {
+	case smartcontract.BoolType:
+		for _, b := range data {
+			if b != 0 {
+				return true, nil
+			}
+		}
+		return false, nil
+	case smartcontract.IntegerType:
+		return bigint.FromBytes(data), nil
+	case smartcontract.StringType:
+		return string(data), nil
+	case smartcontract.Hash160Type:
+		return util.Uint160DecodeBytesBE(data)
+	case smartcontract.Hash256Type:
+		return util.Uint256DecodeBytesBE(data)
+	case smartcontract.PublicKeyType:
+		return keys.NewPublicKeyFromBytes(data, elliptic.P256())
+	case smartcontract.AnyType, smartcontract.ArrayType, smartcontract.MapType:
+		return stackitem.Deserialize(data)
+	default:
+		return data, nil
+	}
+}
@@ -0,0 +1,110 @@
+package binding
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func TestStoragePrefixYAML(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected StoragePrefix
+	}{
+		{"1", StoragePrefix{1}},
+		{"0x0a", StoragePrefix{10}},
+		{"255", StoragePrefix{255}},
+		{`"0x0a0b"`, StoragePrefix{10, 11}},
+		{"abc", StoragePrefix("abc")},
+		{`"12"`, StoragePrefix("12")},
+	}
+	for _, tc := range testCases {
+		var p StoragePrefix
+		require.NoError(t, yaml.Unmarshal([]byte(tc.value), &p), tc.value)
+		require.Equal(t, tc.expected, p, tc.value)
+	}
+	for _, s := range []string{"256", "-1", `"0xzz"`, "[1]"} {
+		var p StoragePrefix
+		require.Error(t, yaml.Unmarshal([]byte(s), &p), s)
+	}
+
+	data, err := yaml.Marshal(StoragePrefix{10, 11})
+	require.NoError(t, err)
+	var p StoragePrefix
+	require.NoError(t, yaml.Unmarshal(data, &p))
+	require.Equal(t, StoragePrefix{10, 11}, p)
+}
+
+func TestStorageItemJSON(t *testing.T) {
+	it := StorageItem{Name: "balance", Prefix: StoragePrefix{1}, Key: smartcontract.Hash160Type, Value: smartcontract.IntegerType}
+	data, err := json.Marshal(it)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"name":"balance","prefix":"01","key":"Hash160","value":"Integer"}`, string(data))
+
+	var actual StorageItem
+	require.NoError(t, json.Unmarshal(data, &actual))
+	require.Equal(t, it, actual)
+
+	data, err = json.Marshal(StorageItem{Name: "total", Prefix: StoragePrefix{2}, Value: smartcontract.IntegerType})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"name":"total","prefix":"02","value":"Integer"}`, string(data))
+}
+
+func TestValidateStorage(t *testing.T) {
+	valid := StorageItem{Name: "a", Prefix: StoragePrefix{1}, Key: smartcontract.IntegerType, Value: smartcontract.MapType}
+	require.NoError(t, ValidateStorage([]StorageItem{valid}))
+
+	testCases := map[string][]StorageItem{
+		"invalid name":      {{Name: "1a", Prefix: StoragePrefix{1}}},
+		"duplicate name":    {valid, {Name: "a", Prefix: StoragePrefix{2}}},
+		"empty prefix":      {{Name: "a"}},
+		"invalid key":       {{Name: "a", Prefix: StoragePrefix{1}, Key: smartcontract.ArrayType}},
+		"invalid value":     {{Name: "a", Prefix: StoragePrefix{1}, Value: smartcontract.VoidType}},
+		"same prefix":       {valid, {Name: "b", Prefix: StoragePrefix{1}}},
+		"overlapped prefix": {valid, {Name: "b", Prefix: StoragePrefix{1, 2}}},
+	}
+	for name, items := range testCases {
+		require.Error(t, ValidateStorage(items), name)
+	}
+}
+
+func TestDecodeStorage(t *testing.T) {
+	pub, err := keys.NewPublicKeyFromString("03b209fd4f53a7170ea4444e0cb0a6bb6a53c2bd016926989cf85f9b0fba17a70c")
+	require.NoError(t, err)
+	h256 := util.Uint256{1, 2, 3}
+	items := []StorageItem{
+		{Name: "total", Prefix: StoragePrefix("t"), Value: smartcontract.IntegerType},
+		{Name: "keys", Prefix: StoragePrefix("k"), Key: smartcontract.PublicKeyType, Value: smartcontract.Hash256Type},
+		{Name: "raw", Prefix: StoragePrefix("r"), Key: smartcontract.ByteArrayType, Value: smartcontract.ByteArrayType},
+		{Name: "flags", Prefix: StoragePrefix("f"), Key: smartcontract.BoolType, Value: smartcontract.BoolType},
+	}
+
+	d, err := DecodeStorage(items, []byte("t"), []byte{0xff, 0x00})
+	require.NoError(t, err)
+	require.Equal(t, &DecodedStorageItem{Name: "total", Value: big.NewInt(255)}, d)
+
+	d, err = DecodeStorage(items, append([]byte("k"), pub.Bytes()...), h256.BytesBE())
+	require.NoError(t, err)
+	require.Equal(t, &DecodedStorageItem{Name: "keys", Key: pub, Value: h256}, d)
+
+	d, err = DecodeStorage(items, []byte("rab"), []byte{1, 2})
+	require.NoError(t, err)
+	require.Equal(t, &DecodedStorageItem{Name: "raw", Key: []byte("ab"), Value: []byte{1, 2}}, d)
+
+	d, err = DecodeStorage(items, []byte{'f', 1}, []byte{})
+	require.NoError(t, err)
+	require.Equal(t, &DecodedStorageItem{Name: "flags", Key: true, Value: false}, d)
+
+	_, err = DecodeStorage(items, []byte("tt"), []byte{1})
+	require.Error(t, err)
+	_, err = DecodeStorage(items, []byte("k1"), []byte{1})
+	require.Error(t, err)
+	_, err = DecodeStorage(items, []byte("x"), []byte{1})
+	require.Error(t, err)
+}
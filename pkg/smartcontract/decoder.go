@@ -0,0 +1,373 @@
+package smartcontract
+
+import (
+	"crypto/elliptic"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/vm/stackitem"
+)
+
+// TypeHint describes the expected type of stack item. Unlike ParamType it can
+// also describe types of array elements and map keys and values.
+type TypeHint struct {
+	Type ParamType
+	// Key is the type of map keys, Any is assumed if it's nil.
+	Key *TypeHint
+	// Value is the type of array elements or map values, Any is assumed if
+	// it's nil.
+	Value *TypeHint
+}
+
+// MapElement is a key-value pair of decoded map. Maps are decoded into slices
+// of MapElement because their keys can be of non-comparable types and their
+// order is significant.
+type MapElement struct {
+	Key   interface{}
+	Value interface{}
+}
+
+// ErrRecursiveItem is returned when stack item contains a reference to itself.
+var ErrRecursiveItem = errors.New("recursive stack item")
+
+// NewTypeHint returns type hint for the given parameter type.
+func NewTypeHint(typ ParamType) *TypeHint {
+	return &TypeHint{Type: typ}
+}
+
+// ParseTypeHint parses user-provided type hint. It accepts everything
+// ParseParamType does and additionally composite types in the form of
+// `array<T>` or `map<K,V>` (where T, K and V are type hints themselves),
+// e.g. `array<hash160>` or `map<string,array<int>>`.
+func ParseTypeHint(s string) (*TypeHint, error) {
+	h, rest, err := parseTypeHint(strings.TrimSpace(s))
+	if err != nil {
+		return nil, err
+	}
+	if rest != "" {
+		return nil, fmt.Errorf("unexpected trailing characters in type: %s", rest)
+	}
+	return h, nil
+}
+
+func parseTypeHint(s string) (*TypeHint, string, error) {
+	end := strings.IndexAny(s, "<,>")
+	if end < 0 {
+		end = len(s)
+	}
+	typ, err := ParseParamType(strings.TrimSpace(s[:end]))
+	if err != nil {
+		return nil, "", err
+	}
+	h := NewTypeHint(typ)
+	s = strings.TrimSpace(s[end:])
+	if !strings.HasPrefix(s, "<") {
+		return h, s, nil
+	}
+	switch typ {
+	case ArrayType:
+		h.Value, s, err = parseTypeHint(s[1:])
+	case MapType:
+		h.Key, s, err = parseTypeHint(s[1:])
+		if err != nil {
+			return nil, "", err
+		}
+		if !strings.HasPrefix(s, ",") {
+			return nil, "", fmt.Errorf("missing map value type")
+		}
+		h.Value, s, err = parseTypeHint(s[1:])
+	default:
+		return nil, "", fmt.Errorf("%s type can't have parameters", typ)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	if !strings.HasPrefix(s, ">") {
+		return nil, "", errors.New("missing '>' in type")
+	}
+	return h, strings.TrimSpace(s[1:]), nil
+}
+
+// String implements fmt.Stringer interface, it returns type hint in the form
+// accepted by ParseTypeHint.
+func (h *TypeHint) String() string {
+	switch {
+	case h == nil:
+		return AnyType.String()
+	case h.Type == ArrayType && h.Value != nil:
+		return fmt.Sprintf("%s<%s>", h.Type, h.Value)
+	case h.Type == MapType && (h.Key != nil || h.Value != nil):
+		return fmt.Sprintf("%s<%s,%s>", h.Type, h.Key, h.Value)
+	default:
+		return h.Type.String()
+	}
+}
+
+// DecodeStackItem converts stack item into a Go value according to the given
+// type (usually it's the return type of contract method from its manifest).
+// See DecodeStackItemWithHint for details.
+func DecodeStackItem(item stackitem.Item, typ ParamType) (interface{}, error) {
+	return DecodeStackItemWithHint(item, NewTypeHint(typ))
+}
+
+// DecodeStackItemWithHint converts stack item into a Go value according to
+// the given type hint. The following conversions are performed:
+//
+//	Any -> depends on the item type (see below)
+//	Boolean -> bool
+//	Integer -> *big.Int
+//	ByteArray, Signature -> []byte
+//	String -> string
+//	Hash160 -> util.Uint160
+//	Hash256 -> util.Uint256
+//	PublicKey -> *keys.PublicKey
+//	Array -> []interface{}
+//	Map -> []MapElement
+//	InteropInterface -> the value stored in the interop item
+//	Void -> nil
+//
+// Null item is decoded into nil for any type except Boolean and Integer. Items
+// of Any type are decoded into bool, *big.Int, []byte, []interface{},
+// []MapElement or interop value depending on the item type. Nil hint is
+// treated as Any.
+func DecodeStackItemWithHint(item stackitem.Item, hint *TypeHint) (interface{}, error) {
+	return decodeStackItem(item, hint, make(map[stackitem.Item]bool))
+}
+
+func decodeStackItem(item stackitem.Item, hint *TypeHint, seen map[stackitem.Item]bool) (interface{}, error) {
+	typ := AnyType
+	if hint != nil {
+		typ = hint.Type
+	}
+	if typ == VoidType {
+		return nil, nil
+	}
+	if _, ok := item.(stackitem.Null); ok && typ != BoolType && typ != IntegerType {
+		return nil, nil
+	}
+	switch typ {
+	case AnyType:
+		return decodeAny(item, seen)
+	case BoolType:
+		return item.TryBool()
+	case IntegerType:
+		return item.TryInteger()
+	case ByteArrayType, SignatureType:
+		b, err := item.TryBytes()
+		if err != nil {
+			return nil, err
+		}
+		if typ == SignatureType && len(b) != 64 {
+			return nil, fmt.Errorf("invalid signature length: %d", len(b))
+		}
+		return b, nil
+	case StringType:
+		b, err := item.TryBytes()
+		if err != nil {
+			return nil, err
+		}
+		if !utf8.Valid(b) {
+			return nil, errors.New("not a valid UTF-8 string")
+		}
+		return string(b), nil
+	case Hash160Type:
+		b, err := item.TryBytes()
+		if err != nil {
+			return nil, err
+		}
+		return util.Uint160DecodeBytesBE(b)
+	case Hash256Type:
+		b, err := item.TryBytes()
+		if err != nil {
+			return nil, err
+		}
+		return util.Uint256DecodeBytesBE(b)
+	case PublicKeyType:
+		b, err := item.TryBytes()
+		if err != nil {
+			return nil, err
+		}
+		return keys.NewPublicKeyFromBytes(b, elliptic.P256())
+	case ArrayType:
+		var elem *TypeHint
+		if hint != nil {
+			elem = hint.Value
+		}
+		switch item.(type) {
+		case *stackitem.Array, *stackitem.Struct:
+		default:
+			return nil, fmt.Errorf("%s item is not an array", item.Type())
+		}
+		return decodeArray(item, elem, seen)
+	case MapType:
+		var key, value *TypeHint
+		if hint != nil {
+			key, value = hint.Key, hint.Value
+		}
+		m, ok := item.(*stackitem.Map)
+		if !ok {
+			return nil, fmt.Errorf("%s item is not a map", item.Type())
+		}
+		return decodeMap(m, key, value, seen)
+	case InteropInterfaceType:
+		if _, ok := item.(*stackitem.Interop); !ok {
+			return nil, fmt.Errorf("%s item is not an interop interface", item.Type())
+		}
+		return item.Value(), nil
+	default:
+		return nil, fmt.Errorf("unsupported type %s", typ)
+	}
+}
+
+func decodeAny(item stackitem.Item, seen map[stackitem.Item]bool) (interface{}, error) {
+	switch it := item.(type) {
+	case stackitem.Bool:
+		return it.Value().(bool), nil
+	case *stackitem.BigInteger:
+		return new(big.Int).Set(it.Value().(*big.Int)), nil
+	case *stackitem.ByteArray, *stackitem.Buffer:
+		return it.TryBytes()
+	case *stackitem.Array, *stackitem.Struct:
+		return decodeArray(it, nil, seen)
+	case *stackitem.Map:
+		return decodeMap(it, nil, nil, seen)
+	case *stackitem.Interop:
+		return it.Value(), nil
+	case *stackitem.Pointer:
+		return it.Position(), nil
+	default:
+		return nil, fmt.Errorf("unsupported stack item type: %s", item.Type())
+	}
+}
+
+func decodeArray(item stackitem.Item, elem *TypeHint, seen map[stackitem.Item]bool) ([]interface{}, error) {
+	if seen[item] {
+		return nil, ErrRecursiveItem
+	}
+	seen[item] = true
+	defer delete(seen, item)
+
+	items := item.Value().([]stackitem.Item)
+	res := make([]interface{}, len(items))
+	for i := range items {
+		v, err := decodeStackItem(items[i], elem, seen)
+		if err != nil {
+			return nil, fmt.Errorf("element %d: %w", i, err)
+		}
+		res[i] = v
+	}
+	return res, nil
+}
+
+func decodeMap(m *stackitem.Map, key, value *TypeHint, seen map[stackitem.Item]bool) ([]MapElement, error) {
+	if seen[m] {
+		return nil, ErrRecursiveItem
+	}
+	seen[m] = true
+	defer delete(seen, m)
+
+	elems := m.Value().([]stackitem.MapElement)
+	res := make([]MapElement, len(elems))
+	for i := range elems {
+		k, err := decodeStackItem(elems[i].Key, key, seen)
+		if err != nil {
+			return nil, fmt.Errorf("key %d: %w", i, err)
+		}
+		v, err := decodeStackItem(elems[i].Value, value, seen)
+		if err != nil {
+			return nil, fmt.Errorf("value %d: %w", i, err)
+		}
+		res[i] = MapElement{Key: k, Value: v}
+	}
+	return res, nil
+}
+
+// FormatStackItem returns human-readable representation of stack item decoded
+// according to the given type hint (see DecodeStackItemWithHint). Nil hint
+// is treated as Any. Integers are printed in decimal form, strings are quoted,
+// hashes are printed as 0x-prefixed LE hex strings, public keys and byte
+// arrays are hex-encoded (with printable byte arrays of Any type also shown
+// as strings), arrays and maps are printed one element per line with nested
+// elements indented.
+func FormatStackItem(item stackitem.Item, hint *TypeHint) (string, error) {
+	v, err := DecodeStackItemWithHint(item, hint)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	formatValue(&sb, v, "")
+	return sb.String(), nil
+}
+
+func formatValue(sb *strings.Builder, v interface{}, indent string) {
+	const step = "    "
+
+	switch val := v.(type) {
+	case nil:
+		sb.WriteString("null")
+	case bool:
+		fmt.Fprintf(sb, "%t", val)
+	case *big.Int:
+		sb.WriteString(val.String())
+	case string:
+		fmt.Fprintf(sb, "%q", val)
+	case []byte:
+		sb.WriteString(hex.EncodeToString(val))
+		if len(val) != 0 && isPrintable(val) {
+			fmt.Fprintf(sb, " (%q)", val)
+		}
+	case util.Uint160:
+		sb.WriteString("0x" + val.StringLE())
+	case util.Uint256:
+		sb.WriteString("0x" + val.StringLE())
+	case *keys.PublicKey:
+		sb.WriteString(hex.EncodeToString(val.Bytes()))
+	case []interface{}:
+		if len(val) == 0 {
+			sb.WriteString("[]")
+			return
+		}
+		sb.WriteString("[\n")
+		for i := range val {
+			sb.WriteString(indent + step)
+			formatValue(sb, val[i], indent+step)
+			sb.WriteString(",\n")
+		}
+		sb.WriteString(indent + "]")
+	case []MapElement:
+		if len(val) == 0 {
+			sb.WriteString("{}")
+			return
+		}
+		sb.WriteString("{\n")
+		for i := range val {
+			sb.WriteString(indent + step)
+			formatValue(sb, val[i].Key, indent+step)
+			sb.WriteString(": ")
+			formatValue(sb, val[i].Value, indent+step)
+			sb.WriteString(",\n")
+		}
+		sb.WriteString(indent + "}")
+	default:
+		fmt.Fprintf(sb, "%v", val)
+	}
+}
+
+func isPrintable(b []byte) bool {
+	if !utf8.Valid(b) {
+		return false
+	}
+	for _, r := range string(b) {
+		if !unicode.IsPrint(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,179 @@
+package smartcontract
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/vm/stackitem"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTypeHint(t *testing.T) {
+	good := map[string]string{
+		"int":                        "Integer",
+		" Hash160 ":                  "Hash160",
+		"array":                      "Array",
+		"array<hash160>":             "Array<Hash160>",
+		"map<string, array<int>>":    "Map<String,Array<Integer>>",
+		"array<map<any,publickey>> ": "Array<Map<Any,PublicKey>>",
+	}
+	for s, expected := range good {
+		h, err := ParseTypeHint(s)
+		require.NoError(t, err, s)
+		require.Equal(t, expected, h.String(), s)
+	}
+
+	bad := []string{"", "unknown", "int<int>", "array<int", "map<int>", "array<int>>", "array<int>x"}
+	for _, s := range bad {
+		_, err := ParseTypeHint(s)
+		require.Error(t, err, s)
+	}
+}
+
+func TestDecodeStackItem(t *testing.T) {
+	h160 := util.Uint160{1, 2, 3}
+	h256 := util.Uint256{4, 5, 6}
+	priv, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	pub := priv.PublicKey()
+	sig := make([]byte, 64)
+
+	testCases := []struct {
+		item     stackitem.Item
+		typ      ParamType
+		expected interface{}
+	}{
+		{stackitem.NewBool(true), BoolType, true},
+		{stackitem.NewBigInteger(big.NewInt(1)), BoolType, true},
+		{stackitem.Null{}, BoolType, false},
+		{stackitem.NewBigInteger(big.NewInt(42)), IntegerType, big.NewInt(42)},
+		{stackitem.NewByteArray([]byte{1, 2}), ByteArrayType, []byte{1, 2}},
+		{stackitem.NewByteArray(sig), SignatureType, sig},
+		{stackitem.NewByteArray([]byte("str")), StringType, "str"},
+		{stackitem.NewByteArray(h160.BytesBE()), Hash160Type, h160},
+		{stackitem.NewByteArray(h256.BytesBE()), Hash256Type, h256},
+		{stackitem.NewByteArray(pub.Bytes()), PublicKeyType, pub},
+		{stackitem.Null{}, Hash160Type, nil},
+		{stackitem.NewBigInteger(big.NewInt(1)), VoidType, nil},
+		{stackitem.NewInterop(42), InteropInterfaceType, 42},
+		{stackitem.NewBigInteger(big.NewInt(7)), AnyType, big.NewInt(7)},
+		{stackitem.NewBuffer([]byte{3}), AnyType, []byte{3}},
+		{stackitem.NewArray([]stackitem.Item{stackitem.NewBool(false), stackitem.Null{}}), ArrayType,
+			[]interface{}{false, nil}},
+		{stackitem.NewStruct([]stackitem.Item{stackitem.NewBigInteger(big.NewInt(1))}), AnyType,
+			[]interface{}{big.NewInt(1)}},
+	}
+	for _, tc := range testCases {
+		actual, err := DecodeStackItem(tc.item, tc.typ)
+		require.NoError(t, err, tc.typ)
+		require.Equal(t, tc.expected, actual, tc.typ)
+	}
+
+	errCases := []struct {
+		item stackitem.Item
+		typ  ParamType
+	}{
+		{stackitem.NewByteArray([]byte{1, 2}), SignatureType},
+		{stackitem.NewByteArray([]byte{0xff}), StringType},
+		{stackitem.NewByteArray([]byte{1, 2}), Hash160Type},
+		{stackitem.NewByteArray([]byte{1, 2}), Hash256Type},
+		{stackitem.NewByteArray([]byte{1, 2}), PublicKeyType},
+		{stackitem.NewByteArray([]byte{1, 2}), ArrayType},
+		{stackitem.NewArray(nil), MapType},
+		{stackitem.NewBool(true), InteropInterfaceType},
+		{stackitem.NewArray(nil), IntegerType},
+		{stackitem.Null{}, IntegerType},
+	}
+	for _, tc := range errCases {
+		_, err := DecodeStackItem(tc.item, tc.typ)
+		require.Error(t, err, tc.typ)
+	}
+}
+
+func TestDecodeStackItemWithHint(t *testing.T) {
+	h160 := util.Uint160{1, 2, 3}
+
+	t.Run("nested", func(t *testing.T) {
+		m := stackitem.NewMap()
+		m.Add(stackitem.NewByteArray([]byte("owners")),
+			stackitem.NewArray([]stackitem.Item{stackitem.NewByteArray(h160.BytesBE())}))
+		hint, err := ParseTypeHint("map<string,array<hash160>>")
+		require.NoError(t, err)
+		actual, err := DecodeStackItemWithHint(m, hint)
+		require.NoError(t, err)
+		require.Equal(t, []MapElement{{Key: "owners", Value: []interface{}{h160}}}, actual)
+
+		hint, err = ParseTypeHint("map<string,array<publickey>>")
+		require.NoError(t, err)
+		_, err = DecodeStackItemWithHint(m, hint)
+		require.Error(t, err)
+	})
+	t.Run("nil hint", func(t *testing.T) {
+		actual, err := DecodeStackItemWithHint(stackitem.NewByteArray([]byte{1}), nil)
+		require.NoError(t, err)
+		require.Equal(t, []byte{1}, actual)
+	})
+	t.Run("recursive", func(t *testing.T) {
+		arr := stackitem.NewArray(nil)
+		arr.Append(arr)
+		_, err := DecodeStackItemWithHint(arr, nil)
+		require.ErrorIs(t, err, ErrRecursiveItem)
+
+		m := stackitem.NewMap()
+		m.Add(stackitem.NewBigInteger(big.NewInt(1)), m)
+		_, err = DecodeStackItem(m, MapType)
+		require.ErrorIs(t, err, ErrRecursiveItem)
+	})
+	t.Run("same item twice", func(t *testing.T) {
+		inner := stackitem.NewArray([]stackitem.Item{stackitem.NewBool(true)})
+		arr := stackitem.NewArray([]stackitem.Item{inner, inner})
+		actual, err := DecodeStackItem(arr, ArrayType)
+		require.NoError(t, err)
+		require.Equal(t, []interface{}{[]interface{}{true}, []interface{}{true}}, actual)
+	})
+}
+
+func TestFormatStackItem(t *testing.T) {
+	h160 := util.Uint160{1, 2, 3}
+
+	m := stackitem.NewMap()
+	m.Add(stackitem.NewByteArray([]byte("owner")), stackitem.NewByteArray(h160.BytesBE()))
+	m.Add(stackitem.NewByteArray([]byte("list")), stackitem.NewArray([]stackitem.Item{
+		stackitem.NewBigInteger(big.NewInt(-5)),
+		stackitem.NewArray(nil),
+		stackitem.Null{},
+	}))
+
+	actual, err := FormatStackItem(m, nil)
+	require.NoError(t, err)
+	require.Equal(t, `{
+    6f776e6572 ("owner"): `+h160.StringBE()+`,
+    6c697374 ("list"): [
+        -5,
+        [],
+        null,
+    ],
+}`, actual)
+
+	hint, err := ParseTypeHint("map<string,any>")
+	require.NoError(t, err)
+	actual, err = FormatStackItem(stackitem.NewMapWithValue([]stackitem.MapElement{{
+		Key:   stackitem.NewByteArray([]byte("owner")),
+		Value: stackitem.NewByteArray(h160.BytesBE()),
+	}}), hint)
+	require.NoError(t, err)
+	require.Equal(t, "{\n    \"owner\": "+h160.StringBE()+",\n}", actual)
+
+	actual, err = FormatStackItem(stackitem.NewByteArray(h160.BytesBE()), NewTypeHint(Hash160Type))
+	require.NoError(t, err)
+	require.Equal(t, "0x"+h160.StringLE(), actual)
+
+	actual, err = FormatStackItem(stackitem.NewMap(), nil)
+	require.NoError(t, err)
+	require.Equal(t, "{}", actual)
+
+	_, err = FormatStackItem(stackitem.NewBool(true), NewTypeHint(Hash160Type))
+	require.Error(t, err)
+}
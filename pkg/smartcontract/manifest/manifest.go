@@ -23,6 +23,14 @@ const (
 	NEP11Payable = "NEP-11-Payable"
 	// NEP17Payable represents the name of contract interface which can receive NEP-17 tokens.
 	NEP17Payable = "NEP-17-Payable"
+	// NEP24StandardName represents the name of NEP-24 (NFT royalty) standard.
+	NEP24StandardName = "NEP-24"
+	// NEP26StandardName represents the name of NEP-26 standard, it's the same
+	// as NEP11Payable.
+	NEP26StandardName = "NEP-26"
+	// NEP27StandardName represents the name of NEP-27 standard, it's the same
+	// as NEP17Payable.
+	NEP27StandardName = "NEP-27"
 )
 
 // Manifest represens contract metadata.
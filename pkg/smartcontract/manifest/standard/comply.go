@@ -18,27 +18,41 @@ var (
 	ErrSafeMethodMismatch    = errors.New("method has wrong safe flag")
 )
 
-var checks = map[string][]*Standard{
+var checks = Standards{
 	manifest.NEP11StandardName: {nep11NonDivisible, nep11Divisible},
 	manifest.NEP17StandardName: {nep17},
 	manifest.NEP11Payable:      {nep11payable},
 	manifest.NEP17Payable:      {nep17payable},
+	manifest.NEP24StandardName: {nep24},
+	manifest.NEP26StandardName: {nep11payable},
+	manifest.NEP27StandardName: {nep17payable},
 }
 
-// Check checks if manifest complies with all provided standards.
-// Currently only NEP-17 is supported.
+// Check checks if manifest complies with all provided built-in standards.
+// Unknown standards are ignored.
 func Check(m *manifest.Manifest, standards ...string) error {
-	return check(m, true, standards...)
+	return checks.Check(m, standards...)
 }
 
 // CheckABI is similar to Check but doesn't check parameter names.
 func CheckABI(m *manifest.Manifest, standards ...string) error {
-	return check(m, false, standards...)
+	return checks.CheckABI(m, standards...)
 }
 
-func check(m *manifest.Manifest, checkNames bool, standards ...string) error {
-	for i := range standards {
-		ss, ok := checks[standards[i]]
+// Check checks if manifest complies with all provided standards from s.
+// Unknown standards are ignored.
+func (s Standards) Check(m *manifest.Manifest, standards ...string) error {
+	return s.check(m, true, standards...)
+}
+
+// CheckABI is similar to Check but doesn't check parameter names.
+func (s Standards) CheckABI(m *manifest.Manifest, standards ...string) error {
+	return s.check(m, false, standards...)
+}
+
+func (s Standards) check(m *manifest.Manifest, checkNames bool, standards ...string) error {
+	for _, name := range standards {
+		ss, ok := s[name]
 		if ok {
 			var err error
 			for i := range ss {
@@ -47,7 +61,7 @@ func check(m *manifest.Manifest, checkNames bool, standards ...string) error {
 				}
 			}
 			if err != nil {
-				return fmt.Errorf("manifest is not compliant with '%s': %w", standards[i], err)
+				return fmt.Errorf("manifest is not compliant with '%s': %w", name, err)
 			}
 		}
 	}
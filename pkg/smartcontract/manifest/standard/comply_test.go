@@ -177,3 +177,24 @@ func TestOptional(t *testing.T) {
 		require.NoError(t, Comply(&actual, &m))
 	})
 }
+
+func TestCheckNEP24(t *testing.T) {
+	m := manifest.NewManifest("Test")
+	require.Error(t, Check(m, manifest.NEP24StandardName))
+
+	m.ABI.Methods = append(m.ABI.Methods, nep24.ABI.Methods...)
+	require.NoError(t, Check(m, manifest.NEP24StandardName))
+
+	m.ABI.Methods[0].Safe = false
+	require.True(t, errors.Is(Check(m, manifest.NEP24StandardName), ErrSafeMethodMismatch))
+}
+
+func TestCheckPayable(t *testing.T) {
+	m := manifest.NewManifest("Test")
+	m.ABI.Methods = append(m.ABI.Methods, nep17payable.ABI.Methods...)
+	require.NoError(t, CheckABI(m, manifest.NEP17Payable, manifest.NEP27StandardName))
+	require.Error(t, CheckABI(m, manifest.NEP26StandardName))
+
+	m.ABI.Methods = append(m.ABI.Methods, nep11payable.ABI.Methods...)
+	require.NoError(t, CheckABI(m, manifest.NEP11Payable, manifest.NEP26StandardName))
+}
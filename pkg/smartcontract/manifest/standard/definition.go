@@ -0,0 +1,122 @@
+package standard
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/manifest"
+	"gopkg.in/yaml.v2"
+)
+
+// Definition is a declarative description of a standard. It's a manifest
+// fragment with mandatory methods and events in its ABI which can also contain
+// optional methods and the name of the base standard. Several definitions with
+// the same name are variants of the same standard, contract complies with the
+// standard if it complies with any of them.
+type Definition struct {
+	Name string `json:"name" yaml:"name"`
+	// Base is the name of the standard this one extends (if any).
+	Base     string            `json:"base,omitempty" yaml:"base,omitempty"`
+	ABI      manifest.ABI      `json:"abi" yaml:"abi"`
+	Optional []manifest.Method `json:"optional,omitempty" yaml:"optional,omitempty"`
+}
+
+// Standards is a set of standards indexed by name, each standard can have
+// several variants.
+type Standards map[string][]*Standard
+
+// Builtin returns a new set of standards supported out of the box: NEP-11,
+// NEP-17, NEP-24 and payable interfaces (NEP-26 and NEP-27).
+func Builtin() Standards {
+	s := make(Standards, len(checks))
+	for name, ss := range checks {
+		s[name] = append([]*Standard(nil), ss...)
+	}
+	return s
+}
+
+// Add adds standards described by defs to s. Base standards are looked up in s
+// and every variant of the base standard produces a variant of the new one.
+// Standards already present in s can't be redefined.
+func (s Standards) Add(defs ...Definition) error {
+	added := make(map[string]bool)
+	for i := range defs {
+		d := &defs[i]
+		if d.Name == "" {
+			return fmt.Errorf("standard #%d: empty name", i)
+		}
+		if _, ok := s[d.Name]; ok && !added[d.Name] {
+			return fmt.Errorf("standard '%s' is already defined", d.Name)
+		}
+		if err := d.isValid(); err != nil {
+			return fmt.Errorf("standard '%s': %w", d.Name, err)
+		}
+		st := Standard{
+			Manifest: manifest.Manifest{ABI: d.ABI},
+			Optional: d.Optional,
+		}
+		if d.Base == "" {
+			s[d.Name] = append(s[d.Name], &st)
+		} else {
+			bases, ok := s[d.Base]
+			if !ok || d.Base == d.Name {
+				return fmt.Errorf("standard '%s': unknown base standard '%s'", d.Name, d.Base)
+			}
+			for _, b := range bases {
+				v := st
+				v.Base = b
+				s[d.Name] = append(s[d.Name], &v)
+			}
+		}
+		added[d.Name] = true
+	}
+	return nil
+}
+
+func (d *Definition) isValid() error {
+	if len(d.ABI.Methods) == 0 && len(d.ABI.Events) == 0 {
+		return errors.New("no methods or events")
+	}
+	for i := range d.ABI.Methods {
+		if err := d.ABI.Methods[i].IsValid(); err != nil {
+			return err
+		}
+	}
+	for i := range d.ABI.Events {
+		if err := d.ABI.Events[i].IsValid(); err != nil {
+			return err
+		}
+	}
+	for i := range d.Optional {
+		if err := d.Optional[i].IsValid(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadDefinitions reads standard definitions from the file. Files with `.json`
+// extension are parsed as JSON, all others as YAML. File can contain either a
+// single definition or a list of them.
+func LoadDefinitions(path string) ([]Definition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	unmarshal := yaml.Unmarshal
+	if filepath.Ext(path) == ".json" {
+		unmarshal = json.Unmarshal
+	}
+	var defs []Definition
+	if err := unmarshal(data, &defs); err == nil {
+		return defs, nil
+	}
+	var d Definition
+	if err := unmarshal(data, &d); err != nil {
+		return nil, fmt.Errorf("can't parse standard definition: %w", err)
+	}
+	return []Definition{d}, nil
+}
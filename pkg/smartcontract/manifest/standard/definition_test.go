@@ -0,0 +1,183 @@
+package standard
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/manifest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadDefinitions(t *testing.T) {
+	expected := Definition{
+		Name: "NEP-X",
+		Base: manifest.NEP17StandardName,
+		ABI: manifest.ABI{
+			Methods: []manifest.Method{{
+				Name:       "mint",
+				Parameters: []manifest.Parameter{{Name: "to", Type: smartcontract.Hash160Type}},
+				ReturnType: smartcontract.BoolType,
+			}},
+			Events: []manifest.Event{{
+				Name:       "Mint",
+				Parameters: []manifest.Parameter{{Name: "to", Type: smartcontract.Hash160Type}},
+			}},
+		},
+		Optional: []manifest.Method{{
+			Name:       "burn",
+			Parameters: []manifest.Parameter{{Name: "from", Type: smartcontract.Hash160Type}},
+			ReturnType: smartcontract.VoidType,
+		}},
+	}
+	testCases := map[string]string{
+		"single.json": `{
+  "name": "NEP-X",
+  "base": "NEP-17",
+  "abi": {
+    "methods": [{"name": "mint", "parameters": [{"name": "to", "type": "Hash160"}], "returntype": "Boolean"}],
+    "events": [{"name": "Mint", "parameters": [{"name": "to", "type": "Hash160"}]}]
+  },
+  "optional": [{"name": "burn", "parameters": [{"name": "from", "type": "Hash160"}], "returntype": "Void"}]
+}`,
+		"list.json": `[{
+  "name": "NEP-X",
+  "base": "NEP-17",
+  "abi": {
+    "methods": [{"name": "mint", "parameters": [{"name": "to", "type": "Hash160"}], "returntype": "Boolean"}],
+    "events": [{"name": "Mint", "parameters": [{"name": "to", "type": "Hash160"}]}]
+  },
+  "optional": [{"name": "burn", "parameters": [{"name": "from", "type": "Hash160"}], "returntype": "Void"}]
+}]`,
+		"single.yml": `name: NEP-X
+base: NEP-17
+abi:
+  methods:
+    - name: mint
+      parameters:
+        - name: to
+          type: Hash160
+      returntype: Boolean
+  events:
+    - name: Mint
+      parameters:
+        - name: to
+          type: Hash160
+optional:
+  - name: burn
+    parameters:
+      - name: from
+        type: Hash160
+    returntype: Void
+`,
+		"list.yaml": `- name: NEP-X
+  base: NEP-17
+  abi:
+    methods:
+      - name: mint
+        parameters:
+          - name: to
+            type: Hash160
+        returntype: Boolean
+    events:
+      - name: Mint
+        parameters:
+          - name: to
+            type: Hash160
+  optional:
+    - name: burn
+      parameters:
+        - name: from
+          type: Hash160
+      returntype: Void
+`,
+	}
+	dir := t.TempDir()
+	for name, data := range testCases {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(data), os.ModePerm))
+		defs, err := LoadDefinitions(path)
+		require.NoError(t, err, name)
+		require.Equal(t, []Definition{expected}, defs, name)
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := LoadDefinitions(filepath.Join(dir, "unknown.yml"))
+		require.Error(t, err)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		path := filepath.Join(dir, "invalid.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"name": 1}`), os.ModePerm))
+		_, err := LoadDefinitions(path)
+		require.Error(t, err)
+	})
+}
+
+func TestStandardsAdd(t *testing.T) {
+	mint := manifest.Method{
+		Name:       "mint",
+		Parameters: []manifest.Parameter{{Name: "to", Type: smartcontract.Hash160Type}},
+		ReturnType: smartcontract.BoolType,
+	}
+	t.Run("base variants", func(t *testing.T) {
+		s := Builtin()
+		require.NoError(t, s.Add(Definition{
+			Name: "NEP-X",
+			Base: manifest.NEP11StandardName,
+			ABI:  manifest.ABI{Methods: []manifest.Method{mint}},
+		}))
+		require.Equal(t, 2, len(s["NEP-X"]))
+		require.Same(t, nep11NonDivisible, s["NEP-X"][0].Base)
+		require.Same(t, nep11Divisible, s["NEP-X"][1].Base)
+
+		m := manifest.NewManifest("Test")
+		m.ABI.Methods = append(m.ABI.Methods, decimalTokenBase.ABI.Methods...)
+		m.ABI.Methods = append(m.ABI.Methods, nep11Base.ABI.Methods...)
+		m.ABI.Methods = append(m.ABI.Methods, nep11NonDivisible.ABI.Methods...)
+		m.ABI.Events = append(m.ABI.Events, nep11Base.ABI.Events...)
+		require.NoError(t, s.Check(m, manifest.NEP11StandardName))
+		require.True(t, errors.Is(s.Check(m, "NEP-X"), ErrMethodMissing))
+
+		m.ABI.Methods = append(m.ABI.Methods, mint)
+		require.NoError(t, s.Check(m, "NEP-X"))
+
+		// Built-in standards are not affected.
+		_, ok := Builtin()["NEP-X"]
+		require.False(t, ok)
+	})
+	t.Run("variants", func(t *testing.T) {
+		s := Builtin()
+		burn := manifest.Method{Name: "burn", ReturnType: smartcontract.VoidType}
+		require.NoError(t, s.Add(
+			Definition{Name: "NEP-X", ABI: manifest.ABI{Methods: []manifest.Method{mint}}},
+			Definition{Name: "NEP-X", ABI: manifest.ABI{Methods: []manifest.Method{burn}}},
+		))
+		m := manifest.NewManifest("Test")
+		m.ABI.Methods = []manifest.Method{burn}
+		require.NoError(t, s.Check(m, "NEP-X"))
+		m.ABI.Methods = []manifest.Method{mint}
+		require.NoError(t, s.Check(m, "NEP-X"))
+		m.ABI.Methods = nil
+		require.Error(t, s.Check(m, "NEP-X"))
+
+		require.Error(t, s.Add(Definition{Name: "NEP-X", ABI: manifest.ABI{Methods: []manifest.Method{mint}}}))
+	})
+	t.Run("errors", func(t *testing.T) {
+		testCases := map[string]Definition{
+			"empty name":     {ABI: manifest.ABI{Methods: []manifest.Method{mint}}},
+			"redefinition":   {Name: manifest.NEP17StandardName, ABI: manifest.ABI{Methods: []manifest.Method{mint}}},
+			"empty":          {Name: "NEP-X"},
+			"unknown base":   {Name: "NEP-X", Base: "NEP-Y", ABI: manifest.ABI{Methods: []manifest.Method{mint}}},
+			"self base":      {Name: "NEP-X", Base: "NEP-X", ABI: manifest.ABI{Methods: []manifest.Method{mint}}},
+			"invalid method": {Name: "NEP-X", ABI: manifest.ABI{Methods: []manifest.Method{{}}}},
+			"invalid event":  {Name: "NEP-X", ABI: manifest.ABI{Events: []manifest.Event{{}}}},
+			"invalid optional": {Name: "NEP-X", ABI: manifest.ABI{Methods: []manifest.Method{mint}},
+				Optional: []manifest.Method{{}}},
+		}
+		for name, d := range testCases {
+			require.Error(t, Builtin().Add(d), name)
+		}
+	})
+}
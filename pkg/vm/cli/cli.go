@@ -22,6 +22,7 @@ import (
 	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
 	"github.com/nspcc-dev/neo-go/pkg/encoding/address"
 	"github.com/nspcc-dev/neo-go/pkg/encoding/bigint"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract"
 	"github.com/nspcc-dev/neo-go/pkg/smartcontract/callflag"
 	"github.com/nspcc-dev/neo-go/pkg/smartcontract/manifest"
 	"github.com/nspcc-dev/neo-go/pkg/util"
@@ -37,6 +38,7 @@ const (
 	exitFuncKey         = "exitFunc"
 	readlineInstanceKey = "readlineKey"
 	printLogoKey        = "printLogoKey"
+	returnTypeKey       = "returnType"
 	boolType            = "bool"
 	boolFalse           = "false"
 	boolTrue            = "true"
@@ -72,6 +74,25 @@ var commands = []cli.Command{
 		Description: "Show evaluation stack contents",
 		Action:      handleXStack,
 	},
+	{
+		Name:      "pretty",
+		Usage:     "Show evaluation stack contents in human-readable form",
+		UsageText: `pretty [<type>...]`,
+		Description: `pretty [<type>...]
+
+Show evaluation stack items (starting from the top one) decoded according to
+their types.
+<type> is an optional type of the respective stack item, it can be any contract
+        parameter type like 'hash160' or 'publickey' or a composite type like
+        'array<hash160>' or 'map<string,int>'. If no types are specified, the
+        top item is decoded using the return type of the method executed with
+        'run' (if any) and all other items are decoded according to their
+        stack item types.
+
+Example:
+> pretty array<hash160> int`,
+		Action: handlePretty,
+	},
 	{
 		Name:        "istack",
 		Usage:       "Show invocation stack contents",
@@ -295,6 +316,7 @@ func NewWithConfig(printLogotype bool, onExit func(int), c *readline.Config) *VM
 		exitFuncKey:         onExit,
 		readlineInstanceKey: l,
 		printLogoKey:        printLogotype,
+		returnTypeKey:       new(smartcontract.ParamType),
 	}
 	changePrompt(vmcli.shell)
 	return &vmcli
@@ -330,6 +352,14 @@ func setManifestInContext(app *cli.App, m *manifest.Manifest) {
 	*old = *m
 }
 
+func getReturnTypeFromContext(app *cli.App) smartcontract.ParamType {
+	return *app.Metadata[returnTypeKey].(*smartcontract.ParamType)
+}
+
+func setReturnTypeInContext(app *cli.App, typ smartcontract.ParamType) {
+	*app.Metadata[returnTypeKey].(*smartcontract.ParamType) = typ
+}
+
 func checkVMIsReady(app *cli.App) bool {
 	v := getVMFromContext(app)
 	if v == nil || !v.Ready() {
@@ -397,6 +427,35 @@ func handleXStack(c *cli.Context) error {
 	return nil
 }
 
+func handlePretty(c *cli.Context) error {
+	v := getVMFromContext(c.App)
+	args := c.Args()
+	hints := make([]*smartcontract.TypeHint, len(args))
+	for i := range args {
+		h, err := smartcontract.ParseTypeHint(args[i])
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidParameter, err)
+		}
+		hints[i] = h
+	}
+	if len(hints) == 0 {
+		hints = append(hints, smartcontract.NewTypeHint(getReturnTypeFromContext(c.App)))
+	}
+	estack := v.Estack()
+	for i := 0; i < estack.Len(); i++ {
+		var hint *smartcontract.TypeHint
+		if i < len(hints) {
+			hint = hints[i]
+		}
+		s, err := smartcontract.FormatStackItem(estack.Peek(i).Item(), hint)
+		if err != nil {
+			return fmt.Errorf("item %d: %w", i, err)
+		}
+		fmt.Fprintf(c.App.Writer, "%d: %s\n", i, s)
+	}
+	return nil
+}
+
 func handleSlots(c *cli.Context) error {
 	v := getVMFromContext(c.App)
 	vmCtx := v.Context()
@@ -433,6 +492,7 @@ func handleLoadNEF(c *cli.Context) error {
 	}
 	fmt.Fprintf(c.App.Writer, "READY: loaded %d instructions\n", v.Context().LenInstr())
 	setManifestInContext(c.App, m)
+	setReturnTypeInContext(c.App, smartcontract.AnyType)
 	changePrompt(c.App)
 	return nil
 }
@@ -449,6 +509,7 @@ func handleLoadBase64(c *cli.Context) error {
 	}
 	v.LoadWithFlags(b, callflag.All)
 	fmt.Fprintf(c.App.Writer, "READY: loaded %d instructions\n", v.Context().LenInstr())
+	setReturnTypeInContext(c.App, smartcontract.AnyType)
 	changePrompt(c.App)
 	return nil
 }
@@ -465,6 +526,7 @@ func handleLoadHex(c *cli.Context) error {
 	}
 	v.LoadWithFlags(b, callflag.All)
 	fmt.Fprintf(c.App.Writer, "READY: loaded %d instructions\n", v.Context().LenInstr())
+	setReturnTypeInContext(c.App, smartcontract.AnyType)
 	changePrompt(c.App)
 	return nil
 }
@@ -491,12 +553,14 @@ func handleLoadGo(c *cli.Context) error {
 
 	v.LoadWithFlags(b.Script, callflag.All)
 	fmt.Fprintf(c.App.Writer, "READY: loaded %d instructions\n", v.Context().LenInstr())
+	setReturnTypeInContext(c.App, smartcontract.AnyType)
 	changePrompt(c.App)
 	return nil
 }
 
 func handleReset(c *cli.Context) error {
 	setVMInContext(c.App, vm.New())
+	setReturnTypeInContext(c.App, smartcontract.AnyType)
 	changePrompt(c.App)
 	return nil
 }
@@ -536,6 +600,7 @@ func handleRun(c *cli.Context) error {
 				return fmt.Errorf("%w: method not found", ErrInvalidParameter)
 			}
 			offset = md.Offset
+			setReturnTypeInContext(c.App, md.ReturnType)
 		}
 		for i := len(params) - 1; i >= 0; i-- {
 			v.Estack().PushVal(params[i])
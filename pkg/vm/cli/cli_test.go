@@ -662,3 +662,41 @@ func TestReset(t *testing.T) {
 	e.checkNextLine(t, "")
 	e.checkError(t, fmt.Errorf("VM is not ready: no program loaded"))
 }
+
+func TestPretty(t *testing.T) {
+	src := `package kek
+	func GetString() string {
+		return "hello"
+	}
+	func GetOwner() []byte {
+		return []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20}
+	}`
+
+	tmpDir := t.TempDir()
+	filename := filepath.Join(tmpDir, "pretty_vmtestcontract.go")
+	require.NoError(t, os.WriteFile(filename, []byte(src), os.ModePerm))
+	filename = "'" + filename + "'"
+
+	owner := util.Uint160{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20}
+	e := newTestVMCLI(t)
+	e.runProgWithTimeout(t, 30*time.Second,
+		"loadgo "+filename, "run getString", "pretty",
+		"loadgo "+filename, "run getOwner", "pretty hash160", "pretty unknown",
+		"loadhex "+hex.EncodeToString([]byte{byte(opcode.PUSH1), byte(opcode.PUSH2)}),
+		"run", "pretty",
+	)
+
+	e.checkNextLine(t, "READY: loaded \\d.* instructions")
+	e.checkStack(t, "hello")
+	e.checkNextLine(t, `^0: "hello"`)
+
+	e.checkNextLine(t, "READY: loaded \\d.* instructions")
+	e.checkStack(t, stackitem.NewBuffer(owner.BytesBE()))
+	e.checkNextLine(t, "^0: 0x"+owner.StringLE())
+	e.checkError(t, ErrInvalidParameter)
+
+	e.checkNextLine(t, "READY: loaded 2 instructions")
+	e.checkStack(t, 1, 2)
+	e.checkNextLine(t, "^0: 2")
+	e.checkNextLine(t, "^1: 1")
+}
@@ -0,0 +1,731 @@
+package vm
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/encoding/bigint"
+	"github.com/nspcc-dev/neo-go/pkg/io"
+	"github.com/nspcc-dev/neo-go/pkg/vm/emit"
+	"github.com/nspcc-dev/neo-go/pkg/vm/opcode"
+	"github.com/nspcc-dev/neo-go/pkg/vm/stackitem"
+	"github.com/stretchr/testify/require"
+)
+
+// Script execution results are checked against:
+//   - C# neo-vm JSON test vectors (see TestUT), it's the only independent
+//     reference available without any additional setup;
+//   - regression corpus of cases stored in regressionCorpusDir. Results of
+//     cases with diffSourceSelf were recorded from NeoGo VM itself, so they
+//     only catch changes in its behaviour, while diffSourceReference cases
+//     are divergences found by FuzzVMDifferential with results obtained from
+//     the external reference;
+//   - external reference VM implementation specified via diffReferenceEnv
+//     environment variable (differential testing). It's an executable that
+//     reads base64-encoded script from its standard input and outputs
+//     diffResult in JSON.
+//
+// GAS is not compared, opcode prices are defined by the caller of VM (see
+// fee package) rather than by VM itself. Execution is limited to
+// diffMaxSteps instructions to prevent infinite loops, reference
+// implementation must follow the same rule.
+const (
+	regressionCorpusDir = "testdata/regression"
+	diffReferenceEnv    = "NEOGO_VM_REFERENCE"
+	diffMaxSteps        = 10000
+	diffMaxInstrs       = 64
+
+	// diffSourceSelf marks cases with results recorded from the current VM.
+	diffSourceSelf = "neo-go"
+	// diffSourceReference marks cases with results recorded from the
+	// external reference implementation.
+	diffSourceReference = "reference"
+)
+
+type (
+	// diffCase is a single regression corpus case.
+	diffCase struct {
+		Name string `json:"name"`
+		// Source is the implementation Result was recorded from.
+		Source string     `json:"source"`
+		Script vmUTScript `json:"script"`
+		Result diffResult `json:"result"`
+	}
+
+	// diffResult is the final VM state after script execution. Stack items
+	// are stored in the typed JSON form (see stackitem.ToJSONWithTypes)
+	// starting from the top one.
+	diffResult struct {
+		State string            `json:"state"`
+		Stack []json.RawMessage `json:"stack"`
+	}
+
+	// diffReference is a reference implementation of VM.
+	diffReference interface {
+		Run(script []byte) (*diffResult, error)
+	}
+
+	// commandReference runs scripts using an external executable.
+	commandReference string
+)
+
+// MarshalJSON implements json.Marshaler interface, it outputs script in the
+// same form C# test vectors use (one opcode or hex-encoded operand per string).
+func (v vmUTScript) MarshalJSON() ([]byte, error) {
+	var (
+		ops []string
+		ctx = NewContext(v)
+	)
+	for ctx.nextip < len(ctx.prog) {
+		op, _, err := ctx.Next()
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, op.String())
+		if ctx.nextip > ctx.ip+1 {
+			ops = append(ops, "0x"+hex.EncodeToString(ctx.prog[ctx.ip+1:ctx.nextip]))
+		}
+	}
+	return json.Marshal(ops)
+}
+
+// Run implements diffReference interface.
+func (c commandReference) Run(script []byte) (*diffResult, error) {
+	var out bytes.Buffer
+
+	cmd := exec.Command(string(c))
+	cmd.Stdin = strings.NewReader(base64.StdEncoding.EncodeToString(script) + "\n")
+	cmd.Stdout = &out
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("reference VM failed: %w", err)
+	}
+	res := new(diffResult)
+	if err := json.Unmarshal(out.Bytes(), res); err != nil {
+		return nil, fmt.Errorf("invalid reference VM output: %w", err)
+	}
+	return res, nil
+}
+
+// getDiffReference returns external reference VM if it's configured.
+func getDiffReference() diffReference {
+	if path := os.Getenv(diffReferenceEnv); path != "" {
+		return commandReference(path)
+	}
+	return nil
+}
+
+// runDiffScript executes script with the current VM.
+func runDiffScript(script []byte) *diffResult {
+	v := load(script)
+	v.SyscallHandler = testSyscallHandler
+	// Every instruction costs 1, so GAS limit is a limit for the number of
+	// executed instructions.
+	v.GasLimit = diffMaxSteps
+	v.SetPriceGetter(func(opcode.Opcode, []byte) int64 { return 1 })
+	_ = v.Run()
+
+	res := &diffResult{State: v.state.String()}
+	if v.HasFailed() {
+		return res
+	}
+	for i := 0; i < v.estack.Len(); i++ {
+		res.Stack = append(res.Stack, diffItemJSON(v.estack.Peek(i).Item()))
+	}
+	return res
+}
+
+func diffItemJSON(item stackitem.Item) json.RawMessage {
+	data, err := stackitem.ToJSONWithTypes(item)
+	if err != nil {
+		// Still comparable, but with less precision.
+		data, _ = json.Marshal(fmt.Sprintf("%s: %s", item.Type(), err))
+	}
+	return data
+}
+
+// compareDiffResults returns an error describing the difference between
+// expected and actual results. Stacks are not compared for faulted executions.
+func compareDiffResults(expected, actual *diffResult) error {
+	if expected.State != actual.State {
+		return fmt.Errorf("state mismatch: expected %s, got %s", expected.State, actual.State)
+	}
+	if expected.State == FaultState.String() {
+		return nil
+	}
+	if len(expected.Stack) != len(actual.Stack) {
+		return fmt.Errorf("stack length mismatch: expected %d, got %d", len(expected.Stack), len(actual.Stack))
+	}
+	for i := range expected.Stack {
+		var e, a interface{}
+		if err := json.Unmarshal(expected.Stack[i], &e); err != nil {
+			return fmt.Errorf("invalid expected item %d: %w", i, err)
+		}
+		if err := json.Unmarshal(actual.Stack[i], &a); err != nil {
+			return fmt.Errorf("invalid actual item %d: %w", i, err)
+		}
+		if !reflect.DeepEqual(e, a) {
+			return fmt.Errorf("stack item %d mismatch: expected %s, got %s", i, expected.Stack[i], actual.Stack[i])
+		}
+	}
+	return nil
+}
+
+// loadDiffCorpus reads all regression corpus cases.
+func loadDiffCorpus(t testing.TB) []diffCase {
+	files, err := filepath.Glob(filepath.Join(regressionCorpusDir, "*.json"))
+	require.NoError(t, err)
+
+	var cases []diffCase
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+
+		var c diffCase
+		require.NoError(t, json.Unmarshal(data, &c), "file: %s", file)
+		if c.Name == "" {
+			c.Name = filepath.Base(file)
+		}
+		require.Contains(t, []string{diffSourceSelf, diffSourceReference}, c.Source, "file: %s", file)
+		cases = append(cases, c)
+	}
+	return cases
+}
+
+// loadUTScripts returns scripts of C# test vectors (if they're available)
+// indexed by test names.
+func loadUTScripts(t testing.TB) map[string][]byte {
+	scripts := make(map[string][]byte)
+	_ = filepath.Walk(testsDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || !strings.HasSuffix(path, ".json") || strings.HasSuffix(path, "MEMCPY.json") {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		data = bytes.TrimPrefix(data, []byte{0xef, 0xbb, 0xbf})
+
+		ut := new(vmUT)
+		require.NoErrorf(t, json.Unmarshal(data, ut), "file: %s", path)
+		for _, test := range ut.Tests {
+			scripts[ut.Category+":"+ut.Name+":"+test.Name] = test.Script
+		}
+		return nil
+	})
+	return scripts
+}
+
+func TestVMRegressionCorpus(t *testing.T) {
+	for _, c := range loadDiffCorpus(t) {
+		c := c
+		t.Run(c.Name, func(t *testing.T) {
+			require.NoError(t, IsScriptCorrect(c.Script, nil))
+			require.NoError(t, compareDiffResults(&c.Result, runDiffScript(c.Script)))
+		})
+	}
+}
+
+// TestVMDifferentialReference compares the current VM with the external
+// reference implementation using corpus and C# test vectors scripts.
+func TestVMDifferentialReference(t *testing.T) {
+	ref := getDiffReference()
+	if ref == nil {
+		t.Skipf("no reference VM specified via %s", diffReferenceEnv)
+	}
+	scripts := loadUTScripts(t)
+	for _, c := range loadDiffCorpus(t) {
+		scripts[c.Name] = c.Script
+	}
+	for name, script := range scripts {
+		script := script
+		t.Run(name, func(t *testing.T) {
+			expected, err := ref.Run(script)
+			require.NoError(t, err)
+			require.NoError(t, compareDiffResults(expected, runDiffScript(script)))
+		})
+	}
+}
+
+// scriptGen generates random scripts from the given data, every generated
+// script is valid (it's straight-line and passes IsScriptCorrect), but can
+// fail at runtime.
+type scriptGen struct {
+	data   []byte
+	pos    int
+	kinds  []stackitem.Type // approximate stack contents, top is the last one
+	instrs [][]byte
+}
+
+var (
+	genUnaryOps  = []opcode.Opcode{opcode.NEGATE, opcode.ABS, opcode.INC, opcode.DEC, opcode.SIGN, opcode.SQRT}
+	genBinaryOps = []opcode.Opcode{opcode.ADD, opcode.SUB, opcode.MUL, opcode.DIV, opcode.MOD,
+		opcode.MAX, opcode.MIN, opcode.AND, opcode.OR, opcode.XOR}
+	genCompareOps = []opcode.Opcode{opcode.NUMEQUAL, opcode.NUMNOTEQUAL, opcode.LT, opcode.LE,
+		opcode.GT, opcode.GE}
+	genConvertTypes = []stackitem.Type{stackitem.IntegerT, stackitem.ByteArrayT, stackitem.BufferT,
+		stackitem.BooleanT}
+)
+
+// generateScript returns instructions generated from data, the last one is
+// always RET.
+func generateScript(data []byte) [][]byte {
+	g := &scriptGen{data: data}
+	for g.pos < len(g.data) && len(g.instrs) < diffMaxInstrs {
+		g.next()
+	}
+	g.emit(func(w *io.BinWriter) { emit.Opcodes(w, opcode.RET) })
+	return g.instrs
+}
+
+func joinInstrs(instrs [][]byte) []byte {
+	return bytes.Join(instrs, nil)
+}
+
+func (g *scriptGen) byte() byte {
+	if g.pos >= len(g.data) {
+		return 0
+	}
+	g.pos++
+	return g.data[g.pos-1]
+}
+
+func (g *scriptGen) bytes(n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = g.byte()
+	}
+	return b
+}
+
+func (g *scriptGen) emit(f func(w *io.BinWriter)) {
+	w := io.NewBufBinWriter()
+	f(w.BinWriter)
+	g.instrs = append(g.instrs, w.Bytes())
+}
+
+func (g *scriptGen) ops(ops ...opcode.Opcode) {
+	g.emit(func(w *io.BinWriter) { emit.Opcodes(w, ops...) })
+}
+
+func (g *scriptGen) push(typ stackitem.Type) {
+	g.kinds = append(g.kinds, typ)
+}
+
+func (g *scriptGen) pop(n int) {
+	g.kinds = g.kinds[:len(g.kinds)-n]
+}
+
+// top checks that there are at least len(types) items on the stack and
+// that their types match (AnyT matches everything), the last type is for the
+// top item.
+func (g *scriptGen) top(types ...stackitem.Type) bool {
+	if len(g.kinds) < len(types) {
+		return false
+	}
+	for i, typ := range types {
+		k := g.kinds[len(g.kinds)-len(types)+i]
+		if typ != stackitem.AnyT && k != typ {
+			return false
+		}
+	}
+	return true
+}
+
+func (g *scriptGen) next() {
+	switch g.byte() % 14 {
+	case 0:
+		g.pushInt()
+	case 1:
+		b := g.bytes(int(g.byte() % 40))
+		g.emit(func(w *io.BinWriter) { emit.Bytes(w, b) })
+		g.push(stackitem.ByteArrayT)
+	case 2:
+		b := g.byte()&1 == 1
+		g.emit(func(w *io.BinWriter) { emit.Bool(w, b) })
+		g.push(stackitem.BooleanT)
+	case 3:
+		g.ops(opcode.PUSHNULL)
+		g.push(stackitem.AnyT)
+	case 4:
+		if !g.top(stackitem.IntegerT) {
+			g.pushInt()
+			return
+		}
+		g.ops(genUnaryOps[int(g.byte())%len(genUnaryOps)])
+	case 5:
+		if !g.top(stackitem.IntegerT, stackitem.IntegerT) {
+			g.pushInt()
+			return
+		}
+		g.ops(genBinaryOps[int(g.byte())%len(genBinaryOps)])
+		g.pop(1)
+	case 6:
+		if !g.top(stackitem.IntegerT, stackitem.IntegerT) {
+			g.pushInt()
+			return
+		}
+		g.ops(genCompareOps[int(g.byte())%len(genCompareOps)])
+		g.pop(2)
+		g.push(stackitem.BooleanT)
+	case 7:
+		if !g.top(stackitem.IntegerT) {
+			g.pushInt()
+			return
+		}
+		shift := int64(g.byte() % 70)
+		op := opcode.SHL
+		if g.byte()&1 == 1 {
+			op = opcode.SHR
+		}
+		g.emit(func(w *io.BinWriter) {
+			emit.Int(w, shift)
+			emit.Opcodes(w, op)
+		})
+	case 8:
+		g.nextBytesOp()
+	case 9:
+		g.nextStackOp()
+	case 10:
+		g.nextCompoundOp()
+	case 11:
+		if !g.top(stackitem.AnyT, stackitem.AnyT) {
+			g.pushInt()
+			return
+		}
+		ops := []opcode.Opcode{opcode.EQUAL, opcode.NOTEQUAL, opcode.BOOLAND, opcode.BOOLOR}
+		g.ops(ops[int(g.byte())%len(ops)])
+		g.pop(2)
+		g.push(stackitem.BooleanT)
+	case 12:
+		if !g.top(stackitem.AnyT) {
+			g.pushInt()
+			return
+		}
+		ops := []opcode.Opcode{opcode.NOT, opcode.NZ, opcode.ISNULL}
+		g.ops(ops[int(g.byte())%len(ops)])
+		g.pop(1)
+		g.push(stackitem.BooleanT)
+	case 13:
+		if !g.top(stackitem.AnyT) {
+			g.pushInt()
+			return
+		}
+		typ := genConvertTypes[int(g.byte())%len(genConvertTypes)]
+		g.emit(func(w *io.BinWriter) { emit.Instruction(w, opcode.CONVERT, []byte{byte(typ)}) })
+		g.pop(1)
+		g.push(typ)
+	}
+}
+
+func (g *scriptGen) pushInt() {
+	var n *big.Int
+	if g.byte()%4 != 0 {
+		n = big.NewInt(int64(int8(g.byte())))
+	} else {
+		// Up to 256-bit numbers.
+		n = bigint.FromBytes(g.bytes(int(g.byte()%32) + 1))
+	}
+	g.emit(func(w *io.BinWriter) { emit.BigInt(w, n) })
+	g.push(stackitem.IntegerT)
+}
+
+func (g *scriptGen) nextBytesOp() {
+	switch g.byte() % 3 {
+	case 0:
+		if !g.top(stackitem.AnyT, stackitem.AnyT) {
+			g.pushInt()
+			return
+		}
+		g.ops(opcode.CAT)
+		g.pop(2)
+		g.push(stackitem.BufferT)
+	case 1:
+		if !g.top(stackitem.AnyT) {
+			g.pushInt()
+			return
+		}
+		ops := []opcode.Opcode{opcode.LEFT, opcode.RIGHT}
+		n, op := int64(g.byte()%8), ops[int(g.byte())%len(ops)]
+		g.emit(func(w *io.BinWriter) {
+			emit.Int(w, n)
+			emit.Opcodes(w, op)
+		})
+		g.pop(1)
+		g.push(stackitem.BufferT)
+	case 2:
+		if !g.top(stackitem.AnyT) {
+			g.pushInt()
+			return
+		}
+		g.ops(opcode.SIZE)
+		g.pop(1)
+		g.push(stackitem.IntegerT)
+	}
+}
+
+func (g *scriptGen) nextStackOp() {
+	n := len(g.kinds)
+	switch op := g.byte() % 8; {
+	case op == 0 && n >= 1:
+		g.ops(opcode.DUP)
+		g.push(g.kinds[n-1])
+	case op == 1 && n >= 1:
+		g.ops(opcode.DROP)
+		g.pop(1)
+	case op == 2 && n >= 2:
+		g.ops(opcode.SWAP)
+		g.kinds[n-1], g.kinds[n-2] = g.kinds[n-2], g.kinds[n-1]
+	case op == 3 && n >= 2:
+		g.ops(opcode.OVER)
+		g.push(g.kinds[n-2])
+	case op == 4 && n >= 3:
+		g.ops(opcode.ROT)
+		k := g.kinds[n-3]
+		g.kinds = append(append(g.kinds[:n-3], g.kinds[n-2:]...), k)
+	case op == 5 && n >= 2:
+		g.ops(opcode.NIP)
+		g.kinds = append(g.kinds[:n-2], g.kinds[n-1])
+	case op == 6 && n >= 3:
+		g.ops(opcode.REVERSE3)
+		g.kinds[n-1], g.kinds[n-3] = g.kinds[n-3], g.kinds[n-1]
+	case op == 7:
+		g.ops(opcode.DEPTH)
+		g.push(stackitem.IntegerT)
+	default:
+		g.pushInt()
+	}
+}
+
+func (g *scriptGen) nextCompoundOp() {
+	n := len(g.kinds)
+	switch op := g.byte() % 7; {
+	case op == 0:
+		g.ops(opcode.NEWARRAY0)
+		g.push(stackitem.ArrayT)
+	case op == 1:
+		g.ops(opcode.NEWMAP)
+		g.push(stackitem.MapT)
+	case op == 2 && n >= 1:
+		cnt := int(g.byte()) % (n + 1)
+		if cnt > 5 {
+			cnt = 5
+		}
+		ops := []opcode.Opcode{opcode.PACK, opcode.PACKSTRUCT}
+		pack := ops[int(g.byte())%len(ops)]
+		g.emit(func(w *io.BinWriter) {
+			emit.Int(w, int64(cnt))
+			emit.Opcodes(w, pack)
+		})
+		g.pop(cnt)
+		if pack == opcode.PACK {
+			g.push(stackitem.ArrayT)
+		} else {
+			g.push(stackitem.StructT)
+		}
+	case op == 3 && g.top(stackitem.ArrayT, stackitem.AnyT):
+		// Keep array on the stack.
+		g.ops(opcode.OVER, opcode.SWAP, opcode.APPEND)
+		g.pop(1)
+	case op == 4 && g.top(stackitem.MapT, stackitem.AnyT, stackitem.AnyT):
+		// Keep map on the stack.
+		g.ops(opcode.ROT, opcode.DUP, opcode.REVERSE4, opcode.SWAP, opcode.SETITEM)
+		g.pop(2)
+	case op == 5 && n >= 1 && isGenCompound(g.kinds[n-1]):
+		ops := []opcode.Opcode{opcode.KEYS, opcode.VALUES, opcode.UNPACK}
+		o := ops[int(g.byte())%len(ops)]
+		g.ops(o)
+		g.pop(1)
+		if o == opcode.UNPACK {
+			// Unknown number of items, but at least the size.
+			g.push(stackitem.IntegerT)
+		} else {
+			g.push(stackitem.ArrayT)
+		}
+	case op == 6 && n >= 2 && isGenCompound(g.kinds[n-2]):
+		ops := []opcode.Opcode{opcode.PICKITEM, opcode.HASKEY}
+		o := ops[int(g.byte())%len(ops)]
+		g.ops(o)
+		g.pop(2)
+		if o == opcode.HASKEY {
+			g.push(stackitem.BooleanT)
+		} else {
+			g.push(stackitem.AnyT)
+		}
+	default:
+		g.pushInt()
+	}
+}
+
+func isGenCompound(typ stackitem.Type) bool {
+	return typ == stackitem.ArrayT || typ == stackitem.StructT || typ == stackitem.MapT
+}
+
+// minimiseScript removes as many instructions from the script as possible
+// while it still passes check (the last instruction is never removed). It
+// tries to remove chunks of instructions first, halving chunk size until
+// single instructions are tried, and repeats this until no more instructions
+// can be removed.
+func minimiseScript(instrs [][]byte, check func([]byte) bool) [][]byte {
+	for {
+		n := len(instrs)
+		for chunk := len(instrs) - 1; chunk > 0; chunk /= 2 {
+			for i := 0; i+chunk < len(instrs); {
+				candidate := append(append([][]byte{}, instrs[:i]...), instrs[i+chunk:]...)
+				script := joinInstrs(candidate)
+				if IsScriptCorrect(script, nil) == nil && check(script) {
+					instrs = candidate
+					continue
+				}
+				i++
+			}
+		}
+		// Removing some instruction can make others redundant.
+		if len(instrs) == n {
+			return instrs
+		}
+	}
+}
+
+// saveDiffCase stores divergence as a new corpus case and returns its path.
+func saveDiffCase(t testing.TB, script []byte, expected *diffResult) string {
+	h := sha256.Sum256(script)
+	name := "fuzz-" + hex.EncodeToString(h[:8])
+	data, err := json.MarshalIndent(diffCase{
+		Name:   name,
+		Source: diffSourceReference,
+		Script: script,
+		Result: *expected,
+	}, "", "  ")
+	require.NoError(t, err)
+
+	require.NoError(t, os.MkdirAll(regressionCorpusDir, os.ModePerm))
+	path := filepath.Join(regressionCorpusDir, name+".json")
+	require.NoError(t, os.WriteFile(path, append(data, '\n'), os.ModePerm))
+	return path
+}
+
+// checkDifferential runs the script generated from data with both VM
+// implementations, divergences are minimised and saved into the corpus.
+func checkDifferential(t testing.TB, ref diffReference, data []byte) {
+	instrs := generateScript(data)
+	script := joinInstrs(instrs)
+	require.NoError(t, IsScriptCorrect(script, nil))
+
+	diverges := func(script []byte) bool {
+		expected, err := ref.Run(script)
+		return err == nil && compareDiffResults(expected, runDiffScript(script)) != nil
+	}
+	expected, err := ref.Run(script)
+	require.NoError(t, err)
+	if compareDiffResults(expected, runDiffScript(script)) == nil {
+		return
+	}
+
+	script = joinInstrs(minimiseScript(instrs, diverges))
+	expected, err = ref.Run(script)
+	require.NoError(t, err)
+	diff := compareDiffResults(expected, runDiffScript(script))
+	if diff == nil {
+		// Flaky reference, nothing to minimise.
+		diff = errors.New("divergence is not reproducible")
+	}
+	path := saveDiffCase(t, script, expected)
+	t.Fatalf("VM diverges from the reference: %s (minimised case is saved to %s)", diff, path)
+}
+
+func TestGenerateScript(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		data := randomBytes(200)
+		instrs := generateScript(data)
+		require.LessOrEqual(t, len(instrs), diffMaxInstrs+1)
+		script := joinInstrs(instrs)
+		require.NoError(t, IsScriptCorrect(script, nil))
+
+		res := runDiffScript(script)
+		require.NoError(t, compareDiffResults(res, runDiffScript(script)), "non-deterministic execution")
+	}
+}
+
+func TestMinimiseScript(t *testing.T) {
+	instrs := generateScript(randomBytes(200))
+	instrs = append(instrs[:len(instrs)-1], []byte{byte(opcode.PUSH7)}, instrs[len(instrs)-1])
+
+	// Divergence is caused by PUSH7, so it's the only instruction left.
+	check := func(script []byte) bool { return bytes.IndexByte(script, byte(opcode.PUSH7)) >= 0 }
+	require.Equal(t, [][]byte{{byte(opcode.PUSH7)}, {byte(opcode.RET)}}, minimiseScript(instrs, check))
+}
+
+func TestCheckDifferential(t *testing.T) {
+	t.Run("same", func(t *testing.T) {
+		checkDifferential(t, selfReference{}, randomBytes(100))
+	})
+	t.Run("divergence", func(t *testing.T) {
+		dir := t.TempDir()
+		wd, err := os.Getwd()
+		require.NoError(t, err)
+		require.NoError(t, os.Chdir(dir))
+		t.Cleanup(func() { require.NoError(t, os.Chdir(wd)) })
+
+		// Reference produces different results for PUSH1.
+		ref := selfReference{wrong: opcode.PUSH1}
+		ft := &fatalRecorder{TB: t}
+		func() {
+			defer func() { _ = recover() }()
+			// PUSH1, PUSH5, ADD.
+			checkDifferential(ft, ref, []byte{0, 1, 1, 0, 1, 5, 5, 0})
+		}()
+		require.True(t, ft.failed)
+
+		cases := loadDiffCorpus(t)
+		require.Equal(t, 1, len(cases))
+		require.Equal(t, []byte{byte(opcode.PUSH1), byte(opcode.RET)}, []byte(cases[0].Script))
+		require.Equal(t, HaltState.String(), cases[0].Result.State)
+		require.Equal(t, diffSourceReference, cases[0].Source)
+	})
+}
+
+// selfReference uses the current VM as a reference, changing results for
+// scripts containing wrong opcode.
+type selfReference struct {
+	wrong opcode.Opcode
+}
+
+func (r selfReference) Run(script []byte) (*diffResult, error) {
+	res := runDiffScript(script)
+	if r.wrong == 0 {
+		return res, nil
+	}
+	ctx := NewContext(script)
+	for ctx.nextip < len(ctx.prog) {
+		op, _, err := ctx.Next()
+		if err != nil {
+			return nil, err
+		}
+		if op == r.wrong {
+			res.Stack = append(res.Stack, diffItemJSON(stackitem.Null{}))
+			break
+		}
+	}
+	return res, nil
+}
+
+// fatalRecorder intercepts Fatalf calls.
+type fatalRecorder struct {
+	testing.TB
+	failed bool
+}
+
+func (f *fatalRecorder) Fatalf(format string, args ...interface{}) {
+	f.failed = true
+	panic(fmt.Sprintf(format, args...))
+}
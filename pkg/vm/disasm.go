@@ -0,0 +1,491 @@
+package vm
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/manifest"
+	"github.com/nspcc-dev/neo-go/pkg/vm/opcode"
+)
+
+// EdgeKind describes the way control is transferred from one basic block to
+// another.
+type EdgeKind byte
+
+// Possible control flow edge kinds.
+const (
+	// EdgeFallthrough is a transition to the instruction following the last
+	// instruction of the block.
+	EdgeFallthrough EdgeKind = iota
+	// EdgeJump is an unconditional jump (JMP*, ENDTRY*).
+	EdgeJump
+	// EdgeBranch is a conditional jump (JMPIF*, JMPEQ* and alike).
+	EdgeBranch
+	// EdgeCall is a CALL* target.
+	EdgeCall
+	// EdgeCatch is a TRY* catch block.
+	EdgeCatch
+	// EdgeFinally is a TRY* finally block.
+	EdgeFinally
+	// EdgePointer is a PUSHA target (it's likely to be called via CALLA later).
+	EdgePointer
+	// EdgeInvalid is a transfer of any kind to an offset that is not an
+	// instruction boundary, such scripts are rejected by IsScriptCorrect.
+	EdgeInvalid
+)
+
+var edgeKindNames = [...]string{
+	EdgeFallthrough: "fallthrough",
+	EdgeJump:        "jump",
+	EdgeBranch:      "branch",
+	EdgeCall:        "call",
+	EdgeCatch:       "catch",
+	EdgeFinally:     "finally",
+	EdgePointer:     "pointer",
+	EdgeInvalid:     "invalid",
+}
+
+// String implements fmt.Stringer interface.
+func (k EdgeKind) String() string {
+	if int(k) < len(edgeKindNames) {
+		return edgeKindNames[k]
+	}
+	return fmt.Sprintf("unknown(%d)", byte(k))
+}
+
+// MarshalJSON implements json.Marshaler interface.
+func (k EdgeKind) MarshalJSON() ([]byte, error) {
+	return json.Marshal(k.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface.
+func (k *EdgeKind) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	for i := range edgeKindNames {
+		if edgeKindNames[i] == s {
+			*k = EdgeKind(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown edge kind: %s", s)
+}
+
+// Instruction is a single decoded script instruction.
+type Instruction struct {
+	Offset    int
+	Opcode    opcode.Opcode
+	Parameter []byte
+	// Desc is a human-readable description of the parameter (jump target,
+	// SYSCALL name, integer value, etc).
+	Desc string
+}
+
+type instructionAux struct {
+	Offset    int    `json:"offset"`
+	Opcode    string `json:"opcode"`
+	Parameter string `json:"parameter,omitempty"`
+	Desc      string `json:"description,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler interface.
+func (i Instruction) MarshalJSON() ([]byte, error) {
+	return json.Marshal(instructionAux{
+		Offset:    i.Offset,
+		Opcode:    i.Opcode.String(),
+		Parameter: hex.EncodeToString(i.Parameter),
+		Desc:      i.Desc,
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface.
+func (i *Instruction) UnmarshalJSON(data []byte) error {
+	aux := new(instructionAux)
+	if err := json.Unmarshal(data, aux); err != nil {
+		return err
+	}
+	op, err := opcode.FromString(aux.Opcode)
+	if err != nil {
+		return err
+	}
+	param, err := hex.DecodeString(aux.Parameter)
+	if err != nil {
+		return err
+	}
+	if len(param) == 0 {
+		param = nil
+	}
+	*i = Instruction{
+		Offset:    aux.Offset,
+		Opcode:    op,
+		Parameter: param,
+		Desc:      aux.Desc,
+	}
+	return nil
+}
+
+// Edge is a control flow graph edge between two basic blocks identified by
+// their starting offsets.
+type Edge struct {
+	From int      `json:"from"`
+	To   int      `json:"to"`
+	Kind EdgeKind `json:"kind"`
+}
+
+// BasicBlock is a sequence of instructions with a single entry point and a
+// single exit point.
+type BasicBlock struct {
+	// Start is an offset of the first instruction of the block.
+	Start int `json:"start"`
+	// End is an offset of the first byte after the last instruction of the block.
+	End int `json:"end"`
+	// Method is the name of the method this block belongs to.
+	Method       string        `json:"method"`
+	Instructions []Instruction `json:"instructions"`
+	// Edges are outgoing edges of the block.
+	Edges []Edge `json:"edges"`
+}
+
+// MethodInfo describes method boundaries in the script.
+type MethodInfo struct {
+	Name   string `json:"name"`
+	Offset int    `json:"offset"`
+	End    int    `json:"end"`
+	// Exported is true for methods present in the manifest ABI.
+	Exported bool `json:"exported"`
+}
+
+// Disassembly is a disassembled script with its control flow graph.
+type Disassembly struct {
+	Methods []MethodInfo  `json:"methods"`
+	Blocks  []*BasicBlock `json:"blocks"`
+}
+
+// Disassemble decodes the given script and builds its control flow graph.
+// Manifest is optional, if provided, its ABI is used to annotate method
+// boundaries. Methods not present in the manifest that are reachable via
+// CALL* or PUSHA get a "sub_<offset>" name. Targets in the middle of some
+// instruction don't split blocks, they're reported as EdgeInvalid edges.
+func Disassemble(script []byte, m *manifest.Manifest) (*Disassembly, error) {
+	var (
+		instrs  []Instruction
+		targets = make(map[int][]Edge)
+		leaders = map[int]bool{0: true}
+		methods = make(map[int]MethodInfo)
+		l       = len(script)
+	)
+	if l == 0 {
+		return nil, errors.New("empty script")
+	}
+	if m != nil {
+		for _, md := range m.ABI.Methods {
+			if md.Offset < 0 || md.Offset >= l {
+				return nil, fmt.Errorf("method %s has invalid offset %d", md.Name, md.Offset)
+			}
+			if _, ok := methods[md.Offset]; !ok {
+				methods[md.Offset] = MethodInfo{Name: md.Name, Offset: md.Offset, Exported: true}
+			}
+			leaders[md.Offset] = true
+		}
+	}
+
+	addTarget := func(from int, to int, kind EdgeKind) {
+		// Jumping to the end of script is an implicit RET.
+		if to == l {
+			return
+		}
+		targets[from] = append(targets[from], Edge{To: to, Kind: kind})
+	}
+
+	ctx := NewContext(script)
+	for ctx.nextip < l {
+		op, param, err := ctx.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode instruction at %d: %w", ctx.ip, err)
+		}
+		instrs = append(instrs, Instruction{
+			Offset:    ctx.ip,
+			Opcode:    op,
+			Parameter: param,
+			Desc:      getInstrDesc(ctx, op, param),
+		})
+		var kind EdgeKind
+		switch {
+		case isUnconditionalJump(op):
+			kind = EdgeJump
+		case isConditionalJump(op):
+			kind = EdgeBranch
+		case op == opcode.CALL || op == opcode.CALLL:
+			kind = EdgeCall
+		case op == opcode.PUSHA:
+			kind = EdgePointer
+		case op == opcode.TRY || op == opcode.TRYL:
+			catchP, finallyP := getTryParams(op, param)
+			catchOff, _, err := calcJumpOffset(ctx, catchP)
+			if err != nil {
+				return nil, err
+			}
+			finallyOff, _, err := calcJumpOffset(ctx, finallyP)
+			if err != nil {
+				return nil, err
+			}
+			if catchOff != ctx.ip {
+				addTarget(ctx.ip, catchOff, EdgeCatch)
+			}
+			if finallyOff != ctx.ip {
+				addTarget(ctx.ip, finallyOff, EdgeFinally)
+			}
+			leaders[ctx.nextip] = true
+			continue
+		default:
+			if endsBlock(op) {
+				leaders[ctx.nextip] = true
+			}
+			continue
+		}
+		off, _, err := calcJumpOffset(ctx, param)
+		if err != nil {
+			return nil, err
+		}
+		addTarget(ctx.ip, off, kind)
+		if kind != EdgePointer {
+			leaders[ctx.nextip] = true
+		}
+	}
+
+	// Targets are only known to be valid once all instructions are decoded.
+	starts := make(map[int]bool, len(instrs))
+	for _, instr := range instrs {
+		starts[instr.Offset] = true
+	}
+	for _, es := range targets {
+		for i := range es {
+			to := es[i].To
+			if !starts[to] {
+				es[i].Kind = EdgeInvalid
+				continue
+			}
+			leaders[to] = true
+			if es[i].Kind == EdgeCall || es[i].Kind == EdgePointer {
+				if _, ok := methods[to]; !ok {
+					methods[to] = MethodInfo{Name: fmt.Sprintf("sub_%d", to), Offset: to}
+				}
+			}
+		}
+	}
+
+	d := new(Disassembly)
+	for _, mi := range methods {
+		d.Methods = append(d.Methods, mi)
+	}
+	sort.Slice(d.Methods, func(i, j int) bool { return d.Methods[i].Offset < d.Methods[j].Offset })
+	if len(d.Methods) == 0 || d.Methods[0].Offset != 0 {
+		d.Methods = append([]MethodInfo{{Name: "sub_0"}}, d.Methods...)
+	}
+	for i := range d.Methods {
+		if i+1 < len(d.Methods) {
+			d.Methods[i].End = d.Methods[i+1].Offset
+		} else {
+			d.Methods[i].End = l
+		}
+	}
+
+	var curr *BasicBlock
+	for _, instr := range instrs {
+		if leaders[instr.Offset] {
+			if curr != nil {
+				d.Blocks = append(d.Blocks, curr)
+			}
+			curr = &BasicBlock{
+				Start:  instr.Offset,
+				Method: d.methodAt(instr.Offset),
+			}
+		}
+		curr.Instructions = append(curr.Instructions, instr)
+		curr.End = instr.Offset + 1 + len(instr.Parameter) + prefixLen(instr.Opcode)
+		for _, e := range targets[instr.Offset] {
+			e.From = curr.Start
+			curr.Edges = append(curr.Edges, e)
+		}
+	}
+	d.Blocks = append(d.Blocks, curr)
+
+	for _, b := range d.Blocks {
+		last := b.Instructions[len(b.Instructions)-1].Opcode
+		if b.End < l && !isUnconditionalJump(last) && !isTerminator(last) {
+			b.Edges = append(b.Edges, Edge{From: b.Start, To: b.End, Kind: EdgeFallthrough})
+		}
+	}
+	return d, nil
+}
+
+// methodAt returns the name of the method containing the given offset.
+func (d *Disassembly) methodAt(off int) string {
+	i := sort.Search(len(d.Methods), func(i int) bool { return d.Methods[i].End > off })
+	if i < len(d.Methods) {
+		return d.Methods[i].Name
+	}
+	return ""
+}
+
+// prefixLen returns the length of PUSHDATA* length prefix.
+func prefixLen(op opcode.Opcode) int {
+	switch op {
+	case opcode.PUSHDATA1:
+		return 1
+	case opcode.PUSHDATA2:
+		return 2
+	case opcode.PUSHDATA4:
+		return 4
+	}
+	return 0
+}
+
+func isUnconditionalJump(op opcode.Opcode) bool {
+	switch op {
+	case opcode.JMP, opcode.JMPL, opcode.ENDTRY, opcode.ENDTRYL:
+		return true
+	}
+	return false
+}
+
+func isConditionalJump(op opcode.Opcode) bool {
+	return opcode.JMPIF <= op && op <= opcode.JMPLEL
+}
+
+// isTerminator returns true for instructions that never pass control to the
+// next one.
+func isTerminator(op opcode.Opcode) bool {
+	switch op {
+	case opcode.RET, opcode.THROW, opcode.ABORT, opcode.ENDFINALLY:
+		return true
+	}
+	return false
+}
+
+// endsBlock returns true for instructions (other than jumps and calls) that
+// end a basic block.
+func endsBlock(op opcode.Opcode) bool {
+	return isTerminator(op) || op == opcode.CALLA
+}
+
+// WriteText writes human-readable disassembly to w.
+func (d *Disassembly) WriteText(w io.Writer) error {
+	var offWidth, opWidth int
+	for _, b := range d.Blocks {
+		for _, instr := range b.Instructions {
+			if l := len(strconv.Itoa(instr.Offset)); l > offWidth {
+				offWidth = l
+			}
+			if l := len(instr.Opcode.String()); l > opWidth {
+				opWidth = l
+			}
+		}
+	}
+	bw := bufio.NewWriter(w)
+	method := ""
+	for i, b := range d.Blocks {
+		if i == 0 || b.Method != method {
+			method = b.Method
+			if i != 0 {
+				fmt.Fprintln(bw)
+			}
+			fmt.Fprintf(bw, "; method %s\n", method)
+		}
+		fmt.Fprintf(bw, "L%d:%s\n", b.Start, edgesDesc(b.Edges))
+		for _, instr := range b.Instructions {
+			line := fmt.Sprintf("    %*d  %-*s  %s", offWidth, instr.Offset, opWidth, instr.Opcode, instr.Desc)
+			fmt.Fprintln(bw, strings.TrimRight(line, " "))
+		}
+	}
+	return bw.Flush()
+}
+
+func edgesDesc(es []Edge) string {
+	if len(es) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString(" ; ->")
+	for i, e := range es {
+		if i != 0 {
+			sb.WriteByte(',')
+		}
+		fmt.Fprintf(&sb, " L%d (%s)", e.To, e.Kind)
+	}
+	return sb.String()
+}
+
+// WriteJSON writes disassembly to w in JSON format.
+func (d *Disassembly) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(d)
+}
+
+// WriteDOT writes control flow graph to w in Graphviz DOT format. Blocks are
+// grouped into clusters by method.
+func (d *Disassembly) WriteDOT(w io.Writer) error {
+	var sb strings.Builder
+
+	sb.WriteString("digraph script {\n")
+	sb.WriteString("\tnode [shape=box fontname=\"monospace\"];\n")
+	for i, mi := range d.Methods {
+		fmt.Fprintf(&sb, "\tsubgraph cluster_%d {\n", i)
+		fmt.Fprintf(&sb, "\t\tlabel=%s;\n", dotQuote(mi.Name))
+		for _, b := range d.Blocks {
+			if b.Start < mi.Offset || b.Start >= mi.End {
+				continue
+			}
+			var label strings.Builder
+			for _, instr := range b.Instructions {
+				fmt.Fprintf(&label, "%d: %s", instr.Offset, instr.Opcode)
+				if instr.Desc != "" {
+					label.WriteString(" " + instr.Desc)
+				}
+				label.WriteString("\n")
+			}
+			fmt.Fprintf(&sb, "\t\tL%d [label=%s];\n", b.Start, dotLabel(label.String()))
+		}
+		sb.WriteString("\t}\n")
+	}
+	for _, b := range d.Blocks {
+		for _, e := range b.Edges {
+			fmt.Fprintf(&sb, "\tL%d -> L%d [label=%q", e.From, e.To, e.Kind)
+			switch e.Kind {
+			case EdgeCall, EdgePointer:
+				sb.WriteString(" style=dashed")
+			case EdgeCatch, EdgeFinally:
+				sb.WriteString(" style=dotted")
+			case EdgeInvalid:
+				sb.WriteString(" color=red")
+			}
+			sb.WriteString("];\n")
+		}
+	}
+	sb.WriteString("}\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// dotQuote returns s as a quoted DOT string.
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
+
+// dotLabel returns multiline s as a left-aligned DOT label.
+func dotLabel(s string) string {
+	s = dotQuote(s)
+	return strings.ReplaceAll(s, "\n", `\l`)
+}
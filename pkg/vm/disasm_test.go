@@ -0,0 +1,184 @@
+package vm
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/core/interop/interopnames"
+	"github.com/nspcc-dev/neo-go/pkg/io"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/manifest"
+	"github.com/nspcc-dev/neo-go/pkg/vm/emit"
+	"github.com/nspcc-dev/neo-go/pkg/vm/opcode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDisassembleBranches(t *testing.T) {
+	w := io.NewBufBinWriter()
+	emit.Opcodes(w.BinWriter, opcode.PUSH1)                  // 0
+	emit.Instruction(w.BinWriter, opcode.JMPIF, []byte{4})   // 1
+	emit.Opcodes(w.BinWriter, opcode.PUSH2, opcode.RET)      // 3, 4
+	emit.Opcodes(w.BinWriter, opcode.PUSH3)                  // 5
+	emit.Syscall(w.BinWriter, interopnames.SystemRuntimeLog) // 6
+	emit.Opcodes(w.BinWriter, opcode.RET)                    // 11
+	require.NoError(t, w.Err)
+
+	d, err := Disassemble(w.Bytes(), nil)
+	require.NoError(t, err)
+	require.Equal(t, []MethodInfo{{Name: "sub_0", Offset: 0, End: 12}}, d.Methods)
+	require.Equal(t, 3, len(d.Blocks))
+
+	require.Equal(t, 0, d.Blocks[0].Start)
+	require.Equal(t, 3, d.Blocks[0].End)
+	require.Equal(t, []Edge{
+		{From: 0, To: 5, Kind: EdgeBranch},
+		{From: 0, To: 3, Kind: EdgeFallthrough},
+	}, d.Blocks[0].Edges)
+
+	require.Equal(t, 3, d.Blocks[1].Start)
+	require.Equal(t, 0, len(d.Blocks[1].Edges))
+
+	require.Equal(t, 5, d.Blocks[2].Start)
+	require.Equal(t, 12, d.Blocks[2].End)
+	require.Equal(t, opcode.SYSCALL, d.Blocks[2].Instructions[1].Opcode)
+	require.True(t, strings.HasPrefix(d.Blocks[2].Instructions[1].Desc, interopnames.SystemRuntimeLog))
+}
+
+func TestDisassembleCallsAndTry(t *testing.T) {
+	w := io.NewBufBinWriter()
+	emit.Instruction(w.BinWriter, opcode.TRY, []byte{7, 0}) // 0
+	emit.Instruction(w.BinWriter, opcode.CALL, []byte{6})   // 3
+	emit.Instruction(w.BinWriter, opcode.ENDTRY, []byte{3}) // 5
+	emit.Opcodes(w.BinWriter, opcode.DROP)                  // 7
+	emit.Opcodes(w.BinWriter, opcode.RET)                   // 8
+	emit.Opcodes(w.BinWriter, opcode.PUSH1, opcode.RET)     // 9, 10
+	require.NoError(t, w.Err)
+	script := w.Bytes()
+
+	m := manifest.NewManifest("Test")
+	m.ABI.Methods = append(m.ABI.Methods, manifest.Method{Name: "main", Offset: 0})
+	d, err := Disassemble(script, m)
+	require.NoError(t, err)
+	require.Equal(t, []MethodInfo{
+		{Name: "main", Offset: 0, End: 9, Exported: true},
+		{Name: "sub_9", Offset: 9, End: 11},
+	}, d.Methods)
+
+	var starts []int
+	for _, b := range d.Blocks {
+		starts = append(starts, b.Start)
+	}
+	require.Equal(t, []int{0, 3, 5, 7, 8, 9}, starts)
+	require.Equal(t, []Edge{
+		{From: 0, To: 7, Kind: EdgeCatch},
+		{From: 0, To: 3, Kind: EdgeFallthrough},
+	}, d.Blocks[0].Edges)
+	require.Equal(t, []Edge{
+		{From: 3, To: 9, Kind: EdgeCall},
+		{From: 3, To: 5, Kind: EdgeFallthrough},
+	}, d.Blocks[1].Edges)
+	require.Equal(t, []Edge{{From: 5, To: 8, Kind: EdgeJump}}, d.Blocks[2].Edges)
+	require.Equal(t, []Edge{{From: 7, To: 8, Kind: EdgeFallthrough}}, d.Blocks[3].Edges)
+	require.Equal(t, 0, len(d.Blocks[4].Edges))
+	require.Equal(t, "main", d.Blocks[4].Method)
+	require.Equal(t, "sub_9", d.Blocks[5].Method)
+
+	t.Run("text", func(t *testing.T) {
+		buf := bytes.NewBuffer(nil)
+		require.NoError(t, d.WriteText(buf))
+		out := buf.String()
+		require.Contains(t, out, "; method main")
+		require.Contains(t, out, "; method sub_9")
+		require.Contains(t, out, "L9 (call)")
+	})
+	t.Run("json", func(t *testing.T) {
+		buf := bytes.NewBuffer(nil)
+		require.NoError(t, d.WriteJSON(buf))
+		var actual struct {
+			Methods []MethodInfo `json:"methods"`
+			Blocks  []struct {
+				Start        int `json:"start"`
+				Instructions []struct {
+					Opcode string `json:"opcode"`
+				} `json:"instructions"`
+				Edges []struct {
+					Kind string `json:"kind"`
+				} `json:"edges"`
+			} `json:"blocks"`
+		}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &actual))
+		require.Equal(t, d.Methods, actual.Methods)
+		require.Equal(t, 6, len(actual.Blocks))
+		require.Equal(t, "TRY", actual.Blocks[0].Instructions[0].Opcode)
+		require.Equal(t, "catch", actual.Blocks[0].Edges[0].Kind)
+	})
+	t.Run("json roundtrip", func(t *testing.T) {
+		data, err := json.Marshal(d)
+		require.NoError(t, err)
+		actual := new(Disassembly)
+		require.NoError(t, json.Unmarshal(data, actual))
+		require.Equal(t, d, actual)
+	})
+	t.Run("dot", func(t *testing.T) {
+		buf := bytes.NewBuffer(nil)
+		require.NoError(t, d.WriteDOT(buf))
+		out := buf.String()
+		require.True(t, strings.HasPrefix(out, "digraph script {"))
+		require.Contains(t, out, `label="main";`)
+		require.Contains(t, out, `L3 -> L9 [label="call" style=dashed];`)
+		require.Contains(t, out, `L0 -> L7 [label="catch" style=dotted];`)
+	})
+}
+
+func TestDisassembleInvalidTargets(t *testing.T) {
+	w := io.NewBufBinWriter()
+	emit.Bytes(w.BinWriter, []byte{1, 2})                     // 0
+	emit.Instruction(w.BinWriter, opcode.JMPIF, []byte{0xfe}) // 4
+	emit.Instruction(w.BinWriter, opcode.CALL, []byte{0xfb})  // 6
+	emit.Opcodes(w.BinWriter, opcode.RET)                     // 8
+	require.NoError(t, w.Err)
+
+	d, err := Disassemble(w.Bytes(), nil)
+	require.NoError(t, err)
+	require.Equal(t, []MethodInfo{{Name: "sub_0", Offset: 0, End: 9}}, d.Methods)
+
+	var starts []int
+	for _, b := range d.Blocks {
+		starts = append(starts, b.Start)
+	}
+	require.Equal(t, []int{0, 6, 8}, starts)
+	require.Equal(t, []Edge{
+		{From: 0, To: 2, Kind: EdgeInvalid},
+		{From: 0, To: 6, Kind: EdgeFallthrough},
+	}, d.Blocks[0].Edges)
+	require.Equal(t, []Edge{
+		{From: 6, To: 1, Kind: EdgeInvalid},
+		{From: 6, To: 8, Kind: EdgeFallthrough},
+	}, d.Blocks[1].Edges)
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, d.WriteText(buf))
+	require.Contains(t, buf.String(), "L0: ; -> L2 (invalid), L6 (fallthrough)")
+}
+
+func TestDisassembleErrors(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		_, err := Disassemble(nil, nil)
+		require.Error(t, err)
+	})
+	t.Run("bad instruction", func(t *testing.T) {
+		_, err := Disassemble([]byte{byte(opcode.PUSHDATA1), 10}, nil)
+		require.Error(t, err)
+	})
+	t.Run("bad jump", func(t *testing.T) {
+		_, err := Disassemble([]byte{byte(opcode.JMP), 10}, nil)
+		require.Error(t, err)
+	})
+	t.Run("bad method offset", func(t *testing.T) {
+		m := manifest.NewManifest("Test")
+		m.ABI.Methods = append(m.ABI.Methods, manifest.Method{Name: "main", Offset: 10})
+		_, err := Disassemble([]byte{byte(opcode.RET)}, m)
+		require.Error(t, err)
+	})
+}
@@ -52,3 +52,23 @@ func FuzzVMDontPanic(f *testing.F) {
 		})
 	})
 }
+
+func FuzzVMDifferential(f *testing.F) {
+	for _, s := range fuzzSeedValidScripts {
+		f.Add(s)
+	}
+	ref := getDiffReference()
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if ref == nil {
+			// Nothing to compare with, just check that generated scripts are
+			// correct and can be executed.
+			script := joinInstrs(generateScript(data))
+			require.NoError(t, IsScriptCorrect(script, nil))
+			require.NotPanics(t, func() {
+				_ = runDiffScript(script)
+			})
+			return
+		}
+		checkDifferential(t, ref, data)
+	})
+}
@@ -0,0 +1,56 @@
+package vm
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/nspcc-dev/neo-go/pkg/vm/stackitem"
+)
+
+// Limits contains VM execution limits. Default values (returned by
+// DefaultLimits) match the ones used by public networks.
+type Limits struct {
+	// MaxStackSize is the maximum number of items allowed to be on all
+	// stacks at once.
+	MaxStackSize int
+	// MaxInvocationStackSize is the maximum size of an invocation stack.
+	MaxInvocationStackSize int
+	// MaxTryNestingDepth is the maximum level of TRY nesting allowed.
+	MaxTryNestingDepth int
+	// MaxItemSize is the maximum size of items that can be pushed by
+	// PUSHDATA* or created by NEWBUFFER, CAT and serialization.
+	MaxItemSize int
+	// MaxComparableSize is the maximum size of byte arrays that can be
+	// compared by EQUAL and NOTEQUAL (map keys are much smaller, so it
+	// doesn't affect map operations).
+	MaxComparableSize int
+	// MaxComparableNumOfItems is the maximum number of items that can be
+	// compared for structs.
+	MaxComparableNumOfItems int
+}
+
+// DefaultLimits returns default VM limits.
+func DefaultLimits() Limits {
+	return Limits{
+		MaxStackSize:            MaxStackSize,
+		MaxInvocationStackSize:  MaxInvocationStackSize,
+		MaxTryNestingDepth:      MaxTryNestingDepth,
+		MaxItemSize:             stackitem.MaxSize,
+		MaxComparableSize:       stackitem.MaxByteArrayComparableSize,
+		MaxComparableNumOfItems: stackitem.MaxComparableNumOfItems,
+	}
+}
+
+// IsValid checks that all limits are positive and MaxItemSize doesn't exceed
+// stackitem.MaxSize (item serialization and PUSHDATA4 parameters are always
+// limited by it, so it can only be lowered).
+func (l Limits) IsValid() error {
+	if l.MaxStackSize <= 0 || l.MaxInvocationStackSize <= 0 || l.MaxTryNestingDepth <= 0 ||
+		l.MaxItemSize <= 0 || l.MaxComparableSize <= 0 || l.MaxComparableNumOfItems <= 0 {
+		return errors.New("all VM limits must be positive")
+	}
+	if l.MaxItemSize > stackitem.MaxSize {
+		return fmt.Errorf("MaxItemSize can't exceed %d", stackitem.MaxSize)
+	}
+	return nil
+}
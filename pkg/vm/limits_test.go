@@ -0,0 +1,99 @@
+package vm
+
+import (
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/io"
+	"github.com/nspcc-dev/neo-go/pkg/vm/emit"
+	"github.com/nspcc-dev/neo-go/pkg/vm/opcode"
+	"github.com/nspcc-dev/neo-go/pkg/vm/stackitem"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimitsIsValid(t *testing.T) {
+	require.NoError(t, DefaultLimits().IsValid())
+
+	l := DefaultLimits()
+	l.MaxComparableSize = 0
+	require.Error(t, l.IsValid())
+
+	l = DefaultLimits()
+	l.MaxItemSize = stackitem.MaxSize + 1
+	require.Error(t, l.IsValid())
+}
+
+func TestVMLimits(t *testing.T) {
+	loadWithLimits := func(prog []byte, modify func(l *Limits)) *VM {
+		v := newTestVM()
+		l := DefaultLimits()
+		modify(&l)
+		v.SetLimits(l)
+		v.LoadScript(prog)
+		return v
+	}
+	check := func(t *testing.T, prog []byte, modify func(l *Limits)) {
+		runVM(t, loadWithLimits(prog, func(*Limits) {}))
+		checkVMFailed(t, loadWithLimits(prog, modify))
+	}
+
+	t.Run("MaxStackSize", func(t *testing.T) {
+		check(t, makeProgram(opcode.PUSH1, opcode.PUSH2, opcode.PUSH3), func(l *Limits) {
+			l.MaxStackSize = 2
+		})
+	})
+	t.Run("MaxStackSize, NEWARRAY", func(t *testing.T) {
+		check(t, makeProgram(opcode.PUSH10, opcode.NEWARRAY), func(l *Limits) {
+			l.MaxStackSize = 9
+		})
+	})
+	t.Run("MaxInvocationStackSize", func(t *testing.T) {
+		// CALL +3 (skipping RET), CALL +3, RET, RET.
+		prog := []byte{byte(opcode.CALL), 3, byte(opcode.RET), byte(opcode.CALL), 3, byte(opcode.RET), byte(opcode.RET)}
+		check(t, prog, func(l *Limits) {
+			l.MaxInvocationStackSize = 2
+		})
+	})
+	t.Run("MaxTryNestingDepth", func(t *testing.T) {
+		w := io.NewBufBinWriter()
+		emit.Instruction(w.BinWriter, opcode.TRY, []byte{0, 11})
+		emit.Instruction(w.BinWriter, opcode.TRY, []byte{0, 5})
+		emit.Instruction(w.BinWriter, opcode.ENDTRY, []byte{3})
+		emit.Opcodes(w.BinWriter, opcode.ENDFINALLY)
+		emit.Instruction(w.BinWriter, opcode.ENDTRY, []byte{3})
+		emit.Opcodes(w.BinWriter, opcode.ENDFINALLY, opcode.RET)
+		check(t, w.Bytes(), func(l *Limits) {
+			l.MaxTryNestingDepth = 1
+		})
+	})
+	t.Run("MaxItemSize, NEWBUFFER", func(t *testing.T) {
+		check(t, makeProgram(opcode.PUSH10, opcode.NEWBUFFER), func(l *Limits) {
+			l.MaxItemSize = 9
+		})
+	})
+	t.Run("MaxItemSize, PUSHDATA", func(t *testing.T) {
+		w := io.NewBufBinWriter()
+		emit.Bytes(w.BinWriter, make([]byte, 10))
+		check(t, w.Bytes(), func(l *Limits) {
+			l.MaxItemSize = 9
+		})
+	})
+	t.Run("MaxItemSize, CAT", func(t *testing.T) {
+		check(t, makeProgram(opcode.PUSH5, opcode.NEWBUFFER, opcode.DUP, opcode.CAT), func(l *Limits) {
+			l.MaxItemSize = 9
+		})
+	})
+	t.Run("MaxComparableSize", func(t *testing.T) {
+		w := io.NewBufBinWriter()
+		emit.Bytes(w.BinWriter, []byte{1, 2, 3})
+		emit.Opcodes(w.BinWriter, opcode.DUP, opcode.EQUAL)
+		check(t, w.Bytes(), func(l *Limits) {
+			l.MaxComparableSize = 2
+		})
+	})
+	t.Run("MaxComparableNumOfItems", func(t *testing.T) {
+		prog := makeProgram(opcode.PUSH3, opcode.NEWSTRUCT, opcode.PUSH3, opcode.NEWSTRUCT, opcode.EQUAL)
+		check(t, prog, func(l *Limits) {
+			l.MaxComparableNumOfItems = 3
+		})
+	})
+}
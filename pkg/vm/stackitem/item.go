@@ -254,10 +254,10 @@ func (i *Struct) Equals(s Item) bool {
 		return false
 	}
 	var limit = MaxComparableNumOfItems - 1 // 1 for current element.
-	return i.equalStruct(val, &limit)
+	return i.equalStruct(val, &limit, MaxByteArrayComparableSize)
 }
 
-func (i *Struct) equalStruct(s *Struct, limit *int) bool {
+func (i *Struct) equalStruct(s *Struct, limit *int, maxSize int) bool {
 	if i == s {
 		return true
 	} else if len(i.value) != len(s.value) {
@@ -271,7 +271,11 @@ func (i *Struct) equalStruct(s *Struct, limit *int) bool {
 		sa, oka := i.value[j].(*Struct)
 		sb, okb := s.value[j].(*Struct)
 		if oka && okb {
-			if !sa.equalStruct(sb, limit) {
+			if !sa.equalStruct(sb, limit, maxSize) {
+				return false
+			}
+		} else if ba, ok := i.value[j].(*ByteArray); ok {
+			if !ba.equals(s.value[j], maxSize) {
 				return false
 			}
 		} else if !i.value[j].Equals(s.value[j]) {
@@ -594,7 +598,11 @@ func (i ByteArray) TryInteger() (*big.Int, error) {
 
 // Equals implements Item interface.
 func (i *ByteArray) Equals(s Item) bool {
-	if len(*i) > MaxByteArrayComparableSize {
+	return i.equals(s, MaxByteArrayComparableSize)
+}
+
+func (i *ByteArray) equals(s Item, maxSize int) bool {
+	if len(*i) > maxSize {
 		panic(errTooBigComparable)
 	}
 	if i == s {
@@ -606,12 +614,32 @@ func (i *ByteArray) Equals(s Item) bool {
 	if !ok {
 		return false
 	}
-	if len(*val) > MaxByteArrayComparableSize {
+	if len(*val) > maxSize {
 		panic(errTooBigComparable)
 	}
 	return bytes.Equal(*i, *val)
 }
 
+// EqualsWithLimits checks whether a and b are equal the same way a.Equals(b)
+// does, but uses the given maximum comparable ByteArray size and the maximum
+// number of compared Struct elements instead of MaxByteArrayComparableSize and
+// MaxComparableNumOfItems. It panics if these limits are exceeded.
+func EqualsWithLimits(a, b Item, maxComparableSize, maxComparableNumOfItems int) bool {
+	switch x := a.(type) {
+	case *ByteArray:
+		return x.equals(b, maxComparableSize)
+	case *Struct:
+		y, ok := b.(*Struct)
+		if !ok {
+			return false
+		}
+		var limit = maxComparableNumOfItems - 1 // 1 for current element.
+		return x.equalStruct(y, &limit, maxComparableSize)
+	default:
+		return a.Equals(b)
+	}
+}
+
 // Dup implements Item interface.
 func (i *ByteArray) Dup() Item {
 	ba := slice.Copy(*i)
@@ -420,6 +420,25 @@ func TestEqualsDeepStructure(t *testing.T) {
 	require.Panics(t, func() { sa.Equals(sb) })
 }
 
+func TestEqualsWithLimits(t *testing.T) {
+	small := NewByteArray([]byte{1, 2, 3})
+	large := NewByteArray(make([]byte, MaxByteArrayComparableSize+1))
+
+	require.True(t, EqualsWithLimits(small, NewByteArray([]byte{1, 2, 3}), 3, 10))
+	require.Panics(t, func() { EqualsWithLimits(small, small, 2, 10) })
+	require.True(t, EqualsWithLimits(large, large.Dup(), MaxByteArrayComparableSize+1, 10))
+
+	sa := NewStruct([]Item{small, NewStruct([]Item{Make(1), Make(2)})})
+	sb := NewStruct([]Item{NewByteArray([]byte{1, 2, 3}), NewStruct([]Item{Make(1), Make(2)})})
+	require.True(t, EqualsWithLimits(sa, sb, 3, 10))
+	require.Panics(t, func() { EqualsWithLimits(sa, sb, 2, 10) })
+	require.Panics(t, func() { EqualsWithLimits(sa, sb, 3, 4) })
+	require.False(t, EqualsWithLimits(sa, small, 3, 10))
+
+	// Other types are compared as usual.
+	require.True(t, EqualsWithLimits(Make(1), Make(1), 0, 0))
+}
+
 var marshalJSONTestCases = []struct {
 	input  Item
 	result []byte
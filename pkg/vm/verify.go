@@ -0,0 +1,583 @@
+package vm
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+
+	"github.com/nspcc-dev/neo-go/pkg/encoding/bigint"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/callflag"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/manifest"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/nef"
+	"github.com/nspcc-dev/neo-go/pkg/util/bitfield"
+	"github.com/nspcc-dev/neo-go/pkg/vm/opcode"
+)
+
+// SyscallDesc is a static description of a system call used for script
+// verification.
+type SyscallDesc struct {
+	Name string
+	// ParamCount is the number of items the syscall takes from the stack.
+	ParamCount int
+	// ReturnCount is the number of items the syscall pushes onto the stack,
+	// it's negative if the number can't be determined statically.
+	ReturnCount   int
+	RequiredFlags callflag.CallFlag
+}
+
+// VerifyOptions contains additional data used by Verify, every field is
+// optional, but the more data is provided, the more checks are performed.
+type VerifyOptions struct {
+	// Manifest is used to get method boundaries, parameters and return types.
+	Manifest *manifest.Manifest
+	// Tokens are NEF method tokens used by CALLT instructions.
+	Tokens []nef.MethodToken
+	// Syscalls maps system call IDs to their descriptions.
+	Syscalls map[uint32]SyscallDesc
+	// Limits are VM limits to check against, default ones are used if nil.
+	Limits *Limits
+}
+
+// Issue is a problem found by Verify.
+type Issue struct {
+	Offset int    `json:"offset"`
+	Method string `json:"method,omitempty"`
+	// Error is true for problems that lead to execution failure or are
+	// certainly a bug, it's false for warnings.
+	Error   bool   `json:"error"`
+	Message string `json:"message"`
+}
+
+// String implements fmt.Stringer interface.
+func (i Issue) String() string {
+	kind := "warning"
+	if i.Error {
+		kind = "error"
+	}
+	if i.Method != "" {
+		return fmt.Sprintf("%s: %s (method %s, offset %d)", kind, i.Message, i.Method, i.Offset)
+	}
+	return fmt.Sprintf("%s: %s (offset %d)", kind, i.Message, i.Offset)
+}
+
+// stackState is an abstract state of the VM at some point of the script.
+type stackState struct {
+	// depth is the number of items on the evaluation stack, valid only if
+	// known is true.
+	depth int
+	known bool
+	// tryLo and tryHi are the lower and upper bounds of TRY nesting level.
+	tryLo int
+	tryHi int
+}
+
+// methodSummary describes the effect of method invocation on the stack.
+type methodSummary struct {
+	done bool
+	// start is stack depth at method entry.
+	start int
+	// min is the minimum stack depth reached.
+	min int
+	// ret is stack depth at RET, valid only if retKnown is true.
+	ret      int
+	retKnown bool
+	retSet   bool
+	// flags are the call flags required by the method and its callees.
+	flags callflag.CallFlag
+}
+
+type verifier struct {
+	o         *VerifyOptions
+	limits    Limits
+	d         *Disassembly
+	blocks    map[int]*BasicBlock
+	methods   map[int]*manifest.Method
+	visited   map[int]bool
+	summaries map[int]*methodSummary
+	seen      map[string]bool
+	issues    []Issue
+	// quiet suppresses instruction-level reports until the state of the
+	// method analyzed is final.
+	quiet bool
+}
+
+// Verify performs static analysis of the script and returns a list of
+// problems found. In addition to checks done by IsScriptCorrect it checks
+// stack depth consistency across branches, TRY blocks balance, RET stack
+// state against the manifest ABI return type, SYSCALLs against the call
+// flags of safe methods, and reports unreachable code. Error is returned
+// only if the script can't be decoded at all.
+func Verify(script []byte, o *VerifyOptions) ([]Issue, error) {
+	if o == nil {
+		o = new(VerifyOptions)
+	}
+	var methods bitfield.Field
+	if o.Manifest != nil {
+		methods = bitfield.New(len(script))
+		for i := range o.Manifest.ABI.Methods {
+			off := o.Manifest.ABI.Methods[i].Offset
+			if off < 0 || off >= len(script) {
+				return nil, fmt.Errorf("method %s has invalid offset %d", o.Manifest.ABI.Methods[i].Name, off)
+			}
+			methods.Set(off)
+		}
+	}
+	if err := IsScriptCorrect(script, methods); err != nil {
+		return nil, err
+	}
+	d, err := Disassemble(script, o.Manifest)
+	if err != nil {
+		return nil, err
+	}
+	v := &verifier{
+		o:         o,
+		limits:    DefaultLimits(),
+		d:         d,
+		blocks:    make(map[int]*BasicBlock, len(d.Blocks)),
+		methods:   make(map[int]*manifest.Method),
+		visited:   make(map[int]bool, len(d.Blocks)),
+		summaries: make(map[int]*methodSummary),
+		seen:      make(map[string]bool),
+	}
+	if o.Limits != nil {
+		v.limits = *o.Limits
+	}
+	for _, b := range d.Blocks {
+		v.blocks[b.Start] = b
+	}
+	if o.Manifest != nil {
+		for i := range o.Manifest.ABI.Methods {
+			md := &o.Manifest.ABI.Methods[i]
+			v.checkSignature(md)
+			if _, ok := v.methods[md.Offset]; !ok {
+				v.methods[md.Offset] = md
+			}
+		}
+		for i := range o.Manifest.ABI.Methods {
+			v.analyzeMethod(o.Manifest.ABI.Methods[i].Offset)
+		}
+	} else {
+		v.analyzeMethod(0)
+	}
+	// Lambdas can only be reached via PUSHA.
+	for _, b := range d.Blocks {
+		for _, e := range b.Edges {
+			if e.Kind == EdgePointer {
+				v.analyzeMethod(e.To)
+			}
+		}
+	}
+	if o.Manifest != nil {
+		v.checkUnreachable()
+	}
+	sort.SliceStable(v.issues, func(i, j int) bool { return v.issues[i].Offset < v.issues[j].Offset })
+	return v.issues, nil
+}
+
+func (v *verifier) report(off int, isErr bool, format string, args ...interface{}) {
+	if !v.quiet {
+		v.addIssue(off, isErr, format, args...)
+	}
+}
+
+func (v *verifier) addIssue(off int, isErr bool, format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	key := fmt.Sprintf("%d:%s", off, msg)
+	if v.seen[key] {
+		return
+	}
+	v.seen[key] = true
+	v.issues = append(v.issues, Issue{
+		Offset:  off,
+		Method:  v.d.methodAt(off),
+		Error:   isErr,
+		Message: msg,
+	})
+}
+
+// checkSignature checks special methods signatures.
+func (v *verifier) checkSignature(md *manifest.Method) {
+	switch md.Name {
+	case manifest.MethodDeploy:
+		if len(md.Parameters) != 2 || md.Parameters[0].Type != smartcontract.AnyType ||
+			md.Parameters[1].Type != smartcontract.BoolType || md.ReturnType != smartcontract.VoidType {
+			v.report(md.Offset, true, "%s method should have (Any, Boolean) parameters and return Void", md.Name)
+		}
+	case manifest.MethodVerify:
+		if md.ReturnType != smartcontract.BoolType {
+			v.report(md.Offset, true, "%s method should return Boolean", md.Name)
+		}
+	case manifest.MethodInit:
+		if len(md.Parameters) != 0 || md.ReturnType != smartcontract.VoidType {
+			v.report(md.Offset, true, "%s method should have no parameters and return Void", md.Name)
+		}
+	}
+}
+
+// analyzeMethod walks through all blocks reachable from the method entry and
+// returns its summary.
+func (v *verifier) analyzeMethod(entry int) *methodSummary {
+	if s, ok := v.summaries[entry]; ok {
+		return s
+	}
+	s := new(methodSummary)
+	v.summaries[entry] = s
+	b, ok := v.blocks[entry]
+	if !ok {
+		return s
+	}
+	md := v.methods[entry]
+	if md != nil {
+		s.start = len(md.Parameters)
+	}
+	if first := b.Instructions[0]; first.Opcode == opcode.INITSLOT {
+		args := int(first.Parameter[1])
+		if md != nil && args != len(md.Parameters) {
+			v.addIssue(entry, true, "method takes %d argument(s) while it has %d parameter(s) in manifest",
+				args, len(md.Parameters))
+		}
+		s.start = args
+	}
+	s.min = s.start
+
+	var (
+		in    = map[int]stackState{entry: {depth: s.start, known: true}}
+		queue = []int{entry}
+		quiet = v.quiet
+	)
+	propagate := func(to int, st stackState) {
+		prev, ok := in[to]
+		if !ok {
+			in[to] = st
+			queue = append(queue, to)
+			return
+		}
+		next := prev
+		if st.tryLo < next.tryLo {
+			next.tryLo = st.tryLo
+		}
+		if st.tryHi > next.tryHi {
+			next.tryHi = st.tryHi
+		}
+		if prev.known && st.known && prev.depth != st.depth {
+			v.addIssue(to, true, "inconsistent stack depth at merge point: %d vs %d", prev.depth, st.depth)
+		}
+		if !prev.known && st.known {
+			next.depth, next.known = st.depth, true
+		}
+		if next != prev {
+			in[to] = next
+			queue = append(queue, to)
+		}
+	}
+	v.quiet = true
+	for len(queue) > 0 {
+		start := queue[0]
+		queue = queue[1:]
+		b := v.blocks[start]
+		v.visited[start] = true
+		out := v.runBlock(b, in[start], s, md)
+		for _, e := range b.Edges {
+			switch e.Kind {
+			case EdgeCall, EdgePointer:
+				continue
+			case EdgeCatch, EdgeFinally:
+				// Exception can be thrown at any stack depth.
+				propagate(e.To, stackState{tryLo: out.tryLo, tryHi: out.tryHi})
+			default:
+				propagate(e.To, out)
+			}
+		}
+	}
+	// Input states are final now, so every block is run once more to
+	// report problems found.
+	starts := make([]int, 0, len(in))
+	for start := range in {
+		starts = append(starts, start)
+	}
+	sort.Ints(starts)
+	v.quiet = false
+	for _, start := range starts {
+		v.runBlock(v.blocks[start], in[start], s, md)
+	}
+	v.quiet = quiet
+	s.done = true
+	return s
+}
+
+// runBlock simulates block execution and returns the resulting state.
+func (v *verifier) runBlock(b *BasicBlock, st stackState, s *methodSummary, md *manifest.Method) stackState {
+	var (
+		exported = md != nil
+		safe     = md != nil && md.Safe
+		// constant is the value pushed by the previous instruction if it's
+		// a small integer.
+		constant    int
+		hasConstant bool
+	)
+	for _, instr := range b.Instructions {
+		var (
+			op      = instr.Opcode
+			off     = instr.Offset
+			unknown bool
+		)
+		pop, push := stackEffect(op)
+		switch op {
+		case opcode.PACK, opcode.PACKSTRUCT:
+			if hasConstant {
+				pop, push = constant+1, 1
+			} else {
+				pop, unknown = 1, true
+			}
+		case opcode.PACKMAP:
+			if hasConstant {
+				pop, push = 2*constant+1, 1
+			} else {
+				pop, unknown = 1, true
+			}
+		case opcode.UNPACK:
+			pop, unknown = 1, true
+		case opcode.PICK, opcode.ROLL, opcode.XDROP, opcode.REVERSEN:
+			n := 0
+			if hasConstant {
+				n = constant
+			}
+			switch op {
+			case opcode.PICK:
+				pop, push = n+2, n+2
+			case opcode.ROLL:
+				pop, push = n+2, n+1
+			case opcode.XDROP:
+				pop, push = n+2, n
+			case opcode.REVERSEN:
+				pop, push = n+1, n
+			}
+		case opcode.CLEAR:
+			st.depth, st.known = 0, true
+		case opcode.INITSLOT:
+			pop = int(instr.Parameter[1])
+		case opcode.CALL, opcode.CALLL:
+			target := -1
+			for _, e := range b.Edges {
+				if e.Kind == EdgeCall {
+					target = e.To
+				}
+			}
+			callee := v.analyzeMethod(target)
+			if !callee.done || !callee.retKnown {
+				pop, unknown = callee.start-callee.min, true
+			} else {
+				pop, push = callee.start-callee.min, callee.ret-callee.min
+			}
+			s.flags |= callee.flags
+			if safe && !callflag.ReadOnly.Has(callee.flags) {
+				v.report(off, true, "called method requires %s flags not allowed for safe method", callee.flags)
+			}
+		case opcode.CALLA:
+			pop, unknown = 1, true
+		case opcode.CALLT:
+			id := int(binary.LittleEndian.Uint16(instr.Parameter))
+			if id < len(v.o.Tokens) {
+				t := v.o.Tokens[id]
+				pop = int(t.ParamCount)
+				if t.HasReturn {
+					push = 1
+				}
+			} else {
+				if v.o.Tokens != nil {
+					v.report(off, true, "invalid method token %d", id)
+				}
+				unknown = true
+			}
+		case opcode.SYSCALL:
+			desc, ok := v.o.Syscalls[GetInteropID(instr.Parameter)]
+			if !ok {
+				if v.o.Syscalls != nil {
+					v.report(off, true, "unknown syscall %x", instr.Parameter)
+				}
+				unknown = true
+				break
+			}
+			pop = desc.ParamCount
+			if desc.ReturnCount < 0 {
+				unknown = true
+			} else {
+				push = desc.ReturnCount
+			}
+			s.flags |= desc.RequiredFlags
+			if safe && !callflag.ReadOnly.Has(desc.RequiredFlags) {
+				v.report(off, true, "%s requires %s flags not allowed for safe method", desc.Name, desc.RequiredFlags)
+			}
+		case opcode.TRY, opcode.TRYL:
+			if st.tryLo >= v.limits.MaxTryNestingDepth {
+				v.report(off, true, "maximum TRY nesting depth exceeded")
+			}
+			// Bounds are limited to make loops converge.
+			if st.tryLo <= v.limits.MaxTryNestingDepth {
+				st.tryLo++
+			}
+			if st.tryHi <= v.limits.MaxTryNestingDepth {
+				st.tryHi++
+			}
+		case opcode.ENDTRY, opcode.ENDTRYL, opcode.ENDFINALLY:
+			if st.tryHi == 0 {
+				v.report(off, true, "%s outside of TRY block", op)
+			} else {
+				st.tryHi--
+				if st.tryLo > 0 {
+					st.tryLo--
+				}
+			}
+		case opcode.RET:
+			if st.tryLo > 0 {
+				v.report(off, false, "RET inside TRY block")
+			}
+			if !st.known {
+				s.retKnown = false
+				s.retSet = true
+				break
+			}
+			if exported {
+				switch {
+				case md.ReturnType == smartcontract.VoidType && st.depth != 0:
+					v.report(off, true, "RET with %d item(s) on stack in void method", st.depth)
+				case md.ReturnType != smartcontract.VoidType && st.depth != 1:
+					v.report(off, true, "RET with %d item(s) on stack, 1 expected", st.depth)
+				}
+			}
+			if !s.retSet {
+				s.ret, s.retKnown, s.retSet = st.depth, true, true
+			} else if s.retKnown && s.ret != st.depth {
+				v.report(off, true, "inconsistent stack depth at RET: %d vs %d", s.ret, st.depth)
+			}
+		}
+		if st.known {
+			if st.depth-pop < s.min {
+				s.min = st.depth - pop
+			}
+			// Internal functions can take their arguments directly from
+			// the stack, but exported ones can't.
+			if st.depth < pop && exported {
+				v.report(off, true, "stack underflow: %s needs %d item(s), %d available", op, pop, st.depth)
+				unknown = true
+			}
+			st.depth += push - pop
+			if unknown {
+				st.known = false
+			}
+		}
+		constant, hasConstant = intConstant(instr, v.limits.MaxStackSize)
+	}
+	return st
+}
+
+// checkUnreachable reports blocks not visited by any of the analyzed methods.
+func (v *verifier) checkUnreachable() {
+	var start, end = -1, -1
+	flush := func() {
+		if start >= 0 {
+			v.report(start, false, "unreachable code at %d-%d", start, end-1)
+		}
+		start, end = -1, -1
+	}
+	for _, b := range v.d.Blocks {
+		if v.visited[b.Start] || isFiller(b) {
+			flush()
+			continue
+		}
+		if start < 0 {
+			start = b.Start
+		}
+		end = b.End
+	}
+	flush()
+}
+
+// isFiller returns true if the block contains only instructions that don't
+// do anything useful, the compiler can leave such blocks after RET.
+func isFiller(b *BasicBlock) bool {
+	for _, instr := range b.Instructions {
+		switch instr.Opcode {
+		case opcode.NOP, opcode.JMP, opcode.JMPL, opcode.RET:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// intConstant returns an integer pushed by the instruction if it's a
+// non-negative value not exceeding max.
+func intConstant(instr Instruction, max int) (int, bool) {
+	switch {
+	case opcode.PUSH0 <= instr.Opcode && instr.Opcode <= opcode.PUSH16:
+		return int(instr.Opcode - opcode.PUSH0), true
+	case instr.Opcode <= opcode.PUSHINT64:
+		n := bigint.FromBytes(instr.Parameter)
+		if n.IsInt64() && n.Sign() >= 0 && n.Int64() <= int64(max) {
+			return int(n.Int64()), true
+		}
+	}
+	return 0, false
+}
+
+// stackEffect returns the number of items popped and pushed by instructions
+// with fixed stack effect.
+func stackEffect(op opcode.Opcode) (int, int) {
+	switch op {
+	case opcode.PUSHINT8, opcode.PUSHINT16, opcode.PUSHINT32, opcode.PUSHINT64,
+		opcode.PUSHINT128, opcode.PUSHINT256, opcode.PUSHA, opcode.PUSHNULL,
+		opcode.PUSHDATA1, opcode.PUSHDATA2, opcode.PUSHDATA4,
+		opcode.DEPTH, opcode.NEWARRAY0, opcode.NEWSTRUCT0, opcode.NEWMAP:
+		return 0, 1
+	case opcode.JMPIF, opcode.JMPIFL, opcode.JMPIFNOT, opcode.JMPIFNOTL,
+		opcode.ASSERT, opcode.THROW, opcode.DROP, opcode.REVERSEITEMS, opcode.CLEARITEMS:
+		return 1, 0
+	case opcode.JMPEQ, opcode.JMPEQL, opcode.JMPNE, opcode.JMPNEL,
+		opcode.JMPGT, opcode.JMPGTL, opcode.JMPGE, opcode.JMPGEL,
+		opcode.JMPLT, opcode.JMPLTL, opcode.JMPLE, opcode.JMPLEL,
+		opcode.APPEND, opcode.REMOVE:
+		return 2, 0
+	case opcode.SETITEM:
+		return 3, 0
+	case opcode.MEMCPY:
+		return 5, 0
+	case opcode.NIP, opcode.CAT, opcode.LEFT, opcode.RIGHT,
+		opcode.AND, opcode.OR, opcode.XOR, opcode.EQUAL, opcode.NOTEQUAL,
+		opcode.ADD, opcode.SUB, opcode.MUL, opcode.DIV, opcode.MOD, opcode.POW,
+		opcode.SHL, opcode.SHR, opcode.BOOLAND, opcode.BOOLOR,
+		opcode.NUMEQUAL, opcode.NUMNOTEQUAL, opcode.LT, opcode.LE, opcode.GT, opcode.GE,
+		opcode.MIN, opcode.MAX, opcode.HASKEY, opcode.PICKITEM:
+		return 2, 1
+	case opcode.SUBSTR, opcode.WITHIN:
+		return 3, 1
+	case opcode.DUP:
+		return 1, 2
+	case opcode.OVER, opcode.TUCK:
+		return 2, 3
+	case opcode.SWAP:
+		return 2, 2
+	case opcode.ROT, opcode.REVERSE3:
+		return 3, 3
+	case opcode.REVERSE4:
+		return 4, 4
+	case opcode.NEWBUFFER, opcode.INVERT, opcode.SIGN, opcode.ABS, opcode.NEGATE,
+		opcode.INC, opcode.DEC, opcode.SQRT, opcode.NOT, opcode.NZ,
+		opcode.NEWARRAY, opcode.NEWARRAYT, opcode.NEWSTRUCT, opcode.SIZE,
+		opcode.KEYS, opcode.VALUES, opcode.POPITEM, opcode.ISNULL, opcode.ISTYPE, opcode.CONVERT:
+		return 1, 1
+	}
+	if opcode.PUSHM1 <= op && op <= opcode.PUSH16 {
+		return 0, 1
+	}
+	if (opcode.LDSFLD0 <= op && op <= opcode.LDSFLD) || (opcode.LDLOC0 <= op && op <= opcode.LDLOC) ||
+		(opcode.LDARG0 <= op && op <= opcode.LDARG) {
+		return 0, 1
+	}
+	if (opcode.STSFLD0 <= op && op <= opcode.STSFLD) || (opcode.STLOC0 <= op && op <= opcode.STLOC) ||
+		(opcode.STARG0 <= op && op <= opcode.STARG) {
+		return 1, 0
+	}
+	return 0, 0
+}
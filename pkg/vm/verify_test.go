@@ -0,0 +1,243 @@
+package vm
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/core/interop/interopnames"
+	"github.com/nspcc-dev/neo-go/pkg/io"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/callflag"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/manifest"
+	"github.com/nspcc-dev/neo-go/pkg/vm/emit"
+	"github.com/nspcc-dev/neo-go/pkg/vm/opcode"
+	"github.com/stretchr/testify/require"
+)
+
+var testSyscalls = map[uint32]SyscallDesc{
+	interopnames.ToID([]byte(interopnames.SystemStoragePut)): {
+		Name:          interopnames.SystemStoragePut,
+		ParamCount:    3,
+		RequiredFlags: callflag.WriteStates,
+	},
+	interopnames.ToID([]byte(interopnames.SystemStorageGetContext)): {
+		Name:          interopnames.SystemStorageGetContext,
+		ReturnCount:   1,
+		RequiredFlags: callflag.ReadStates,
+	},
+}
+
+func testManifest(methods ...manifest.Method) *manifest.Manifest {
+	m := manifest.NewManifest("Test")
+	m.ABI.Methods = methods
+	return m
+}
+
+func requireIssue(t *testing.T, issues []Issue, isErr bool, offset int, msg string) {
+	for _, issue := range issues {
+		if issue.Offset == offset && issue.Error == isErr && strings.Contains(issue.Message, msg) {
+			return
+		}
+	}
+	require.Failf(t, "issue not found", "%q at %d in %v", msg, offset, issues)
+}
+
+func TestVerifyCorrect(t *testing.T) {
+	w := io.NewBufBinWriter()
+	emit.Instruction(w.BinWriter, opcode.INITSLOT, []byte{1, 1}) // 0
+	emit.Opcodes(w.BinWriter, opcode.LDARG0)                     // 3
+	emit.Instruction(w.BinWriter, opcode.JMPIFNOT, []byte{8})    // 4
+	emit.Opcodes(w.BinWriter, opcode.PUSH1, opcode.PUSH2)        // 6, 7
+	emit.Instruction(w.BinWriter, opcode.CALL, []byte{6})        // 8
+	emit.Instruction(w.BinWriter, opcode.JMP, []byte{3})         // 10
+	emit.Opcodes(w.BinWriter, opcode.PUSH0)                      // 12
+	emit.Opcodes(w.BinWriter, opcode.RET)                        // 13
+	emit.Opcodes(w.BinWriter, opcode.PUSH2, opcode.PACK)         // 14, 15
+	emit.Opcodes(w.BinWriter, opcode.SIZE, opcode.RET)           // 16, 17
+	require.NoError(t, w.Err)
+
+	m := testManifest(manifest.Method{
+		Name:       "main",
+		Offset:     0,
+		Parameters: []manifest.Parameter{manifest.NewParameter("a", smartcontract.BoolType)},
+		ReturnType: smartcontract.IntegerType,
+		Safe:       true,
+	})
+	issues, err := Verify(w.Bytes(), &VerifyOptions{Manifest: m, Syscalls: testSyscalls})
+	require.NoError(t, err)
+	require.Equal(t, 0, len(issues), issues)
+}
+
+func TestVerifyStackDepth(t *testing.T) {
+	t.Run("merge mismatch", func(t *testing.T) {
+		w := io.NewBufBinWriter()
+		emit.Opcodes(w.BinWriter, opcode.PUSH1)                // 0
+		emit.Instruction(w.BinWriter, opcode.JMPIF, []byte{4}) // 1
+		emit.Opcodes(w.BinWriter, opcode.PUSH2, opcode.NOP)    // 3, 4
+		emit.Opcodes(w.BinWriter, opcode.RET)                  // 5
+		require.NoError(t, w.Err)
+
+		issues, err := Verify(w.Bytes(), nil)
+		require.NoError(t, err)
+		requireIssue(t, issues, true, 5, "inconsistent stack depth at merge point")
+	})
+	t.Run("underflow", func(t *testing.T) {
+		script := []byte{byte(opcode.PUSH1), byte(opcode.ADD), byte(opcode.RET)}
+		m := testManifest(manifest.Method{Name: "main", ReturnType: smartcontract.IntegerType})
+		issues, err := Verify(script, &VerifyOptions{Manifest: m})
+		require.NoError(t, err)
+		require.Equal(t, 1, len(issues), issues)
+		requireIssue(t, issues, true, 1, "stack underflow")
+	})
+	t.Run("void method returns value", func(t *testing.T) {
+		script := []byte{byte(opcode.PUSH1), byte(opcode.RET)}
+		m := testManifest(manifest.Method{Name: "main", ReturnType: smartcontract.VoidType})
+		issues, err := Verify(script, &VerifyOptions{Manifest: m})
+		require.NoError(t, err)
+		requireIssue(t, issues, true, 1, "in void method")
+	})
+	t.Run("no return value", func(t *testing.T) {
+		script := []byte{byte(opcode.RET)}
+		m := testManifest(manifest.Method{Name: "main", ReturnType: smartcontract.IntegerType})
+		issues, err := Verify(script, &VerifyOptions{Manifest: m})
+		require.NoError(t, err)
+		requireIssue(t, issues, true, 0, "1 expected")
+	})
+	t.Run("inconsistent return", func(t *testing.T) {
+		w := io.NewBufBinWriter()
+		emit.Instruction(w.BinWriter, opcode.CALL, []byte{3})  // 0
+		emit.Opcodes(w.BinWriter, opcode.RET)                  // 2
+		emit.Opcodes(w.BinWriter, opcode.PUSH1)                // 3
+		emit.Instruction(w.BinWriter, opcode.JMPIF, []byte{3}) // 4
+		emit.Opcodes(w.BinWriter, opcode.RET)                  // 6
+		emit.Opcodes(w.BinWriter, opcode.PUSH1, opcode.RET)    // 7, 8
+		require.NoError(t, w.Err)
+
+		issues, err := Verify(w.Bytes(), nil)
+		require.NoError(t, err)
+		requireIssue(t, issues, true, 6, "inconsistent stack depth at RET")
+	})
+}
+
+func TestVerifyTry(t *testing.T) {
+	t.Run("ENDTRY without TRY", func(t *testing.T) {
+		w := io.NewBufBinWriter()
+		emit.Instruction(w.BinWriter, opcode.ENDTRY, []byte{2}) // 0
+		emit.Opcodes(w.BinWriter, opcode.RET)                   // 2
+		require.NoError(t, w.Err)
+
+		issues, err := Verify(w.Bytes(), nil)
+		require.NoError(t, err)
+		requireIssue(t, issues, true, 0, "ENDTRY outside of TRY block")
+	})
+	t.Run("RET inside TRY", func(t *testing.T) {
+		w := io.NewBufBinWriter()
+		emit.Instruction(w.BinWriter, opcode.TRY, []byte{4, 0}) // 0
+		emit.Opcodes(w.BinWriter, opcode.RET)                   // 3
+		emit.Opcodes(w.BinWriter, opcode.DROP)                  // 4
+		emit.Instruction(w.BinWriter, opcode.ENDTRY, []byte{2}) // 5
+		emit.Opcodes(w.BinWriter, opcode.RET)                   // 7
+		require.NoError(t, w.Err)
+
+		issues, err := Verify(w.Bytes(), nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(issues), issues)
+		requireIssue(t, issues, false, 3, "RET inside TRY block")
+	})
+	t.Run("conditional TRY", func(t *testing.T) {
+		// This is the way compiler handles `defer` statements.
+		w := io.NewBufBinWriter()
+		emit.Opcodes(w.BinWriter, opcode.PUSH1)                 // 0
+		emit.Instruction(w.BinWriter, opcode.JMPIF, []byte{5})  // 1
+		emit.Instruction(w.BinWriter, opcode.TRY, []byte{8, 0}) // 3
+		emit.Opcodes(w.BinWriter, opcode.PUSH1)                 // 6
+		emit.Instruction(w.BinWriter, opcode.JMPIF, []byte{4})  // 7
+		emit.Instruction(w.BinWriter, opcode.ENDTRY, []byte{2}) // 9
+		emit.Opcodes(w.BinWriter, opcode.RET)                   // 11
+		require.NoError(t, w.Err)
+
+		issues, err := Verify(w.Bytes(), nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, len(issues), issues)
+	})
+}
+
+func TestVerifyCallFlags(t *testing.T) {
+	w := io.NewBufBinWriter()
+	emit.Instruction(w.BinWriter, opcode.CALL, []byte{3})           // 0
+	emit.Opcodes(w.BinWriter, opcode.RET)                           // 2
+	emit.Syscall(w.BinWriter, interopnames.SystemStorageGetContext) // 3
+	emit.Opcodes(w.BinWriter, opcode.PUSH1, opcode.PUSH2)           // 8, 9
+	emit.Syscall(w.BinWriter, interopnames.SystemStoragePut)        // 10
+	emit.Opcodes(w.BinWriter, opcode.RET)                           // 15
+	require.NoError(t, w.Err)
+	script := w.Bytes()
+
+	m := testManifest(
+		manifest.Method{Name: "main", Offset: 0, ReturnType: smartcontract.VoidType, Safe: true},
+		manifest.Method{Name: "put", Offset: 3, ReturnType: smartcontract.VoidType, Safe: true},
+	)
+	issues, err := Verify(script, &VerifyOptions{Manifest: m, Syscalls: testSyscalls})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(issues), issues)
+	requireIssue(t, issues, true, 0, "called method requires")
+	requireIssue(t, issues, true, 10, "System.Storage.Put requires WriteStates")
+
+	m.ABI.Methods[0].Safe = false
+	m.ABI.Methods[1].Safe = false
+	issues, err = Verify(script, &VerifyOptions{Manifest: m, Syscalls: testSyscalls})
+	require.NoError(t, err)
+	require.Equal(t, 0, len(issues), issues)
+
+	t.Run("unknown syscall", func(t *testing.T) {
+		w := io.NewBufBinWriter()
+		emit.Syscall(w.BinWriter, interopnames.SystemRuntimeLog)
+		emit.Opcodes(w.BinWriter, opcode.RET)
+		require.NoError(t, w.Err)
+		issues, err := Verify(w.Bytes(), &VerifyOptions{Syscalls: testSyscalls})
+		require.NoError(t, err)
+		requireIssue(t, issues, true, 0, "unknown syscall")
+	})
+}
+
+func TestVerifyManifest(t *testing.T) {
+	t.Run("unreachable code", func(t *testing.T) {
+		script := []byte{byte(opcode.RET), byte(opcode.PUSH1), byte(opcode.DROP), byte(opcode.RET)}
+		m := testManifest(manifest.Method{Name: "main", ReturnType: smartcontract.VoidType})
+		issues, err := Verify(script, &VerifyOptions{Manifest: m})
+		require.NoError(t, err)
+		require.Equal(t, 1, len(issues), issues)
+		requireIssue(t, issues, false, 1, "unreachable code at 1-3")
+	})
+	t.Run("argument count", func(t *testing.T) {
+		script := []byte{byte(opcode.INITSLOT), 0, 2, byte(opcode.RET)}
+		m := testManifest(manifest.Method{Name: "main", ReturnType: smartcontract.VoidType})
+		issues, err := Verify(script, &VerifyOptions{Manifest: m})
+		require.NoError(t, err)
+		requireIssue(t, issues, true, 0, "method takes 2 argument(s) while it has 0 parameter(s)")
+	})
+	t.Run("_deploy", func(t *testing.T) {
+		script := []byte{byte(opcode.DROP), byte(opcode.RET)}
+		m := testManifest(manifest.Method{
+			Name:       manifest.MethodDeploy,
+			Parameters: []manifest.Parameter{manifest.NewParameter("data", smartcontract.AnyType)},
+			ReturnType: smartcontract.VoidType,
+		})
+		issues, err := Verify(script, &VerifyOptions{Manifest: m})
+		require.NoError(t, err)
+		requireIssue(t, issues, true, 0, "_deploy method should have (Any, Boolean) parameters")
+	})
+	t.Run("verify", func(t *testing.T) {
+		script := []byte{byte(opcode.PUSH1), byte(opcode.RET)}
+		m := testManifest(manifest.Method{Name: manifest.MethodVerify, ReturnType: smartcontract.IntegerType})
+		issues, err := Verify(script, &VerifyOptions{Manifest: m})
+		require.NoError(t, err)
+		requireIssue(t, issues, true, 0, "verify method should return Boolean")
+	})
+	t.Run("bad method offset", func(t *testing.T) {
+		script := []byte{byte(opcode.PUSHINT8), 1, byte(opcode.RET)}
+		m := testManifest(manifest.Method{Name: "main", Offset: 1, ReturnType: smartcontract.IntegerType})
+		_, err := Verify(script, &VerifyOptions{Manifest: m})
+		require.Error(t, err)
+	})
+}
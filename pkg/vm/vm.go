@@ -43,14 +43,15 @@ func newError(ip int, op opcode.Opcode, err interface{}) *errorAtInstruct {
 type StateMessage string
 
 const (
-	// MaxInvocationStackSize is the maximum size of an invocation stack.
+	// MaxInvocationStackSize is the default maximum size of an invocation
+	// stack (see Limits).
 	MaxInvocationStackSize = 1024
 
-	// MaxTryNestingDepth is the maximum level of TRY nesting allowed,
+	// MaxTryNestingDepth is the default maximum level of TRY nesting allowed,
 	// that is you can't have more exception handling contexts than this.
 	MaxTryNestingDepth = 16
 
-	// MaxStackSize is the maximum number of items allowed to be
+	// MaxStackSize is the default maximum number of items allowed to be
 	// on all stacks at once.
 	MaxStackSize = 2 * 1024
 
@@ -85,6 +86,9 @@ type VM struct {
 
 	trigger trigger.Type
 
+	// limits are execution limits used by this VM.
+	limits Limits
+
 	// invTree is a top-level invocation tree (if enabled).
 	invTree *InvocationTree
 }
@@ -101,6 +105,7 @@ func NewWithTrigger(t trigger.Type) *VM {
 	vm := &VM{
 		state:   NoneState,
 		trigger: t,
+		limits:  DefaultLimits(),
 
 		SyscallHandler: defaultSyscallHandler,
 	}
@@ -111,6 +116,17 @@ func NewWithTrigger(t trigger.Type) *VM {
 	return vm
 }
 
+// SetLimits sets execution limits for this VM, it should be done before
+// loading any scripts.
+func (v *VM) SetLimits(l Limits) {
+	v.limits = l
+}
+
+// Limits returns execution limits used by this VM.
+func (v *VM) Limits() Limits {
+	return v.limits
+}
+
 // SetPriceGetter registers the given PriceGetterFunc in v.
 // f accepts vm's Context, current instruction and instruction parameter.
 func (v *VM) SetPriceGetter(f func(opcode.Opcode, []byte) int64) {
@@ -159,49 +175,7 @@ func (v *VM) PrintOps(out io.Writer) {
 			fmt.Fprintf(w, "%d\t%s\tERROR: %s%s\n", ctx.ip, instr, err, cursor)
 			break
 		}
-		var desc = ""
-		if parameter != nil {
-			switch instr {
-			case opcode.JMP, opcode.JMPIF, opcode.JMPIFNOT, opcode.CALL,
-				opcode.JMPEQ, opcode.JMPNE,
-				opcode.JMPGT, opcode.JMPGE, opcode.JMPLE, opcode.JMPLT,
-				opcode.JMPL, opcode.JMPIFL, opcode.JMPIFNOTL, opcode.CALLL,
-				opcode.JMPEQL, opcode.JMPNEL,
-				opcode.JMPGTL, opcode.JMPGEL, opcode.JMPLEL, opcode.JMPLTL,
-				opcode.PUSHA, opcode.ENDTRY, opcode.ENDTRYL:
-				desc = getOffsetDesc(ctx, parameter)
-			case opcode.TRY, opcode.TRYL:
-				catchP, finallyP := getTryParams(instr, parameter)
-				desc = fmt.Sprintf("catch %s, finally %s",
-					getOffsetDesc(ctx, catchP), getOffsetDesc(ctx, finallyP))
-			case opcode.INITSSLOT:
-				desc = fmt.Sprint(parameter[0])
-			case opcode.CONVERT, opcode.ISTYPE:
-				typ := stackitem.Type(parameter[0])
-				desc = fmt.Sprintf("%s (%x)", typ, parameter[0])
-			case opcode.INITSLOT:
-				desc = fmt.Sprintf("%d local, %d arg", parameter[0], parameter[1])
-			case opcode.SYSCALL:
-				name, err := interopnames.FromID(GetInteropID(parameter))
-				if err != nil {
-					name = "not found"
-				}
-				desc = fmt.Sprintf("%s (%x)", name, parameter)
-			case opcode.PUSHINT8, opcode.PUSHINT16, opcode.PUSHINT32,
-				opcode.PUSHINT64, opcode.PUSHINT128, opcode.PUSHINT256:
-				val := bigint.FromBytes(parameter)
-				desc = fmt.Sprintf("%d (%x)", val, parameter)
-			case opcode.LDLOC, opcode.STLOC, opcode.LDARG, opcode.STARG, opcode.LDSFLD, opcode.STSFLD:
-				desc = fmt.Sprintf("%d (%x)", parameter[0], parameter)
-			default:
-				if utf8.Valid(parameter) {
-					desc = fmt.Sprintf("%x (%q)", parameter, parameter)
-				} else {
-					desc = fmt.Sprintf("%x", parameter)
-				}
-			}
-		}
-
+		desc := getInstrDesc(ctx, instr, parameter)
 		fmt.Fprintf(w, "%d\t%s\t%s%s\n", ctx.ip, instr, desc, cursor)
 		if ctx.nextip >= len(ctx.prog) {
 			break
@@ -210,6 +184,52 @@ func (v *VM) PrintOps(out io.Writer) {
 	w.Flush()
 }
 
+// getInstrDesc returns human-readable description of the instruction parameter.
+// ctx must point to the instruction being described.
+func getInstrDesc(ctx *Context, instr opcode.Opcode, parameter []byte) string {
+	if parameter == nil {
+		return ""
+	}
+	switch instr {
+	case opcode.JMP, opcode.JMPIF, opcode.JMPIFNOT, opcode.CALL,
+		opcode.JMPEQ, opcode.JMPNE,
+		opcode.JMPGT, opcode.JMPGE, opcode.JMPLE, opcode.JMPLT,
+		opcode.JMPL, opcode.JMPIFL, opcode.JMPIFNOTL, opcode.CALLL,
+		opcode.JMPEQL, opcode.JMPNEL,
+		opcode.JMPGTL, opcode.JMPGEL, opcode.JMPLEL, opcode.JMPLTL,
+		opcode.PUSHA, opcode.ENDTRY, opcode.ENDTRYL:
+		return getOffsetDesc(ctx, parameter)
+	case opcode.TRY, opcode.TRYL:
+		catchP, finallyP := getTryParams(instr, parameter)
+		return fmt.Sprintf("catch %s, finally %s",
+			getOffsetDesc(ctx, catchP), getOffsetDesc(ctx, finallyP))
+	case opcode.INITSSLOT:
+		return fmt.Sprint(parameter[0])
+	case opcode.CONVERT, opcode.ISTYPE:
+		typ := stackitem.Type(parameter[0])
+		return fmt.Sprintf("%s (%x)", typ, parameter[0])
+	case opcode.INITSLOT:
+		return fmt.Sprintf("%d local, %d arg", parameter[0], parameter[1])
+	case opcode.SYSCALL:
+		name, err := interopnames.FromID(GetInteropID(parameter))
+		if err != nil {
+			name = "not found"
+		}
+		return fmt.Sprintf("%s (%x)", name, parameter)
+	case opcode.PUSHINT8, opcode.PUSHINT16, opcode.PUSHINT32,
+		opcode.PUSHINT64, opcode.PUSHINT128, opcode.PUSHINT256:
+		val := bigint.FromBytes(parameter)
+		return fmt.Sprintf("%d (%x)", val, parameter)
+	case opcode.LDLOC, opcode.STLOC, opcode.LDARG, opcode.STARG, opcode.LDSFLD, opcode.STSFLD:
+		return fmt.Sprintf("%d (%x)", parameter[0], parameter)
+	default:
+		if utf8.Valid(parameter) {
+			return fmt.Sprintf("%x (%q)", parameter, parameter)
+		}
+		return fmt.Sprintf("%x", parameter)
+	}
+}
+
 func getOffsetDesc(ctx *Context, parameter []byte) string {
 	offset, rOffset, err := calcJumpOffset(ctx, parameter)
 	if err != nil {
@@ -552,7 +572,7 @@ func (v *VM) execute(ctx *Context, op opcode.Opcode, parameter []byte) (err erro
 		if errRecover := recover(); errRecover != nil {
 			v.state = FaultState
 			err = newError(ctx.ip, op, errRecover)
-		} else if v.refs > MaxStackSize {
+		} else if int(v.refs) > v.limits.MaxStackSize {
 			v.state = FaultState
 			err = newError(ctx.ip, op, "stack is too big")
 		}
@@ -580,6 +600,9 @@ func (v *VM) execute(ctx *Context, op opcode.Opcode, parameter []byte) (err erro
 		v.estack.PushItem(stackitem.NewBigInteger(big.NewInt(int64(val))))
 
 	case opcode.PUSHDATA1, opcode.PUSHDATA2, opcode.PUSHDATA4:
+		if len(parameter) > v.limits.MaxItemSize {
+			panic(fmt.Sprintf("too big item: %d", len(parameter)))
+		}
 		v.estack.PushItem(stackitem.NewByteArray(parameter))
 
 	case opcode.PUSHA:
@@ -681,7 +704,7 @@ func (v *VM) execute(ctx *Context, op opcode.Opcode, parameter []byte) (err erro
 
 	case opcode.NEWBUFFER:
 		n := toInt(v.estack.Pop().BigInt())
-		if n < 0 || n > stackitem.MaxSize {
+		if n < 0 || n > v.limits.MaxItemSize {
 			panic("invalid size")
 		}
 		v.estack.PushItem(stackitem.NewBuffer(make([]byte, n)))
@@ -713,7 +736,7 @@ func (v *VM) execute(ctx *Context, op opcode.Opcode, parameter []byte) (err erro
 		b := v.estack.Pop().Bytes()
 		a := v.estack.Pop().Bytes()
 		l := len(a) + len(b)
-		if l > stackitem.MaxSize {
+		if l > v.limits.MaxItemSize {
 			panic(fmt.Sprintf("too big item: %d", l))
 		}
 		ab := make([]byte, l)
@@ -875,7 +898,8 @@ func (v *VM) execute(ctx *Context, op opcode.Opcode, parameter []byte) (err erro
 		}
 		b := v.estack.Pop()
 		a := v.estack.Pop()
-		res := stackitem.Bool(a.value.Equals(b.value) == (op == opcode.EQUAL))
+		eq := stackitem.EqualsWithLimits(a.value, b.value, v.limits.MaxComparableSize, v.limits.MaxComparableNumOfItems)
+		res := stackitem.Bool(eq == (op == opcode.EQUAL))
 		v.estack.PushItem(res)
 
 	// Numeric operations.
@@ -1048,7 +1072,7 @@ func (v *VM) execute(ctx *Context, op opcode.Opcode, parameter []byte) (err erro
 
 	case opcode.NEWARRAY, opcode.NEWARRAYT, opcode.NEWSTRUCT:
 		n := toInt(v.estack.Pop().BigInt())
-		if n < 0 || n > MaxStackSize {
+		if n < 0 || n > v.limits.MaxStackSize {
 			panic("wrong number of elements")
 		}
 		typ := stackitem.AnyT
@@ -1487,7 +1511,7 @@ func (v *VM) execute(ctx *Context, op opcode.Opcode, parameter []byte) (err erro
 
 	case opcode.TRY, opcode.TRYL:
 		catchP, finallyP := getTryParams(op, parameter)
-		if ctx.tryStack.Len() >= MaxTryNestingDepth {
+		if ctx.tryStack.Len() >= v.limits.MaxTryNestingDepth {
 			panic("maximum TRY depth exceeded")
 		}
 		cOffset := getJumpOffset(ctx, catchP)
@@ -1834,7 +1858,7 @@ func validateMapKey(key Element) {
 }
 
 func (v *VM) checkInvocationStackSize() {
-	if v.istack.Len() >= MaxInvocationStackSize {
+	if v.istack.Len() >= v.limits.MaxInvocationStackSize {
 		panic("invocation stack is too big")
 	}
 }